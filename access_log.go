@@ -0,0 +1,121 @@
+package go11y
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// AccessLogFormat is the output format of the access log middleware
+type AccessLogFormat int
+
+const (
+	// AccessLogJSON logs each request as a structured go11y record at Info level
+	AccessLogJSON AccessLogFormat = iota
+	// AccessLogCombined writes each request as an Apache/NCSA combined log format line
+	AccessLogCombined
+)
+
+// AccessLogMessage is the message of the structured access log records
+const AccessLogMessage = "access"
+
+// AccessLogMiddlewareMuxOpts are the options used to initialise the access log middleware for a mux.Router
+type AccessLogMiddlewareMuxOpts struct {
+	Format AccessLogFormat // optional - defaults to AccessLogJSON
+	Output io.Writer       // optional - where AccessLogCombined lines are written, defaults to os.Stdout
+}
+
+// AccessLogMiddlewareMux returns a middleware that writes one access log entry per request with the status code,
+// bytes written, duration, referer and user agent. Unlike the Debug level records of RequestLoggerMiddlewareMux, the
+// JSON format is logged at Info level so it survives in production.
+// Query parameters matching the redaction policy are redacted from the logged request URI.
+// If the Observer cannot be retrieved from the provided context, an error is returned.
+func AccessLogMiddlewareMux(ctxWithObserver context.Context, opts AccessLogMiddlewareMuxOpts) (accessLogMiddleware mux.MiddlewareFunc, fault error) {
+	_, o, err := Get(ctxWithObserver)
+	if err != nil {
+		return nil, fmt.Errorf("could not get go11y observer from context: %w", err)
+	}
+
+	if opts.Output == nil {
+		opts.Output = os.Stdout
+	}
+
+	mw := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t0 := o.clock.Now()
+
+			mrw := newMiddlewareResponseWriter(w)
+			next.ServeHTTP(mrw, r)
+
+			duration := o.clock.Since(t0)
+
+			if opts.Format == AccessLogCombined {
+				_, _ = io.WriteString(opts.Output, combinedLogLine(r, mrw.StatusCode(), mrw.BytesWritten(), t0))
+				return
+			}
+
+			o.log(r.Context(), 3, LevelInfo, AccessLogMessage,
+				FieldRequestID, GetRequestID(r.Context()),
+				FieldRequestMethod, r.Method,
+				FieldRequestURL, RedactURL(r.URL),
+				FieldStatusCode, mrw.StatusCode(),
+				FieldResponseSize, mrw.BytesWritten(),
+				FieldRequestDuration, duration.Milliseconds(),
+				FieldClientIP, clientIP(r),
+				FieldReferer, r.Referer(),
+				FieldUserAgent, r.UserAgent(),
+			)
+		})
+	}
+
+	return mw, nil
+}
+
+// combinedLogLine formats a request in the Apache/NCSA combined log format:
+// %h %l %u %t "%r" %>s %b "%{Referer}i" "%{User-agent}i"
+func combinedLogLine(r *http.Request, status int, size int64, start time.Time) string {
+	user := "-"
+	if u, _, ok := r.BasicAuth(); ok && u != "" {
+		user = u
+	}
+
+	bytes := "-"
+	if size > 0 {
+		bytes = strconv.FormatInt(size, 10)
+	}
+
+	// only the request URI belongs in the request line, even when the request was made in absolute form
+	uri := RedactURL(&url.URL{Path: r.URL.Path, RawPath: r.URL.RawPath, RawQuery: r.URL.RawQuery})
+	if uri == "" {
+		uri = "/"
+	}
+
+	return fmt.Sprintf("%s - %s [%s] %s %d %s %s %s\n",
+		clientIP(r),
+		user,
+		start.Format("02/Jan/2006:15:04:05 -0700"),
+		strconv.Quote(fmt.Sprintf("%s %s %s", r.Method, uri, r.Proto)),
+		status,
+		bytes,
+		strconv.Quote(r.Referer()),
+		strconv.Quote(r.UserAgent()),
+	)
+}
+
+// clientIP returns the host part of the request's remote address
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
@@ -0,0 +1,94 @@
+package go11y_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/cirruscomms/go11y"
+)
+
+func TestAccessLogMiddlewareMux(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("hello"))
+	})
+
+	newRequest := func() *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/things?token=supersecretvalue&page=2", nil)
+		req.RemoteAddr = "192.0.2.10:51234"
+		req.Header.Set("Referer", "https://example.com/")
+		req.Header.Set("User-Agent", "go11y-test/1.0")
+		return req
+	}
+
+	t.Run("combined", func(t *testing.T) {
+		cfg := go11y.CreateConfig(go11y.LevelInfo, "", "", "", []string{}, []string{})
+		ctx, _, err := go11y.Initialise(context.Background(), cfg, io.Discard, io.Discard)
+		if err != nil {
+			t.Fatalf("failed to initialise observer: %v", err)
+		}
+
+		out := &bytes.Buffer{}
+		mw, err := go11y.AccessLogMiddlewareMux(ctx, go11y.AccessLogMiddlewareMuxOpts{Format: go11y.AccessLogCombined, Output: out})
+		if err != nil {
+			t.Fatalf("failed to create middleware: %v", err)
+		}
+
+		mw(handler).ServeHTTP(httptest.NewRecorder(), newRequest())
+
+		rex := regexp.MustCompile(`^192\.0\.2\.10 - - \[[^\]]+\] "POST /things\?token=[^ &]+&page=2 HTTP/1\.1" 201 5 "https://example.com/" "go11y-test/1\.0"\n$`)
+		if !rex.MatchString(out.String()) {
+			t.Errorf("unexpected combined log line: %q", out.String())
+		}
+
+		if bytes.Contains(out.Bytes(), []byte("supersecretvalue")) {
+			t.Errorf("expected token to be redacted: %q", out.String())
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		cfg := go11y.CreateConfig(go11y.LevelInfo, "", "", "", []string{}, []string{})
+		ctx, _, err := go11y.Initialise(context.Background(), cfg, out, io.Discard)
+		if err != nil {
+			t.Fatalf("failed to initialise observer: %v", err)
+		}
+
+		mw, err := go11y.AccessLogMiddlewareMux(ctx, go11y.AccessLogMiddlewareMuxOpts{})
+		if err != nil {
+			t.Fatalf("failed to create middleware: %v", err)
+		}
+
+		mw(handler).ServeHTTP(httptest.NewRecorder(), newRequest())
+
+		record := map[string]any{}
+		if err := json.Unmarshal(out.Bytes(), &record); err != nil {
+			t.Fatalf("failed to parse access log record %q: %v", out.String(), err)
+		}
+
+		expected := map[string]any{
+			"msg":                    go11y.AccessLogMessage,
+			go11y.FieldStatusCode:    float64(http.StatusCreated),
+			go11y.FieldResponseSize:  float64(5),
+			go11y.FieldClientIP:      "192.0.2.10",
+			go11y.FieldReferer:       "https://example.com/",
+			go11y.FieldUserAgent:     "go11y-test/1.0",
+			go11y.FieldRequestMethod: http.MethodPost,
+		}
+		for key, value := range expected {
+			if record[key] != value {
+				t.Errorf("expected %s to be %v, got %v", key, value, record[key])
+			}
+		}
+
+		if _, ok := record[go11y.FieldRequestDuration]; !ok {
+			t.Errorf("expected %s to be logged", go11y.FieldRequestDuration)
+		}
+	})
+}
@@ -0,0 +1,174 @@
+package go11y
+
+import (
+	"errors"
+	"io"
+	"sync"
+)
+
+// BackpressurePolicy determines what an AsyncWriter does when its buffer is full.
+type BackpressurePolicy int
+
+const (
+	// DropOldest discards the oldest buffered record to make room for the new one, so writers never block
+	DropOldest BackpressurePolicy = iota
+	// DropNewest discards the new record when the buffer is full, so writers never block
+	DropNewest
+	// Block makes the writer wait until there is room in the buffer, so no records are lost
+	Block
+)
+
+// DropReasonBufferFull is the reason label used when an AsyncWriter discards a record because its buffer is full
+const DropReasonBufferFull = "buffer_full"
+
+// ErrWriterClosed is returned when writing to an AsyncWriter that has been closed
+var ErrWriterClosed = errors.New("async writer is closed")
+
+// AsyncWriter is an io.Writer that buffers writes in a fixed-size ring buffer and writes them to the wrapped writer
+// from a background goroutine, so slow outputs (e.g. stdout under journald pressure) don't stall request handling.
+// Each call to Write is treated as one record - slog handlers write one record per call.
+// Call Flush to wait until all buffered records have been written and Close to flush and stop the background
+// goroutine. The Observer flushes its outputs when it is closed.
+type AsyncWriter struct {
+	out      io.Writer
+	policy   BackpressurePolicy
+	buffer   [][]byte
+	head     int // index of the oldest record
+	count    int // number of buffered records
+	writing  bool
+	closed   bool
+	mu       sync.Mutex
+	notEmpty *sync.Cond
+	notFull  *sync.Cond
+	idle     *sync.Cond
+	done     chan struct{}
+}
+
+// NewAsyncWriter creates a new AsyncWriter wrapping $out, buffering up to $size records and applying $policy when the
+// buffer is full. A size below 1 is treated as 1.
+func NewAsyncWriter(out io.Writer, size int, policy BackpressurePolicy) *AsyncWriter {
+	if size < 1 {
+		size = 1
+	}
+
+	w := &AsyncWriter{
+		out:    out,
+		policy: policy,
+		buffer: make([][]byte, size),
+		done:   make(chan struct{}),
+	}
+
+	w.notEmpty = sync.NewCond(&w.mu)
+	w.notFull = sync.NewCond(&w.mu)
+	w.idle = sync.NewCond(&w.mu)
+
+	go w.run()
+
+	return w
+}
+
+// Write copies $p into the buffer. It only blocks if the policy is Block and the buffer is full.
+func (w *AsyncWriter) Write(p []byte) (int, error) {
+	record := make([]byte, len(p))
+	copy(record, p)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return 0, ErrWriterClosed
+	}
+
+	for w.count == len(w.buffer) {
+		switch w.policy {
+		case DropNewest:
+			LogRecordsDropped.WithLabelValues("unknown", DropReasonBufferFull).Inc()
+			return len(p), nil
+		case DropOldest:
+			w.buffer[w.head] = nil
+			w.head = (w.head + 1) % len(w.buffer)
+			w.count--
+			LogRecordsDropped.WithLabelValues("unknown", DropReasonBufferFull).Inc()
+		default:
+			w.notFull.Wait()
+			if w.closed {
+				return 0, ErrWriterClosed
+			}
+		}
+	}
+
+	w.buffer[(w.head+w.count)%len(w.buffer)] = record
+	w.count++
+	w.notEmpty.Signal()
+
+	return len(p), nil
+}
+
+// Flush blocks until every record buffered so far has been written to the wrapped writer.
+func (w *AsyncWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for w.count > 0 || w.writing {
+		w.idle.Wait()
+	}
+
+	return nil
+}
+
+// Close flushes the buffer and stops the background goroutine. Writes after Close return ErrWriterClosed.
+// The wrapped writer is not closed.
+func (w *AsyncWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+
+	w.closed = true
+	w.notEmpty.Broadcast()
+	w.notFull.Broadcast()
+	w.mu.Unlock()
+
+	<-w.done
+
+	return nil
+}
+
+func (w *AsyncWriter) run() {
+	defer close(w.done)
+
+	for {
+		w.mu.Lock()
+		for w.count == 0 && !w.closed {
+			w.idle.Broadcast()
+			w.notEmpty.Wait()
+		}
+
+		if w.count == 0 && w.closed {
+			w.idle.Broadcast()
+			w.mu.Unlock()
+			return
+		}
+
+		record := w.buffer[w.head]
+		w.buffer[w.head] = nil
+		w.head = (w.head + 1) % len(w.buffer)
+		w.count--
+		w.writing = true
+		w.notFull.Signal()
+		w.mu.Unlock()
+
+		if _, err := w.out.Write(record); err != nil {
+			LogHandlerErrors.Inc()
+			LogRecordsDropped.WithLabelValues("unknown", DropReasonHandlerError).Inc()
+		}
+
+		w.mu.Lock()
+		w.writing = false
+		if w.count == 0 {
+			w.idle.Broadcast()
+		}
+		w.mu.Unlock()
+	}
+}
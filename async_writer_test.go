@@ -0,0 +1,98 @@
+package go11y_test
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cirruscomms/go11y"
+)
+
+// slowWriter blocks every write until it is released
+type slowWriter struct {
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	release chan struct{}
+}
+
+func (w *slowWriter) Write(p []byte) (int, error) {
+	<-w.release
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.buf.Write(p)
+}
+
+func (w *slowWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.buf.String()
+}
+
+func TestAsyncWriter(t *testing.T) {
+	testCases := map[string]struct {
+		policy   go11y.BackpressurePolicy
+		expected string
+	}{
+		"drop oldest keeps the newest records": {
+			policy:   go11y.DropOldest,
+			expected: "0\n3\n4\n",
+		},
+		"drop newest keeps the oldest records": {
+			policy:   go11y.DropNewest,
+			expected: "0\n1\n2\n",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			out := &slowWriter{release: make(chan struct{})}
+			w := go11y.NewAsyncWriter(out, 2, tc.policy)
+
+			// the first record is picked up by the background goroutine and blocks there
+			_, _ = w.Write([]byte("0\n"))
+			time.Sleep(50 * time.Millisecond)
+
+			for i := 1; i < 5; i++ {
+				_, _ = fmt.Fprintf(w, "%d\n", i)
+			}
+
+			close(out.release)
+
+			if err := w.Close(); err != nil {
+				t.Fatalf("failed to close writer: %v", err)
+			}
+
+			if out.String() != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, out.String())
+			}
+
+			if _, err := w.Write([]byte("late\n")); err != go11y.ErrWriterClosed {
+				t.Errorf("expected ErrWriterClosed, got %v", err)
+			}
+		})
+	}
+}
+
+func TestAsyncWriterBlock(t *testing.T) {
+	out := &bytes.Buffer{}
+	w := go11y.NewAsyncWriter(out, 1, go11y.Block)
+
+	for i := range 100 {
+		_, _ = fmt.Fprintf(w, "%d\n", i)
+	}
+
+	if err := w.Flush(); err != nil {
+		t.Fatalf("failed to flush writer: %v", err)
+	}
+
+	if bytes.Count(out.Bytes(), []byte("\n")) != 100 {
+		t.Errorf("expected 100 records, got %q", out.String())
+	}
+
+	_ = w.Close()
+}
@@ -0,0 +1,93 @@
+package go11y
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// Bridge is the hook through which adapters for other logging libraries write to an Observer, such as those in
+// github.com/cirruscomms/go11y/bridge/zapcore and github.com/cirruscomms/go11y/bridge/zerologw, see Observer.Bridge
+type Bridge struct {
+	o           *Observer
+	pkgPrefixes []string
+}
+
+// Bridge returns the hook for an adapter of another logging library. $pkgPrefixes are the packages of the library and
+// of the adapter, e.g. "go.uber.org/zap", skipped when finding the source of a record.
+func (o *Observer) Bridge(pkgPrefixes ...string) Bridge {
+	return Bridge{o: o, pkgPrefixes: pkgPrefixes}
+}
+
+// Enabled reports whether a record logged at $level would be written
+func (b Bridge) Enabled(level slog.Level) bool {
+	return b.o.bridgeEnabled(level)
+}
+
+// Log logs a record of the bridged library as if it had been logged with the Observer's own methods, see bridged
+func (b Bridge) Log(level slog.Level, msg string, args ...any) {
+	b.o.bridged(level, msg, args, b.pkgPrefixes...)
+}
+
+// Flush flushes the Observer's outputs, e.g. when the bridged library syncs its logger
+func (b Bridge) Flush() {
+	b.o.flushOutputs()
+}
+
+// bridged logs a record written through another logging library (see Bridge and Logr) as if it had been logged with
+// the Observer's own methods: it is routed to the log or error output by level, the span's trace and span IDs are
+// added when they aren't already stable arguments, and the record is added to the span as an event.
+// $pkgPrefixes are the packages of the bridged library, skipped when finding the source of the record.
+func (o *Observer) bridged(level slog.Level, msg string, args []any, pkgPrefixes ...string) {
+	logger := o.logger(level)
+
+	span := o.currentSpan()
+
+	if span != nil && span.SpanContext().IsValid() && !slices.Contains(o.stableArgs, any(FieldTraceID)) {
+		args = append(args, FieldTraceID, span.SpanContext().TraceID(), FieldSpanID, span.SpanContext().SpanID())
+	}
+
+	var pc uintptr
+	if o.sourceMode != SourceOff {
+		pc = bridgeCaller(pkgPrefixes)
+	}
+
+	if !o.handle(context.Background(), logger, pc, level, msg, args...) {
+		return
+	}
+
+	o.addSpanEvent(span, level, msg, args)
+
+	if span != nil && level >= LevelError {
+		o.failSpan(msg)
+	}
+}
+
+// bridgeEnabled reports whether a record bridged at $level would be logged
+func (o *Observer) bridgeEnabled(level slog.Level) bool {
+	logger := o.logger(level)
+
+	return logger != nil && logger.Enabled(context.Background(), level)
+}
+
+// bridgeCaller returns the program counter of the first caller outside go11y and the packages in $pkgPrefixes, so the
+// source of a bridged record is the code that called the bridged library
+func bridgeCaller(pkgPrefixes []string) (pc uintptr) {
+	var pcs [32]uintptr
+	// skip [runtime.Callers, this function]
+	n := runtime.Callers(2, pcs[:])
+
+	for _, pc := range pcs[:n] {
+		frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+
+		bridge := strings.HasPrefix(frame.Function, "github.com/cirruscomms/go11y.") ||
+			slices.ContainsFunc(pkgPrefixes, func(p string) bool { return strings.HasPrefix(frame.Function, p) })
+		if !bridge {
+			return pc
+		}
+	}
+
+	return 0
+}
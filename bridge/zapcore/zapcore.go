@@ -0,0 +1,102 @@
+// Package zapcore provides a zapcore.Core that writes zap records to a go11y Observer, so code still using zap logs to
+// the same outputs, with the same stable arguments and span correlation, as code using go11y during a migration.
+package zapcore
+
+import (
+	"log/slog"
+	"maps"
+	"slices"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/cirruscomms/go11y"
+)
+
+// core is a zapcore.Core that writes to an Observer, see New
+type core struct {
+	bridge go11y.Bridge
+	fields []zapcore.Field
+}
+
+// New returns a zapcore.Core that writes zap records to $o, e.g. with zap.New(zapcore.New(o)).
+// zap's levels are mapped to go11y's: DPanic and Panic to LevelPanic and Fatal to LevelFatal. zap itself still panics
+// or exits after writing those records. The logger name, if set, is logged as the "logger" field.
+func New(o *go11y.Observer) zapcore.Core {
+	return &core{bridge: o.Bridge("go.uber.org/zap", "github.com/cirruscomms/go11y/bridge/zapcore.")}
+}
+
+// Enabled reports whether the Observer logs records at $level
+func (c *core) Enabled(level zapcore.Level) bool {
+	return c.bridge.Enabled(toLevel(level))
+}
+
+// With returns a Core that adds $fields to every record
+func (c *core) With(fields []zapcore.Field) zapcore.Core {
+	return &core{bridge: c.bridge, fields: slices.Concat(c.fields, fields)}
+}
+
+// Check adds the Core to $checked if it is enabled for the level of $entry
+func (c *core) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+
+	return checked
+}
+
+// Write logs $entry with the Core's fields and $fields to the Observer
+func (c *core) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range slices.Concat(c.fields, fields) {
+		f.AddTo(enc)
+	}
+
+	args := make([]any, 0, 2*len(enc.Fields)+2)
+	if entry.LoggerName != "" {
+		args = append(args, "logger", entry.LoggerName)
+	}
+
+	// the encoder's fields are a map, so they are sorted to keep the output stable
+	for _, k := range slices.Sorted(maps.Keys(enc.Fields)) {
+		args = append(args, k, enc.Fields[k])
+	}
+
+	if entry.Stack != "" {
+		args = append(args, "stack", entry.Stack)
+	}
+
+	c.bridge.Log(toLevel(entry.Level), entry.Message, args...)
+
+	return nil
+}
+
+// Sync flushes the Observer's outputs
+func (c *core) Sync() error {
+	c.bridge.Flush()
+
+	return nil
+}
+
+// toLevel maps a zap level to the go11y level
+func toLevel(level zapcore.Level) slog.Level {
+	switch level {
+	case zapcore.DebugLevel:
+		return go11y.LevelDebug
+	case zapcore.InfoLevel:
+		return go11y.LevelInfo
+	case zapcore.WarnLevel:
+		return go11y.LevelWarning
+	case zapcore.ErrorLevel:
+		return go11y.LevelError
+	case zapcore.DPanicLevel, zapcore.PanicLevel:
+		return go11y.LevelPanic
+	case zapcore.FatalLevel:
+		return go11y.LevelFatal
+	default:
+		if level < zapcore.DebugLevel {
+			return go11y.LevelDevelop
+		}
+
+		return go11y.LevelInfo
+	}
+}
@@ -0,0 +1,96 @@
+package zapcore_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+	otelSDKTrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/zap"
+
+	"github.com/cirruscomms/go11y"
+	"github.com/cirruscomms/go11y/bridge/zapcore"
+)
+
+func decodeRecords(t *testing.T, buf *bytes.Buffer) (records []map[string]any) {
+	t.Helper()
+
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		record := map[string]any{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("failed to decode record %q: %v", line, err)
+		}
+		records = append(records, record)
+	}
+
+	return records
+}
+func TestZapCore(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := otelSDKTrace.NewTracerProvider(otelSDKTrace.WithSpanProcessor(recorder))
+
+	out, errOut := new(bytes.Buffer), new(bytes.Buffer)
+	cfg := go11y.NewConfig(go11y.WithLogLevel(go11y.LevelInfo))
+
+	ctx, _, err := go11y.Initialise(context.Background(), cfg, out, errOut, "service", "bridge")
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	_, o, err := go11y.Span(ctx, tp.Tracer("test"), "TestZapCore", go11y.SpanKindInternal)
+	if err != nil {
+		t.Fatalf("failed to start span: %v", err)
+	}
+	out.Reset()
+
+	logger := zap.New(zapcore.New(o)).Named("legacy").With(zap.String("component", "billing"))
+	logger.Debug("not logged")
+	logger.Info("invoice sent", zap.Int("invoice", 42))
+	logger.Error("invoice failed", zap.Error(errors.New("declined")))
+	o.End()
+
+	records := decodeRecords(t, out)
+	if len(records) != 1 {
+		t.Fatalf("expected 1 record in the output, got %d: %s", len(records), out.String())
+	}
+
+	info := records[0]
+	expected := map[string]any{
+		"msg":       "invoice sent",
+		"level":     "INFO",
+		"logger":    "legacy",
+		"component": "billing",
+		"invoice":   float64(42),
+		"service":   "bridge",
+	}
+	for k, v := range expected {
+		if info[k] != v {
+			t.Errorf("expected %s to be %v, got %v", k, v, info[k])
+		}
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 ended span, got %d", len(spans))
+	}
+	if info[go11y.FieldTraceID] != spans[0].SpanContext().TraceID().String() {
+		t.Errorf("expected the record to be correlated with the span, got trace ID %v", info[go11y.FieldTraceID])
+	}
+	source, _ := info["source"].(map[string]any)
+	if source["function"] != "github.com/cirruscomms/go11y/bridge/zapcore_test.TestZapCore" {
+		t.Errorf("expected the source to be the caller of zap, got %v", info["source"])
+	}
+	if spans[0].Status().Code != codes.Error {
+		t.Errorf("expected the zap error to fail the span, got %v", spans[0].Status().Code)
+	}
+
+	errRecords := decodeRecords(t, errOut)
+	if last := errRecords[len(errRecords)-1]; last["msg"] != "invoice failed" || last["error"] != "declined" {
+		t.Errorf("expected the zap error in the error output, got %v", last)
+	}
+}
@@ -0,0 +1,87 @@
+// Package zerologw provides a zerolog.LevelWriter that writes zerolog records to a go11y Observer, so code still using
+// zerolog logs to the same outputs, with the same stable arguments and span correlation, as code using go11y during a
+// migration.
+package zerologw
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"maps"
+	"slices"
+
+	"github.com/rs/zerolog"
+
+	"github.com/cirruscomms/go11y"
+)
+
+// Writer is a zerolog.LevelWriter that writes to an Observer, see New
+type Writer struct {
+	bridge go11y.Bridge
+}
+
+// New returns a zerolog.LevelWriter that writes zerolog records to $o, e.g. with zerolog.New(zerologw.New(o)).
+// zerolog's level, message and timestamp fields are replaced by go11y's and its other fields are kept as arguments.
+// zerolog's trace level is mapped to LevelDevelop and records without a level are logged at LevelInfo.
+func New(o *go11y.Observer) *Writer {
+	return &Writer{bridge: o.Bridge("github.com/rs/zerolog", "github.com/cirruscomms/go11y/bridge/zerologw.")}
+}
+
+// Write logs the zerolog record $p at LevelInfo
+func (w *Writer) Write(p []byte) (n int, fault error) {
+	return w.WriteLevel(zerolog.NoLevel, p)
+}
+
+// WriteLevel logs the zerolog record $p at the go11y level mapped from $level
+func (w *Writer) WriteLevel(level zerolog.Level, p []byte) (n int, fault error) {
+	fields := map[string]any{}
+
+	dec := json.NewDecoder(bytes.NewReader(p))
+	dec.UseNumber()
+	if err := dec.Decode(&fields); err != nil {
+		// not a JSON record (e.g. zerolog built with binary_log), so log it as it is
+		w.bridge.Log(toLevel(level), string(bytes.TrimSpace(p)))
+
+		return len(p), nil
+	}
+
+	msg, _ := fields[zerolog.MessageFieldName].(string)
+
+	delete(fields, zerolog.MessageFieldName)
+	delete(fields, zerolog.LevelFieldName)
+	delete(fields, zerolog.TimestampFieldName)
+	// the source of the record is logged by go11y
+	delete(fields, zerolog.CallerFieldName)
+
+	args := make([]any, 0, 2*len(fields))
+	// the record's fields are decoded into a map, so they are sorted to keep the output stable
+	for _, k := range slices.Sorted(maps.Keys(fields)) {
+		args = append(args, k, fields[k])
+	}
+
+	w.bridge.Log(toLevel(level), msg, args...)
+
+	return len(p), nil
+}
+
+// toLevel maps a zerolog level to the go11y level
+func toLevel(level zerolog.Level) slog.Level {
+	switch level {
+	case zerolog.TraceLevel:
+		return go11y.LevelDevelop
+	case zerolog.DebugLevel:
+		return go11y.LevelDebug
+	case zerolog.InfoLevel:
+		return go11y.LevelInfo
+	case zerolog.WarnLevel:
+		return go11y.LevelWarning
+	case zerolog.ErrorLevel:
+		return go11y.LevelError
+	case zerolog.FatalLevel:
+		return go11y.LevelFatal
+	case zerolog.PanicLevel:
+		return go11y.LevelPanic
+	default:
+		return go11y.LevelInfo
+	}
+}
@@ -0,0 +1,70 @@
+package zerologw_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+
+	"github.com/cirruscomms/go11y"
+	"github.com/cirruscomms/go11y/bridge/zerologw"
+)
+
+func decodeRecords(t *testing.T, buf *bytes.Buffer) (records []map[string]any) {
+	t.Helper()
+
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		record := map[string]any{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("failed to decode record %q: %v", line, err)
+		}
+		records = append(records, record)
+	}
+
+	return records
+}
+func TestZerologWriter(t *testing.T) {
+	out, errOut := new(bytes.Buffer), new(bytes.Buffer)
+	cfg := go11y.NewConfig(go11y.WithLogLevel(go11y.LevelInfo))
+
+	_, o, err := go11y.Initialise(context.Background(), cfg, out, errOut)
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+	out.Reset()
+
+	logger := zerolog.New(zerologw.New(o)).With().Timestamp().Str("component", "billing").Logger()
+	logger.Debug().Msg("not logged")
+	logger.Warn().Int("invoice", 42).Msg("invoice late")
+	logger.Error().Err(errors.New("declined")).Msg("invoice failed")
+
+	records := decodeRecords(t, out)
+	if len(records) != 1 {
+		t.Fatalf("expected 1 record in the output, got %d: %s", len(records), out.String())
+	}
+
+	warning := records[0]
+	expected := map[string]any{
+		"msg":       "invoice late",
+		"level":     "WARN",
+		"component": "billing",
+		"invoice":   float64(42),
+	}
+	for k, v := range expected {
+		if warning[k] != v {
+			t.Errorf("expected %s to be %v, got %v", k, v, warning[k])
+		}
+	}
+	if _, ok := warning["message"]; ok {
+		t.Errorf("expected zerolog's message field to be replaced by msg, got %v", warning)
+	}
+
+	errRecords := decodeRecords(t, errOut)
+	if last := errRecords[len(errRecords)-1]; last["msg"] != "invoice failed" || last["error"] != "declined" {
+		t.Errorf("expected the zerolog error in the error output, got %v", last)
+	}
+}
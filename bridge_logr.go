@@ -0,0 +1,82 @@
+package go11y
+
+import (
+	"log/slog"
+	"slices"
+
+	"github.com/go-logr/logr"
+)
+
+// logrSink is a logr.LogSink that writes to an Observer, see Logr
+type logrSink struct {
+	o      *Observer
+	name   string
+	values []any
+}
+
+// Logr returns a logr.Logger that writes to the Observer, for libraries that log with logr such as Kubernetes
+// client-go (klog.SetLogger) and controller-runtime (ctrl.SetLogger), so their logs go to the same outputs with the
+// Observer's stable arguments and span correlation.
+// logr's verbosity levels are mapped to go11y's: V(0) to LevelInfo, V(1) to LevelDebug and V(2) and above to
+// LevelDevelop. The logger's name, if set with WithName, is logged as the "logger" field.
+func (o *Observer) Logr() logr.Logger {
+	return logr.New(&logrSink{o: o})
+}
+
+// Init is part of the logr.LogSink interface, the source of records is found by the Observer
+func (s *logrSink) Init(logr.RuntimeInfo) {}
+
+// Enabled reports whether the Observer logs records at the verbosity $level
+func (s *logrSink) Enabled(level int) bool {
+	return s.o.bridgeEnabled(logrToLevel(level))
+}
+
+// Info logs $msg at the go11y level mapped from the verbosity $level
+func (s *logrSink) Info(level int, msg string, keysAndValues ...any) {
+	s.o.bridged(logrToLevel(level), msg, s.args(keysAndValues), "github.com/go-logr/logr")
+}
+
+// Error logs $msg and $err at LevelError
+func (s *logrSink) Error(err error, msg string, keysAndValues ...any) {
+	args := s.args(keysAndValues)
+	if err != nil {
+		args = append(args, "error", err.Error())
+	}
+
+	s.o.bridged(LevelError, msg, args, "github.com/go-logr/logr")
+}
+
+// WithValues returns a LogSink that adds $keysAndValues to every record
+func (s *logrSink) WithValues(keysAndValues ...any) logr.LogSink {
+	return &logrSink{o: s.o, name: s.name, values: slices.Concat(s.values, keysAndValues)}
+}
+
+// WithName returns a LogSink with $name appended to its name, separated by a "/"
+func (s *logrSink) WithName(name string) logr.LogSink {
+	if s.name != "" {
+		name = s.name + "/" + name
+	}
+
+	return &logrSink{o: s.o, name: name, values: s.values}
+}
+
+// args returns the arguments of a record with $keysAndValues
+func (s *logrSink) args(keysAndValues []any) (args []any) {
+	if s.name != "" {
+		args = append(args, "logger", s.name)
+	}
+
+	return slices.Concat(args, s.values, keysAndValues)
+}
+
+// logrToLevel maps a logr verbosity level to the go11y level
+func logrToLevel(level int) slog.Level {
+	switch {
+	case level <= 0:
+		return LevelInfo
+	case level == 1:
+		return LevelDebug
+	default:
+		return LevelDevelop
+	}
+}
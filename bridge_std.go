@@ -0,0 +1,35 @@
+package go11y
+
+import (
+	"bytes"
+	"log"
+	"log/slog"
+)
+
+// stdLogWriter is the writer of a StdLogger, logging each line written to it as a record
+type stdLogWriter struct {
+	o     *Observer
+	level slog.Level
+}
+
+// StdLogger returns a *log.Logger that logs every line written to it as a record at $level, for libraries that only
+// accept a *log.Logger such as http.Server's ErrorLog. The logger has no prefix or flags, as the record has its own
+// time and source.
+func (o *Observer) StdLogger(level slog.Level) *log.Logger {
+	return log.New(&stdLogWriter{o: o, level: level}, "", 0)
+}
+
+// Write logs each line of $p as a record, log.Logger writes a single line per call
+func (w *stdLogWriter) Write(p []byte) (n int, fault error) {
+	if !w.o.bridgeEnabled(w.level) {
+		return len(p), nil
+	}
+
+	for _, line := range bytes.Split(p, []byte("\n")) {
+		if line = bytes.TrimRight(line, "\r"); len(line) != 0 {
+			w.o.bridged(w.level, string(line), nil, "log.")
+		}
+	}
+
+	return len(p), nil
+}
@@ -0,0 +1,104 @@
+package go11y_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/cirruscomms/go11y"
+)
+
+func decodeRecords(t *testing.T, buf *bytes.Buffer) (records []map[string]any) {
+	t.Helper()
+
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		record := map[string]any{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("failed to decode record %q: %v", line, err)
+		}
+		records = append(records, record)
+	}
+
+	return records
+}
+
+func TestLogr(t *testing.T) {
+	out, errOut := new(bytes.Buffer), new(bytes.Buffer)
+	cfg := go11y.NewConfig(go11y.WithLogLevel(go11y.LevelDebug))
+
+	_, o, err := go11y.Initialise(context.Background(), cfg, out, errOut, "service", "bridge")
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+	out.Reset()
+
+	logger := o.Logr().WithName("controller").WithName("reconciler").WithValues("namespace", "default")
+	logger.Info("reconciling", "pod", "web-0")
+	logger.V(1).Info("cache hit")
+	logger.V(2).Info("not logged")
+	logger.Error(errors.New("conflict"), "reconcile failed")
+
+	records := decodeRecords(t, out)
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records in the output, got %d: %s", len(records), out.String())
+	}
+
+	expected := map[string]any{
+		"msg":       "reconciling",
+		"level":     "INFO",
+		"logger":    "controller/reconciler",
+		"namespace": "default",
+		"pod":       "web-0",
+		"service":   "bridge",
+	}
+	for k, v := range expected {
+		if records[0][k] != v {
+			t.Errorf("expected %s to be %v, got %v", k, v, records[0][k])
+		}
+	}
+
+	source, _ := records[0]["source"].(map[string]any)
+	if source["function"] != "github.com/cirruscomms/go11y_test.TestLogr" {
+		t.Errorf("expected the source to be the caller of logr, got %v", records[0]["source"])
+	}
+
+	if records[1]["level"] != "DEBUG" {
+		t.Errorf("expected V(1) to be logged at debug, got %v", records[1]["level"])
+	}
+
+	errRecords := decodeRecords(t, errOut)
+	if last := errRecords[len(errRecords)-1]; last["msg"] != "reconcile failed" || last["error"] != "conflict" {
+		t.Errorf("expected the logr error in the error output, got %v", last)
+	}
+}
+
+func TestStdLogger(t *testing.T) {
+	out, errOut := new(bytes.Buffer), new(bytes.Buffer)
+	cfg := go11y.NewConfig(go11y.WithLogLevel(go11y.LevelInfo))
+
+	_, o, err := go11y.Initialise(context.Background(), cfg, out, errOut)
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+	out.Reset()
+
+	o.StdLogger(go11y.LevelDebug).Print("not logged")
+	o.StdLogger(go11y.LevelWarning).Printf("http: TLS handshake error from %s: EOF", "10.0.0.1:5000")
+
+	records := decodeRecords(t, out)
+	if len(records) != 1 {
+		t.Fatalf("expected 1 record in the output, got %d: %s", len(records), out.String())
+	}
+
+	if records[0]["msg"] != "http: TLS handshake error from 10.0.0.1:5000: EOF" || records[0]["level"] != "WARN" {
+		t.Errorf("expected the line to be logged as a warning, got %v", records[0])
+	}
+
+	source, _ := records[0]["source"].(map[string]any)
+	if source["function"] != "github.com/cirruscomms/go11y_test.TestStdLogger" {
+		t.Errorf("expected the source to be the caller of the logger, got %v", records[0]["source"])
+	}
+}
@@ -0,0 +1,98 @@
+package go11y
+
+import (
+	"os"
+	"runtime"
+	"runtime/debug"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// FieldRevision is the structured log field name for "revision"
+const FieldRevision = "revision"
+
+// FieldBuildTime is the structured log field name for "build_time"
+const FieldBuildTime = "build_time"
+
+// FieldGoVersion is the structured log field name for "go_version"
+const FieldGoVersion = "go_version"
+
+// AppBuildInfo is the metric describing the build of the running binary, always 1, with its version, VCS revision,
+// build time and Go version as labels, so dashboards can show which build is deployed where and when it changed
+var AppBuildInfo = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "app_build_info",
+	Help: "Build information of the running binary, always 1",
+}, []string{"version", "revision", "build_time", "go_version"})
+
+var registerBuildInfoMetricsOnce sync.Once
+
+// BuildInfo is the build and deployment metadata of the running binary, see ReadBuildInfo
+type BuildInfo struct {
+	Version   string // the version of the service, see ServiceConfigurator
+	Revision  string // the VCS revision (git SHA) the binary was built from
+	BuildTime string // the time of the revision, or the build if BUILD_TIME is set, in RFC 3339 format
+	GoVersion string // the version of Go the binary was built with
+}
+
+// ReadBuildInfo returns the build metadata of the running binary: the revision and time stamped by the go tool from
+// the VCS (debug.ReadBuildInfo), overridden by the GIT_SHA and BUILD_TIME environment variables for binaries built
+// without VCS information, e.g. in a Docker build without the .git directory, and the version of $cfg, or of the main
+// module if it has none.
+func ReadBuildInfo(cfg Configurator) (info BuildInfo) {
+	info = BuildInfo{
+		Version:   configServiceIdentity(cfg).version,
+		GoVersion: runtime.Version(),
+	}
+
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				info.Revision = s.Value
+			case "vcs.time":
+				info.BuildTime = s.Value
+			}
+		}
+	}
+
+	if sha := os.Getenv("GIT_SHA"); sha != "" {
+		info.Revision = sha
+	}
+
+	if buildTime := os.Getenv("BUILD_TIME"); buildTime != "" {
+		info.BuildTime = buildTime
+	}
+
+	if info.Version == "" {
+		info.Version = buildVersion()
+	}
+
+	return info
+}
+
+// LogArgs returns the known build metadata as log args
+func (bi BuildInfo) LogArgs() (args []any) {
+	for _, f := range [][2]string{
+		{FieldServiceVersion, bi.Version},
+		{FieldRevision, bi.Revision},
+		{FieldBuildTime, bi.BuildTime},
+		{FieldGoVersion, bi.GoVersion},
+	} {
+		if f[1] != "" {
+			args = append(args, f[0], f[1])
+		}
+	}
+
+	return args
+}
+
+// setBuildInfoMetric sets the AppBuildInfo metric to describe $info
+func setBuildInfoMetric(info BuildInfo) {
+	registerBuildInfoMetricsOnce.Do(func() {
+		registerCollectors(AppBuildInfo)
+	})
+
+	AppBuildInfo.Reset()
+	AppBuildInfo.WithLabelValues(info.Version, info.Revision, info.BuildTime, info.GoVersion).Set(1)
+}
@@ -0,0 +1,50 @@
+package go11y_test
+
+import (
+	"bytes"
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/cirruscomms/go11y"
+	"github.com/cirruscomms/go11y/go11ytest"
+)
+
+func TestBuildInfo(t *testing.T) {
+	t.Setenv("GIT_SHA", "4f1c2ab9e07d")
+	t.Setenv("BUILD_TIME", "2026-01-02T03:04:05Z")
+
+	cfg := go11y.NewConfig(go11y.WithLogLevel(go11y.LevelDebug), go11y.WithServiceVersion("v1.2.3"))
+
+	info := go11y.ReadBuildInfo(cfg)
+	expected := go11y.BuildInfo{
+		Version:   "v1.2.3",
+		Revision:  "4f1c2ab9e07d",
+		BuildTime: "2026-01-02T03:04:05Z",
+		GoVersion: runtime.Version(),
+	}
+	if info != expected {
+		t.Errorf("expected %+v, got %+v", expected, info)
+	}
+
+	buf := new(bytes.Buffer)
+	_, o, err := go11y.Initialise(context.Background(), cfg, buf, buf)
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+	defer o.Close()
+
+	if !strings.Contains(buf.String(), `"msg":"service starting"`) || !strings.Contains(buf.String(), `"revision":"4f1c2ab9e07d"`) {
+		t.Errorf("expected a startup record with the revision, got %s", buf.String())
+	}
+
+	go11ytest.GatherMetrics(t, prometheus.DefaultGatherer).AssertValue(t, "app_build_info", go11ytest.Labels{
+		"version":    "v1.2.3",
+		"revision":   "4f1c2ab9e07d",
+		"build_time": "2026-01-02T03:04:05Z",
+		"go_version": runtime.Version(),
+	}, 1)
+}
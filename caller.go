@@ -0,0 +1,29 @@
+package go11y
+
+import "runtime"
+
+// WithCallerSkip returns a child Observer (see With) whose records have the caller $skip frames further up the stack
+// as their source, for helpers that wrap go11y's logging methods: a helper that calls o.Info directly should use
+// o.WithCallerSkip(1) so its records point at the helper's caller rather than the helper.
+// Unlike IncreaseDistance, the receiver and other users of it are unaffected.
+func (o *Observer) WithCallerSkip(skip int) (child *Observer) {
+	child = o.With()
+	child.callerSkip += skip
+
+	return child
+}
+
+// callerPC returns the program counter of the source of a record: the frame runtime.Callers($skip) would return if
+// called by the caller of callerPC, plus the Observer's callerSkip. Every logging method captures the source of its
+// records through it, and it returns 0 without walking the stack when the source isn't logged.
+func (o *Observer) callerPC(skip int) (pc uintptr) {
+	if o.sourceMode == SourceOff {
+		return 0
+	}
+
+	var pcs [1]uintptr
+	// skip [runtime.Callers, this function] as well
+	runtime.Callers(skip+1+o.callerSkip, pcs[:])
+
+	return pcs[0]
+}
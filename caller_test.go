@@ -0,0 +1,154 @@
+package go11y_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+
+	"github.com/cirruscomms/go11y"
+)
+
+// here returns the file and line it is called from
+func here() (file string, line int) {
+	_, file, line, _ = runtime.Caller(1)
+	return file, line
+}
+
+// logHelper wraps o.Info like a service's own logging helper would
+func logHelper(o *go11y.Observer, msg string) {
+	o.WithCallerSkip(1).Info(msg)
+}
+
+func TestSourceLocation(t *testing.T) {
+	err := errors.New("failure")
+
+	testCases := map[string]func(o *go11y.Observer) (file string, line int){
+		"Develop": func(o *go11y.Observer) (string, int) {
+			o.Develop("m")
+			return here()
+		},
+		"Debug": func(o *go11y.Observer) (string, int) {
+			o.Debug("m")
+			return here()
+		},
+		"Info": func(o *go11y.Observer) (string, int) {
+			o.Info("m")
+			return here()
+		},
+		"Notice": func(o *go11y.Observer) (string, int) {
+			o.Notice("m")
+			return here()
+		},
+		"Warning": func(o *go11y.Observer) (string, int) {
+			o.Warning("m")
+			return here()
+		},
+		"Warn": func(o *go11y.Observer) (string, int) {
+			o.Warn("m")
+			return here()
+		},
+		"Error": func(o *go11y.Observer) (string, int) {
+			o.Error("m", err, go11y.SeverityLow)
+			return here()
+		},
+		"Infof": func(o *go11y.Observer) (string, int) {
+			o.Infof("m %d", 1)
+			return here()
+		},
+		"Errorf": func(o *go11y.Observer) (string, int) {
+			o.Errorf(err, go11y.SeverityLow, "m %d", 1)
+			return here()
+		},
+		"StdLogger": func(o *go11y.Observer) (string, int) {
+			o.StdLogger(go11y.LevelInfo).Print("m")
+			return here()
+		},
+		"Logr": func(o *go11y.Observer) (string, int) {
+			o.Logr().Info("m")
+			return here()
+		},
+		"WithCallerSkip": func(o *go11y.Observer) (string, int) {
+			logHelper(o, "m")
+			return here()
+		},
+	}
+
+	for name, log := range testCases {
+		t.Run(name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			cfg := go11y.NewConfig(go11y.WithLogLevel(go11y.LevelDevelop))
+
+			_, o, err := go11y.Initialise(context.Background(), cfg, buf, buf)
+			if err != nil {
+				t.Fatalf("failed to initialise observer: %v", err)
+			}
+			buf.Reset()
+
+			file, line := log(o)
+			// the call is on the line before here()
+			line--
+
+			records := decodeRecords(t, buf)
+			source, _ := records[len(records)-1]["source"].(map[string]any)
+			if source["file"] != file || source["line"] != float64(line) {
+				t.Errorf("expected the source to be %s:%d, got %v", file, line, source)
+			}
+		})
+	}
+}
+
+func TestWithCallerSkipIsolated(t *testing.T) {
+	buf := new(bytes.Buffer)
+	cfg := go11y.NewConfig(go11y.WithLogLevel(go11y.LevelInfo))
+
+	_, o, err := go11y.Initialise(context.Background(), cfg, buf, buf)
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	_ = o.WithCallerSkip(5)
+	buf.Reset()
+
+	o.Info("m")
+	file, line := here()
+
+	source, _ := decodeRecords(t, buf)[0]["source"].(map[string]any)
+	if source["file"] != file || source["line"] != float64(line-1) {
+		t.Errorf("expected WithCallerSkip not to affect the parent, got %v", source)
+	}
+}
+
+func TestSourceModes(t *testing.T) {
+	testCases := map[string]struct {
+		mode     go11y.SourceMode
+		expected any
+	}{
+		"function": {mode: go11y.SourceFunction, expected: "github.com/cirruscomms/go11y_test.TestSourceModes.func1"},
+		"off":      {mode: go11y.SourceOff, expected: nil},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			cfg := go11y.NewConfig(go11y.WithLogLevel(go11y.LevelInfo), go11y.WithLogSource(tc.mode))
+
+			_, o, err := go11y.Initialise(context.Background(), cfg, buf, buf)
+			if err != nil {
+				t.Fatalf("failed to initialise observer: %v", err)
+			}
+			buf.Reset()
+
+			o.Info("m")
+
+			if got := decodeRecords(t, buf)[0]["source"]; got != tc.expected {
+				t.Errorf("expected the source to be %v, got %v", tc.expected, got)
+			}
+		})
+	}
+
+	if go11y.ParseSourceMode("Function") != go11y.SourceFunction || go11y.ParseSourceMode("bogus") != go11y.SourceFull {
+		t.Errorf("expected source modes to be parsed case-insensitively, defaulting to full")
+	}
+}
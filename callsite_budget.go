@@ -0,0 +1,147 @@
+package go11y
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// CallsiteBudgetMessage is the message of the warnings written when a callsite logs more than its budget, see
+// WithCallsiteBudget
+const CallsiteBudgetMessage = "callsite over log budget"
+
+// LogCallsitesOverBudget is the metric for the number of times a callsite logged more records than the budget set by
+// WithCallsiteBudget in a window, by the function of the callsite
+var LogCallsitesOverBudget = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "go11y_log_callsites_over_budget_total",
+	Help: "Number of windows in which a callsite logged more records than its budget",
+}, []string{"function"})
+
+// callsiteBudget counts the records logged by each callsite in the current window, to find those logging more than
+// limit records per interval
+type callsiteBudget struct {
+	limit    int
+	interval time.Duration
+	start    time.Time
+	counts   map[uintptr]int
+	mu       sync.Mutex
+}
+
+// callsiteOverBudget is a callsite that logged more records than the budget in a window
+type callsiteOverBudget struct {
+	pc      uintptr
+	records int
+}
+
+func newCallsiteBudget(limit int, interval time.Duration) *callsiteBudget {
+	return &callsiteBudget{
+		limit:    limit,
+		interval: interval,
+		counts:   map[uintptr]int{},
+	}
+}
+
+// count counts a record logged by the callsite $pc at $now. When it starts a new window, the callsites that were over
+// the budget in the previous one are returned.
+func (b *callsiteBudget) count(now time.Time, pc uintptr) (over []callsiteOverBudget) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.start.IsZero() {
+		b.start = now
+	}
+
+	if now.Sub(b.start) >= b.interval {
+		over = b.overLocked()
+		b.start = now
+	}
+
+	b.counts[pc]++
+
+	return over
+}
+
+// drain returns the callsites over the budget in the current window and starts a new one.
+func (b *callsiteBudget) drain() (over []callsiteOverBudget) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.overLocked()
+}
+
+// overLocked returns the callsites over the budget in the current window and resets the counts. b.mu must be held.
+func (b *callsiteBudget) overLocked() (over []callsiteOverBudget) {
+	for pc, records := range b.counts {
+		if records > b.limit {
+			over = append(over, callsiteOverBudget{pc: pc, records: records})
+		}
+	}
+
+	b.counts = map[uintptr]int{}
+
+	return over
+}
+
+// WithCallsiteBudget warns when a single callsite logs more than $limit records in an $interval, whatever their level,
+// to find accidental logging in hot loops (e.g. a Debug record left in a loop over every row of a table). At the end of
+// each window, or when the Observer is closed, a CallsiteBudgetMessage warning is written for each callsite over the
+// budget, with that callsite as its source, and the LogCallsitesOverBudget metric is incremented.
+// Windows end lazily, when the first record after them is logged. Records are only counted while the source of records
+// is logged, see WithLogSource.
+func WithCallsiteBudget(limit int, interval time.Duration) Option {
+	return func(o *Observer) {
+		o.callsiteBudget = newCallsiteBudget(limit, interval)
+	}
+}
+
+// countCallsite counts a record logged by the callsite $pc against the Observer's callsite budget, if it has one,
+// warning about the callsites that were over it if a new window has started
+func (o *Observer) countCallsite(ctx context.Context, pc uintptr) {
+	if o.callsiteBudget == nil || pc == 0 {
+		return
+	}
+
+	for _, over := range o.callsiteBudget.count(o.clock.Now(), pc) {
+		o.warnOverBudget(ctx, over)
+	}
+}
+
+func (o *Observer) warnOverBudget(ctx context.Context, over callsiteOverBudget) {
+	function := "unknown"
+	if fn := runtime.FuncForPC(over.pc); fn != nil {
+		function = fn.Name()
+	}
+
+	LogCallsitesOverBudget.WithLabelValues(function).Inc()
+
+	logger := o.logger(LevelWarning)
+	if !logger.Enabled(ctx, LevelWarning) {
+		return
+	}
+
+	// the warning has the offending callsite as its source, and isn't counted itself
+	r := slog.NewRecord(o.clock.Now(), LevelWarning, CallsiteBudgetMessage, over.pc)
+	r.Add(
+		"records", over.records,
+		"limit", o.callsiteBudget.limit,
+		"interval", o.callsiteBudget.interval.String(),
+	)
+
+	err := logger.Handler().Handle(ctx, r)
+	recordHandled(LevelToString(LevelWarning), o.component, err)
+}
+
+// flushCallsiteBudget warns about the callsites over the budget in the current window.
+func (o *Observer) flushCallsiteBudget() {
+	if o.callsiteBudget == nil {
+		return
+	}
+
+	for _, over := range o.callsiteBudget.drain() {
+		o.warnOverBudget(context.Background(), over)
+	}
+}
@@ -0,0 +1,67 @@
+package go11y
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCallsiteBudget(t *testing.T) {
+	buf := new(bytes.Buffer)
+	clock := &stepClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	cfg := CreateConfig(LevelDebug, "", "", "", []string{}, []string{})
+
+	_, o, err := Initialise(context.Background(), cfg, buf, buf, WithClock(clock), WithCallsiteBudget(5, time.Minute))
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	for i := range 8 {
+		o.Debug("processing row", "row", i)
+	}
+	o.Info("rows processed")
+
+	if strings.Contains(buf.String(), CallsiteBudgetMessage) {
+		t.Fatalf("expected no warning before the window ends, got %s", buf.String())
+	}
+
+	before := testutil.ToFloat64(LogCallsitesOverBudget.WithLabelValues("github.com/cirruscomms/go11y.TestCallsiteBudget"))
+
+	clock.now = clock.now.Add(time.Minute)
+	o.Info("next window")
+
+	warnings := []string{}
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if strings.Contains(line, CallsiteBudgetMessage) {
+			warnings = append(warnings, line)
+		}
+	}
+
+	if len(warnings) != 1 {
+		t.Fatalf("expected a warning for the loop's callsite only, got %v", warnings)
+	}
+
+	if !strings.Contains(warnings[0], `"records":8,"limit":5,"interval":"1m0s"`) ||
+		!strings.Contains(warnings[0], "callsite_budget_test.go") {
+		t.Errorf("expected the warning to have the count and the loop's callsite as its source, got %s", warnings[0])
+	}
+
+	after := testutil.ToFloat64(LogCallsitesOverBudget.WithLabelValues("github.com/cirruscomms/go11y.TestCallsiteBudget"))
+	if after-before != 1 {
+		t.Errorf("expected the metric to be incremented once, got %v", after-before)
+	}
+
+	// a callsite over the budget when the Observer is closed is reported too
+	for range 6 {
+		o.Info("flushing")
+	}
+	o.Close()
+
+	if strings.Count(buf.String(), CallsiteBudgetMessage) != 2 {
+		t.Errorf("expected the callsite over the budget at close to be reported, got %s", buf.String())
+	}
+}
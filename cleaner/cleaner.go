@@ -9,6 +9,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/cirruscomms/go11y"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -33,25 +34,44 @@ func New(ctx context.Context, dbConnStr string) (dbCleaner *Cleaner, fault error
 
 // Exec cleans the clears out db records created by the storer that are older than 180 days
 func (s *Cleaner) Exec(ctx context.Context) error {
+	_, err := s.exec(ctx)
+
+	return err
+}
+
+func (s *Cleaner) exec(ctx context.Context) (deleted int64, fault error) {
 	tx, err := s.pool.Begin(ctx)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	defer tx.Rollback(ctx)
 
 	sql := fmt.Sprintf(`DELETE FROM remote_api_requests WHERE created_at < (NOW() - interval '%s');`, maxAge)
 
-	_, err = tx.Exec(ctx, sql)
+	tag, err := tx.Exec(ctx, sql)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	err = tx.Commit(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// Run cleans out old records like Exec and logs how many were deleted. It matches go11y.JobFunc so the cleaner can be
+// scheduled with a go11y.Scheduler, e.g. scheduler.Add("storer_cleaner", go11y.Every(24*time.Hour), c.Run)
+func (s *Cleaner) Run(ctx context.Context, o *go11y.Observer) error {
+	deleted, err := s.exec(ctx)
 	if err != nil {
 		return err
 	}
 
+	o.Info("storer records cleaned", "deleted", deleted)
+
 	return nil
 }
 
@@ -0,0 +1,32 @@
+package go11y
+
+import "time"
+
+// Clock is the source of time for an Observer's record timestamps and the durations measured by its middleware,
+// roundtrippers and jobs. Inject one with WithClock to freeze time in tests.
+type Clock interface {
+	Now() time.Time
+	Since(t time.Time) time.Duration
+}
+
+// SystemClock is the Clock backed by the time package, used unless WithClock is given
+type SystemClock struct{}
+
+// Now returns the current local time
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// Since returns the time elapsed since $t
+func (SystemClock) Since(t time.Time) time.Duration {
+	return time.Since(t)
+}
+
+// WithClock makes the Observer use $clock for record timestamps and measured durations instead of the system clock.
+func WithClock(clock Clock) Option {
+	return func(o *Observer) {
+		if clock != nil {
+			o.clock = clock
+		}
+	}
+}
@@ -0,0 +1,55 @@
+// Command go11y-rules writes the Prometheus rules or Grafana dashboard of a service from the metrics go11y registers,
+// so every service using go11y gets the same alerts and dashboards:
+//
+//	go run github.com/cirruscomms/go11y/cmd/go11y-rules -service orders > rules.yaml
+//	go run github.com/cirruscomms/go11y/cmd/go11y-rules -service orders -format grafana > dashboard.json
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/cirruscomms/go11y"
+)
+
+func main() {
+	opts := go11y.SLORulesOpts{}
+
+	flag.StringVar(&opts.Service, "service", "", "the service name passed to GetMetricsMiddlewareMux")
+	format := flag.String("format", "prometheus", `"prometheus" for a rule file or "grafana" for a dashboard`)
+	flag.Float64Var(&opts.AvailabilityObjective, "availability", go11y.DefaultAvailabilityObjective,
+		"the ratio of requests that must not be answered with a 5xx")
+	flag.DurationVar(&opts.LatencyObjective, "latency", go11y.DefaultLatencyObjective,
+		"the 99th percentile request time alerted above")
+	flag.Float64Var(&opts.IntegrationObjective, "integration-availability", go11y.DefaultAvailabilityObjective,
+		"the ratio of calls to each integration that must not spend its error budget")
+	flag.Parse()
+
+	if err := run(*format, opts); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(format string, opts go11y.SLORulesOpts) (fault error) {
+	var out []byte
+	var err error
+
+	switch format {
+	case "prometheus":
+		out, err = go11y.GeneratePrometheusRules(opts)
+	case "grafana":
+		out, err = go11y.GenerateGrafanaDashboard(opts)
+	default:
+		return fmt.Errorf("unknown format %q, expected \"prometheus\" or \"grafana\"", format)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	_, err = os.Stdout.Write(out)
+
+	return err
+}
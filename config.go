@@ -1,10 +1,13 @@
 package go11y
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/caarlos0/env/v10"
 )
@@ -15,10 +18,17 @@ import (
 // custom configuration struct - ideal for our unit tests or when you want to use a your own bespoke configuration
 // source
 type Configurator interface {
+	// LogLevel is the minimum level of the records written to the log outputs
 	LogLevel() slog.Level
+	// OtelURL is the OTLP HTTP traces URL (scheme, host, port, path), tracing is disabled if it is empty
 	OtelURL() string
+	// DatabaseURL is the connection string of the database used to store outbound calls, if any
+	DatabaseURL() string
+	// ServiceName is the service name reported to OpenTelemetry
 	ServiceName() string
+	// TrimPaths are trimmed from the source.file attribute
 	TrimPaths() []string
+	// TrimModules are trimmed from the source.function attribute
 	TrimModules() []string
 }
 
@@ -29,28 +39,171 @@ type Configuration struct {
 	strLevel    string
 	databaseURL string
 	serviceName string
+	version     string
+	instanceID  string
 	trimModules []string
 	trimPaths   []string
+	logOutput   string
+	environment Environment
+	sampleRatio float64
+
+	secretResolvers SecretResolvers // resolvers of secret references, see WithSecretResolver
+	resolvedSecrets []string        // names of the settings resolved from secret references
+
+	validationMode ValidationMode
+	probeTimeout   time.Duration
+	problems       []*ConfigError // problems found by LoadConfig in permissive mode, logged by Initialise
+	otelRequired   bool
+	sourceMode     SourceMode
+	fieldSchema    FieldSchema
+	piiDetectors   PIIDetectors
+	redactionMode  RedactionMode
+	redactionSalt  string
+	propagators    []string
 }
 
 type interimConfig struct {
-	StrLevel    string `env:"LOG_LEVEL" envDefault:"debug"`
-	OtelURL     string `env:"OTEL_URL" envDefault:""`
-	DatabaseURL string `env:"DATABASE_URL" envDefault:""`
-	ServiceName string `env:"OTEL_SERVICE_NAME" envDefault:""`
-	TrimModules string `env:"TRIM_MODULES" envDefault:""`
-	TrimPaths   string `env:"TRIM_PATHS" envDefault:""`
+	StrLevel     string        `env:"LOG_LEVEL" envDefault:"debug"`
+	OtelURL      string        `env:"OTEL_URL" envDefault:""`
+	DatabaseURL  string        `env:"DATABASE_URL" envDefault:""`
+	ServiceName  string        `env:"OTEL_SERVICE_NAME" envDefault:""`
+	Version      string        `env:"SERVICE_VERSION" envDefault:""`
+	InstanceID   string        `env:"SERVICE_INSTANCE_ID" envDefault:""`
+	TrimModules  string        `env:"TRIM_MODULES" envDefault:""`
+	TrimPaths    string        `env:"TRIM_PATHS" envDefault:""`
+	LogOutput    string        `env:"LOG_OUTPUT" envDefault:""`
+	Environment  string        `env:"ENV" envDefault:""`
+	SampleRatio  float64       `env:"TRACE_SAMPLE_RATIO" envDefault:"-1"`
+	Validation   string        `env:"CONFIG_VALIDATION" envDefault:"strict"`
+	ProbeTimeout time.Duration `env:"CONFIG_PROBE_TIMEOUT" envDefault:"0s"`
+	OtelRequired bool          `env:"OTEL_REQUIRED" envDefault:"true"`
+	LogSource    string        `env:"LOG_SOURCE" envDefault:"full"`
+	FieldSchema  string        `env:"LOG_FIELD_SCHEMA" envDefault:"go11y"`
+	RedactPII    string        `env:"REDACT_PII" envDefault:""`
+	Redaction    string        `env:"REDACTION_MODE" envDefault:"length"`
+	RedactSalt   string        `env:"REDACTION_SALT" envDefault:""`
+	Propagators  string        `env:"OTEL_PROPAGATORS" envDefault:"tracecontext,baggage"`
+}
+
+// ConfigOption sets a value of a Configuration built by NewConfig or loaded by LoadConfig.
+type ConfigOption func(c *Configuration)
+
+// WithLogLevel sets the minimum level of the records written to the log outputs.
+func WithLogLevel(level slog.Level) ConfigOption {
+	return func(c *Configuration) {
+		c.logLevel = level
+		c.strLevel = LevelToString(level)
+	}
+}
+
+// WithOtelURL sets the OTLP HTTP traces URL (scheme, host, port, path). Tracing is disabled if it is empty.
+func WithOtelURL(otelURL string) ConfigOption {
+	return func(c *Configuration) {
+		c.otelURL = otelURL
+	}
+}
+
+// WithDatabaseURL sets the connection string of the database used to store outbound calls.
+func WithDatabaseURL(databaseURL string) ConfigOption {
+	return func(c *Configuration) {
+		c.databaseURL = databaseURL
+	}
+}
+
+// WithServiceName sets the service name reported to OpenTelemetry.
+func WithServiceName(serviceName string) ConfigOption {
+	return func(c *Configuration) {
+		c.serviceName = serviceName
+	}
+}
+
+// WithServiceVersion sets the version of the service added to every signal, e.g. a release tag or commit.
+func WithServiceVersion(version string) ConfigOption {
+	return func(c *Configuration) {
+		c.version = version
+	}
+}
+
+// WithInstanceID sets the ID of the service instance added to every signal, e.g. the hostname of its pod.
+func WithInstanceID(instanceID string) ConfigOption {
+	return func(c *Configuration) {
+		c.instanceID = instanceID
+	}
+}
+
+// WithTrimModules sets the strings trimmed from the source.function attribute.
+func WithTrimModules(trimModules ...string) ConfigOption {
+	return func(c *Configuration) {
+		c.trimModules = trimModules
+	}
+}
+
+// WithTrimPaths sets the strings trimmed from the source.file attribute.
+func WithTrimPaths(trimPaths ...string) ConfigOption {
+	return func(c *Configuration) {
+		c.trimPaths = trimPaths
+	}
+}
+
+// WithLogOutput sets the log output opened when none is passed to Initialise, see OpenLogOutput.
+func WithLogOutput(logOutput string) ConfigOption {
+	return func(c *Configuration) {
+		c.logOutput = logOutput
+	}
+}
+
+// WithEnvironment sets the environment the service is deployed to, which selects the defaults described on its
+// Environment constant and is added to every record and to the OTel resource.
+func WithEnvironment(environment Environment) ConfigOption {
+	return func(c *Configuration) {
+		c.environment = environment
+	}
+}
+
+// WithTraceSampleRatio sets the ratio (0-1) of new traces to sample, overriding the environment's preset.
+func WithTraceSampleRatio(ratio float64) ConfigOption {
+	return func(c *Configuration) {
+		c.sampleRatio = ratio
+	}
+}
+
+// NewConfig builds a Configuration from $opts, without reading the environment.
+// Anything not set by an option is left at its zero value, except the log level which defaults to info.
+func NewConfig(opts ...ConfigOption) (cfg *Configuration) {
+	cfg = &Configuration{
+		logLevel:    LevelInfo,
+		strLevel:    LevelToString(LevelInfo),
+		trimModules: []string{},
+		trimPaths:   []string{},
+		sampleRatio: -1,
+
+		otelRequired: true,
+	}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return cfg
 }
 
 // LoadConfig loads the configuration from environment variables.
+// SERVICE_VERSION defaults to the version of the main module in the binary's build info, or the VCS revision it was
+// built at, and SERVICE_INSTANCE_ID to the hostname.
 // It returns a Configuration instance that implements the Configurator interface.
-// If any required environment variable is missing or invalid, it returns an error.
-func LoadConfig() (cfg *Configuration, fault error) {
+// Any $overrides are applied after the environment has been read, so they take precedence over it.
+// Settings holding secret references (e.g. DATABASE_URL=file:/run/secrets/db_url) are then resolved, see
+// WithSecretResolver.
+// If any environment variable is invalid, it returns an error listing every problem found (see ValidateConfig), unless
+// CONFIG_VALIDATION is "permissive" (problems are logged by Initialise) or "off".
+func LoadConfig(overrides ...ConfigOption) (cfg *Configuration, fault error) {
 	h := interimConfig{}
 	if err := env.Parse(&h); err != nil {
 		return nil, fmt.Errorf("could not load config: %w", err)
 	}
 
+	level, levelErr := ParseLevel(h.StrLevel)
+
 	trimModules := strings.Split(h.TrimModules, ",")
 
 	path, _ := os.Getwd()
@@ -61,13 +214,70 @@ func LoadConfig() (cfg *Configuration, fault error) {
 		trimPaths = strings.Split(h.TrimPaths, ",")
 	}
 
+	if h.Version == "" {
+		h.Version = buildVersion()
+	}
+
+	if h.InstanceID == "" {
+		h.InstanceID = defaultInstanceID()
+	}
+
 	c := &Configuration{
 		otelURL:     h.OtelURL,
 		strLevel:    h.StrLevel,
-		logLevel:    StringToLevel(h.StrLevel),
+		logLevel:    level,
+		databaseURL: h.DatabaseURL,
 		serviceName: h.ServiceName,
+		version:     h.Version,
+		instanceID:  h.InstanceID,
 		trimModules: trimModules,
 		trimPaths:   trimPaths,
+		logOutput:   h.LogOutput,
+		environment: ParseEnvironment(h.Environment),
+		sampleRatio: h.SampleRatio,
+
+		validationMode: ParseValidationMode(h.Validation),
+		probeTimeout:   h.ProbeTimeout,
+		otelRequired:   h.OtelRequired,
+		sourceMode:     ParseSourceMode(h.LogSource),
+		fieldSchema:    ParseFieldSchema(h.FieldSchema),
+		piiDetectors:   ParsePIIDetectors(h.RedactPII),
+		redactionMode:  ParseRedactionMode(h.Redaction),
+		redactionSalt:  h.RedactSalt,
+		propagators:    ParsePropagators(h.Propagators),
+	}
+
+	for _, opt := range overrides {
+		opt(c)
+	}
+
+	if err := c.resolveSecrets(context.Background()); err != nil {
+		return nil, fmt.Errorf("could not load config: %w", err)
+	}
+
+	if c.validationMode == ValidationOff {
+		return c, nil
+	}
+
+	problems := []*ConfigError{}
+	if levelErr != nil {
+		problems = append(problems, &ConfigError{Setting: "LOG_LEVEL", Err: levelErr})
+	}
+
+	if c.validationMode == ValidationPermissive {
+		// the URLs are validated again, and probed, by Initialise
+		c.problems = problems
+		return c, nil
+	}
+
+	problems = append(problems, validateConfig(context.Background(), c, 0)...)
+	if len(problems) != 0 {
+		errs := make([]error, 0, len(problems))
+		for _, p := range problems {
+			errs = append(errs, p)
+		}
+
+		return nil, fmt.Errorf("could not load config: %w", errors.Join(errs...))
 	}
 
 	return c, nil
@@ -77,16 +287,16 @@ func LoadConfig() (cfg *Configuration, fault error) {
 // This is intended to be used for when you want to create a config without loading from environment variables.
 // The Configuration returned satisfies the Configurator interface, allowing it to be used interchangeably with
 // configurations loaded from environment variables.
+// NewConfig is preferred for new code, as it doesn't need every value spelled out.
 func CreateConfig(logLevel slog.Level, otelURL, dbConStr, serviceName string, trimModules, trimPaths []string) *Configuration {
-	return &Configuration{
-		logLevel:    logLevel,
-		otelURL:     otelURL,
-		strLevel:    logLevel.String(),
-		databaseURL: dbConStr,
-		serviceName: serviceName,
-		trimModules: trimModules,
-		trimPaths:   trimPaths,
-	}
+	return NewConfig(
+		WithLogLevel(logLevel),
+		WithOtelURL(otelURL),
+		WithDatabaseURL(dbConStr),
+		WithServiceName(serviceName),
+		WithTrimModules(trimModules...),
+		WithTrimPaths(trimPaths...),
+	)
 }
 
 // LogLevel returns the configured log level for the observer.
@@ -101,12 +311,30 @@ func (c *Configuration) OtelURL() string {
 	return c.otelURL
 }
 
+// DatabaseURL returns the configured connection string of the database used to store outbound calls.
+// This method is part of the Configurator interface.
+func (c *Configuration) DatabaseURL() string {
+	return c.databaseURL
+}
+
 // ServiceName returns the configured service name for OpenTelemetry.
 // This method is part of the Configurator interface.
 func (c *Configuration) ServiceName() string {
 	return c.serviceName
 }
 
+// ServiceVersion returns the configured version of the service.
+// This method is part of the ServiceConfigurator interface.
+func (c *Configuration) ServiceVersion() string {
+	return c.version
+}
+
+// InstanceID returns the configured ID of the service instance.
+// This method is part of the ServiceConfigurator interface.
+func (c *Configuration) InstanceID() string {
+	return c.instanceID
+}
+
 // TrimPaths returns the configured strings to be trimmed from the source.file attribute.
 // This method is part of the Configurator interface.
 func (c *Configuration) TrimPaths() []string {
@@ -118,3 +346,22 @@ func (c *Configuration) TrimPaths() []string {
 func (c *Configuration) TrimModules() []string {
 	return c.trimModules
 }
+
+// LogOutput returns the configured log output, used when no log output writer is passed to Initialise.
+// See OpenLogOutput for the supported values.
+// This method is part of the LogOutputConfigurator interface.
+func (c *Configuration) LogOutput() string {
+	return c.logOutput
+}
+
+// Environment returns the configured environment the service is deployed to.
+// This method is part of the EnvironmentConfigurator interface.
+func (c *Configuration) Environment() Environment {
+	return c.environment
+}
+
+// TraceSampleRatio returns the configured ratio of new traces to sample, negative if the environment preset is used.
+// This method is part of the SamplingConfigurator interface.
+func (c *Configuration) TraceSampleRatio() float64 {
+	return c.sampleRatio
+}
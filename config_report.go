@@ -0,0 +1,122 @@
+package go11y
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+)
+
+// ConfigSnapshot is the effective configuration of an Observer, with secrets redacted. It is logged by Initialise as
+// the "observability configured" record and served by ConfigHandler, for debugging misconfigured deployments.
+type ConfigSnapshot struct {
+	LogLevel         string  `json:"log_level"`
+	Environment      string  `json:"environment,omitempty"`
+	ServiceName      string  `json:"service_name"`
+	ServiceVersion   string  `json:"service_version,omitempty"`
+	InstanceID       string  `json:"service_instance_id,omitempty"`
+	Exporter         string  `json:"exporter"` // "otlp-http", or "none" when tracing is disabled
+	OtelURL          string  `json:"otel_url,omitempty"`
+	TraceSampleRatio float64 `json:"trace_sample_ratio"`
+	DatabaseEnabled  bool    `json:"database_enabled"`
+	DatabaseURL      string  `json:"database_url,omitempty"`
+	LogOutput        string  `json:"log_output,omitempty"`
+	LogFormat        string  `json:"log_format"`   // "json", or "text" in the development environment
+	LogSource        string  `json:"log_source"`   // "full", "function" or "off", see SourceMode
+	FieldSchema      string  `json:"field_schema"` // "go11y", "ecs" or "otel", see FieldSchema
+	Propagators      string  `json:"propagators"`  // the global TextMapPropagator installed, or "none"
+	LogSampling      string  `json:"log_sampling,omitempty"`
+	Sinks            int     `json:"sinks"`
+	AttrRedaction    bool    `json:"attr_redaction"`
+	AttrPolicy       string  `json:"attr_redaction_policy"` // the log and span attribute keys and key suffixes redacted
+	RedactionPolicy  string  `json:"redaction_policy"`      // the pattern of header and body field names redacted
+	RedactionMode    string  `json:"redaction_mode"`        // "length" or "hash", the salt is never reported
+	PIIDetectors     string  `json:"pii_detectors"`         // the value-based detectors enabled, or "none"
+}
+
+// ConfigSnapshot returns the effective configuration of the Observer, with secrets redacted.
+func (o *Observer) ConfigSnapshot() (snapshot ConfigSnapshot) {
+	resolved := []string{}
+	if c, ok := o.cfg.(*Configuration); ok {
+		resolved = c.resolvedSecrets
+	}
+
+	redactionMode, _ := configRedaction(o.cfg)
+
+	snapshot = ConfigSnapshot{
+		LogLevel:         LevelToString(o.level),
+		Environment:      string(configEnvironment(o.cfg)),
+		ServiceName:      o.cfg.ServiceName(),
+		ServiceVersion:   configServiceIdentity(o.cfg).version,
+		InstanceID:       configServiceIdentity(o.cfg).instanceID,
+		Exporter:         "none",
+		TraceSampleRatio: configSampleRatio(o.cfg),
+		DatabaseEnabled:  o.cfg.DatabaseURL() != "",
+		DatabaseURL:      redactSetting(o.cfg.DatabaseURL(), slices.Contains(resolved, "DATABASE_URL")),
+		LogOutput:        configLogOutput(o.cfg),
+		LogFormat:        "json",
+		LogSource:        o.sourceMode.String(),
+		FieldSchema:      o.fieldSchema.String(),
+		Propagators:      o.propagators,
+		Sinks:            len(o.sinks),
+		AttrRedaction:    o.redactAttrs,
+		AttrPolicy:       attrRedactionPolicy(),
+		RedactionPolicy:  forbiddenKeysRex.String(),
+		PIIDetectors:     configPIIDetectors(o.cfg).String(),
+		RedactionMode:    redactionMode.String(),
+	}
+
+	if o.traceProvider != nil {
+		snapshot.Exporter = "otlp-http"
+		snapshot.OtelURL = redactSetting(o.cfg.OtelURL(), slices.Contains(resolved, "OTEL_URL"))
+	}
+
+	if o.console {
+		snapshot.LogFormat = "text"
+	}
+
+	if o.sampler != nil {
+		snapshot.LogSampling = fmt.Sprintf("%d per %s", o.sampler.limit, o.sampler.interval)
+	}
+
+	return snapshot
+}
+
+// LogValue implements slog.LogValuer, so the snapshot is logged as a group in both the JSON and text formats.
+func (s ConfigSnapshot) LogValue() slog.Value {
+	attrs := []slog.Attr{
+		slog.String("log_level", s.LogLevel),
+		slog.String("environment", s.Environment),
+		slog.String("service_name", s.ServiceName),
+		slog.String("service_version", s.ServiceVersion),
+		slog.String("service_instance_id", s.InstanceID),
+		slog.String("exporter", s.Exporter),
+		slog.String("otel_url", s.OtelURL),
+		slog.Float64("trace_sample_ratio", s.TraceSampleRatio),
+		slog.Bool("database_enabled", s.DatabaseEnabled),
+		slog.String("database_url", s.DatabaseURL),
+		slog.String("log_output", s.LogOutput),
+		slog.String("log_format", s.LogFormat),
+		slog.String("log_source", s.LogSource),
+		slog.String("propagators", s.Propagators),
+		slog.String("log_sampling", s.LogSampling),
+		slog.Int("sinks", s.Sinks),
+		slog.Bool("attr_redaction", s.AttrRedaction),
+		slog.String("attr_redaction_policy", s.AttrPolicy),
+		slog.String("redaction_policy", s.RedactionPolicy),
+		slog.String("redaction_mode", s.RedactionMode),
+		slog.String("pii_detectors", s.PIIDetectors),
+	}
+
+	return slog.GroupValue(attrs...)
+}
+
+// ConfigHandler returns a handler serving the Observer's ConfigSnapshot as JSON, intended to be mounted at
+// /internal/config alongside /internal/metrics.
+func (o *Observer) ConfigHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(o.ConfigSnapshot())
+	})
+}
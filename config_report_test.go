@@ -0,0 +1,59 @@
+package go11y_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cirruscomms/go11y"
+)
+
+func TestConfigReport(t *testing.T) {
+	buf := new(bytes.Buffer)
+	cfg := go11y.NewConfig(
+		go11y.WithLogLevel(go11y.LevelDebug),
+		go11y.WithDatabaseURL("postgres://user:hunter2hunter2@db:5432/audit"),
+		go11y.WithServiceName("billing"),
+		go11y.WithEnvironment(go11y.EnvironmentProduction),
+	)
+
+	_, o, err := go11y.Initialise(context.Background(), cfg, buf, buf)
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	if !strings.Contains(buf.String(), `"msg":"observability configured"`) {
+		t.Errorf("expected the configuration to be reported on initialisation, got %s", buf.String())
+	}
+	if strings.Contains(buf.String(), "hunter2hunter2") {
+		t.Errorf("expected the database password to be redacted, got %s", buf.String())
+	}
+
+	rec := httptest.NewRecorder()
+	o.ConfigHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/internal/config", nil))
+
+	snapshot := go11y.ConfigSnapshot{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &snapshot); err != nil {
+		t.Fatalf("failed to decode config snapshot %q: %v", rec.Body.String(), err)
+	}
+
+	if snapshot.LogLevel != "debug" || snapshot.ServiceName != "billing" || snapshot.Environment != "production" {
+		t.Errorf("unexpected snapshot: %+v", snapshot)
+	}
+	if snapshot.Exporter != "none" || snapshot.TraceSampleRatio != 0.1 || !snapshot.DatabaseEnabled || !snapshot.AttrRedaction {
+		t.Errorf("unexpected snapshot: %+v", snapshot)
+	}
+	if !strings.Contains(snapshot.AttrPolicy, "api_key") || !strings.Contains(snapshot.AttrPolicy, "_token") {
+		t.Errorf("expected the attribute redaction policy to be reported, got %q", snapshot.AttrPolicy)
+	}
+	if !strings.Contains(snapshot.RedactionPolicy, "authorization") {
+		t.Errorf("expected the header and body redaction policy to be reported, got %q", snapshot.RedactionPolicy)
+	}
+	if strings.Contains(snapshot.DatabaseURL, "hunter2hunter2") || !strings.Contains(snapshot.DatabaseURL, "db:5432") {
+		t.Errorf("expected only the database password to be redacted, got %q", snapshot.DatabaseURL)
+	}
+}
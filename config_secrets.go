@@ -0,0 +1,171 @@
+package go11y
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+)
+
+const (
+	// SecretSchemeFile is the scheme of references to a file holding the secret, e.g. "file:/run/secrets/db_url".
+	// A resolver for it is registered by default.
+	SecretSchemeFile = "file"
+	// SecretSchemeVault is the scheme of references to a HashiCorp Vault secret, e.g. "vault:secret/data/db#url"
+	SecretSchemeVault = "vault"
+	// SecretSchemeAWSSecretsManager is the scheme of references to an AWS Secrets Manager secret, e.g.
+	// "aws-sm:prod/db-url"
+	SecretSchemeAWSSecretsManager = "aws-sm"
+)
+
+// SecretResolver resolves a reference to a secret (the part of a setting after "scheme:") to the secret's value.
+type SecretResolver interface {
+	Resolve(ctx context.Context, ref string) (value string, fault error)
+}
+
+// SecretResolverFunc adapts a function to a SecretResolver.
+type SecretResolverFunc func(ctx context.Context, ref string) (value string, fault error)
+
+// Resolve calls f(ctx, ref).
+func (f SecretResolverFunc) Resolve(ctx context.Context, ref string) (value string, fault error) {
+	return f(ctx, ref)
+}
+
+// SecretResolvers maps reference schemes (e.g. "vault") to the SecretResolver that resolves them.
+type SecretResolvers map[string]SecretResolver
+
+// FileSecretResolver resolves "file:" references by reading the referenced file, trimming the trailing newline that
+// most secret mounts (Kubernetes, Docker) leave in place.
+var FileSecretResolver = SecretResolverFunc(func(_ context.Context, ref string) (value string, fault error) {
+	b, err := os.ReadFile(ref)
+	if err != nil {
+		return "", fmt.Errorf("could not read secret file: %w", err)
+	}
+
+	return strings.TrimRight(string(b), "\r\n"), nil
+})
+
+// WithSecretResolver registers $resolver for settings starting with "$scheme:", e.g. a Vault client for "vault:".
+// LoadConfig resolves the DATABASE_URL and OTEL_URL settings with the registered resolvers after reading the
+// environment. go11y doesn't ship Vault or AWS Secrets Manager clients, so services that reference those stores must
+// register their own resolver; an unregistered "vault:" or "aws-sm:" reference is an error rather than being used as
+// the literal setting.
+func WithSecretResolver(scheme string, resolver SecretResolver) ConfigOption {
+	return func(c *Configuration) {
+		if c.secretResolvers == nil {
+			c.secretResolvers = SecretResolvers{}
+		}
+
+		c.secretResolvers[scheme] = resolver
+	}
+}
+
+// ResolveSecret resolves $value with the resolver registered in $resolvers for its scheme. Values that aren't secret
+// references (including URLs like "postgres://...") are returned unchanged.
+// $isSecret reports whether $value was a secret reference. The error never includes the resolved value.
+func ResolveSecret(
+	ctx context.Context,
+	value string,
+	resolvers SecretResolvers,
+) (
+	resolved string,
+	isSecret bool,
+	fault error,
+) {
+	scheme, ref, found := strings.Cut(value, ":")
+	if !found || strings.HasPrefix(ref, "//") {
+		return value, false, nil
+	}
+
+	resolver, ok := resolvers[scheme]
+	if !ok && scheme == SecretSchemeFile {
+		resolver, ok = FileSecretResolver, true
+	}
+
+	if !ok {
+		if scheme == SecretSchemeVault || scheme == SecretSchemeAWSSecretsManager {
+			return "", true, fmt.Errorf("no secret resolver registered for scheme '%s'", scheme)
+		}
+
+		return value, false, nil
+	}
+
+	resolved, err := resolver.Resolve(ctx, ref)
+	if err != nil {
+		return "", true, fmt.Errorf("could not resolve '%s' secret '%s': %w", scheme, ref, err)
+	}
+
+	return resolved, true, nil
+}
+
+// resolveSecrets resolves the sensitive settings of $c that are secret references
+func (c *Configuration) resolveSecrets(ctx context.Context) (fault error) {
+	settings := []struct {
+		name  string
+		value *string
+	}{
+		{"DATABASE_URL", &c.databaseURL},
+		{"OTEL_URL", &c.otelURL},
+		{"REDACTION_SALT", &c.redactionSalt},
+	}
+
+	for _, s := range settings {
+		resolved, isSecret, err := ResolveSecret(ctx, *s.value, c.secretResolvers)
+		if err != nil {
+			return fmt.Errorf("could not resolve %s: %w", s.name, err)
+		}
+
+		if isSecret {
+			*s.value = resolved
+			c.resolvedSecrets = append(c.resolvedSecrets, s.name)
+		}
+	}
+
+	return nil
+}
+
+// redactSetting redacts a URL setting for logging: the password and secret query parameters are redacted, and
+// settings that were resolved from a secret store (or aren't URLs) are redacted in their entirety.
+func redactSetting(value string, resolvedFromSecret bool) string {
+	if value == "" {
+		return ""
+	}
+
+	u, err := url.Parse(value)
+	if err != nil || u.Scheme == "" || (resolvedFromSecret && u.User == nil && u.RawQuery == "") {
+		return RedactSecret(value, 0)
+	}
+
+	return RedactURL(u)
+}
+
+// LogValue implements slog.LogValuer, so a Configuration can be logged without exposing its secrets: the database and
+// OTel URLs have their passwords and secret query parameters redacted.
+func (c *Configuration) LogValue() slog.Value {
+	resolved := func(name string) bool {
+		return slices.Contains(c.resolvedSecrets, name)
+	}
+
+	return slog.GroupValue(
+		slog.String("log_level", LevelToString(c.logLevel)),
+		slog.String("otel_url", redactSetting(c.otelURL, resolved("OTEL_URL"))),
+		slog.String("database_url", redactSetting(c.databaseURL, resolved("DATABASE_URL"))),
+		slog.String("service_name", c.serviceName),
+		slog.String("environment", string(c.environment)),
+		slog.String("log_output", c.logOutput),
+	)
+}
+
+// String implements fmt.Stringer with the redacted settings, so a Configuration printed with %v doesn't expose its
+// secrets either.
+func (c *Configuration) String() string {
+	parts := []string{}
+	for _, a := range c.LogValue().Group() {
+		parts = append(parts, a.Key+"="+a.Value.String())
+	}
+
+	return "{" + strings.Join(parts, " ") + "}"
+}
@@ -0,0 +1,51 @@
+package go11y_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cirruscomms/go11y"
+)
+
+func TestLoadConfigSecrets(t *testing.T) {
+	secretFile := filepath.Join(t.TempDir(), "db_url")
+	if err := os.WriteFile(secretFile, []byte("postgres://user:hunter2hunter2@db:5432/audit\n"), 0o600); err != nil {
+		t.Fatalf("failed to write secret file: %v", err)
+	}
+
+	t.Setenv("DATABASE_URL", "file:"+secretFile)
+	t.Setenv("OTEL_URL", "vault:secret/data/otel#url")
+
+	if _, err := go11y.LoadConfig(); err == nil || !strings.Contains(err.Error(), "no secret resolver registered for scheme 'vault'") {
+		t.Errorf("expected an unregistered vault reference to fail, got %v", err)
+	}
+
+	vault := go11y.SecretResolverFunc(func(ctx context.Context, ref string) (string, error) {
+		if ref != "secret/data/otel#url" {
+			return "", errors.New("not found")
+		}
+		return "https://collector:4318/v1/traces?token=s3cr3t-t0k3n-value", nil
+	})
+
+	cfg, err := go11y.LoadConfig(go11y.WithSecretResolver(go11y.SecretSchemeVault, vault))
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+
+	if cfg.DatabaseURL() != "postgres://user:hunter2hunter2@db:5432/audit" {
+		t.Errorf("expected the database url to be read from the file, got %q", cfg.DatabaseURL())
+	}
+	if cfg.OtelURL() != "https://collector:4318/v1/traces?token=s3cr3t-t0k3n-value" {
+		t.Errorf("expected the otel url to be resolved from vault, got %q", cfg.OtelURL())
+	}
+
+	printed := fmt.Sprintf("%v", cfg)
+	if strings.Contains(printed, "hunter2hunter2") || strings.Contains(printed, "s3cr3t-t0k3n-value") {
+		t.Errorf("expected the resolved secrets to be redacted, got %s", printed)
+	}
+}
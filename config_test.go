@@ -0,0 +1,81 @@
+package go11y_test
+
+import (
+	"log/slog"
+	"slices"
+	"testing"
+
+	"github.com/cirruscomms/go11y"
+)
+
+// staticConfig is a bespoke Configurator, as a service supplying its own configuration source would write
+type staticConfig struct{}
+
+func (staticConfig) LogLevel() slog.Level  { return go11y.LevelDebug }
+func (staticConfig) OtelURL() string       { return "" }
+func (staticConfig) DatabaseURL() string   { return "postgres://localhost/audit" }
+func (staticConfig) ServiceName() string   { return "static" }
+func (staticConfig) TrimPaths() []string   { return nil }
+func (staticConfig) TrimModules() []string { return nil }
+
+var _ go11y.Configurator = staticConfig{}
+
+func TestNewConfig(t *testing.T) {
+	cfg := go11y.NewConfig()
+	if cfg.LogLevel() != go11y.LevelInfo {
+		t.Errorf("expected the log level to default to info, got %v", cfg.LogLevel())
+	}
+
+	cfg = go11y.NewConfig(
+		go11y.WithLogLevel(go11y.LevelDebug),
+		go11y.WithOtelURL("http://collector:4318/v1/traces"),
+		go11y.WithDatabaseURL("postgres://localhost/audit"),
+		go11y.WithServiceName("billing"),
+		go11y.WithTrimModules("github.com/cirruscomms/"),
+		go11y.WithTrimPaths("/src/", "/go/pkg/mod/"),
+		go11y.WithLogOutput("stderr"),
+	)
+
+	if cfg.LogLevel() != go11y.LevelDebug {
+		t.Errorf("expected a debug log level, got %v", cfg.LogLevel())
+	}
+	if cfg.OtelURL() != "http://collector:4318/v1/traces" {
+		t.Errorf("unexpected otel url %q", cfg.OtelURL())
+	}
+	if cfg.DatabaseURL() != "postgres://localhost/audit" {
+		t.Errorf("unexpected database url %q", cfg.DatabaseURL())
+	}
+	if cfg.ServiceName() != "billing" {
+		t.Errorf("unexpected service name %q", cfg.ServiceName())
+	}
+	if !slices.Equal(cfg.TrimModules(), []string{"github.com/cirruscomms/"}) {
+		t.Errorf("unexpected trim modules %v", cfg.TrimModules())
+	}
+	if !slices.Equal(cfg.TrimPaths(), []string{"/src/", "/go/pkg/mod/"}) {
+		t.Errorf("unexpected trim paths %v", cfg.TrimPaths())
+	}
+	if cfg.LogOutput() != "stderr" {
+		t.Errorf("unexpected log output %q", cfg.LogOutput())
+	}
+}
+
+func TestLoadConfigOverrides(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "debug")
+	t.Setenv("OTEL_SERVICE_NAME", "from-env")
+	t.Setenv("DATABASE_URL", "postgres://env/audit")
+
+	cfg, err := go11y.LoadConfig(go11y.WithServiceName("override"))
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+
+	if cfg.ServiceName() != "override" {
+		t.Errorf("expected the override to take precedence over the environment, got %q", cfg.ServiceName())
+	}
+	if cfg.DatabaseURL() != "postgres://env/audit" {
+		t.Errorf("expected the database url to be loaded from the environment, got %q", cfg.DatabaseURL())
+	}
+	if cfg.LogLevel() != go11y.LevelDebug {
+		t.Errorf("expected the log level to be loaded from the environment, got %v", cfg.LogLevel())
+	}
+}
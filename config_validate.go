@@ -0,0 +1,259 @@
+package go11y
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ValidationMode controls what happens when the configuration is invalid, see ValidateConfig
+type ValidationMode int
+
+const (
+	// ValidationStrict fails LoadConfig and Initialise with every configuration problem found
+	ValidationStrict ValidationMode = iota
+	// ValidationPermissive logs every configuration problem as a warning and carries on, disabling tracing (i.e. using
+	// a noop exporter) if the OTel configuration is the problem and defaulting an invalid log level to debug
+	ValidationPermissive
+	// ValidationOff skips validation, as go11y did before validation was introduced
+	ValidationOff
+)
+
+// ParseValidationMode maps "strict", "permissive" and "off" to a ValidationMode, defaulting to ValidationStrict.
+func ParseValidationMode(mode string) ValidationMode {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case "permissive":
+		return ValidationPermissive
+	case "off", "none", "disabled":
+		return ValidationOff
+	default:
+		return ValidationStrict
+	}
+}
+
+// ConfigError is a problem with a single configuration setting
+type ConfigError struct {
+	Setting string // the environment variable name of the setting, e.g. "OTEL_URL"
+	Err     error
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("invalid %s: %s", e.Setting, e.Err)
+}
+
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationConfigurator is implemented by Configurators that choose how they are validated by Initialise.
+// Configuration implements it, other Configurators are validated strictly without connectivity probes.
+type ValidationConfigurator interface {
+	// ValidationMode is what to do when the configuration is invalid
+	ValidationMode() ValidationMode
+	// ProbeTimeout is the timeout of the connectivity probes of the OTel collector and database, 0 to skip them
+	ProbeTimeout() time.Duration
+}
+
+// WithValidation sets what LoadConfig and Initialise do when the configuration is invalid.
+func WithValidation(mode ValidationMode) ConfigOption {
+	return func(c *Configuration) {
+		c.validationMode = mode
+	}
+}
+
+// WithConnectivityProbe makes Initialise check the OTel collector and database ports accept connections within
+// $timeout, so an unreachable endpoint is reported at startup rather than at first use.
+func WithConnectivityProbe(timeout time.Duration) ConfigOption {
+	return func(c *Configuration) {
+		c.probeTimeout = timeout
+	}
+}
+
+// ValidationMode returns the configured validation mode.
+// This method is part of the ValidationConfigurator interface.
+func (c *Configuration) ValidationMode() ValidationMode {
+	return c.validationMode
+}
+
+// ProbeTimeout returns the configured timeout of the connectivity probes, 0 if they are disabled.
+// This method is part of the ValidationConfigurator interface.
+func (c *Configuration) ProbeTimeout() time.Duration {
+	return c.probeTimeout
+}
+
+// ParseLevel maps a string representation of a log level to its slog.Level like StringToLevel, but returns an error
+// for unrecognised names instead of defaulting to debug.
+func ParseLevel(level string) (parsed slog.Level, fault error) {
+	switch strings.ToLower(level) {
+	case "develop", "debug", "info", "notice", "warning", "warn", "error", "err", "panic", "fatal":
+		return StringToLevel(level), nil
+	default:
+		return LevelDebug, fmt.Errorf("unknown log level '%s'", level)
+	}
+}
+
+// ValidateConfig checks the OTel URL and database URL of $cfg are well formed and, if $probeTimeout is positive, that
+// their hosts accept connections within it, that its propagators are supported, and that a salt is set if secrets are
+// hashed (see WithRedactionMode).
+// Every problem found is returned, joined, as a *ConfigError.
+func ValidateConfig(ctx context.Context, cfg Configurator, probeTimeout time.Duration) (fault error) {
+	problems := validateConfig(ctx, cfg, probeTimeout)
+
+	errs := make([]error, 0, len(problems))
+	for _, p := range problems {
+		errs = append(errs, p)
+	}
+
+	return errors.Join(errs...)
+}
+
+func validateConfig(ctx context.Context, cfg Configurator, probeTimeout time.Duration) (problems []*ConfigError) {
+	if otelURL := cfg.OtelURL(); otelURL != "" {
+		address, err := otelAddress(otelURL)
+		if err == nil && probeTimeout > 0 {
+			err = probe(ctx, address, probeTimeout)
+		}
+
+		if err != nil {
+			problems = append(problems, &ConfigError{Setting: "OTEL_URL", Err: err})
+		}
+	}
+
+	if dbURL := cfg.DatabaseURL(); dbURL != "" {
+		address, err := databaseAddress(dbURL)
+		// unix sockets aren't probed
+		if err == nil && probeTimeout > 0 && !strings.HasPrefix(address, "/") {
+			err = probe(ctx, address, probeTimeout)
+		}
+
+		if err != nil {
+			problems = append(problems, &ConfigError{Setting: "DATABASE_URL", Err: err})
+		}
+	}
+
+	if _, err := textMapPropagator(configPropagators(cfg)); err != nil {
+		problems = append(problems, &ConfigError{Setting: "OTEL_PROPAGATORS", Err: err})
+	}
+
+	if mode, salt := configRedaction(cfg); mode == RedactHash && salt == "" {
+		problems = append(problems, &ConfigError{Setting: "REDACTION_SALT", Err: errRedactionSaltMissing})
+	}
+
+	return problems
+}
+
+// otelAddress returns the host:port of the OTLP HTTP URL $otelURL, or an error if it isn't a usable URL
+func otelAddress(otelURL string) (address string, fault error) {
+	u, err := url.Parse(otelURL)
+	if err != nil {
+		// the parse error quotes the URL, which could contain a token
+		return "", errors.New("not a valid URL")
+	}
+
+	port := u.Port()
+	switch u.Scheme {
+	case "http":
+		if port == "" {
+			port = "80"
+		}
+	case "https":
+		if port == "" {
+			port = "443"
+		}
+	default:
+		return "", fmt.Errorf("scheme must be http or https, got '%s'", u.Scheme)
+	}
+
+	if u.Hostname() == "" {
+		return "", errors.New("missing host")
+	}
+
+	return net.JoinHostPort(u.Hostname(), port), nil
+}
+
+// databaseAddress returns the host:port of the Postgres connection string $dbURL, or an error if it can't be parsed
+func databaseAddress(dbURL string) (address string, fault error) {
+	pgCfg, err := pgx.ParseConfig(dbURL)
+	if err != nil {
+		// pgx redacts the password in its parse errors
+		return "", fmt.Errorf("could not parse connection string: %w", err)
+	}
+
+	if strings.HasPrefix(pgCfg.Host, "/") {
+		return pgCfg.Host, nil
+	}
+
+	return net.JoinHostPort(pgCfg.Host, strconv.Itoa(int(pgCfg.Port))), nil
+}
+
+// probe checks $address accepts TCP connections within $timeout
+func probe(ctx context.Context, address string, timeout time.Duration) (fault error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", address)
+	if err != nil {
+		return fmt.Errorf("%w: could not connect to %s: %w", ErrEndpointUnreachable, address, err)
+	}
+
+	_ = conn.Close()
+
+	return nil
+}
+
+// validateForInitialise validates $cfg as configured by it, returning the problems Initialise should log or an error if
+// the configuration is strictly validated and has problems.
+// When the OTel collector isn't required, it is always probed and its problems never fail Initialise.
+func validateForInitialise(ctx context.Context, cfg Configurator) (problems []*ConfigError, fault error) {
+	mode, probeTimeout := configValidation(cfg)
+	otelRequired := configOtelRequired(cfg)
+	if mode == ValidationOff && otelRequired {
+		return nil, nil
+	}
+	if mode == ValidationOff {
+		// only the collector is checked, so tracing can degrade
+		cfg = NewConfig(WithOtelURL(cfg.OtelURL()))
+	}
+
+	if c, ok := cfg.(*Configuration); ok {
+		problems = append(problems, c.problems...)
+	}
+
+	if !otelRequired && probeTimeout <= 0 {
+		probeTimeout = otelProbeTimeout
+	}
+
+	problems = append(problems, validateConfig(ctx, cfg, probeTimeout)...)
+
+	errs := make([]error, 0, len(problems))
+	for _, p := range problems {
+		if p.Setting == "OTEL_URL" && !otelRequired {
+			// tracing degrades instead, see Initialise
+			continue
+		}
+		errs = append(errs, p)
+	}
+
+	if mode == ValidationStrict && len(errs) != 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return problems, nil
+}
+
+// configValidation returns how $cfg should be validated
+func configValidation(cfg Configurator) (mode ValidationMode, probeTimeout time.Duration) {
+	if vc, ok := cfg.(ValidationConfigurator); ok {
+		return vc.ValidationMode(), vc.ProbeTimeout()
+	}
+
+	return ValidationStrict, 0
+}
@@ -0,0 +1,87 @@
+package go11y_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cirruscomms/go11y"
+)
+
+func TestLoadConfigValidation(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "verbose")
+	t.Setenv("OTEL_URL", "ftp://collector/v1/traces")
+	t.Setenv("DATABASE_URL", "postgres://user:hunter2@db:notaport/audit")
+
+	_, err := go11y.LoadConfig()
+	if err == nil {
+		t.Fatalf("expected the invalid configuration to fail")
+	}
+
+	for _, setting := range []string{"LOG_LEVEL", "OTEL_URL", "DATABASE_URL"} {
+		if !strings.Contains(err.Error(), "invalid "+setting) {
+			t.Errorf("expected every problem to be reported, %s missing from %v", setting, err)
+		}
+	}
+
+	var configErr *go11y.ConfigError
+	if !errors.As(err, &configErr) {
+		t.Errorf("expected the problems to be ConfigErrors, got %T", err)
+	}
+
+	if strings.Contains(err.Error(), "hunter2") {
+		t.Errorf("expected the database password not to be in the error, got %v", err)
+	}
+}
+
+func TestPermissiveValidation(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "verbose")
+	t.Setenv("OTEL_URL", "ftp://collector/v1/traces")
+	t.Setenv("CONFIG_VALIDATION", "permissive")
+
+	cfg, err := go11y.LoadConfig()
+	if err != nil {
+		t.Fatalf("expected permissive validation to load the config, got %v", err)
+	}
+
+	buf := new(bytes.Buffer)
+	_, o, err := go11y.Initialise(context.Background(), cfg, buf, buf)
+	if err != nil {
+		t.Fatalf("expected permissive validation to initialise, got %v", err)
+	}
+
+	if o.ConfigSnapshot().Exporter != "none" {
+		t.Errorf("expected tracing to be disabled, got %+v", o.ConfigSnapshot())
+	}
+
+	if strings.Count(buf.String(), "invalid configuration - continuing in permissive mode") != 2 {
+		t.Errorf("expected a warning for each problem, got %s", buf.String())
+	}
+	if !strings.Contains(buf.String(), `"tracing":"disabled"`) {
+		t.Errorf("expected the OTel problem to report tracing as disabled, got %s", buf.String())
+	}
+}
+
+func TestConnectivityProbe(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	address := listener.Addr().String()
+
+	cfg := go11y.NewConfig(go11y.WithOtelURL("http://" + address + "/v1/traces"))
+	if err := go11y.ValidateConfig(context.Background(), cfg, time.Second); err != nil {
+		t.Errorf("expected a listening collector to pass the probe, got %v", err)
+	}
+
+	_ = listener.Close()
+
+	cfg = go11y.NewConfig(go11y.WithOtelURL("http://"+address+"/v1/traces"), go11y.WithConnectivityProbe(time.Second))
+	if _, _, err := go11y.Initialise(context.Background(), cfg, new(bytes.Buffer), new(bytes.Buffer)); err == nil {
+		t.Errorf("expected an unreachable collector to fail initialisation")
+	}
+}
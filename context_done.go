@@ -0,0 +1,109 @@
+package go11y
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	otelAttribute "go.opentelemetry.io/otel/attribute"
+	otelTrace "go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// DoneCanceled is the done reason of a context that was cancelled, e.g. because the client disconnected
+	DoneCanceled = "canceled"
+	// DoneDeadlineExceeded is the done reason of a context whose deadline passed
+	DoneDeadlineExceeded = "deadline_exceeded"
+)
+
+// FieldDoneReason is the structured log field name for "done_reason", DoneCanceled or DoneDeadlineExceeded
+const FieldDoneReason = "done_reason"
+
+// FieldDoneCause is the structured log field name for "done_cause", the cause of a context being done
+const FieldDoneCause = "done_cause"
+
+// FieldDeadlineExceededBy is the structured log field name for "deadline_exceeded_by"
+const FieldDeadlineExceededBy = "deadline_exceeded_by"
+
+// FieldCheckpoint is the structured log field name for "checkpoint"
+const FieldCheckpoint = "checkpoint"
+
+// doneReason returns why $ctx is done, or "" if it isn't
+func doneReason(ctx context.Context) string {
+	switch err := ctx.Err(); {
+	case err == nil:
+		return ""
+	case errors.Is(err, context.DeadlineExceeded):
+		return DoneDeadlineExceeded
+	default:
+		return DoneCanceled
+	}
+}
+
+// doneArgs returns why $ctx is done as log args: the reason, the cause set by context.WithCancelCause and the like if
+// it differs from the reason, and how long ago the deadline passed
+func doneArgs(ctx context.Context, now time.Time) (args []any) {
+	args = []any{FieldDoneReason, doneReason(ctx)}
+
+	if cause := context.Cause(ctx); cause != nil && cause != ctx.Err() {
+		args = append(args, FieldDoneCause, cause.Error())
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && now.After(deadline) {
+		args = append(args, FieldDeadlineExceededBy, now.Sub(deadline))
+	}
+
+	return args
+}
+
+// WhyDone reports why $ctx is done, if it is: a warning is logged with the reason, the cause (see context.Cause) and
+// how long ago the deadline passed, and the span in $ctx is tagged with the same as context.* attributes and a
+// "context done" event. It returns whether $ctx is done, so handlers can call it where they give up on a request.
+// RequestLoggerMiddlewareMux calls it for every request whose context is done when the handler returns.
+func (o *Observer) WhyDone(ctx context.Context) (done bool) {
+	if ctx.Err() == nil {
+		return false
+	}
+
+	args := doneArgs(ctx, o.clock.Now())
+
+	attrs := make([]otelAttribute.KeyValue, 0, len(args)/2)
+	for i := 0; i < len(args); i += 2 {
+		attrs = appendAttribute(attrs, "context."+args[i].(string), args[i+1])
+	}
+
+	span := otelTrace.SpanFromContext(ctx)
+	span.SetAttributes(attrs...)
+	span.AddEvent("context done", otelTrace.WithAttributes(attrs...))
+
+	o.log(ctx, 3, LevelWarning, "context done", args...)
+
+	return true
+}
+
+// Checkpoint logs reaching the checkpoint $name at Debug level with the time left until the deadline of $ctx, if it
+// has one, so slow steps can be found in requests that run out of time. If $ctx is already done, the reason is
+// reported as by WhyDone instead, and true is returned.
+func (o *Observer) Checkpoint(ctx context.Context, name string) (done bool) {
+	if ctx.Err() != nil {
+		args := append([]any{FieldCheckpoint, name}, doneArgs(ctx, o.clock.Now())...)
+
+		otelTrace.SpanFromContext(ctx).AddEvent("context done", otelTrace.WithAttributes(
+			otelAttribute.String("context."+FieldCheckpoint, name),
+			otelAttribute.String("context."+FieldDoneReason, doneReason(ctx)),
+		))
+
+		o.log(ctx, 3, LevelWarning, "context done", args...)
+
+		return true
+	}
+
+	args := []any{FieldCheckpoint, name}
+	if deadline, ok := ctx.Deadline(); ok {
+		args = append(args, FieldDeadlineRemaining, deadline.Sub(o.clock.Now()))
+	}
+
+	o.log(ctx, 3, LevelDebug, "checkpoint", args...)
+
+	return false
+}
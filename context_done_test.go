@@ -0,0 +1,115 @@
+package go11y_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cirruscomms/go11y"
+)
+
+func TestWhyDone(t *testing.T) {
+	buf := new(bytes.Buffer)
+	ctx, _, spans, err := go11y.InitialiseTestTracerInMemory(context.Background(), go11y.LevelDebug, buf, buf)
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	ctx, o, end, err := go11y.StartSpan(ctx, nil, "handler", go11y.SpanKindServer)
+	if err != nil {
+		t.Fatalf("failed to start span: %v", err)
+	}
+
+	if o.WhyDone(ctx) {
+		t.Errorf("expected a live context not to be done")
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	cancel(errors.New("client disconnected"))
+
+	if !o.WhyDone(ctx) {
+		t.Errorf("expected a cancelled context to be done")
+	}
+	end()
+
+	for _, expected := range []string{
+		`"msg":"context done"`,
+		`"` + go11y.FieldDoneReason + `":"` + go11y.DoneCanceled + `"`,
+		`"` + go11y.FieldDoneCause + `":"client disconnected"`,
+	} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("expected %s to be logged, got %s", expected, buf.String())
+		}
+	}
+
+	span, _ := spans.Find("handler")
+	attrs := map[string]string{}
+	for _, a := range span.Attributes {
+		attrs[string(a.Key)] = a.Value.Emit()
+	}
+
+	if attrs["context.done_reason"] != go11y.DoneCanceled || attrs["context.done_cause"] != "client disconnected" {
+		t.Errorf("expected the span to be tagged with the cancellation, got %v", attrs)
+	}
+}
+
+func TestCheckpoint(t *testing.T) {
+	buf := new(bytes.Buffer)
+	ctx, o, err := go11y.InitialiseTestLogger(context.Background(), go11y.LevelDebug, buf, buf)
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Hour)
+	defer cancel()
+
+	if o.Checkpoint(ctx, "loaded account") {
+		t.Errorf("expected the context not to be done")
+	}
+
+	if !strings.Contains(buf.String(), `"checkpoint":"loaded account","deadline_remaining":`) {
+		t.Errorf("expected the checkpoint to be logged with the deadline remaining, got %s", buf.String())
+	}
+
+	expired, cancelExpired := context.WithDeadline(ctx, time.Now().Add(-time.Second))
+	defer cancelExpired()
+
+	if !o.Checkpoint(expired, "charged card") {
+		t.Errorf("expected the context to be done")
+	}
+
+	for _, expected := range []string{`"done_reason":"deadline_exceeded"`, `"deadline_exceeded_by":`} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("expected %s to be logged, got %s", expected, buf.String())
+		}
+	}
+}
+
+func TestRequestLoggerContextDone(t *testing.T) {
+	buf := new(bytes.Buffer)
+	ctx, _, err := go11y.InitialiseTestLogger(context.Background(), go11y.LevelDebug, buf, buf)
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	mw, err := go11y.RequestLoggerMiddlewareMux(ctx)
+	if err != nil {
+		t.Fatalf("failed to create middleware: %v", err)
+	}
+
+	reqCtx, cancel := context.WithCancelCause(context.Background())
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/reports", nil).WithContext(reqCtx)
+
+	mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cancel(errors.New("client went away"))
+	})).ServeHTTP(httptest.NewRecorder(), r)
+
+	if !strings.Contains(buf.String(), `"done_cause":"client went away"`) {
+		t.Errorf("expected the cancellation cause to be logged, got %s", buf.String())
+	}
+}
@@ -0,0 +1,97 @@
+package go11y
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"sync"
+)
+
+// crashDump keeps the most recent records in a ring buffer, whatever their level, to be dumped when the application
+// crashes, see WithCrashDump
+type crashDump struct {
+	output  io.Writer
+	handler slog.Handler // formats records into the ring buffer, set by Initialise
+
+	mu      sync.Mutex
+	records [][]byte
+	next    int
+	full    bool
+}
+
+// WithCrashDump keeps the last $records records in memory, at every level down to LevelDevelop even if it is below
+// the Observer's level, and writes them to $output (os.Stderr if nil) when the application crashes: when Fatal or
+// Panic is called, or a goroutine started with Go or a job run with RunJob panics. This gives the context of a crash
+// in a post-mortem even when the service runs at Info level. The records are redacted as they would be when logged, but
+// formatting the records below the Observer's level has a cost, so keep $records small on hot paths.
+func WithCrashDump(records int, output io.Writer) Option {
+	return func(o *Observer) {
+		if records <= 0 {
+			return
+		}
+
+		if output == nil {
+			output = os.Stderr
+		}
+
+		o.crashDump = &crashDump{output: output, records: make([][]byte, records)}
+	}
+}
+
+// Write keeps the JSON record $p in the ring buffer, replacing the oldest record if it is full
+func (d *crashDump) Write(p []byte) (n int, fault error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.records[d.next] = bytes.Clone(p)
+	d.next = (d.next + 1) % len(d.records)
+	if d.next == 0 {
+		d.full = true
+	}
+
+	return len(p), nil
+}
+
+// recordCrashDump formats the record of $msg at $level, logged by the callsite $pc with $args and the Observer's stable
+// args, into the ring buffer of the Observer's crash dump, if it has one
+func (o *Observer) recordCrashDump(ctx context.Context, pc uintptr, level slog.Level, msg string, args []any) {
+	if o.crashDump == nil || o.crashDump.handler == nil {
+		return
+	}
+
+	r := slog.NewRecord(o.clock.Now(), level, msg, pc)
+	r.AddAttrs(appendDedupedAttrs(nil, slices.Concat(o.stableArgs, args))...)
+
+	_ = o.crashDump.handler.Handle(ctx, r)
+}
+
+// DumpCrash writes the records kept by WithCrashDump to its output, oldest first, after a line giving $reason. It is
+// called by go11y when the application crashes, and can be called by recover handlers of the application's own. It
+// does nothing if the Observer doesn't keep a crash dump.
+func (o *Observer) DumpCrash(reason string) {
+	d := o.crashDump
+	if d == nil {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	recent := d.records[:d.next]
+	if d.full {
+		recent = append(slices.Clone(d.records[d.next:]), d.records[:d.next]...)
+	}
+
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "go11y crash dump: %s - the last %d records:\n", reason, len(recent))
+	for _, r := range recent {
+		buf.Write(r)
+	}
+	buf.WriteString("go11y crash dump: end\n")
+
+	_, _ = d.output.Write(buf.Bytes())
+}
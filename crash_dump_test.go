@@ -0,0 +1,79 @@
+package go11y_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/cirruscomms/go11y"
+)
+
+func TestCrashDump(t *testing.T) {
+	t.Setenv("ENV", "test")
+
+	out := new(bytes.Buffer)
+	dump := new(bytes.Buffer)
+
+	cfg := go11y.CreateConfig(go11y.LevelInfo, "", "", "", []string{}, []string{})
+
+	ctx, o, err := go11y.Initialise(context.Background(), cfg, out, out, go11y.WithCrashDump(3, dump), "service", "test")
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	o.Info("first record")
+	o.Debug("second record", "password", "hunter2")
+	o.Develop("third record")
+
+	if bytes.Contains(out.Bytes(), []byte("second record")) {
+		t.Fatalf("expected the debug record not to be logged at info level")
+	}
+
+	if dump.Len() != 0 {
+		t.Fatalf("expected nothing to be dumped before a crash, got %s", dump.String())
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("expected Panic to panic")
+			}
+		}()
+
+		o.Panic("crashed", errors.New("TestCrashDump"))
+	}()
+
+	lines := strings.Split(strings.TrimSpace(dump.String()), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("expected a header, 3 records and a footer, got:\n%s", dump.String())
+	}
+
+	if lines[0] != "go11y crash dump: panic: crashed - the last 3 records:" {
+		t.Errorf("unexpected header: %s", lines[0])
+	}
+
+	for i, expected := range []string{`"msg":"second record"`, `"msg":"third record"`, `"msg":"crashed"`} {
+		if !strings.Contains(lines[i+1], expected) || !strings.Contains(lines[i+1], `"service":"test"`) {
+			t.Errorf("expected record %d to contain %s with the stable args, got %s", i, expected, lines[i+1])
+		}
+	}
+
+	if strings.Contains(dump.String(), "hunter2") {
+		t.Errorf("expected the dumped records to be redacted, got %s", dump.String())
+	}
+
+	dump.Reset()
+
+	wait := go11y.Go(ctx, "worker", func(ctx context.Context) error {
+		panic("boom")
+	})
+	if err := wait(); err == nil {
+		t.Errorf("expected the panicking goroutine to return an error")
+	}
+
+	if !strings.HasPrefix(dump.String(), "go11y crash dump: goroutine worker panicked: boom") {
+		t.Errorf("expected a crash dump when a goroutine panics, got %s", dump.String())
+	}
+}
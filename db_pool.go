@@ -0,0 +1,199 @@
+package go11y
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DBPool is the subset of *pgxpool.Pool used by a DBPoolMonitor
+type DBPool interface {
+	Stat() *pgxpool.Stat
+	Ping(ctx context.Context) error
+}
+
+// DBPoolMonitorOpts are the options used to create a DBPoolMonitor
+type DBPoolMonitorOpts struct {
+	Name        string        // optional - value of the "pool" label, unique per monitored pool, defaults to "default"
+	Interval    time.Duration // optional - how often the pool stats are logged at debug level, defaults to 1 minute
+	PingTimeout time.Duration // optional - timeout of the readiness ping, defaults to 2 seconds
+}
+
+// DBPoolMonitor publishes the stats of a pgx connection pool (e.g. the one used by storer.StoreRequest for the audit
+// database) as Prometheus metrics, periodically logs them at debug level and provides a readiness check.
+// The metrics are collected when scraped, so they are always current: go11y_db_pool_acquired_connections,
+// go11y_db_pool_idle_connections, go11y_db_pool_total_connections, go11y_db_pool_max_connections,
+// go11y_db_pool_acquires_total, go11y_db_pool_empty_acquires_total, go11y_db_pool_canceled_acquires_total and
+// go11y_db_pool_acquire_wait_seconds_total.
+type DBPoolMonitor struct {
+	ctx      context.Context
+	o        *Observer
+	pool     DBPool
+	name     string
+	interval time.Duration
+	timeout  time.Duration
+
+	acquired      *prometheus.Desc
+	idle          *prometheus.Desc
+	total         *prometheus.Desc
+	max           *prometheus.Desc
+	acquires      *prometheus.Desc
+	emptyAcquires *prometheus.Desc
+	canceled      *prometheus.Desc
+	waitSeconds   *prometheus.Desc
+}
+
+// NewDBPoolMonitor creates a DBPoolMonitor for $pool logging with the Observer in $ctxWithObserver and registers it
+// with the default Prometheus registerer, so the pool stats are published on the /internal/metrics endpoint.
+// A monitor created before with the same name is unregistered, so its metrics are published for the new pool.
+// If the Observer cannot be retrieved from the provided context, an error is returned.
+func NewDBPoolMonitor(ctxWithObserver context.Context, pool DBPool, opts DBPoolMonitorOpts) (monitor *DBPoolMonitor, fault error) {
+	ctx, o, err := Get(ctxWithObserver)
+	if err != nil {
+		return nil, fmt.Errorf("could not get go11y observer from context: %w", err)
+	}
+
+	if pool == nil {
+		return nil, errors.New("pool cannot be nil")
+	}
+
+	if opts.Name == "" {
+		opts.Name = "default"
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = time.Minute
+	}
+	if opts.PingTimeout <= 0 {
+		opts.PingTimeout = 2 * time.Second
+	}
+
+	labels := prometheus.Labels{"pool": opts.Name}
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc("go11y_db_pool_"+name, help, nil, labels)
+	}
+
+	monitor = &DBPoolMonitor{
+		ctx:      ctx,
+		o:        o,
+		pool:     pool,
+		name:     opts.Name,
+		interval: opts.Interval,
+		timeout:  opts.PingTimeout,
+
+		acquired:      desc("acquired_connections", "Number of connections currently acquired from the pool"),
+		idle:          desc("idle_connections", "Number of idle connections in the pool"),
+		total:         desc("total_connections", "Number of connections in the pool"),
+		max:           desc("max_connections", "Maximum size of the pool"),
+		acquires:      desc("acquires_total", "Number of successful acquires from the pool"),
+		emptyAcquires: desc("empty_acquires_total", "Number of acquires that waited for a connection"),
+		canceled:      desc("canceled_acquires_total", "Number of acquires cancelled by their context"),
+		waitSeconds:   desc("acquire_wait_seconds_total", "Time spent waiting for a connection by empty acquires"),
+	}
+
+	if err := prometheus.Register(monitor); err != nil {
+		registered := prometheus.AlreadyRegisteredError{}
+		if !errors.As(err, &registered) {
+			return nil, fmt.Errorf("could not register the pool metrics: %w", err)
+		}
+
+		// a monitor with the same name was created before, e.g. for a pool that has since been recreated, so it is
+		// replaced rather than left publishing the stats of the old pool
+		prometheus.Unregister(registered.ExistingCollector)
+
+		if err := prometheus.Register(monitor); err != nil {
+			return nil, fmt.Errorf("could not register the pool metrics: %w", err)
+		}
+	}
+
+	return monitor, nil
+}
+
+// Describe implements prometheus.Collector
+func (m *DBPoolMonitor) Describe(ch chan<- *prometheus.Desc) {
+	ch <- m.acquired
+	ch <- m.idle
+	ch <- m.total
+	ch <- m.max
+	ch <- m.acquires
+	ch <- m.emptyAcquires
+	ch <- m.canceled
+	ch <- m.waitSeconds
+}
+
+// Collect implements prometheus.Collector
+func (m *DBPoolMonitor) Collect(ch chan<- prometheus.Metric) {
+	s := m.pool.Stat()
+
+	ch <- prometheus.MustNewConstMetric(m.acquired, prometheus.GaugeValue, float64(s.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(m.idle, prometheus.GaugeValue, float64(s.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(m.total, prometheus.GaugeValue, float64(s.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(m.max, prometheus.GaugeValue, float64(s.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(m.acquires, prometheus.CounterValue, float64(s.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(m.emptyAcquires, prometheus.CounterValue, float64(s.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(m.canceled, prometheus.CounterValue, float64(s.CanceledAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(m.waitSeconds, prometheus.CounterValue, s.EmptyAcquireWaitTime().Seconds())
+}
+
+// Run logs the pool stats at debug level every interval until $ctx is done.
+// It blocks, so it is usually run in its own goroutine.
+func (m *DBPoolMonitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		m.Log()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Log logs the current pool stats once, at debug level.
+func (m *DBPoolMonitor) Log() {
+	s := m.pool.Stat()
+
+	m.o.log(m.ctx, 4, LevelDebug, "database pool stats",
+		FieldDBPool, m.name,
+		"acquired_connections", s.AcquiredConns(),
+		"idle_connections", s.IdleConns(),
+		"total_connections", s.TotalConns(),
+		"max_connections", s.MaxConns(),
+		"empty_acquires", s.EmptyAcquireCount(),
+		"acquire_wait", s.EmptyAcquireWaitTime().String(),
+	)
+}
+
+// Ready pings the database through the pool, returning an error if it can't be reached within the ping timeout.
+func (m *DBPoolMonitor) Ready(ctx context.Context) (fault error) {
+	ctx, cancel := context.WithTimeout(ctx, m.timeout)
+	defer cancel()
+
+	if err := m.pool.Ping(ctx); err != nil {
+		return fmt.Errorf("could not ping database pool '%s': %w", m.name, err)
+	}
+
+	return nil
+}
+
+// ReadinessHandler returns a handler for a readiness probe endpoint, responding 200 when the database can be reached
+// and 503 (logging a warning) when it can't.
+func (m *DBPoolMonitor) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := m.Ready(r.Context()); err != nil {
+			m.o.log(m.ctx, 4, LevelWarning, "database pool not ready", FieldDBPool, m.name, "error", err.Error())
+			http.Error(w, "database unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok"))
+	})
+}
@@ -0,0 +1,73 @@
+package go11y
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// defaultObserver is the Observer returned by Default, set by Initialise and SetDefault
+var defaultObserver atomic.Pointer[Observer]
+
+var (
+	fallbackOnce     sync.Once
+	fallbackObserver *Observer
+)
+
+// Default returns the default Observer: the one set by SetDefault, which Initialise calls with the Observer it creates.
+// Until then, it returns an Observer that logs at info level to stdout and errors to stderr, without tracing, so code
+// with no context to get an Observer from can always log. It is safe for concurrent use.
+func Default() *Observer {
+	if o := defaultObserver.Load(); o != nil {
+		return o
+	}
+
+	fallbackOnce.Do(func() {
+		registerPipelineMetrics()
+
+		cfg := NewConfig()
+		o := &Observer{
+			cfg:            cfg,
+			output:         os.Stdout,
+			errOutput:      os.Stderr,
+			errorLevel:     DefaultErrorOutputLevel,
+			level:          configLogLevel(cfg),
+			redactAttrs:    true,
+			spanMu:         &sync.Mutex{},
+			clock:          SystemClock{},
+			spanLimits:     SpanAttributeLimits{}.withDefaults(),
+			spanEventLevel: LevelDevelop,
+			sourceMode:     configSourceMode(cfg),
+			fieldSchema:    configFieldSchema(cfg),
+			propagators:    PropagatorsNone,
+		}
+
+		o.outLogger = slog.New(o.newHandler(o.output))
+		o.errLogger = slog.New(o.newHandler(o.errOutput))
+
+		fallbackObserver = o
+	})
+
+	return fallbackObserver
+}
+
+// SetDefault makes $o the Observer returned by Default, or restores the stdout/stderr Observer if $o is nil.
+// Unlike Initialise, it doesn't change slog's default logger or the process-wide redaction settings.
+// It is safe for concurrent use.
+func SetDefault(o *Observer) {
+	defaultObserver.Store(o)
+}
+
+// FromContext returns the Observer in $ctx, or the default Observer (see Default) if there isn't one, so it never
+// returns nil.
+func FromContext(ctx context.Context) (observer *Observer) {
+	if ctx != nil {
+		if o, ok := ctx.Value(obsKeyInstance).(*Observer); ok {
+			return o
+		}
+	}
+
+	return Default()
+}
@@ -0,0 +1,62 @@
+package go11y_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/cirruscomms/go11y"
+)
+
+func TestDefault(t *testing.T) {
+	t.Cleanup(func() { go11y.SetDefault(nil) })
+
+	go11y.SetDefault(nil)
+	if go11y.Default() == nil || go11y.FromContext(context.Background()) != go11y.Default() {
+		t.Fatalf("expected a default observer before one is set")
+	}
+
+	errBuf := new(bytes.Buffer)
+	ctx, o, err := go11y.Initialise(context.Background(), go11y.NewConfig(), io.Discard, errBuf)
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	if go11y.Default() != o {
+		t.Errorf("expected Initialise to set the default observer")
+	}
+
+	otherBuf := new(bytes.Buffer)
+	_, other, err := go11y.InitialiseTestLogger(context.Background(), go11y.LevelInfo, io.Discard, otherBuf)
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	go11y.SetDefault(o)
+	logger := slog.Default()
+
+	if go11y.FromContext(ctx) != o || go11y.FromContext(go11y.AddToContext(context.Background(), other)) != other {
+		t.Errorf("expected FromContext to return the observer in the context")
+	}
+
+	go11y.ErrorContext(go11y.AddToContext(context.Background(), other), "in context", errors.New("failure"), go11y.SeverityLow)
+	if !strings.Contains(otherBuf.String(), "in context") || strings.Contains(errBuf.String(), "in context") {
+		t.Errorf("expected ErrorContext to log with the observer in the context")
+	}
+
+	go11y.ErrorContext(context.Background(), "no context", errors.New("failure"), go11y.SeverityLow)
+	go11y.Error("deprecated", errors.New("failure"), go11y.SeverityLow)
+	for _, msg := range []string{"no context", "deprecated"} {
+		if !strings.Contains(errBuf.String(), msg) {
+			t.Errorf("expected %q to be logged with the default observer", msg)
+		}
+	}
+
+	if slog.Default() != logger {
+		t.Errorf("expected the free functions to leave slog's default logger alone")
+	}
+}
@@ -0,0 +1,166 @@
+package go11y
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// FieldChanges is the structured log field name for "changes", the paths changed between two values, see Diff
+const FieldChanges = "changes"
+
+const (
+	// ChangeAdded is the op of a path that is only in the after value of a Diff
+	ChangeAdded = "added"
+	// ChangeRemoved is the op of a path that is only in the before value of a Diff
+	ChangeRemoved = "removed"
+	// ChangeUpdated is the op of a path whose value differs between the before and after values of a Diff
+	ChangeUpdated = "updated"
+)
+
+// Change is a path whose value differs between the before and after values of a Diff
+type Change struct {
+	Op     string `json:"op"`               // ChangeAdded, ChangeRemoved or ChangeUpdated
+	Before any    `json:"before,omitempty"` // the redacted value before, unset if the path was added
+	After  any    `json:"after,omitempty"`  // the redacted value after, unset if the path was removed
+}
+
+// Diff logs $msg at Info level with the paths that differ between $before and $after under FieldChanges, e.g.
+// {"address.city": {"op": "updated", "before": "Leeds", "after": "York"}, "tags[2]": {"op": "added", "after": "vip"}},
+// for audit logging of updates to entities without logging the whole entity. The values are compared as JSON, so
+// only exported fields are compared, under their JSON names. Values under keys matching the redaction policy (see
+// RedactBody) are redacted, as is the personal data found in the others by the enabled PIIDetectors.
+// Nothing is logged if the values are equal. It returns whether they differ.
+// $ephemeralArgs are any additional key-value pairs to include in the log and span attributes.
+func (o *Observer) Diff(msg string, before, after any, ephemeralArgs ...any) (changed bool) {
+	changes, err := diffValues(before, after)
+	if err != nil {
+		o.log(context.Background(), 3, LevelWarning, "could not diff values", "diff_msg", msg, "error", err.Error())
+		return false
+	}
+
+	if len(changes) == 0 {
+		return false
+	}
+
+	args := append([]any{FieldChanges, changes}, ephemeralArgs...)
+	if o.log(context.Background(), 3, LevelInfo, msg, args...) {
+		o.addSpanEvent(o.currentSpan(), LevelInfo, msg, args)
+	}
+
+	return true
+}
+
+// diffValues returns the redacted changes between $before and $after, by path
+func diffValues(before, after any) (changes map[string]Change, fault error) {
+	b, err := jsonValue(before)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal the before value: %w", err)
+	}
+
+	a, err := jsonValue(after)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal the after value: %w", err)
+	}
+
+	changes = map[string]Change{}
+	diffJSON(changes, "", false, b, a)
+
+	return changes, nil
+}
+
+// jsonValue returns $v as the generic value it is unmarshalled to from JSON, keeping numbers as json.Number so large
+// integers are compared exactly
+func jsonValue(v any) (value any, fault error) {
+	blob, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(blob))
+	dec.UseNumber()
+
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+// diffJSON adds the changes between $before and $after, found at $path, to $changes. $forbidden is whether a key on
+// the path matches the redaction policy.
+func diffJSON(changes map[string]Change, path string, forbidden bool, before, after any) {
+	switch b := before.(type) {
+	case map[string]any:
+		if a, ok := after.(map[string]any); ok {
+			keys := make([]string, 0, len(b)+len(a))
+			for k := range b {
+				keys = append(keys, k)
+			}
+			for k := range a {
+				if _, ok := b[k]; !ok {
+					keys = append(keys, k)
+				}
+			}
+			sort.Strings(keys)
+
+			for _, k := range keys {
+				bv, inBefore := b[k]
+				av, inAfter := a[k]
+				childPath := joinPath(path, k)
+				childForbidden := forbidden || forbiddenKey(k)
+
+				switch {
+				case !inBefore:
+					changes[childPath] = Change{Op: ChangeAdded, After: redactValue(av, childForbidden)}
+				case !inAfter:
+					changes[childPath] = Change{Op: ChangeRemoved, Before: redactValue(bv, childForbidden)}
+				default:
+					diffJSON(changes, childPath, childForbidden, bv, av)
+				}
+			}
+
+			return
+		}
+	case []any:
+		if a, ok := after.([]any); ok {
+			for i := 0; i < max(len(b), len(a)); i++ {
+				childPath := path + "[" + strconv.Itoa(i) + "]"
+
+				switch {
+				case i >= len(b):
+					changes[childPath] = Change{Op: ChangeAdded, After: redactValue(a[i], forbidden)}
+				case i >= len(a):
+					changes[childPath] = Change{Op: ChangeRemoved, Before: redactValue(b[i], forbidden)}
+				default:
+					diffJSON(changes, childPath, forbidden, b[i], a[i])
+				}
+			}
+
+			return
+		}
+	}
+
+	if reflect.DeepEqual(before, after) {
+		return
+	}
+
+	if path == "" {
+		path = "."
+	}
+
+	changes[path] = Change{Op: ChangeUpdated, Before: redactValue(before, forbidden), After: redactValue(after, forbidden)}
+}
+
+// joinPath returns the path of the key $key of the object at $path
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
@@ -0,0 +1,109 @@
+package go11y_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/cirruscomms/go11y"
+)
+
+type customer struct {
+	Name     string            `json:"name"`
+	Email    string            `json:"email"`
+	Password string            `json:"password"`
+	Address  map[string]string `json:"address"`
+	Tags     []string          `json:"tags"`
+	Balance  int64             `json:"balance"`
+	internal string
+}
+
+func TestDiff(t *testing.T) {
+	buf := new(bytes.Buffer)
+	_, o, err := go11y.InitialiseTestLogger(context.Background(), go11y.LevelInfo, buf, buf)
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	before := customer{
+		Name:     "Ada",
+		Email:    "ada@example.com",
+		Password: "correct-horse-battery",
+		Address:  map[string]string{"city": "Leeds", "postcode": "LS1"},
+		Tags:     []string{"new"},
+		Balance:  9007199254740993,
+		internal: "a",
+	}
+
+	if o.Diff("customer updated", before, before) {
+		t.Errorf("expected equal values not to differ")
+	}
+
+	after := before
+	after.Password = "staple-battery-horse"
+	after.Address = map[string]string{"city": "York"}
+	after.Tags = []string{"new", "vip"}
+	after.Balance = 9007199254740992
+	after.internal = "b"
+
+	buf.Reset()
+	if !o.Diff("customer updated", before, after, "customer_id", 7) {
+		t.Fatalf("expected the values to differ")
+	}
+
+	record := struct {
+		Msg        string                  `json:"msg"`
+		CustomerID int                     `json:"customer_id"`
+		Changes    map[string]go11y.Change `json:"changes"`
+	}{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("failed to parse record %s: %v", buf.String(), err)
+	}
+
+	if record.Msg != "customer updated" || record.CustomerID != 7 {
+		t.Errorf("expected the message and args to be logged, got %s", buf.String())
+	}
+
+	expected := map[string]go11y.Change{
+		"address.city":     {Op: go11y.ChangeUpdated, Before: "Leeds", After: "York"},
+		"address.postcode": {Op: go11y.ChangeRemoved, Before: "LS1"},
+		"tags[1]":          {Op: go11y.ChangeAdded, After: "vip"},
+		"balance":          {Op: go11y.ChangeUpdated, Before: 9007199254740993.0, After: 9007199254740992.0},
+	}
+
+	if len(record.Changes) != len(expected)+1 {
+		t.Errorf("expected only the changed paths, got %v", record.Changes)
+	}
+
+	for path, change := range expected {
+		if got := record.Changes[path]; got.Op != change.Op || got.Before != change.Before || got.After != change.After {
+			t.Errorf("expected %s to be %+v, got %+v", path, change, got)
+		}
+	}
+
+	password := record.Changes["password"]
+	if password.Op != go11y.ChangeUpdated || password.Before == before.Password || password.After == after.Password {
+		t.Errorf("expected the password to be redacted, got %+v", password)
+	}
+
+	if !bytes.Contains(buf.Bytes(), []byte(`"before":9007199254740993`)) {
+		t.Errorf("expected large numbers to be logged exactly, got %s", buf.String())
+	}
+}
+
+func TestDiffUnmarshallable(t *testing.T) {
+	buf := new(bytes.Buffer)
+	_, o, err := go11y.InitialiseTestLogger(context.Background(), go11y.LevelInfo, buf, buf)
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	if o.Diff("channel updated", make(chan int), nil) {
+		t.Errorf("expected values that can't be marshalled not to differ")
+	}
+
+	if !bytes.Contains(buf.Bytes(), []byte(`"msg":"could not diff values"`)) {
+		t.Errorf("expected a warning, got %s", buf.String())
+	}
+}
@@ -0,0 +1,158 @@
+package go11y
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/smtp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ExportSignalEmail is the signal label used for failures to send the reports of an EmailSink
+const ExportSignalEmail = "email"
+
+// DefaultEmailRecords is the number of recent records included in the reports of an EmailSink, unless its options set
+// their own
+const DefaultEmailRecords = 50
+
+var registerEmailMetricsOnce sync.Once
+
+func registerEmailMetrics() {
+	registerEmailMetricsOnce.Do(func() {
+		registerCollectors(ExportFailures)
+	})
+}
+
+// EmailSinkOpts are the options used to create an EmailSink
+type EmailSinkOpts struct {
+	Addr string   // the host:port of the SMTP server
+	From string   // the sender of the reports
+	To   []string // the recipients of the reports
+
+	// optional - the authentication used with the SMTP server, e.g. smtp.PlainAuth, defaults to none
+	Auth smtp.Auth
+	// optional - the number of recent records included in the report, defaults to DefaultEmailRecords
+	Records int
+	// optional - the function reports are sent with, defaults to smtp.SendMail
+	SendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// EmailSink emails a report when a Fatal record is logged, summarising the fatal error and including the last records
+// logged before it, for small deployments without a paging system. It is an io.Writer of JSON records, added to an
+// Observer with WithSinks(s.Sink()), keeping the recent records in an in-memory ring buffer. The report is sent while
+// the fatal record is written, so before the Observer's outputs are flushed and the application exits; a failure to
+// send it is counted in the ExportFailures metric with the signal ExportSignalEmail.
+type EmailSink struct {
+	opts EmailSinkOpts
+
+	mu      sync.Mutex
+	records [][]byte
+	next    int
+	full    bool
+}
+
+// NewEmailSink creates an EmailSink with $opts. It returns an error if the SMTP server, sender or recipients are
+// missing.
+func NewEmailSink(opts EmailSinkOpts) (sink *EmailSink, fault error) {
+	switch {
+	case opts.Addr == "":
+		return nil, errors.New("could not create email sink: no SMTP server given")
+	case opts.From == "":
+		return nil, errors.New("could not create email sink: no sender given")
+	case len(opts.To) == 0:
+		return nil, errors.New("could not create email sink: no recipients given")
+	}
+
+	if opts.Records <= 0 {
+		opts.Records = DefaultEmailRecords
+	}
+
+	if opts.SendMail == nil {
+		opts.SendMail = smtp.SendMail
+	}
+
+	registerEmailMetrics()
+
+	return &EmailSink{opts: opts, records: make([][]byte, opts.Records)}, nil
+}
+
+// Sink returns the Sink to add to an Observer with WithSinks, receiving every JSON record the Observer logs
+func (s *EmailSink) Sink() Sink {
+	return Sink{Writer: s, MinLevel: LevelDebug, Format: SinkFormatJSON}
+}
+
+// Write keeps the JSON record $p in the ring buffer of recent records, and sends a report if it is a Fatal record. It
+// never fails.
+func (s *EmailSink) Write(p []byte) (n int, fault error) {
+	record := bytes.Clone(trimNewline(p))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.records[s.next] = record
+	s.next = (s.next + 1) % len(s.records)
+	if s.next == 0 {
+		s.full = true
+	}
+
+	if recordLevel(p) != LevelFatal {
+		return len(p), nil
+	}
+
+	if err := s.opts.SendMail(s.opts.Addr, s.opts.Auth, s.opts.From, s.opts.To, s.reportLocked(record)); err != nil {
+		ExportFailures.WithLabelValues(ExportSignalEmail, ExportFailureExporter).Inc()
+	}
+
+	return len(p), nil
+}
+
+// reportLocked returns the email reporting the fatal $record, with the recent records. s.mu must be held.
+func (s *EmailSink) reportLocked(record []byte) []byte {
+	fields := map[string]any{}
+	_ = json.Unmarshal(record, &fields)
+
+	str := func(field string) string {
+		v, _ := recordField(fields, field).(string)
+		return v
+	}
+
+	service := str(FieldServiceName)
+	if service == "" {
+		service = "service"
+	}
+
+	recent := s.records[:s.next]
+	if s.full {
+		recent = append(append([][]byte{}, s.records[s.next:]...), s.records[:s.next]...)
+	}
+
+	msg := new(bytes.Buffer)
+	fmt.Fprintf(msg, "From: %s\r\n", s.opts.From)
+	fmt.Fprintf(msg, "To: %s\r\n", strings.Join(s.opts.To, ", "))
+	fmt.Fprintf(msg, "Subject: [FATAL] %s: %s\r\n", service, headerValue(str("msg")))
+	fmt.Fprintf(msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+
+	fmt.Fprintf(msg, "%s logged a fatal error at %s.\r\n\r\n", service, str("time"))
+	fmt.Fprintf(msg, "Message: %s\r\n", str("msg"))
+	fmt.Fprintf(msg, "Error: %s\r\n", str("error"))
+	if traceID := str(FieldTraceID); traceID != "" {
+		fmt.Fprintf(msg, "Trace ID: %s\r\n", traceID)
+	}
+
+	fmt.Fprintf(msg, "\r\nThe last %d records:\r\n\r\n", len(recent))
+	for _, r := range recent {
+		msg.Write(r)
+		msg.WriteString("\r\n")
+	}
+
+	return msg.Bytes()
+}
+
+// headerValue returns $v without line breaks, so it can't add headers to an email
+func headerValue(v string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(v)
+}
@@ -0,0 +1,130 @@
+package go11y_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/smtp"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/cirruscomms/go11y"
+)
+
+func TestEmailSink(t *testing.T) {
+	t.Setenv("ENV", "test")
+
+	if _, err := go11y.NewEmailSink(go11y.EmailSinkOpts{Addr: "smtp.test:25", From: "go11y@test"}); err == nil {
+		t.Errorf("expected an email sink without recipients to be rejected")
+	}
+
+	var reports []string
+
+	email, err := go11y.NewEmailSink(go11y.EmailSinkOpts{
+		Addr: "smtp.test:25",
+		From: "go11y@test",
+		To:   []string{"ops@test"},
+		SendMail: func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+			reports = append(reports, string(msg))
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create email sink: %v", err)
+	}
+
+	cfg := go11y.CreateConfig(go11y.LevelDebug, "", "", "", []string{}, []string{})
+
+	_, o, err := go11y.Initialise(context.Background(), cfg, io.Discard, io.Discard, go11y.WithSinks(email.Sink()))
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	o.Info("first record")
+	o.Error("second record", errors.New("declined"), go11y.SeverityHighest)
+
+	if len(reports) != 0 {
+		t.Errorf("expected no report before a fatal record, got %d", len(reports))
+	}
+}
+
+// emailSinkFatalSchema is set, to the name of a field schema, in the environment of the test binary run by
+// TestEmailSinkFatal to log a fatal error, which exits the process
+const emailSinkFatalSchema = "GO11Y_EMAIL_SINK_FATAL_SCHEMA"
+
+func TestEmailSinkFatal(t *testing.T) {
+	if schema, ok := os.LookupEnv(emailSinkFatalSchema); ok {
+		logEmailSinkFatal(schema)
+		return
+	}
+
+	for _, schema := range []go11y.FieldSchema{go11y.SchemaGo11y, go11y.SchemaECS, go11y.SchemaOTel} {
+		t.Run(schema.String(), func(t *testing.T) {
+			cmd := exec.Command(os.Args[0], "-test.run=^TestEmailSinkFatal$")
+			cmd.Env = append(os.Environ(), emailSinkFatalSchema+"="+schema.String())
+
+			out, err := cmd.Output()
+
+			var exitErr *exec.ExitError
+			if !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+				t.Fatalf("expected the fatal error to exit with 1, got %v", err)
+			}
+
+			report := string(out)
+			for _, expected := range []string{
+				"To: ops@test\r\n",
+				"Subject: [FATAL] orders: database gone Bcc: x@test\r\n",
+				"Error: connection refused\r\n",
+				"The last 3 records:",
+				"second record",
+				"third record",
+			} {
+				if !strings.Contains(report, expected) {
+					t.Errorf("expected the report to contain %q, got:\n%s", expected, report)
+				}
+			}
+
+			if strings.Count(report, "Subject:") != 1 {
+				t.Errorf("expected a single report, got:\n%s", report)
+			}
+			if strings.Contains(report, "first record") {
+				t.Errorf("expected the oldest record to have left the ring buffer, got:\n%s", report)
+			}
+		})
+	}
+}
+
+// logEmailSinkFatal logs a fatal error to an Observer with an email sink, writing the reports it sends to stdout
+func logEmailSinkFatal(schema string) {
+	email, err := go11y.NewEmailSink(go11y.EmailSinkOpts{
+		Addr:    "smtp.test:25",
+		From:    "go11y@test",
+		To:      []string{"ops@test"},
+		Records: 3,
+		SendMail: func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+			_, err := os.Stdout.Write(msg)
+			return err
+		},
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to create email sink: %v\n", err)
+		os.Exit(2)
+	}
+
+	cfg := go11y.NewConfig(go11y.WithLogLevel(go11y.LevelDebug), go11y.WithFieldSchema(go11y.ParseFieldSchema(schema)))
+
+	_, o, err := go11y.Initialise(context.Background(), cfg, io.Discard, io.Discard,
+		go11y.WithSinks(email.Sink()), go11y.FieldServiceName, "orders")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to initialise observer: %v\n", err)
+		os.Exit(2)
+	}
+
+	o.Info("first record")
+	o.Debug("second record")
+	o.Error("third record", errors.New("declined"), go11y.SeverityHigh)
+	o.Fatal("database gone\nBcc: x@test", errors.New("connection refused"))
+}
@@ -0,0 +1,103 @@
+package go11y
+
+import (
+	"log/slog"
+	"strings"
+)
+
+// Environment is the stage a service is deployed to, selecting sensible defaults for it (see Initialise)
+type Environment string
+
+const (
+	// EnvironmentDevelopment is a developer's machine: develop level records are enabled, logs are written as
+	// human-readable text rather than JSON and every trace is sampled
+	EnvironmentDevelopment Environment = "development"
+	// EnvironmentTest is an automated test run: develop level records are enabled and every trace is sampled
+	EnvironmentTest Environment = "test"
+	// EnvironmentStaging is a pre-production deployment: develop level records are disabled and every trace is sampled
+	EnvironmentStaging Environment = "staging"
+	// EnvironmentProduction is a production deployment: develop level records are disabled and 10% of new traces
+	// are sampled (traces continued from an upstream service follow the upstream's decision)
+	EnvironmentProduction Environment = "production"
+)
+
+// ParseEnvironment maps the common names of deployment stages (e.g. "dev", "local", "stage", "prod", "live") to an
+// Environment. Unrecognised names are returned as they are, and get no presets.
+func ParseEnvironment(name string) Environment {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "development", "develop", "dev", "local":
+		return EnvironmentDevelopment
+	case "test", "testing", "ci":
+		return EnvironmentTest
+	case "staging", "stage", "uat":
+		return EnvironmentStaging
+	case "production", "prod", "live":
+		return EnvironmentProduction
+	default:
+		return Environment(strings.ToLower(strings.TrimSpace(name)))
+	}
+}
+
+// EnvironmentConfigurator is implemented by Configurators that know the environment the service is deployed to.
+// Configuration implements it, Configurators that don't get no environment presets.
+type EnvironmentConfigurator interface {
+	Environment() Environment
+}
+
+// SamplingConfigurator is implemented by Configurators that set the ratio of new traces to sample, overriding the
+// environment preset. Configuration implements it.
+type SamplingConfigurator interface {
+	// TraceSampleRatio is the ratio of new traces to sample (0-1), or a negative number to use the preset
+	TraceSampleRatio() float64
+}
+
+// environmentPreset holds the defaults selected by an Environment
+type environmentPreset struct {
+	allowDevelop bool    // whether the develop level may be enabled
+	console      bool    // whether the primary outputs are written as human-readable text
+	sampleRatio  float64 // the ratio of new traces sampled
+}
+
+func (e Environment) preset() environmentPreset {
+	switch e {
+	case EnvironmentDevelopment:
+		return environmentPreset{allowDevelop: true, console: true, sampleRatio: 1}
+	case EnvironmentStaging:
+		return environmentPreset{allowDevelop: false, sampleRatio: 1}
+	case EnvironmentProduction:
+		return environmentPreset{allowDevelop: false, sampleRatio: 0.1}
+	default:
+		// test and unknown environments keep go11y's behaviour from before environments were introduced
+		return environmentPreset{allowDevelop: true, sampleRatio: 1}
+	}
+}
+
+// configEnvironment returns the environment of $cfg, if it knows it
+func configEnvironment(cfg Configurator) Environment {
+	if ec, ok := cfg.(EnvironmentConfigurator); ok {
+		return ec.Environment()
+	}
+
+	return ""
+}
+
+// configLogLevel returns the log level of $cfg, raised to debug if the develop level isn't allowed in its environment
+func configLogLevel(cfg Configurator) (level slog.Level) {
+	level = cfg.LogLevel()
+	if level < LevelDebug && !configEnvironment(cfg).preset().allowDevelop {
+		return LevelDebug
+	}
+
+	return level
+}
+
+// configSampleRatio returns the ratio of new traces to sample for $cfg
+func configSampleRatio(cfg Configurator) float64 {
+	if sc, ok := cfg.(SamplingConfigurator); ok {
+		if ratio := sc.TraceSampleRatio(); ratio >= 0 {
+			return min(ratio, 1)
+		}
+	}
+
+	return configEnvironment(cfg).preset().sampleRatio
+}
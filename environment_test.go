@@ -0,0 +1,64 @@
+package go11y_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/cirruscomms/go11y"
+)
+
+func TestParseEnvironment(t *testing.T) {
+	tests := map[string]go11y.Environment{
+		"dev":        go11y.EnvironmentDevelopment,
+		"Local":      go11y.EnvironmentDevelopment,
+		"stage":      go11y.EnvironmentStaging,
+		"PROD":       go11y.EnvironmentProduction,
+		"live":       go11y.EnvironmentProduction,
+		"ci":         go11y.EnvironmentTest,
+		"sandbox-eu": go11y.Environment("sandbox-eu"),
+	}
+
+	for name, expected := range tests {
+		if got := go11y.ParseEnvironment(name); got != expected {
+			t.Errorf("ParseEnvironment(%q) = %q, expected %q", name, got, expected)
+		}
+	}
+}
+
+func TestEnvironmentPresets(t *testing.T) {
+	t.Run("production", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		cfg := go11y.NewConfig(go11y.WithLogLevel(go11y.LevelDevelop), go11y.WithEnvironment(go11y.EnvironmentProduction))
+		_, o, err := go11y.Initialise(context.Background(), cfg, buf, buf)
+		if err != nil {
+			t.Fatalf("failed to initialise observer: %v", err)
+		}
+
+		o.Develop("develop record")
+		o.Debug("debug record")
+
+		if strings.Contains(buf.String(), "develop record") {
+			t.Errorf("expected develop records to be disabled in production, got %s", buf.String())
+		}
+		if !strings.Contains(buf.String(), `"msg":"debug record"`) || !strings.Contains(buf.String(), `"environment":"production"`) {
+			t.Errorf("expected a JSON debug record with the environment, got %s", buf.String())
+		}
+	})
+
+	t.Run("development", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		cfg := go11y.NewConfig(go11y.WithLogLevel(go11y.LevelDevelop), go11y.WithEnvironment(go11y.EnvironmentDevelopment))
+		_, o, err := go11y.Initialise(context.Background(), cfg, buf, buf)
+		if err != nil {
+			t.Fatalf("failed to initialise observer: %v", err)
+		}
+
+		o.Develop("develop record")
+
+		if !strings.Contains(buf.String(), `msg="develop record"`) || !strings.Contains(buf.String(), "environment=development") {
+			t.Errorf("expected a text develop record with the environment, got %s", buf.String())
+		}
+	})
+}
@@ -0,0 +1,144 @@
+package go11y
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	otelAttribute "go.opentelemetry.io/otel/attribute"
+	otelTrace "go.opentelemetry.io/otel/trace"
+)
+
+// FieldEvent is the structured log field name for "event", the group of the name, schema version and payload of a
+// domain event, see Event
+const FieldEvent = "event"
+
+// DefaultEventSchemaVersion is the schema version of the payloads of events that don't implement VersionedEvent
+const DefaultEventSchemaVersion = 1
+
+// ExportSignalEvents is the signal label used for failures to publish events, see WithEventPublishers
+const ExportSignalEvents = "events"
+
+// Events is the metric for the number of domain events recorded with Event, by name
+var Events = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "go11y_events_total",
+	Help: "Number of domain events recorded",
+}, []string{"name"})
+
+var registerEventMetricsOnce sync.Once
+
+func registerEventMetrics() {
+	registerEventMetricsOnce.Do(func() {
+		registerCollectors(Events, ExportFailures)
+	})
+}
+
+// VersionedEvent is implemented by event payloads whose schema has changed, so consumers of the events can tell the
+// versions apart. Payloads that don't implement it are DefaultEventSchemaVersion.
+type VersionedEvent interface {
+	EventSchemaVersion() int
+}
+
+// DomainEvent is a domain event recorded with Event, as passed to EventPublishers
+type DomainEvent struct {
+	Name          string          `json:"name"`
+	SchemaVersion int             `json:"schema_version"`
+	Payload       json.RawMessage `json:"payload"` // the payload as JSON, redacted as by RedactBody
+	Time          time.Time       `json:"time"`
+	Service       string          `json:"service,omitempty"`
+	TraceID       string          `json:"trace_id,omitempty"`
+	SpanID        string          `json:"span_id,omitempty"`
+}
+
+// EventPublisher publishes the domain events recorded with Event, e.g. to a message broker or a webhook, see
+// WithEventPublishers
+type EventPublisher interface {
+	Publish(ctx context.Context, event DomainEvent) error
+}
+
+// EventPublisherFunc is a function that implements EventPublisher
+type EventPublisherFunc func(ctx context.Context, event DomainEvent) error
+
+// Publish calls f(ctx, event)
+func (f EventPublisherFunc) Publish(ctx context.Context, event DomainEvent) error {
+	return f(ctx, event)
+}
+
+// WithEventPublishers publishes every domain event recorded with Event to $publishers as well as logging it. A failure
+// to publish is logged as a warning and counted in the ExportFailures metric with the signal ExportSignalEvents; it
+// doesn't stop the event being published to the other publishers.
+func WithEventPublishers(publishers ...EventPublisher) Option {
+	return func(o *Observer) {
+		o.eventPublishers = append(o.eventPublishers, publishers...)
+	}
+}
+
+// Event records the domain event $name (e.g. "order.placed") with $payload, for a lightweight audit trail of what
+// happened in the service: a record is logged at Info level with the event's name, schema version (see
+// VersionedEvent) and payload under FieldEvent, an event is added to the span, and the event is published to the
+// Observer's EventPublishers, if it has any. The payload is marshalled to JSON and the values under keys matching the
+// redaction policy, and the personal data found by the enabled PIIDetectors, are redacted everywhere.
+func (o *Observer) Event(name string, payload any) {
+	o.event(context.Background(), name, payload)
+}
+
+// EventContext records the domain event $name with $payload like Event, passing $ctx to the EventPublishers.
+func (o *Observer) EventContext(ctx context.Context, name string, payload any) {
+	o.event(ctx, name, payload)
+}
+
+func (o *Observer) event(ctx context.Context, name string, payload any) {
+	registerEventMetrics()
+
+	blob, err := json.Marshal(payload)
+	if err != nil {
+		o.log(ctx, 4, LevelWarning, "could not marshal event payload", "event_name", name, "error", err.Error())
+		return
+	}
+
+	version := DefaultEventSchemaVersion
+	if v, ok := payload.(VersionedEvent); ok {
+		version = v.EventSchemaVersion()
+	}
+
+	ev := DomainEvent{
+		Name:          name,
+		SchemaVersion: version,
+		Payload:       RedactBody(blob),
+		Time:          o.clock.Now(),
+		Service:       o.cfg.ServiceName(),
+	}
+
+	span := o.currentSpan()
+
+	if span != nil && span.SpanContext().IsValid() {
+		ev.TraceID = span.SpanContext().TraceID().String()
+		ev.SpanID = span.SpanContext().SpanID().String()
+
+		span.AddEvent(name, otelTrace.WithAttributes(
+			otelAttribute.String("event.name", name),
+			otelAttribute.Int("event.schema_version", version),
+			otelAttribute.String("event.payload", string(ev.Payload)),
+		))
+	}
+
+	Events.WithLabelValues(name).Inc()
+
+	o.log(ctx, 4, LevelInfo, "event "+name, slog.Group(FieldEvent,
+		"name", name,
+		"schema_version", version,
+		"payload", ev.Payload,
+	))
+
+	for _, p := range o.eventPublishers {
+		if err := p.Publish(ctx, ev); err != nil {
+			ExportFailures.WithLabelValues(ExportSignalEvents, ExportFailureExporter).Inc()
+			o.log(ctx, 4, LevelWarning, "could not publish event", "event_name", name,
+				"error", fmt.Errorf("could not publish event %s: %w", name, err).Error())
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package go11y_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/cirruscomms/go11y"
+)
+
+type orderPlaced struct {
+	OrderID  int    `json:"order_id"`
+	Total    int    `json:"total"`
+	APIToken string `json:"api_token"`
+}
+
+func (orderPlaced) EventSchemaVersion() int {
+	return 2
+}
+
+func TestEvent(t *testing.T) {
+	buf := new(bytes.Buffer)
+	ctx, _, spans, err := go11y.InitialiseTestTracerInMemory(context.Background(), go11y.LevelInfo, buf, buf)
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	_, o, end, err := go11y.StartSpan(ctx, nil, "checkout", go11y.SpanKindInternal)
+	if err != nil {
+		t.Fatalf("failed to start span: %v", err)
+	}
+
+	buf.Reset()
+	o.Event("order.placed", orderPlaced{OrderID: 42, Total: 1999, APIToken: "tok_4f1c2ab9e07d3a5b"})
+	end()
+
+	record := struct {
+		Msg   string `json:"msg"`
+		Event struct {
+			Name          string         `json:"name"`
+			SchemaVersion int            `json:"schema_version"`
+			Payload       map[string]any `json:"payload"`
+		} `json:"event"`
+	}{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("failed to parse record %s: %v", buf.String(), err)
+	}
+
+	if !strings.Contains(buf.String(), "event_test.go") {
+		t.Errorf("expected the caller of Event to be the source of the record, got %s", buf.String())
+	}
+
+	if record.Msg != "event order.placed" || record.Event.Name != "order.placed" || record.Event.SchemaVersion != 2 {
+		t.Errorf("expected the event's name and schema version to be logged, got %s", buf.String())
+	}
+
+	if record.Event.Payload["order_id"] != float64(42) || record.Event.Payload["api_token"] == "tok_4f1c2ab9e07d3a5b" {
+		t.Errorf("expected the payload to be logged with the token redacted, got %v", record.Event.Payload)
+	}
+
+	span, _ := spans.Find("checkout")
+	if len(span.Events) != 1 || span.Events[0].Name != "order.placed" {
+		t.Fatalf("expected the event to be added to the span, got %v", span.Events)
+	}
+
+	for _, a := range span.Events[0].Attributes {
+		if a.Key == "event.payload" && strings.Contains(a.Value.AsString(), "tok_4f1c2ab9e07d3a5b") {
+			t.Errorf("expected the payload of the span event to be redacted, got %s", a.Value.AsString())
+		}
+	}
+}
+
+func TestEventPublishers(t *testing.T) {
+	buf := new(bytes.Buffer)
+
+	published := []go11y.DomainEvent{}
+	publisher := go11y.EventPublisherFunc(func(ctx context.Context, event go11y.DomainEvent) error {
+		published = append(published, event)
+		return nil
+	})
+	failing := go11y.EventPublisherFunc(func(ctx context.Context, event go11y.DomainEvent) error {
+		return errors.New("broker unavailable")
+	})
+
+	cfg := go11y.CreateConfig(go11y.LevelInfo, "", "", "", []string{}, []string{})
+	_, o, err := go11y.Initialise(context.Background(), cfg, buf, buf, go11y.WithEventPublishers(failing, publisher))
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	o.Event("customer.deleted", map[string]any{"customer_id": 7})
+
+	if len(published) != 1 {
+		t.Fatalf("expected the event to be published despite the failing publisher, got %v", published)
+	}
+
+	ev := published[0]
+	if ev.Name != "customer.deleted" || ev.SchemaVersion != go11y.DefaultEventSchemaVersion ||
+		string(ev.Payload) != `{"customer_id":7}` || ev.Time.IsZero() {
+		t.Errorf("expected a well-formed event, got %+v", ev)
+	}
+
+	if !strings.Contains(buf.String(), "could not publish event customer.deleted: broker unavailable") {
+		t.Errorf("expected the failure to publish to be logged, got %s", buf.String())
+	}
+}
@@ -0,0 +1,58 @@
+package go11y
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	otelTrace "go.opentelemetry.io/otel/trace"
+)
+
+// ExemplarTraceID is the exemplar label holding the trace ID of the observation, as expected by Grafana
+const ExemplarTraceID = "trace_id"
+
+// ExemplarSpanID is the exemplar label holding the span ID of the observation
+const ExemplarSpanID = "span_id"
+
+// observeWithExemplar observes $value with $observer, with an exemplar of the trace and span IDs of the span in $ctx
+// if it is sampled, so a latency spike links to an example trace. Exemplars are only exposed by a metrics handler
+// that serves the OpenMetrics format, see MetricsHandler.
+func observeWithExemplar(ctx context.Context, observer prometheus.Observer, value float64) {
+	sc := otelTrace.SpanContextFromContext(ctx)
+
+	eo, ok := observer.(prometheus.ExemplarObserver)
+	if !ok || !sc.IsSampled() {
+		observer.Observe(value)
+		return
+	}
+
+	eo.ObserveWithExemplar(value, prometheus.Labels{
+		ExemplarTraceID: sc.TraceID().String(),
+		ExemplarSpanID:  sc.SpanID().String(),
+	})
+}
+
+// requestTraceContext returns the context of $r, with the caller's span extracted from its headers if it doesn't
+// carry a span, e.g. when the metrics middleware runs before the request logger middleware starts the server span.
+// The caller's span is in the same trace, so it is still a valid exemplar.
+func requestTraceContext(r *http.Request) context.Context {
+	ctx := r.Context()
+	if otelTrace.SpanContextFromContext(ctx).IsValid() {
+		return ctx
+	}
+
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(r.Header))
+}
+
+// MetricsHandler returns the handler of the Prometheus metrics endpoint, serving the metrics of the default registry
+// in the OpenMetrics format when the scraper asks for it, which exemplars need, and the text format otherwise.
+// The service name, version and instance ID of the default Observer are added to every metric as constant labels.
+func MetricsHandler() http.Handler {
+	return promhttp.InstrumentMetricHandler(
+		prometheus.DefaultRegisterer,
+		promhttp.HandlerFor(serviceGatherer{prometheus.DefaultGatherer}, promhttp.HandlerOpts{EnableOpenMetrics: true}),
+	)
+}
@@ -0,0 +1,296 @@
+package go11y
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel"
+	otelSDKTrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// ExportFailures is the metric for the number of failed telemetry exports, by signal and reason
+var ExportFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "go11y_export_failures_total",
+	Help: "Number of telemetry exports to the OTel collector that failed",
+}, []string{"signal", "reason"})
+
+// ExportSpansDropped is the metric for the number of spans dropped because the export queue was full
+var ExportSpansDropped = prometheus.NewCounter(prometheus.CounterOpts{
+	Name: "go11y_export_spans_dropped_total",
+	Help: "Number of spans dropped by go11y because the export queue was full",
+})
+
+// ExportDegraded is the metric for whether telemetry export is currently failing (1) or healthy (0)
+var ExportDegraded = prometheus.NewGauge(prometheus.GaugeOpts{
+	Name: "go11y_export_degraded",
+	Help: "Whether telemetry export to the OTel collector is currently failing",
+})
+
+const (
+	// ExportSignalTraces is the signal label used for trace exports
+	ExportSignalTraces = "traces"
+
+	// ExportFailureExporter is the reason label used when the exporter returns an error, e.g. the collector is down
+	ExportFailureExporter = "exporter_error"
+	// ExportFailureDropped is the reason label used when spans are dropped because the export queue is full
+	ExportFailureDropped = "queue_full"
+	// ExportFailureSDK is the reason label used for other errors reported by the OTel SDK
+	ExportFailureSDK = "sdk_error"
+)
+
+const (
+	exportWarnBackoffMin = 30 * time.Second
+	exportWarnBackoffMax = 30 * time.Minute
+)
+
+var registerExportMetricsOnce sync.Once
+
+// defaultErrorHandler is the OTel SDK's own error handler, which logs with the standard library's log package and
+// delegates to the first handler installed, so errors are only passed on to handlers installed by the service
+var defaultErrorHandler = otel.GetErrorHandler()
+
+// exportWatchdog watches the trace export pipeline for failures, counting them, flipping the degraded flag and logging
+// warnings with an exponential backoff so a collector outage doesn't flood the logs.
+type exportWatchdog struct {
+	o          *Observer
+	mu         sync.Mutex
+	degraded   bool
+	failures   int // failures since the last warning was logged
+	lastWarn   time.Time
+	backoff    time.Duration
+	lastReason string
+	lastErr    string
+	queued     atomic.Int64 // spans ended but not exported yet, see queueLimitProcessor
+}
+
+func newExportWatchdog() *exportWatchdog {
+	registerExportMetricsOnce.Do(func() {
+		registerCollectors(ExportFailures, ExportSpansDropped, ExportDegraded)
+	})
+
+	return &exportWatchdog{backoff: exportWarnBackoffMin}
+}
+
+// attach sets the Observer the watchdog logs with.
+func (w *exportWatchdog) attach(o *Observer) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.o = o
+}
+
+// watch hooks the watchdog into the OTel SDK's global error handler, which is how the batch span processor reports
+// failed exports. The handler installed before is still called, unless it is a previous watchdog's, which is replaced.
+// The SDK's logger is left alone, so its diagnostics go wherever the service sends them.
+func (w *exportWatchdog) watch() {
+	previousHandler := otel.GetErrorHandler()
+	if h, ok := previousHandler.(*exportErrorHandler); ok {
+		previousHandler = h.previous
+	}
+	if previousHandler == defaultErrorHandler {
+		previousHandler = nil
+	}
+
+	otel.SetErrorHandler(&exportErrorHandler{watchdog: w, previous: previousHandler})
+}
+
+// failure records a failed export for $reason, logging a warning unless one was logged within the current backoff.
+func (w *exportWatchdog) failure(reason string, err error) {
+	ExportFailures.WithLabelValues(ExportSignalTraces, reason).Inc()
+	ExportDegraded.Set(1)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.degraded = true
+	w.failures++
+	w.lastReason = reason
+	if err != nil {
+		w.lastErr = err.Error()
+	}
+
+	if w.o == nil {
+		return
+	}
+
+	now := w.o.clock.Now()
+	if !w.lastWarn.IsZero() && now.Sub(w.lastWarn) < w.backoff {
+		return
+	}
+
+	if !w.lastWarn.IsZero() {
+		w.backoff = min(w.backoff*2, exportWarnBackoffMax)
+	}
+	w.lastWarn = now
+
+	w.o.log(context.Background(), 3, LevelWarning, "telemetry export failing",
+		"reason", reason, "error", w.lastErr, "failures", w.failures, "next_warning_after", w.backoff.String())
+	w.failures = 0
+}
+
+// success records a successful export, logging the recovery if exports were failing.
+func (w *exportWatchdog) success() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.degraded {
+		return
+	}
+
+	ExportDegraded.Set(0)
+
+	w.degraded = false
+	w.failures = 0
+	w.lastWarn = time.Time{}
+	w.backoff = exportWarnBackoffMin
+
+	if w.o != nil {
+		w.o.log(context.Background(), 3, LevelInfo, "telemetry export recovered")
+	}
+}
+
+// logInfo logs $msg at info level with the attached Observer, if there is one.
+func (w *exportWatchdog) logInfo(msg string, args ...any) {
+	w.mu.Lock()
+	o := w.o
+	w.mu.Unlock()
+
+	if o != nil {
+		o.log(context.Background(), 3, LevelInfo, msg, args...)
+	}
+}
+
+// enqueue reserves a place in the export queue for a span, returning false if the queue is full
+func (w *exportWatchdog) enqueue(limit int) bool {
+	if w.queued.Add(1) > int64(limit) {
+		w.queued.Add(-1)
+		return false
+	}
+
+	return true
+}
+
+// exported releases the places of $n spans handed to the exporter, whether the export succeeded or not
+func (w *exportWatchdog) exported(n int) {
+	w.queued.Add(-int64(n))
+}
+
+// dropped records $n spans dropped because the export queue was full.
+func (w *exportWatchdog) dropped(n int) {
+	if n == 0 {
+		return
+	}
+
+	ExportSpansDropped.Add(float64(n))
+	w.failure(ExportFailureDropped, nil)
+}
+
+func (w *exportWatchdog) status() (degraded bool, reason, lastErr string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.degraded {
+		return false, "", ""
+	}
+
+	return true, w.lastReason, w.lastErr
+}
+
+// exportError marks an error returned by a watched exporter, which has already been counted
+type exportError struct {
+	error
+}
+
+func (e exportError) Unwrap() error {
+	return e.error
+}
+
+// watchedExporter reports the outcome of each export of the wrapped exporter to the watchdog
+type watchedExporter struct {
+	otelSDKTrace.SpanExporter
+	watchdog *exportWatchdog
+}
+
+func (e watchedExporter) ExportSpans(ctx context.Context, spans []otelSDKTrace.ReadOnlySpan) error {
+	defer e.watchdog.exported(len(spans))
+
+	if err := e.SpanExporter.ExportSpans(ctx, spans); err != nil {
+		e.watchdog.failure(ExportFailureExporter, err)
+		return exportError{err}
+	}
+
+	e.watchdog.success()
+	return nil
+}
+
+// queueLimitProcessor wraps the batch span processor, which blocks rather than drops spans when its queue is full,
+// dropping and counting the spans ended while $limit spans are already waiting to be exported instead.
+type queueLimitProcessor struct {
+	otelSDKTrace.SpanProcessor
+	watchdog *exportWatchdog
+	limit    int
+}
+
+func (p queueLimitProcessor) OnEnd(s otelSDKTrace.ReadOnlySpan) {
+	// spans that aren't sampled are never exported
+	if s.SpanContext().IsSampled() && !p.watchdog.enqueue(p.limit) {
+		p.watchdog.dropped(1)
+		return
+	}
+
+	p.SpanProcessor.OnEnd(s)
+}
+
+// exportErrorHandler counts the errors reported to the OTel SDK's global error handler as failures, passing them on to
+// the handler installed before, if any
+type exportErrorHandler struct {
+	watchdog *exportWatchdog
+	previous otel.ErrorHandler
+}
+
+func (h *exportErrorHandler) Handle(err error) {
+	// errors returned by the watchedExporter have already been counted
+	if !errors.As(err, &exportError{}) {
+		h.watchdog.failure(ExportFailureSDK, err)
+	}
+
+	if h.previous != nil {
+		h.previous.Handle(err)
+	}
+}
+
+// TelemetryDegraded reports whether exporting telemetry to the OTel collector is currently failing, i.e. the last
+// export failed or spans have been dropped since the last successful export.
+// It is always false when tracing is not configured.
+func (o *Observer) TelemetryDegraded() (degraded bool) {
+	if o.exportWatchdog == nil {
+		return false
+	}
+
+	degraded, _, _ = o.exportWatchdog.status()
+	return degraded
+}
+
+// TelemetryHealthHandler returns a handler that reports the telemetry export status as JSON, e.g.
+// {"telemetry":"degraded","reason":"exporter_error","error":"..."}, for inclusion in a service's health endpoint.
+// It always responds 200, as losing telemetry shouldn't take a service out of its load balancer.
+func (o *Observer) TelemetryHealthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := map[string]string{"telemetry": "ok"}
+
+		if o.exportWatchdog != nil {
+			if degraded, reason, lastErr := o.exportWatchdog.status(); degraded {
+				status = map[string]string{"telemetry": "degraded", "reason": reason, "error": lastErr}
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(status)
+	})
+}
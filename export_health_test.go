@@ -0,0 +1,173 @@
+package go11y
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.opentelemetry.io/otel"
+	otelSDKTrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type failingExporter struct {
+	err error
+}
+
+func (e *failingExporter) ExportSpans(context.Context, []otelSDKTrace.ReadOnlySpan) error {
+	return e.err
+}
+
+func (e *failingExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+type stepClock struct {
+	now time.Time
+}
+
+func (c *stepClock) Now() time.Time {
+	return c.now
+}
+
+func (c *stepClock) Since(t time.Time) time.Duration {
+	return c.now.Sub(t)
+}
+
+func TestExportWatchdog(t *testing.T) {
+	t.Setenv("ENV", "test")
+
+	buf := new(bytes.Buffer)
+	clock := &stepClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	cfg := CreateConfig(LevelInfo, "", "", "", []string{}, []string{})
+	_, o, err := Initialise(context.Background(), cfg, buf, buf, WithClock(clock))
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	watchdog := newExportWatchdog()
+	watchdog.attach(o)
+	o.exportWatchdog = watchdog
+
+	inner := &failingExporter{err: errors.New("connection refused")}
+	exporter := watchedExporter{SpanExporter: inner, watchdog: watchdog}
+
+	before := testutil.ToFloat64(ExportFailures.WithLabelValues(ExportSignalTraces, ExportFailureExporter))
+
+	for range 3 {
+		if err := exporter.ExportSpans(context.Background(), nil); !errors.As(err, &exportError{}) {
+			t.Fatalf("expected the export error to be marked as counted, got %v", err)
+		}
+	}
+
+	if got := testutil.ToFloat64(ExportFailures.WithLabelValues(ExportSignalTraces, ExportFailureExporter)) - before; got != 3 {
+		t.Errorf("expected 3 export failures to be counted, got %v", got)
+	}
+
+	if n := strings.Count(buf.String(), "telemetry export failing"); n != 1 {
+		t.Errorf("expected a single warning within the backoff, got %d in %s", n, buf.String())
+	}
+
+	if !o.TelemetryDegraded() {
+		t.Errorf("expected telemetry to be degraded")
+	}
+
+	rec := httptest.NewRecorder()
+	o.TelemetryHealthHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"telemetry":"degraded"`) {
+		t.Errorf("expected the health handler to report degraded telemetry, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	clock.now = clock.now.Add(exportWarnBackoffMin)
+	_ = exporter.ExportSpans(context.Background(), nil)
+	if n := strings.Count(buf.String(), "telemetry export failing"); n != 2 {
+		t.Errorf("expected another warning once the backoff elapsed, got %d", n)
+	}
+	if !strings.Contains(buf.String(), `"failures":3`) || !strings.Contains(buf.String(), `"next_warning_after":"1m0s"`) {
+		t.Errorf("expected the second warning to summarise the failures and double the backoff, got %s", buf.String())
+	}
+
+	droppedBefore := testutil.ToFloat64(ExportSpansDropped)
+	processor := queueLimitProcessor{SpanProcessor: noopProcessor{}, watchdog: watchdog, limit: 2}
+	sampled := tracetest.SpanStub{SpanContext: trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{1},
+		TraceFlags: trace.FlagsSampled,
+	})}.Snapshot()
+	unsampled := tracetest.SpanStub{}.Snapshot()
+
+	for range 3 {
+		processor.OnEnd(sampled)
+		processor.OnEnd(unsampled)
+	}
+	if got := testutil.ToFloat64(ExportSpansDropped) - droppedBefore; got != 1 {
+		t.Errorf("expected the sampled span over the queue limit to be dropped, got %v", got)
+	}
+
+	watchdog.exported(2)
+	processor.OnEnd(sampled)
+	if got := testutil.ToFloat64(ExportSpansDropped) - droppedBefore; got != 1 {
+		t.Errorf("expected exported spans to make room in the queue, got %v drops", got)
+	}
+	if got := testutil.ToFloat64(ExportFailures.WithLabelValues(ExportSignalTraces, ExportFailureDropped)); got != 1 {
+		t.Errorf("expected one queue drop failure, got %v", got)
+	}
+
+	inner.err = nil
+	if err := exporter.ExportSpans(context.Background(), nil); err != nil {
+		t.Fatalf("unexpected export error: %v", err)
+	}
+
+	if o.TelemetryDegraded() {
+		t.Errorf("expected telemetry to recover after a successful export")
+	}
+	if !strings.Contains(buf.String(), "telemetry export recovered") {
+		t.Errorf("expected the recovery to be logged, got %s", buf.String())
+	}
+}
+
+type noopProcessor struct {
+	otelSDKTrace.SpanProcessor
+}
+
+func (noopProcessor) OnEnd(otelSDKTrace.ReadOnlySpan) {}
+
+type recordingErrorHandler struct {
+	errs []error
+}
+
+func (h *recordingErrorHandler) Handle(err error) {
+	h.errs = append(h.errs, err)
+}
+
+func TestExportWatchdogChainsErrorHandler(t *testing.T) {
+	previous := otel.GetErrorHandler()
+	t.Cleanup(func() { otel.SetErrorHandler(previous) })
+
+	service := &recordingErrorHandler{}
+	otel.SetErrorHandler(service)
+
+	before := testutil.ToFloat64(ExportFailures.WithLabelValues(ExportSignalTraces, ExportFailureSDK))
+
+	// a second Initialise replaces the first watchdog's handler rather than chaining to it
+	newExportWatchdog().watch()
+	newExportWatchdog().watch()
+
+	otel.Handle(errors.New("sdk failure"))
+	otel.Handle(exportError{errors.New("export failure")})
+
+	if got := testutil.ToFloat64(ExportFailures.WithLabelValues(ExportSignalTraces, ExportFailureSDK)) - before; got != 1 {
+		t.Errorf("expected the SDK error to be counted once, got %v", got)
+	}
+
+	if len(service.errs) != 2 {
+		t.Errorf("expected both errors to be passed on to the service's handler, got %v", service.errs)
+	}
+}
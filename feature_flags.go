@@ -0,0 +1,116 @@
+package go11y
+
+import (
+	"context"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	otelAttribute "go.opentelemetry.io/otel/attribute"
+	otelTrace "go.opentelemetry.io/otel/trace"
+)
+
+// FlagReasonError is the reason of a flag evaluation that failed, so the default value was served
+const FlagReasonError = "ERROR"
+
+// FieldFeatureFlag is the structured log field name for "feature_flag", the key of the flag
+const FieldFeatureFlag = "feature_flag"
+
+// FieldFeatureFlagVariant is the structured log field name for "feature_flag_variant"
+const FieldFeatureFlagVariant = "feature_flag_variant"
+
+// FieldFeatureFlagValue is the structured log field name for "feature_flag_value"
+const FieldFeatureFlagValue = "feature_flag_value"
+
+// FieldFeatureFlagReason is the structured log field name for "feature_flag_reason"
+const FieldFeatureFlagReason = "feature_flag_reason"
+
+// FieldFeatureFlagProvider is the structured log field name for "feature_flag_provider"
+const FieldFeatureFlagProvider = "feature_flag_provider"
+
+// FeatureFlagEvaluations is the metric for the number of feature flag evaluations, by flag, variant and reason
+var FeatureFlagEvaluations = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "go11y_feature_flag_evaluations_total",
+	Help: "Number of feature flag evaluations",
+}, []string{"flag", "variant", "reason"})
+
+var registerFeatureFlagMetricsOnce sync.Once
+
+// FlagEvaluation is the outcome of evaluating a feature flag, e.g. the details passed to the Finally stage of an
+// OpenFeature hook
+type FlagEvaluation struct {
+	Key      string // the key of the flag
+	Provider string // optional - the name of the provider that evaluated the flag
+	Variant  string // optional - the variant served
+	Value    any    // the value served
+	Reason   string // optional - why the value was served, e.g. "TARGETING_MATCH", FlagReasonError if Err is set
+	Err      error  // optional - the error the evaluation failed with, in which case the default value was served
+}
+
+// LogArgs returns the evaluation as log args, leaving out the optional fields that aren't set
+func (ev FlagEvaluation) LogArgs() (args []any) {
+	args = []any{FieldFeatureFlag, ev.Key, FieldFeatureFlagValue, ev.Value}
+	for _, f := range [][2]string{
+		{FieldFeatureFlagVariant, ev.Variant},
+		{FieldFeatureFlagReason, ev.Reason},
+		{FieldFeatureFlagProvider, ev.Provider},
+	} {
+		if f[1] != "" {
+			args = append(args, f[0], f[1])
+		}
+	}
+
+	if ev.Err != nil {
+		args = append(args, "error", ev.Err.Error())
+	}
+
+	return args
+}
+
+// spanAttributes returns the evaluation as the attributes of a feature_flag.evaluation event, per the OpenTelemetry
+// semantic conventions for feature flags
+func (ev FlagEvaluation) spanAttributes() (attrs []otelAttribute.KeyValue) {
+	attrs = []otelAttribute.KeyValue{otelAttribute.String("feature_flag.key", ev.Key)}
+	attrs = appendAttribute(attrs, "feature_flag.result.value", ev.Value)
+
+	for _, a := range [][2]string{
+		{"feature_flag.result.variant", ev.Variant},
+		{"feature_flag.result.reason", ev.Reason},
+		{"feature_flag.provider.name", ev.Provider},
+	} {
+		if a[1] != "" {
+			attrs = append(attrs, otelAttribute.String(a[0], a[1]))
+		}
+	}
+
+	if ev.Err != nil {
+		attrs = append(attrs, otelAttribute.String("error.type", ev.Err.Error()))
+	}
+
+	return attrs
+}
+
+// RecordFlagEvaluation annotates the span in $ctx with a feature_flag.evaluation event, logs the evaluation with the
+// Observer in $ctx (see FromContext) at Debug level, or Warning level if it failed, and records the
+// FeatureFlagEvaluations metric, so the flags that shaped a request can be seen in its trace and logs.
+//
+// It is the go11y side of an OpenFeature hook: call it from the hook's Finally stage with the flag key, the provider's
+// name and the evaluation details, setting Err to the error the Error stage was called with, if any.
+func RecordFlagEvaluation(ctx context.Context, ev FlagEvaluation) {
+	registerFeatureFlagMetricsOnce.Do(func() {
+		registerCollectors(FeatureFlagEvaluations)
+	})
+
+	level := LevelDebug
+	if ev.Err != nil {
+		level = LevelWarning
+		if ev.Reason == "" {
+			ev.Reason = FlagReasonError
+		}
+	}
+
+	FeatureFlagEvaluations.WithLabelValues(ev.Key, ev.Variant, ev.Reason).Inc()
+
+	otelTrace.SpanFromContext(ctx).AddEvent("feature_flag.evaluation", otelTrace.WithAttributes(ev.spanAttributes()...))
+
+	FromContext(ctx).log(ctx, 3, level, "feature flag evaluated", ev.LogArgs()...)
+}
@@ -0,0 +1,80 @@
+package go11y_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/cirruscomms/go11y"
+)
+
+func TestRecordFlagEvaluation(t *testing.T) {
+	buf := new(bytes.Buffer)
+	ctx, _, spans, err := go11y.InitialiseTestTracerInMemory(context.Background(), go11y.LevelDebug, buf, buf)
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	ctx, _, end, err := go11y.StartSpan(ctx, nil, "checkout", go11y.SpanKindServer)
+	if err != nil {
+		t.Fatalf("failed to start span: %v", err)
+	}
+
+	go11y.RecordFlagEvaluation(ctx, go11y.FlagEvaluation{
+		Key:      "new-checkout",
+		Provider: "flagd",
+		Variant:  "on",
+		Value:    true,
+		Reason:   "TARGETING_MATCH",
+	})
+	go11y.RecordFlagEvaluation(ctx, go11y.FlagEvaluation{
+		Key:   "discount-rate",
+		Value: 0.0,
+		Err:   errors.New("flag not found"),
+	})
+	end()
+
+	span, found := spans.Find("checkout")
+	if !found {
+		t.Fatalf("expected the checkout span, got %v", spans.Names())
+	}
+
+	if len(span.Events) != 2 || span.Events[0].Name != "feature_flag.evaluation" {
+		t.Fatalf("expected 2 feature_flag.evaluation events, got %v", span.Events)
+	}
+
+	attrs := map[string]string{}
+	for _, a := range span.Events[0].Attributes {
+		attrs[string(a.Key)] = a.Value.Emit()
+	}
+
+	expected := map[string]string{
+		"feature_flag.key":            "new-checkout",
+		"feature_flag.result.variant": "on",
+		"feature_flag.result.value":   "true",
+		"feature_flag.result.reason":  "TARGETING_MATCH",
+		"feature_flag.provider.name":  "flagd",
+	}
+	for k, v := range expected {
+		if attrs[k] != v {
+			t.Errorf("expected event attribute %s=%q, got %q", k, v, attrs[k])
+		}
+	}
+
+	if !strings.Contains(buf.String(), `"feature_flag":"new-checkout"`) {
+		t.Errorf("expected the evaluation to be logged, got %s", buf.String())
+	}
+
+	if !strings.Contains(buf.String(), `"level":"WARN"`) || !strings.Contains(buf.String(), "flag not found") {
+		t.Errorf("expected the failed evaluation to be logged as a warning, got %s", buf.String())
+	}
+
+	failed := go11y.FeatureFlagEvaluations.WithLabelValues("discount-rate", "", go11y.FlagReasonError)
+	if got := testutil.ToFloat64(failed); got != 1 {
+		t.Errorf("expected the failed evaluation to be counted with the ERROR reason, got %v", got)
+	}
+}
@@ -0,0 +1,161 @@
+package go11y
+
+import (
+	"log/slog"
+	"strings"
+)
+
+// FieldSchema is the naming scheme of the keys of the records go11y writes, see WithFieldSchema
+type FieldSchema int
+
+const (
+	// SchemaGo11y writes the keys as named by the Field* constants and slog, e.g. "request_method", the default
+	SchemaGo11y FieldSchema = iota
+	// SchemaECS writes the keys the Elastic Common Schema defines, e.g. "http.request.method" and "message"
+	SchemaECS
+	// SchemaOTel writes the keys of the OpenTelemetry semantic conventions, e.g. "http.request.method" and "body"
+	SchemaOTel
+)
+
+// fieldSchemas maps the keys go11y writes to their names in each schema. Keys the schema has no equivalent for are
+// written as they are.
+var fieldSchemas = map[FieldSchema]map[string]string{
+	SchemaECS: {
+		slog.TimeKey:           "@timestamp",
+		slog.LevelKey:          "log.level",
+		slog.MessageKey:        "message",
+		slog.SourceKey:         "log.origin",
+		"error":                "error.message",
+		FieldErrorCode:         "error.code",
+		FieldTraceID:           "trace.id",
+		FieldSpanID:            "span.id",
+		FieldRequestID:         "http.request.id",
+		FieldRequestMethod:     "http.request.method",
+		FieldRequestPath:       "url.path",
+		FieldRequestURL:        "url.full",
+		FieldRequestBody:       "http.request.body.content",
+		FieldResponseBody:      "http.response.body.content",
+		FieldResponseSize:      "http.response.body.bytes",
+		FieldStatusCode:        "http.response.status_code",
+		FieldReferer:           "http.request.referrer",
+		FieldUserAgent:         "user_agent.original",
+		FieldClientIP:          "client.ip",
+		FieldUserID:            "user.id",
+		FieldEnvironment:       "service.environment",
+		FieldServiceName:       "service.name",
+		FieldServiceVersion:    "service.version",
+		FieldServiceInstanceID: "service.node.name",
+	},
+	SchemaOTel: {
+		slog.TimeKey:           "timestamp",
+		slog.LevelKey:          "severity_text",
+		slog.MessageKey:        "body",
+		slog.SourceKey:         "code.function",
+		"error":                "exception.message",
+		FieldRequestMethod:     "http.request.method",
+		FieldRequestPath:       "url.path",
+		FieldRequestURL:        "url.full",
+		FieldResponseSize:      "http.response.body.size",
+		FieldStatusCode:        "http.response.status_code",
+		FieldUserAgent:         "user_agent.original",
+		FieldClientIP:          "client.address",
+		FieldUserID:            "user.id",
+		FieldEnvironment:       "deployment.environment.name",
+		FieldServiceName:       "service.name",
+		FieldServiceVersion:    "service.version",
+		FieldServiceInstanceID: "service.instance.id",
+		FieldGraphQLOperation:  "graphql.operation.name",
+		FieldFeatureFlag:       "feature_flag.key",
+	},
+}
+
+// ParseFieldSchema maps "go11y", "ecs" and "otel" to a FieldSchema, defaulting to SchemaGo11y.
+func ParseFieldSchema(schema string) FieldSchema {
+	switch strings.ToLower(strings.TrimSpace(schema)) {
+	case "ecs", "elastic":
+		return SchemaECS
+	case "otel", "opentelemetry", "semconv":
+		return SchemaOTel
+	default:
+		return SchemaGo11y
+	}
+}
+
+// String returns the name of the schema as accepted by ParseFieldSchema
+func (s FieldSchema) String() string {
+	switch s {
+	case SchemaECS:
+		return "ecs"
+	case SchemaOTel:
+		return "otel"
+	default:
+		return "go11y"
+	}
+}
+
+// Name returns the key $field (e.g. FieldRequestMethod or slog.MessageKey) is written as in the schema, so code that
+// reads go11y's output, such as log queries, can find it whichever schema is configured.
+func (s FieldSchema) Name(field string) string {
+	if name, ok := fieldSchemas[s][field]; ok {
+		return name
+	}
+
+	return field
+}
+
+// FieldName returns the key $field is written as by the default Observer (see Default), see FieldSchema.Name.
+func FieldName(field string) string {
+	return Default().fieldSchema.Name(field)
+}
+
+// FieldSchemaConfigurator is implemented by Configurators that choose the naming scheme of the keys of records.
+// Configuration implements it, other Configurators use SchemaGo11y.
+type FieldSchemaConfigurator interface {
+	LogFieldSchema() FieldSchema
+}
+
+// WithFieldSchema sets the naming scheme of the keys of records, so they can follow the Elastic Common Schema or the
+// OpenTelemetry semantic conventions expected by the log pipeline rather than go11y's own names.
+func WithFieldSchema(schema FieldSchema) ConfigOption {
+	return func(c *Configuration) {
+		c.fieldSchema = schema
+	}
+}
+
+// LogFieldSchema returns the naming scheme of the keys of records.
+// This method is part of the FieldSchemaConfigurator interface.
+func (c *Configuration) LogFieldSchema() FieldSchema {
+	return c.fieldSchema
+}
+
+// configFieldSchema returns the naming scheme of the keys of the records of $cfg
+func configFieldSchema(cfg Configurator) FieldSchema {
+	if fc, ok := cfg.(FieldSchemaConfigurator); ok {
+		return fc.LogFieldSchema()
+	}
+
+	return SchemaGo11y
+}
+
+// schemaReplacer wraps the attr replacer $next, renaming the top-level keys it returns per $schema
+func schemaReplacer(
+	schema FieldSchema, next func(groups []string, a slog.Attr) slog.Attr,
+) func(groups []string, a slog.Attr) slog.Attr {
+	names := fieldSchemas[schema]
+	if len(names) == 0 {
+		return next
+	}
+
+	return func(groups []string, a slog.Attr) slog.Attr {
+		a = next(groups, a)
+		if len(groups) != 0 {
+			return a
+		}
+
+		if name, ok := names[a.Key]; ok {
+			a.Key = name
+		}
+
+		return a
+	}
+}
@@ -0,0 +1,97 @@
+package go11y_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/cirruscomms/go11y"
+)
+
+func TestFieldSchema(t *testing.T) {
+	testCases := []struct {
+		name     string
+		schema   go11y.FieldSchema
+		expected map[string]any
+	}{
+		{
+			name:   "go11y",
+			schema: go11y.SchemaGo11y,
+			expected: map[string]any{
+				"msg":            "request handled",
+				"level":          "INFO",
+				"request_method": "GET",
+				"status_code":    float64(200),
+			},
+		},
+		{
+			name:   "ecs",
+			schema: go11y.SchemaECS,
+			expected: map[string]any{
+				"message":                   "request handled",
+				"log.level":                 "INFO",
+				"http.request.method":       "GET",
+				"http.response.status_code": float64(200),
+			},
+		},
+		{
+			name:   "otel",
+			schema: go11y.SchemaOTel,
+			expected: map[string]any{
+				"body":                      "request handled",
+				"severity_text":             "INFO",
+				"http.request.method":       "GET",
+				"http.response.status_code": float64(200),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := go11y.NewConfig(go11y.WithLogLevel(go11y.LevelInfo), go11y.WithFieldSchema(tc.schema))
+
+			buf := new(bytes.Buffer)
+			_, o, err := go11y.Initialise(context.Background(), cfg, buf, buf)
+			if err != nil {
+				t.Fatalf("failed to initialise observer: %v", err)
+			}
+			defer o.Close()
+
+			buf.Reset()
+			o.Info("request handled", go11y.FieldRequestMethod, "GET", go11y.FieldStatusCode, 200)
+
+			record := map[string]any{}
+			if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+				t.Fatalf("failed to parse record %s: %v", buf.String(), err)
+			}
+
+			for key, value := range tc.expected {
+				if record[key] != value {
+					t.Errorf("expected %s to be %v, got %v in %s", key, value, record[key], buf.String())
+				}
+			}
+
+			if name := go11y.FieldName(go11y.FieldRequestMethod); name != tc.schema.Name(go11y.FieldRequestMethod) {
+				t.Errorf("expected FieldName to follow the default Observer's schema, got %s", name)
+			}
+		})
+	}
+}
+
+func TestParseFieldSchema(t *testing.T) {
+	for input, expected := range map[string]go11y.FieldSchema{
+		"ECS":     go11y.SchemaECS,
+		"otel":    go11y.SchemaOTel,
+		"":        go11y.SchemaGo11y,
+		"unknown": go11y.SchemaGo11y,
+	} {
+		if schema := go11y.ParseFieldSchema(input); schema != expected {
+			t.Errorf("expected %q to parse as %s, got %s", input, expected, schema)
+		}
+	}
+
+	if name := go11y.SchemaECS.Name("order_id"); name != "order_id" {
+		t.Errorf("expected keys without an equivalent to be kept, got %s", name)
+	}
+}
@@ -27,6 +27,9 @@ const FieldResponseBody = "response_body"
 // FieldCallDuration is the structured log field name for "call_duration"
 const FieldCallDuration = "call_duration"
 
+// FieldDeadlineRemaining is the structured log field name for "deadline_remaining"
+const FieldDeadlineRemaining = "deadline_remaining"
+
 // FieldStatusCode is the structured log field name for "status_code"
 const FieldStatusCode = "status_code"
 
@@ -44,3 +47,68 @@ const FieldRemoteSpanID = "remote_span_id"
 
 // FieldEnvironment is the structured log field name for "environment"
 const FieldEnvironment = "environment"
+
+// FieldServiceName is the structured log field name for "service_name"
+const FieldServiceName = "service_name"
+
+// FieldServiceVersion is the structured log field name for "service_version"
+const FieldServiceVersion = "service_version"
+
+// FieldServiceInstanceID is the structured log field name for "service_instance_id"
+const FieldServiceInstanceID = "service_instance_id"
+
+// FieldResponseSize is the structured log field name for "response_size"
+const FieldResponseSize = "response_size"
+
+// FieldRequestDuration is the structured log field name for "request_duration"
+const FieldRequestDuration = "request_duration"
+
+// FieldTenantID is the structured log field name for "tenant_id"
+const FieldTenantID = "tenant_id"
+
+// FieldUserID is the structured log field name for "user_id"
+const FieldUserID = "user_id"
+
+// FieldClientID is the structured log field name for "client_id"
+const FieldClientID = "client_id"
+
+// FieldReferer is the structured log field name for "referer"
+const FieldReferer = "referer"
+
+// FieldUserAgent is the structured log field name for "user_agent"
+const FieldUserAgent = "user_agent"
+
+// FieldClientIP is the structured log field name for "client_ip"
+const FieldClientIP = "client_ip"
+
+// FieldJobName is the structured log field name for "job_name"
+const FieldJobName = "job_name"
+
+// FieldJobRunID is the structured log field name for "job_run_id"
+const FieldJobRunID = "job_run_id"
+
+// FieldJobDuration is the structured log field name for "job_duration"
+const FieldJobDuration = "job_duration"
+
+// FieldContextError is the structured log field name for "context_error"
+const FieldContextError = "context_error"
+
+// FieldDBPool is the structured log field name for "db_pool"
+const FieldDBPool = "db_pool"
+
+// FieldMsgTemplate is the structured log field name for "msg_template"
+const FieldMsgTemplate = "msg_template"
+
+// FieldMsgArgs is the structured log field name for "msg_args"
+const FieldMsgArgs = "msg_args"
+
+// FieldComponent is the structured log field name for "component", the part of the service a record comes from. As a
+// stable arg it labels the LogRecordsByComponent metric.
+const FieldComponent = "component"
+
+// FieldErrorCode is the structured log field name for "error_code", the code of an error, see WithErrorCode
+const FieldErrorCode = "error_code"
+
+// FieldAlert is the structured log field name for "alert", logged with the errors that should alert, see
+// SeverityBehaviour
+const FieldAlert = "alert"
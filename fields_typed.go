@@ -0,0 +1,89 @@
+package go11y
+
+import (
+	"log/slog"
+	"time"
+)
+
+// Field is a typed key-value pair, accepted wherever go11y accepts key-value args (the log methods, Extend, With,
+// StartSpan...) and freely mixed with them, e.g. o.Info("sent", go11y.Int("count", n), "queue", name).
+// Unlike interleaved keys and values, a Field can't lose its value to an odd number of args.
+type Field = slog.Attr
+
+// badKey is the key of a trailing arg without a value, as used by slog
+const badKey = "!BADKEY"
+
+// String returns a Field for a string value
+func String(key, value string) Field {
+	return slog.String(key, value)
+}
+
+// Int returns a Field for an int value
+func Int(key string, value int) Field {
+	return slog.Int(key, value)
+}
+
+// Int64 returns a Field for an int64 value
+func Int64(key string, value int64) Field {
+	return slog.Int64(key, value)
+}
+
+// Float64 returns a Field for a float64 value
+func Float64(key string, value float64) Field {
+	return slog.Float64(key, value)
+}
+
+// Bool returns a Field for a bool value
+func Bool(key string, value bool) Field {
+	return slog.Bool(key, value)
+}
+
+// Duration returns a Field for a time.Duration value
+func Duration(key string, value time.Duration) Field {
+	return slog.Duration(key, value)
+}
+
+// Time returns a Field for a time.Time value
+func Time(key string, value time.Time) Field {
+	return slog.Time(key, value)
+}
+
+// Err returns an "error" Field with the message of $err, or an empty message if $err is nil
+func Err(err error) Field {
+	if err == nil {
+		return slog.String("error", "")
+	}
+
+	return slog.String("error", err.Error())
+}
+
+// Any returns a Field for a value of any type
+func Any(key string, value any) Field {
+	return slog.Any(key, value)
+}
+
+// argPairs returns $args as interleaved keys and values, expanding Fields into their key and value. Like slog, a string
+// is a key for the arg after it, while a trailing key without a value and any arg that is neither a key nor a Field
+// are kept with the key "!BADKEY" rather than being dropped or shifting the pairs after them.
+func argPairs(args []any) (pairs []any) {
+	pairs = make([]any, 0, len(args)+1)
+
+	for i := 0; i < len(args); i++ {
+		switch a := args[i].(type) {
+		case slog.Attr:
+			pairs = append(pairs, a.Key, a.Value.Any())
+		case string:
+			if i+1 == len(args) {
+				pairs = append(pairs, badKey, a)
+				continue
+			}
+
+			pairs = append(pairs, a, args[i+1])
+			i++
+		default:
+			pairs = append(pairs, badKey, a)
+		}
+	}
+
+	return pairs
+}
@@ -0,0 +1,67 @@
+package go11y_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cirruscomms/go11y"
+)
+
+func TestTypedFields(t *testing.T) {
+	out := new(bytes.Buffer)
+	cfg := go11y.NewConfig(go11y.WithLogLevel(go11y.LevelInfo))
+
+	ctx, _, err := go11y.Initialise(context.Background(), cfg, out, out)
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	_, o, err := go11y.Extend(ctx, go11y.String("tenant", "acme"), "region", "eu")
+	if err != nil {
+		t.Fatalf("failed to extend observer: %v", err)
+	}
+	out.Reset()
+
+	o.Info("sent",
+		go11y.Int("count", 3),
+		go11y.Duration("elapsed", 2*time.Millisecond),
+		go11y.Err(errors.New("partial")),
+		go11y.Bool("retried", true),
+		"queue", "emails",
+		"dangling",
+	)
+
+	records := decodeRecords(t, out)
+	if len(records) != 1 {
+		t.Fatalf("expected 1 record in the output, got %d: %s", len(records), out.String())
+	}
+
+	expected := map[string]any{
+		"tenant":  "acme",
+		"region":  "eu",
+		"count":   float64(3),
+		"elapsed": float64(2 * time.Millisecond),
+		"error":   "partial",
+		"retried": true,
+		"queue":   "emails",
+		"msg":     "sent",
+	}
+	for k, v := range expected {
+		if records[0][k] != v {
+			t.Errorf("expected %s to be %v, got %v", k, v, records[0][k])
+		}
+	}
+
+	// the value is redacted, as "!BADKEY" matches the redaction policy
+	if _, ok := records[0]["!BADKEY"]; !ok {
+		t.Errorf("expected the trailing key without a value to be kept, got %v", records[0])
+	}
+
+	stable := o.AddArgs()
+	if len(stable)%2 != 0 {
+		t.Errorf("expected the stable args to be key-value pairs, got %v", stable)
+	}
+}
@@ -0,0 +1,205 @@
+package go11y
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// RotatingFileWriter is an io.Writer that writes to a file, rotating it when it grows beyond a maximum size or becomes
+// older than a maximum age. Rotated files are renamed with a timestamp suffix and optionally gzip compressed.
+type RotatingFileWriter struct {
+	path       string
+	maxSize    int64
+	maxAge     time.Duration
+	maxBackups int
+	compress   bool
+	file       *os.File
+	size       int64
+	openedAt   time.Time
+	mu         sync.Mutex
+	housekeep  sync.Mutex // serialises compression and pruning of rotated files
+	wg         sync.WaitGroup
+}
+
+// RotatingFileOptions are the options used to create a RotatingFileWriter
+type RotatingFileOptions struct {
+	MaxSize    int64         // rotate when the file exceeds this many bytes - 0 disables size based rotation
+	MaxAge     time.Duration // rotate when the file is older than this - 0 disables time based rotation
+	MaxBackups int           // number of rotated files to keep - 0 keeps them all
+	Compress   bool          // gzip rotated files
+}
+
+// NewRotatingFileWriter opens (or creates) the file at $path for appending and returns a writer that rotates it
+// according to $opts.
+func NewRotatingFileWriter(path string, opts RotatingFileOptions) (writer *RotatingFileWriter, fault error) {
+	w := &RotatingFileWriter{
+		path:       path,
+		maxSize:    opts.MaxSize,
+		maxAge:     opts.MaxAge,
+		maxBackups: opts.MaxBackups,
+		compress:   opts.Compress,
+	}
+
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// Write writes $p to the current file, rotating it first if required.
+func (w *RotatingFileWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return 0, ErrWriterClosed
+	}
+
+	if w.shouldRotate(int64(len(p))) {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+
+	return n, err
+}
+
+// Close closes the current file and waits for any background compression to finish.
+func (w *RotatingFileWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+
+	err := w.file.Close()
+	w.file = nil
+	w.wg.Wait()
+
+	return err
+}
+
+func (w *RotatingFileWriter) shouldRotate(incoming int64) bool {
+	if w.maxSize > 0 && w.size > 0 && w.size+incoming > w.maxSize {
+		return true
+	}
+
+	return w.maxAge > 0 && time.Since(w.openedAt) > w.maxAge
+}
+
+func (w *RotatingFileWriter) open() error {
+	if err := os.MkdirAll(filepath.Dir(w.path), 0o755); err != nil {
+		return fmt.Errorf("could not create log directory: %w", err)
+	}
+
+	f, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("could not open log file: %w", err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("could not stat log file: %w", err)
+	}
+
+	w.file = f
+	w.size = info.Size()
+	w.openedAt = time.Now()
+
+	return nil
+}
+
+func (w *RotatingFileWriter) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return fmt.Errorf("could not close log file: %w", err)
+	}
+
+	rotated := fmt.Sprintf("%s.%s", w.path, time.Now().UTC().Format("20060102T150405.000000000"))
+	if err := os.Rename(w.path, rotated); err != nil {
+		return fmt.Errorf("could not rotate log file: %w", err)
+	}
+
+	if err := w.open(); err != nil {
+		return err
+	}
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+
+		w.housekeep.Lock()
+		defer w.housekeep.Unlock()
+
+		if w.compress {
+			_ = compressFile(rotated)
+		}
+
+		w.prune()
+	}()
+
+	return nil
+}
+
+// prune removes the oldest rotated files beyond maxBackups.
+func (w *RotatingFileWriter) prune() {
+	if w.maxBackups <= 0 {
+		return
+	}
+
+	matches, err := filepath.Glob(w.path + ".*")
+	if err != nil {
+		return
+	}
+
+	// the timestamp suffix sorts lexically
+	sort.Strings(matches)
+
+	for len(matches) > w.maxBackups {
+		_ = os.Remove(matches[0])
+		matches = matches[1:]
+	}
+}
+
+func compressFile(path string) (fault error) {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = in.Close()
+	}()
+
+	out, err := os.Create(path + ".gz")
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(out)
+	if _, err = io.Copy(gz, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+
+	if err = gz.Close(); err != nil {
+		_ = out.Close()
+		return err
+	}
+
+	if err = out.Close(); err != nil {
+		return err
+	}
+
+	return os.Remove(path)
+}
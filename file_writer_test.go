@@ -0,0 +1,101 @@
+package go11y_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cirruscomms/go11y"
+)
+
+func TestRotatingFileWriter(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	w, err := go11y.NewRotatingFileWriter(path, go11y.RotatingFileOptions{
+		MaxSize:    20,
+		MaxBackups: 2,
+		Compress:   true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create writer: %v", err)
+	}
+
+	for range 5 {
+		if _, err := w.Write([]byte("0123456789abcdef\n")); err != nil {
+			t.Fatalf("failed to write: %v", err)
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("failed to close writer: %v", err)
+	}
+
+	current, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read current log file: %v", err)
+	}
+
+	if string(current) != "0123456789abcdef\n" {
+		t.Errorf("expected the current file to hold the last record, got %q", string(current))
+	}
+
+	rotated, err := filepath.Glob(path + ".*")
+	if err != nil {
+		t.Fatalf("failed to list rotated files: %v", err)
+	}
+
+	if len(rotated) != 2 {
+		t.Errorf("expected 2 rotated files to be kept, got %v", rotated)
+	}
+
+	for _, r := range rotated {
+		if !strings.HasSuffix(r, ".gz") {
+			t.Errorf("expected rotated file %s to be compressed", r)
+		}
+	}
+}
+
+func TestOpenLogOutput(t *testing.T) {
+	dir := t.TempDir()
+
+	testCases := map[string]struct {
+		spec    string
+		wantErr bool
+	}{
+		"default":              {spec: ""},
+		"stdout":               {spec: "stdout"},
+		"stderr":               {spec: "stderr"},
+		"file":                 {spec: "file:" + filepath.Join(dir, "a.log")},
+		"file with options":    {spec: "file:" + filepath.Join(dir, "b.log") + "?max_size_mb=1&max_age=1h&max_backups=3&compress=false"},
+		"file without path":    {spec: "file:", wantErr: true},
+		"file with bad option": {spec: "file:" + filepath.Join(dir, "c.log") + "?max_age=soon", wantErr: true},
+		"unsupported":          {spec: "s3://bucket", wantErr: true},
+		"unopenable file":      {spec: "file:/dev/null/app.log", wantErr: true},
+		"unreachable syslog":   {spec: "syslog:tcp://127.0.0.1:1", wantErr: true},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			out, err := go11y.OpenLogOutput(tc.spec)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("expected an error for %q", tc.spec)
+				}
+				if out != nil {
+					t.Errorf("expected no output with the error for %q, got %#v", tc.spec, out)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if c, ok := out.(*go11y.RotatingFileWriter); ok {
+				_ = c.Close()
+			}
+		})
+	}
+}
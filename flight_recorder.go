@@ -0,0 +1,146 @@
+package go11y
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// FieldFlightRecorded is the structured log field name for "flight_recorded", set on the records written late by the
+// flight recorder, see FlightRecorderOpts
+const FieldFlightRecorded = "flight_recorded"
+
+// DefaultFlightRecorderLimit is the number of records a flight recorder buffers per request, unless its options set
+// their own
+const DefaultFlightRecorderLimit = 1000
+
+const (
+	// FlightRecorderFailed is the reason label of the requests whose buffered records were written because an error
+	// was logged while handling them, or they were answered with a 5xx
+	FlightRecorderFailed = "failed"
+	// FlightRecorderSlow is the reason label of the requests whose buffered records were written because they took
+	// longer than the latency threshold
+	FlightRecorderSlow = "slow"
+)
+
+// FlightRecorderFlushes is the metric for the number of requests whose buffered records were written by the flight
+// recorder, by reason (FlightRecorderFailed or FlightRecorderSlow)
+var FlightRecorderFlushes = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "go11y_flight_recorder_flushes_total",
+	Help: "Number of requests whose buffered debug records were written by the flight recorder",
+}, []string{"reason"})
+
+var registerFlightRecorderMetricsOnce sync.Once
+
+func registerFlightRecorderMetrics() {
+	registerFlightRecorderMetricsOnce.Do(func() {
+		registerCollectors(FlightRecorderFlushes)
+	})
+}
+
+// FlightRecorderOpts are the options of the flight recorder of the request logger middleware. While handling a
+// request, the records below the Observer's level (down to LevelDevelop) are buffered rather than discarded, and
+// written with FieldFlightRecorded only if the request fails - an error is logged or it is answered with a 5xx - or
+// takes longer than Latency. Otherwise they are discarded when the request ends. This gives the Debug records of
+// failed requests without the cost of writing them for every request.
+type FlightRecorderOpts struct {
+	Latency time.Duration // optional - the buffered records of requests taking longer are written, defaults to never
+	Limit   int           // optional - the number of records buffered per request, defaults to DefaultFlightRecorderLimit
+}
+
+// flightRecorder buffers the records below the Observer's level logged while handling a request
+type flightRecorder struct {
+	limit int
+
+	mu      sync.Mutex
+	records []slog.Record
+	failed  bool
+}
+
+func newFlightRecorder(opts FlightRecorderOpts) *flightRecorder {
+	if opts.Limit <= 0 {
+		opts.Limit = DefaultFlightRecorderLimit
+	}
+
+	registerFlightRecorderMetrics()
+
+	return &flightRecorder{limit: opts.Limit}
+}
+
+// flightFlushKey marks the context of the records written by flushFlight
+var flightFlushKey go11yContextKey = "cirruscomms/go11y/flight"
+
+// flightFlushing reports whether $ctx is that of a record written by the flight recorder, which handlers take whatever
+// the Observer's level
+func flightFlushing(ctx context.Context) (flushing bool) {
+	flushing, _ = ctx.Value(flightFlushKey).(bool)
+	return flushing
+}
+
+// recordFlight buffers the record of $msg at $level, logged by the callsite $pc with $args and the Observer's stable
+// args, in the Observer's flight recorder if it has one and the record is below the Observer's level, and marks the
+// request as failed if the record is an error. It returns whether the record was buffered.
+func (o *Observer) recordFlight(pc uintptr, level slog.Level, msg string, args []any) (buffered bool) {
+	f := o.flight
+	if f == nil {
+		return false
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if level >= LevelError {
+		f.failed = true
+	}
+
+	if level >= o.level || level < LevelDevelop || len(f.records) >= f.limit {
+		return false
+	}
+
+	// the stable args are copied now, as the request's Observer may be extended before the record is written
+	r := slog.NewRecord(o.clock.Now(), level, msg, pc)
+	r.AddAttrs(appendDedupedAttrs(nil, slices.Concat(o.stableArgs, args))...)
+	f.records = append(f.records, r)
+
+	return true
+}
+
+// flushFlight writes the records buffered by the Observer's flight recorder if the request failed (or $failed), or
+// took longer than $latency (if set) with $duration, and discards them otherwise.
+func (o *Observer) flushFlight(ctx context.Context, failed bool, duration, latency time.Duration) {
+	f := o.flight
+	if f == nil {
+		return
+	}
+
+	f.mu.Lock()
+	records := f.records
+	failed = failed || f.failed
+	f.records = nil
+	f.mu.Unlock()
+
+	reason := ""
+	switch {
+	case failed:
+		reason = FlightRecorderFailed
+	case latency > 0 && duration > latency:
+		reason = FlightRecorderSlow
+	default:
+		return
+	}
+
+	FlightRecorderFlushes.WithLabelValues(reason).Inc()
+
+	// the records go through the same handlers as any other, which take them although they are below the level
+	ctx = context.WithValue(ctx, flightFlushKey, true)
+
+	for _, r := range records {
+		r.AddAttrs(slog.Bool(FieldFlightRecorded, true))
+		err := o.logger(r.Level).Handler().Handle(ctx, r)
+		recordHandled(LevelToString(r.Level), o.component, err)
+	}
+}
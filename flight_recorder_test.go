@@ -0,0 +1,133 @@
+package go11y
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func newFlightObserver(t *testing.T, opts FlightRecorderOpts, options ...any) (o *Observer, out *bytes.Buffer) {
+	t.Helper()
+
+	out = new(bytes.Buffer)
+	cfg := CreateConfig(LevelInfo, "", "", "", []string{}, []string{})
+
+	_, o, err := Initialise(context.Background(), cfg, out, out, options...)
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	o.flight = newFlightRecorder(opts)
+	out.Reset()
+
+	return o, out
+}
+
+func TestFlightRecorder(t *testing.T) {
+	testCases := map[string]struct {
+		log      func(o *Observer)
+		failed   bool
+		duration time.Duration
+		reason   string
+	}{
+		"discarded": {
+			log:      func(o *Observer) {},
+			duration: 10 * time.Millisecond,
+		},
+		"failed by an error": {
+			log: func(o *Observer) {
+				o.Error("failed", errors.New("TestFlightRecorder"), SeverityLow)
+			},
+			reason: FlightRecorderFailed,
+		},
+		"failed by the caller": {
+			log:    func(o *Observer) {},
+			failed: true,
+			reason: FlightRecorderFailed,
+		},
+		"slow": {
+			log:      func(o *Observer) {},
+			duration: 30 * time.Millisecond,
+			reason:   FlightRecorderSlow,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			o, out := newFlightObserver(t, FlightRecorderOpts{Latency: 20 * time.Millisecond})
+
+			o.Debug("step", "attempt", 1)
+			tc.log(o)
+
+			if strings.Contains(out.String(), `"msg":"step"`) {
+				t.Fatalf("expected the debug record to be buffered, got %s", out.String())
+			}
+
+			var before float64
+			if tc.reason != "" {
+				before = testutil.ToFloat64(FlightRecorderFlushes.WithLabelValues(tc.reason))
+			}
+
+			o.flushFlight(context.Background(), tc.failed, tc.duration, 20*time.Millisecond)
+
+			written := strings.Contains(out.String(), `"msg":"step","attempt":1,"flight_recorded":true`)
+			if written != (tc.reason != "") {
+				t.Errorf("expected the debug record to be written: %v, got %s", tc.reason != "", out.String())
+			}
+
+			if tc.reason != "" {
+				if after := testutil.ToFloat64(FlightRecorderFlushes.WithLabelValues(tc.reason)); after != before+1 {
+					t.Errorf("expected the %s flushes to be counted, got %v", tc.reason, after-before)
+				}
+			}
+
+			// the buffer is emptied either way
+			out.Reset()
+			o.flushFlight(context.Background(), true, 0, 0)
+			if strings.Contains(out.String(), `"msg":"step"`) {
+				t.Errorf("expected the buffered records to be dropped once flushed, got %s", out.String())
+			}
+		})
+	}
+}
+
+func TestFlightRecorderLimit(t *testing.T) {
+	o, out := newFlightObserver(t, FlightRecorderOpts{Limit: 2})
+
+	o.Debug("first")
+	o.Debug("second")
+	o.Debug("third")
+	o.flushFlight(context.Background(), true, 0, 0)
+
+	if n := strings.Count(out.String(), `"flight_recorded":true`); n != 2 {
+		t.Errorf("expected 2 records to be written, got %d: %s", n, out.String())
+	}
+	if strings.Contains(out.String(), `"msg":"third"`) {
+		t.Errorf("expected the records over the limit to be dropped, got %s", out.String())
+	}
+}
+
+func TestFlightRecorderSinks(t *testing.T) {
+	debugSink, infoSink := new(bytes.Buffer), new(bytes.Buffer)
+	o, out := newFlightObserver(t, FlightRecorderOpts{}, WithSinks(
+		Sink{Writer: debugSink, MinLevel: LevelDebug},
+		Sink{Writer: infoSink, MinLevel: LevelInfo},
+	))
+
+	o.Debug("step")
+	o.flushFlight(context.Background(), true, 0, 0)
+
+	for name, buf := range map[string]*bytes.Buffer{"output": out, "debug sink": debugSink} {
+		if !strings.Contains(buf.String(), `"msg":"step"`) {
+			t.Errorf("expected the %s to get the flight recorded record, got %s", name, buf.String())
+		}
+	}
+	if infoSink.Len() != 0 {
+		t.Errorf("expected a sink above the record's level not to get it, got %s", infoSink.String())
+	}
+}
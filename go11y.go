@@ -4,14 +4,18 @@ package go11y
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"maps"
 	"os"
-	"runtime"
+	"slices"
 	"strings"
-	"time"
+	"sync"
 
+	otelAttribute "go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	otelSDKTrace "go.opentelemetry.io/otel/sdk/trace"
 	otelTrace "go.opentelemetry.io/otel/trace"
 )
@@ -21,26 +25,52 @@ type Fields map[string]any
 
 // Observer is the main struct for observability, containing loggers, tracer providers, and database connections.
 type Observer struct {
-	cfg           Configurator
-	output        io.Writer
-	level         slog.Level
-	outLogger     *slog.Logger
-	errLogger     *slog.Logger
-	traceProvider *otelSDKTrace.TracerProvider
-	tracer        otelTrace.Tracer
-	stableArgs    []any
-	span          otelTrace.Span
-	spans         []otelTrace.Span
-	skipCallers   int
+	cfg             Configurator
+	output          io.Writer
+	errOutput       io.Writer
+	closers         []io.Closer // outputs opened by go11y itself, closed by Close()
+	level           slog.Level  // the effective log level, see configLogLevel
+	console         bool        // whether the primary outputs are written as text rather than JSON, see Environment
+	outLogger       *slog.Logger
+	errLogger       *slog.Logger
+	errorLevel      slog.Level // records at or above it are written to errOutput, see WithErrorOutputLevel
+	traceProvider   *otelSDKTrace.TracerProvider
+	tracer          otelTrace.Tracer
+	stableArgs      []any
+	stableAttrs     []otelAttribute.KeyValue // stableArgs converted to span attributes once, see setStableArgs
+	component       string                   // the component stable arg, labelling the LogRecordsByComponent metric
+	spanLimits      SpanAttributeLimits
+	spanEventMode   SpanEventMode
+	spanEventLevel  slog.Level // records below it aren't added to spans, see WithSpanEvents
+	span            otelTrace.Span
+	spans           []otelTrace.Span
+	callerSkip      int         // extra frames skipped to find the source of records, see WithCallerSkip
+	sourceMode      SourceMode  // what is logged as the source of records, see WithLogSource
+	fieldSchema     FieldSchema // the naming scheme of the keys of records, see WithFieldSchema
+	propagators     string      // the propagators installed as the global TextMapPropagator, see WithPropagators
+	redactAttrs     bool
+	markSpansOK     bool
+	sinks           []Sink
+	sampler         *sampler
+	callsiteBudget  *callsiteBudget  // counts the records of each callsite, see WithCallsiteBudget
+	eventPublishers []EventPublisher // publish the events recorded with Event, see WithEventPublishers
+	crashDump       *crashDump       // keeps the recent records of every level, see WithCrashDump
+	flight          *flightRecorder  // buffers the records of a request below the level, see FlightRecorderOpts
+	failedSpans     map[otelTrace.SpanID]bool
+	spanMu          *sync.Mutex // guards span, spans and failedSpans
+	clock           Clock
+	exportWatchdog  *exportWatchdog // watches the trace export pipeline, nil when tracing is not configured
+
+	restartRecorder RestartRecorder // records the start of the process, see WithRestartTracking
 }
 
 type go11yContextKey string
 
 var obsKeyInstance go11yContextKey = "cirruscomms/go11y"
 
-var ogx *Observer
-
 // Initialise sets up the Observer with the provided configuration, log outputs, and initial arguments.
+// Any Option values in initialArgs are applied to the Observer rather than being added as log fields.
+// The Observer becomes the default Observer (see Default) and its logger slog's default logger.
 func Initialise(
 	ctx context.Context,
 	cfg Configurator,
@@ -51,16 +81,14 @@ func Initialise(
 	observer *Observer,
 	fault error,
 ) {
-	if logOutput == nil {
-		logOutput = os.Stdout
-	}
-
 	if errOutput == nil {
 		errOutput = os.Stderr
 	}
 
 	var err error
 
+	registerPipelineMetrics()
+
 	if cfg == nil {
 		cfg, err = LoadConfig()
 		if err != nil {
@@ -68,21 +96,95 @@ func Initialise(
 		}
 	}
 
-	tp, err := tracerProvider(ctx, cfg)
+	var closers []io.Closer
+
+	if logOutput == nil {
+		logOutput, err = OpenLogOutput(configLogOutput(cfg))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open log output: %w", err)
+		}
+
+		if c, ok := logOutput.(io.Closer); ok && logOutput != os.Stdout && logOutput != os.Stderr {
+			closers = append(closers, c)
+		}
+	}
+
+	problems, err := validateForInitialise(ctx, cfg)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to create tracer: %w", err)
+		return nil, nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	tracingDisabled, tracingDegraded := false, false
+	for _, p := range problems {
+		if p.Setting != "OTEL_URL" {
+			continue
+		}
+
+		if errors.Is(p, ErrEndpointUnreachable) && !configOtelRequired(cfg) {
+			tracingDegraded = true
+		} else {
+			tracingDisabled = true
+		}
+	}
+
+	watchdog := newExportWatchdog()
+
+	var tp *otelSDKTrace.TracerProvider
+	if !tracingDisabled {
+		tp, err = tracerProvider(ctx, cfg, watchdog, tracingDegraded)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create tracer: %w", err)
+		}
+	}
+
+	options, initialArgs := splitOptions(initialArgs)
+
+	environment := configEnvironment(cfg)
+	if environment != "" && !slices.Contains(initialArgs, any(FieldEnvironment)) {
+		initialArgs = append([]any{FieldEnvironment, string(environment)}, initialArgs...)
 	}
 
-	opts := defaultOptions(cfg)
+	initialArgs = append(configServiceIdentity(cfg).args(initialArgs), initialArgs...)
 
 	o := &Observer{
-		cfg:           cfg,
-		output:        logOutput,
-		outLogger:     slog.New(slog.NewJSONHandler(logOutput, opts)),
-		errLogger:     slog.New(slog.NewJSONHandler(errOutput, opts)),
-		traceProvider: tp,
-		stableArgs:    initialArgs,
-		skipCallers:   3, // default to 3 but allow it to be increased via o.IncreaseDistance()
+		cfg:            cfg,
+		output:         logOutput,
+		errOutput:      errOutput,
+		errorLevel:     DefaultErrorOutputLevel,
+		closers:        closers,
+		level:          configLogLevel(cfg),
+		console:        environment.preset().console,
+		traceProvider:  tp,
+		redactAttrs:    true,
+		spanMu:         &sync.Mutex{},
+		clock:          SystemClock{},
+		spanLimits:     SpanAttributeLimits{}.withDefaults(),
+		spanEventLevel: LevelDevelop,
+		sourceMode:     configSourceMode(cfg),
+		fieldSchema:    configFieldSchema(cfg),
+		propagators:    installPropagator(cfg),
+	}
+
+	scope, version := instrumentationScope()
+	o.tracer = o.Tracer(scope, otelTrace.WithInstrumentationVersion(version))
+
+	if tp != nil {
+		o.exportWatchdog = watchdog
+		watchdog.attach(o)
+		watchdog.watch()
+	}
+
+	for _, opt := range options {
+		opt(o)
+	}
+
+	o.outLogger = slog.New(o.newHandler(logOutput))
+	o.errLogger = slog.New(o.newHandler(errOutput))
+
+	if o.crashDump != nil {
+		crashOpts := defaultOptions(o)
+		crashOpts.Level = LevelDevelop
+		o.crashDump.handler = slog.NewJSONHandler(o.crashDump, crashOpts)
 	}
 
 	ctx = context.WithValue(ctx, obsKeyInstance, o)
@@ -91,28 +193,62 @@ func Initialise(
 	}
 
 	slog.SetDefault(o.outLogger)
+	SetDefault(o)
+	setPIIDetectors(configPIIDetectors(cfg))
+	setRedaction(configRedaction(cfg))
 
 	o.Debug("Initialised observer with context")
 
+	for _, p := range problems {
+		args := []any{"setting", p.Setting, "error", p.Err.Error()}
+		switch {
+		case p.Setting == "OTEL_URL" && tracingDegraded:
+			args = append(args, "tracing", "degraded", "reconnect_interval", reconnectInterval.String())
+			o.Warning("telemetry collector unreachable - queueing spans until it can be reached", args...)
+
+			continue
+		case p.Setting == "OTEL_URL":
+			args = append(args, "tracing", "disabled")
+		}
+		o.Warning("invalid configuration - continuing in permissive mode", args...)
+	}
+	o.Debug("observability configured", "config", o.ConfigSnapshot())
+
+	build := ReadBuildInfo(cfg)
+	setBuildInfoMetric(build)
+
+	buildArgs := build.LogArgs()
+	if configServiceIdentity(cfg).version != "" {
+		// the version is already one of the Observer's args
+		buildArgs = buildArgs[2:]
+	}
+	o.Debug("service starting", buildArgs...)
+
+	registerUptimeMetrics()
+	o.recordRestart(ctx, build)
+
 	return ctx, o, nil
 }
 
-// Reset resets the Observer in the context to its initial state.
+// Reset resets the Observer in the context to its initial state, dropping its stable args and those passed to
+// Initialise. It changes the Observer shared through the context, so every other user of it, such as concurrent
+// requests, loses its args too - use ChildContext to start from a clean copy instead.
 func Reset(ctxWithGo11y context.Context) (ctxWithResetObservability context.Context) {
 	ctxWithGo11y, o, err := Get(ctxWithGo11y)
 	if err != nil {
 		return ctxWithGo11y
 	}
 
-	o.outLogger = slog.New(slog.NewJSONHandler(o.output, defaultOptions(o.cfg)))
-	o.errLogger = slog.New(slog.NewJSONHandler(o.output, defaultOptions(o.cfg)))
+	o.outLogger = slog.New(o.newHandler(o.output))
+	o.errLogger = slog.New(o.newHandler(o.errOutput))
 	o.Debug("Observer reset")
-	o.stableArgs = []any{}
+	o.setStableArgs([]any{})
 
 	return context.WithValue(ctxWithGo11y, obsKeyInstance, o)
 }
 
-// Get retrieves the Observer from the context. If none exists, it initializes a new one with default settings.
+// Get retrieves the Observer from the context, returning an error if there isn't one. FromContext falls back to the
+// default Observer instead.
 func Get(ctx context.Context) (ctxWithObserver context.Context, observer *Observer, fault error) {
 	ob := ctx.Value(obsKeyInstance)
 	if ob == nil {
@@ -126,6 +262,8 @@ func Get(ctx context.Context) (ctxWithObserver context.Context, observer *Observ
 
 // Extend retrieves the Observer from the context and adds new arguments to its logger.
 // If no Observer exists in the context, it initializes a new one with default settings and adds the arguments.
+// The Observer shared through the context is changed, so the arguments are seen by every other user of it - use
+// ChildContext for arguments that only belong to one request or goroutine.
 func Extend(ctx context.Context, newArgs ...any) (ctxWithGo11y context.Context, observer *Observer, fault error) {
 	ctx, o, err := Get(ctx)
 	if err != nil {
@@ -135,15 +273,57 @@ func Extend(ctx context.Context, newArgs ...any) (ctxWithGo11y context.Context,
 	if len(newArgs) != 0 {
 		o.outLogger = o.outLogger.With(newArgs...)
 		o.errLogger = o.errLogger.With(newArgs...)
-		o.stableArgs = o.AddArgs(newArgs...)
+		o.setStableArgs(o.AddArgs(newArgs...))
 	}
 
 	return context.WithValue(ctx, obsKeyInstance, o), o, nil
 }
 
-// Span gets the Observer from the context and starts a new tracing span with the given name.
+// With returns a child Observer with the new arguments added to its loggers, leaving the receiver untouched.
+// Unlike Extend, which mutates the Observer shared through the context, the child has its own copy of the loggers,
+// stable arguments and span stack, so it can be handed to a goroutine without affecting other users of the parent.
+func (o *Observer) With(newArgs ...any) (child *Observer) {
+	o.spanMu.Lock()
+	c := *o
+	c.spans = slices.Clone(o.spans)
+	c.failedSpans = maps.Clone(o.failedSpans)
+	o.spanMu.Unlock()
+
+	c.spanMu = &sync.Mutex{}
+	c.stableArgs = slices.Clone(o.stableArgs)
+
+	if len(newArgs) != 0 {
+		c.outLogger = o.outLogger.With(newArgs...)
+		c.errLogger = o.errLogger.With(newArgs...)
+		c.setStableArgs(c.AddArgs(newArgs...))
+	}
+
+	return &c
+}
+
+// ChildContext retrieves the Observer from the context and returns a child of it with the new arguments added (see
+// With), bound to the returned context. Nothing done with the child, such as adding arguments with Extend or resetting
+// it with Reset through the returned context, is seen by the parent or by other children of it, so each request or
+// goroutine can have its own args without clobbering those of others running concurrently.
+// It returns an error if there is no Observer in the context.
+func ChildContext(ctx context.Context, newArgs ...any) (ctxWithChild context.Context, child *Observer, fault error) {
+	ctx, o, err := Get(ctx)
+	if err != nil {
+		return ctx, nil, err
+	}
+
+	child = o.With(newArgs...)
+
+	return context.WithValue(ctx, obsKeyInstance, child), child, nil
+}
+
+// Span gets the Observer from the context and starts a new tracing span with the given name, using $tracer or, if it
+// is nil, the Observer's tracer (see WithTracer).
 // If no Observer exists in the context, it initializes a new one with default settings and starts the span.
 // The tracing equivalent of Get()
+// The span is pushed onto the span stack of the Observer shared through the context and is ended by o.End(), which
+// always ends the newest span on the stack, so Span is only suitable for spans started and ended in order on one
+// goroutine. StartSpan is the supported API for spans started concurrently or ended out of order.
 func Span(
 	ctx context.Context,
 	tracer otelTrace.Tracer,
@@ -159,17 +339,60 @@ func Span(
 		return ctx, nil, err
 	}
 
-	ctx, span := tracer.Start(ctx, spanName, otelTrace.WithSpanKind(spanKind))
+	ctx, span := o.spanTracer(tracer).Start(ctx, spanName, otelTrace.WithSpanKind(spanKind))
 
+	o.spanMu.Lock()
 	o.span = span
 	o.spans = append(o.spans, span)
+	o.spanMu.Unlock()
 
 	return context.WithValue(ctx, obsKeyInstance, o), o, nil
 }
 
-// Expand retrieves the Observer from the context, starts a new tracing span with the given name, and adds new arguments
-// to its logger. If no Observer exists in the context, it initializes a new one with default settings and adds the
-// arguments.
+// StartSpan starts a new tracing span with the given name, using $tracer or, if it is nil, the Observer's tracer, and
+// returns a child Observer (see With) that owns it, bound to the returned context, together with a function that ends
+// the span.
+// Because the span belongs to the child rather than to a stack on the shared Observer, spans can be started and ended
+// in any order and from any goroutine. The end function is safe to call more than once.
+// $newArgs are added to the child Observer's stable arguments.
+func StartSpan(
+	ctx context.Context,
+	tracer otelTrace.Tracer,
+	spanName string,
+	spanKind otelTrace.SpanKind,
+	newArgs ...any,
+) (
+	ctxWithSpan context.Context,
+	observer *Observer,
+	end func(),
+	fault error,
+) {
+	ctx, o, err := Get(ctx)
+	if err != nil {
+		return ctx, nil, func() {}, err
+	}
+
+	ctx, span := o.spanTracer(tracer).Start(ctx, spanName, otelTrace.WithSpanKind(spanKind))
+
+	child := o.With(newArgs...)
+	child.span = span
+	child.spans = nil
+
+	end = sync.OnceFunc(func() {
+		child.spanMu.Lock()
+		defer child.spanMu.Unlock()
+
+		child.endSpan(span)
+	})
+
+	return context.WithValue(ctx, obsKeyInstance, child), child, end, nil
+}
+
+// Expand retrieves the Observer from the context, starts a new tracing span with the given name (using $tracer or, if
+// it is nil, the Observer's tracer), and adds new arguments to its logger. If no Observer exists in the context, it
+// initializes a new one with default settings and adds the arguments.
+// Like Span, the span is pushed onto the stack of the shared Observer and ended by o.End(), and the args are added to
+// the shared Observer. StartSpan, which accepts the same arguments, is the supported API for concurrent code.
 func Expand(
 	ctx context.Context,
 	tracer otelTrace.Tracer,
@@ -189,7 +412,7 @@ func Expand(
 	if len(newArgs) != 0 {
 		o.outLogger = o.outLogger.With(newArgs...)
 		o.errLogger = o.errLogger.With(newArgs...)
-		o.stableArgs = o.AddArgs(newArgs...)
+		o.setStableArgs(o.AddArgs(newArgs...))
 	}
 
 	return context.WithValue(ctx, obsKeyInstance, o), o, nil
@@ -197,27 +420,69 @@ func Expand(
 
 // Close ends all active spans and shuts down the trace provider to ensure all traces are flushed.
 func (o *Observer) Close() {
-	if o.span != nil {
-		o.span.End()
+	o.spanMu.Lock()
+	if o.span != nil && !slices.Contains(o.spans, o.span) {
+		o.endSpan(o.span)
+	}
 
-		for _, s := range o.spans {
-			s.End()
-		}
+	for i := len(o.spans) - 1; i >= 0; i-- {
+		o.endSpan(o.spans[i])
 	}
+
+	o.span = nil
+	o.spans = nil
+	o.spanMu.Unlock()
+
 	if o.traceProvider != nil {
 		if err := o.traceProvider.Shutdown(context.Background()); err != nil {
 			o.Error("could not shut down tracer", err, SeverityMedium)
 		}
 	}
+
+	o.flushSampler()
+	o.flushCallsiteBudget()
+	o.flushOutputs()
+
+	for _, c := range o.closers {
+		_ = c.Close()
+	}
+}
+
+// flushOutputs flushes any log outputs that buffer records, such as an AsyncWriter.
+func (o *Observer) flushOutputs() {
+	writers := []io.Writer{o.output, o.errOutput}
+	for _, s := range o.sinks {
+		writers = append(writers, s.Writer)
+	}
+
+	for _, w := range writers {
+		if f, ok := w.(interface{ Flush() error }); ok {
+			_ = f.Flush()
+		}
+	}
 }
 
 // defaultReplacer creates a function to replace or modify log attributes
-func defaultReplacer(trimModules, trimPaths []string) func(groups []string, a slog.Attr) slog.Attr {
+// If redactAttrs is true, attributes whose keys match the redaction policy have their values redacted.
+func defaultReplacer(
+	trimModules, trimPaths []string,
+	redactAttrs bool,
+	sourceMode SourceMode,
+) func(groups []string, a slog.Attr) slog.Attr {
+	// read once rather than for every attr of every record
+	testEnv := os.Getenv("ENV") == "test"
+
 	return func(groups []string, a slog.Attr) slog.Attr {
-		if os.Getenv("ENV") == "test" && a.Key == slog.TimeKey {
+		if testEnv && a.Key == slog.TimeKey {
 			return slog.Attr{} // remove time key in test to make it easier to compare
 		}
 
+		if redactAttrs {
+			if redacted, ok := redactAttr(a); ok {
+				return redacted
+			}
+		}
+
 		switch a.Key {
 		case slog.SourceKey:
 			source, ok := a.Value.Any().(*slog.Source)
@@ -237,7 +502,12 @@ func defaultReplacer(trimModules, trimPaths []string) func(groups []string, a sl
 				}
 			}
 
-			return slog.Any(a.Key, source)
+			if sourceMode == SourceFunction {
+				return functionSource(a)
+			}
+
+			// the source is modified in place, so the attr doesn't need to be rebuilt
+			return a
 		case slog.LevelKey:
 			var level slog.Level
 
@@ -258,6 +528,8 @@ func defaultReplacer(trimModules, trimPaths []string) func(groups []string, a sl
 				a.Value = slog.StringValue("WARN")
 			case LevelError:
 				a.Value = slog.StringValue("ERR")
+			case LevelPanic:
+				a.Value = slog.StringValue("PANIC")
 			case LevelFatal:
 				a.Value = slog.StringValue("FATAL")
 			default:
@@ -270,86 +542,192 @@ func defaultReplacer(trimModules, trimPaths []string) func(groups []string, a sl
 }
 
 func (o *Observer) log(ctx context.Context, skipCallers int, level slog.Level, msg string, args ...any) (levelEnabled bool) {
-	if o.outLogger == nil || !o.outLogger.Enabled(ctx, level) {
-		return false
+	return o.handle(ctx, o.logger(level), o.callerPC(skipCallers), level, msg, args...)
+}
+
+// handle writes a record with the source $pc to $logger if it is enabled for $level and not sampled out
+func (o *Observer) handle(
+	ctx context.Context,
+	logger *slog.Logger,
+	pc uintptr,
+	level slog.Level,
+	msg string,
+	args ...any,
+) (
+	levelEnabled bool,
+) {
+	if ctx == nil {
+		ctx = context.Background()
 	}
-	var pc uintptr
-	var pcs [1]uintptr
-	// skip [runtime.Callers, this function, this function's caller]
-	runtime.Callers(skipCallers, pcs[:])
-	pc = pcs[0]
 
-	r := slog.NewRecord(time.Now(), level, msg, pc)
+	o.recordCrashDump(ctx, pc, level, msg, args)
 
-	if len(args) != 0 {
-		r.Add(DeduplicateArgs(args)...)
+	if o.recordFlight(pc, level, msg, args) {
+		return false
 	}
 
-	if ctx == nil {
-		ctx = context.Background()
+	if logger == nil || !logger.Enabled(ctx, level) {
+		return false
 	}
-	_ = o.outLogger.Handler().Handle(ctx, r)
 
-	return true
-}
+	o.countCallsite(ctx, pc)
 
-func (o *Observer) error(ctx context.Context, skipCallers int, level slog.Level, msg string, args ...any) (levelEnabled bool) {
-	if o.errLogger == nil || !o.errLogger.Enabled(ctx, level) {
+	if !o.sample(ctx, logger, level, msg) {
 		return false
 	}
-	var pc uintptr
-	var pcs [1]uintptr
-	// skip [runtime.Callers, this function, this function's caller]
-	runtime.Callers(skipCallers, pcs[:])
-	pc = pcs[0]
 
-	r := slog.NewRecord(time.Now(), level, msg, pc)
+	r := slog.NewRecord(o.clock.Now(), level, msg, pc)
 
 	if len(args) != 0 {
-		r.Add(DeduplicateArgs(args)...)
-	}
+		attrs := attrPool.Get().(*[]slog.Attr)
+		*attrs = appendDedupedAttrs((*attrs)[:0], args)
+		r.AddAttrs(*attrs...)
 
-	if ctx == nil {
-		ctx = context.Background()
+		// the record has copied the attrs, so the buffer can be reused
+		clear(*attrs)
+		attrPool.Put(attrs)
 	}
-	_ = o.errLogger.Handler().Handle(ctx, r)
+
+	err := logger.Handler().Handle(ctx, r)
+	recordHandled(LevelToString(level), o.component, err)
 
 	return true
 }
 
-// AddArgs processes the provided arguments, ensuring that they are stable and formatted correctly.
+func (o *Observer) error(ctx context.Context, skipCallers int, level slog.Level, msg string, args ...any) (levelEnabled bool) {
+	return o.handle(ctx, o.logger(level), o.callerPC(skipCallers), level, msg, args...)
+}
+
+// AddArgs returns the Observer's stable args with $args added, as key-value pairs in the order their keys were first
+// seen, so the stable args of an Observer are always logged in the same order.
+// When a key is added again its latest value wins, except that map values (e.g. Fields) and groups are merged key by
+// key, the latest value of each key winning. A dotted key such as "http.method" is merged into the "http" arg when that
+// is a map, and is otherwise kept as a key of its own.
 func (o *Observer) AddArgs(args ...any) (filteredArgs []any) {
-	args = append(o.stableArgs, args...)
+	pairs := argPairs(slices.Concat(o.stableArgs, args))
+
+	keys := make([]string, 0, len(pairs)/2)
+	values := make(map[string]any, len(pairs)/2)
+
+	for i := 0; i < len(pairs); i += 2 {
+		key, value := pairs[i].(string), pairs[i+1]
+
+		if namespace, subKey, found := strings.Cut(key, "."); found {
+			if _, ok := argMap(values[namespace]); ok {
+				values[namespace] = mergeArgValues(values[namespace], map[string]any{subKey: value})
+				continue
+			}
+		}
+
+		existing, seen := values[key]
+		if !seen {
+			keys = append(keys, key)
+			values[key] = value
+
+			continue
+		}
 
-	exArgs := map[any]any{}
+		values[key] = mergeArgValues(existing, value)
+	}
 
-	for len(args) > 0 {
-		exArgs, args = processArgs(exArgs, args)
+	filteredArgs = make([]any, 0, 2*len(keys))
+	for _, k := range keys {
+		filteredArgs = append(filteredArgs, k, values[k])
 	}
 
-	resArgs := make([]any, 0, len(exArgs)/2)
-	for k, v := range exArgs {
-		resArgs = append(resArgs, k, v)
+	return filteredArgs
+}
+
+// setStableArgs sets the Observer's stable args to $args, converting them to span attributes once rather than on every
+// record logged in a span.
+func (o *Observer) setStableArgs(args []any) {
+	o.stableArgs = args
+	o.stableAttrs = o.spanLimits.apply(argsToAttributes(args...))
+
+	o.component = ""
+	if i := slices.Index(args, any(FieldComponent)); i != -1 && i%2 == 0 && i+1 < len(args) {
+		o.component = fmt.Sprint(args[i+1])
 	}
+}
 
-	return resArgs
+// spanAttributes returns the span attributes of a record logged with $args, after the Observer's stable attributes,
+// within the Observer's SpanAttributeLimits
+func (o *Observer) spanAttributes(args []any) (attrs []otelAttribute.KeyValue) {
+	if len(args) == 0 {
+		return o.stableAttrs
+	}
+
+	return o.spanLimits.apply(slices.Concat(o.stableAttrs, argsToAttributes(args...)))
 }
 
-func processArgs(exArgs map[any]any, args []any) (map[any]any, []any) {
-	if len(args) < 2 {
-		return exArgs, []any{}
+// mergeArgValues returns $latest, or $existing and $latest merged key by key if they are both maps or groups
+func mergeArgValues(existing, latest any) (merged any) {
+	if a, ok := existing.([]slog.Attr); ok {
+		if b, ok := latest.([]slog.Attr); ok {
+			attrs := slices.Clone(a)
+			for _, attr := range b {
+				i := slices.IndexFunc(attrs, func(x slog.Attr) bool { return x.Key == attr.Key })
+				if i == -1 {
+					attrs = append(attrs, attr)
+				} else {
+					attrs[i] = attr
+				}
+			}
+
+			return attrs
+		}
+	}
+
+	a, ok := argMap(existing)
+	if !ok {
+		return latest
+	}
+
+	b, ok := argMap(latest)
+	if !ok {
+		return latest
 	}
 
-	exArgs[args[0]] = args[1]
+	m := maps.Clone(a)
+	maps.Copy(m, b)
 
-	return exArgs, args[2:]
+	if _, ok := existing.(Fields); ok {
+		return Fields(m)
+	}
+
+	return m
+}
+
+// argMap returns $value as a map if it is one
+func argMap(value any) (m map[string]any, ok bool) {
+	switch v := value.(type) {
+	case Fields:
+		return v, true
+	case map[string]any:
+		return v, true
+	default:
+		return nil, false
+	}
 }
 
 // End ends the current tracing span and reverts to the previous span in the stack.
+// Calling End when there is no current span does nothing. The spans started with StartSpan are not on the stack; they
+// are ended with the function StartSpan returns.
 func (o *Observer) End() {
-	o.span.End()
+	o.spanMu.Lock()
+	defer o.spanMu.Unlock()
+
+	if o.span == nil {
+		return
+	}
+
+	o.endSpan(o.span)
+
+	spanID := o.span.SpanContext().SpanID()
+	o.spans = slices.DeleteFunc(o.spans, func(s otelTrace.Span) bool {
+		return s.SpanContext().SpanID() == spanID
+	})
 
-	o.spans = o.spans[:len(o.spans)-1]
 	if len(o.spans) > 0 {
 		o.span = o.spans[len(o.spans)-1]
 	} else {
@@ -357,6 +735,47 @@ func (o *Observer) End() {
 	}
 }
 
+// currentSpan returns the current span, or nil if there isn't one, read under o.spanMu as Span, Expand and End move
+// it while other goroutines log with the Observer.
+func (o *Observer) currentSpan() (span otelTrace.Span) {
+	o.spanMu.Lock()
+	defer o.spanMu.Unlock()
+
+	return o.span
+}
+
+// failSpan sets the status of the current span to codes.Error so trace backends can filter failed spans.
+func (o *Observer) failSpan(msg string) {
+	o.spanMu.Lock()
+	defer o.spanMu.Unlock()
+
+	if o.span == nil {
+		return
+	}
+
+	o.span.SetStatus(codes.Error, msg)
+
+	if o.failedSpans == nil {
+		o.failedSpans = map[otelTrace.SpanID]bool{}
+	}
+
+	o.failedSpans[o.span.SpanContext().SpanID()] = true
+}
+
+// endSpan ends the span, first marking it as OK if WithSpanStatusOK is enabled and no error was recorded on it.
+// The caller must hold o.spanMu.
+func (o *Observer) endSpan(span otelTrace.Span) {
+	spanID := span.SpanContext().SpanID()
+
+	if o.markSpansOK && !o.failedSpans[spanID] {
+		span.SetStatus(codes.Ok, "")
+	}
+
+	delete(o.failedSpans, spanID)
+
+	span.End()
+}
+
 // InContext can be used to check if go11y has been added to a context before calling go11y.Get()
 // This is useful for other packages imported by services that use go11y as well as other services that still use the
 // go-logging package.
@@ -366,8 +785,11 @@ func InContext(ctx context.Context) (response bool) {
 
 // IncreaseDistance increases the caller skip distance for logging purposes.
 // This is useful when wrapping go11y (such as the go-common splitLog)
+//
+// Deprecated: IncreaseDistance changes the Observer shared through the context, moving the source of every other
+// user's records too. Use WithCallerSkip, which returns a derived Observer.
 func (o *Observer) IncreaseDistance(distance int) {
-	o.skipCallers += distance
+	o.callerSkip += distance
 }
 
 // AddToContext adds the Observer to the provided context.
@@ -0,0 +1,55 @@
+package go11ytest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/cirruscomms/go11y"
+)
+
+// Clock is a go11y.Clock for tests that only moves when told to, so timestamps and durations are deterministic.
+// Pass it to go11y.Initialise (or New) with go11y.WithClock.
+type Clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewClock returns a Clock frozen at $now.
+func NewClock(now time.Time) *Clock {
+	return &Clock{now: now}
+}
+
+// Now returns the Clock's current time
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// Since returns the time elapsed on the Clock since $t
+func (c *Clock) Since(t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
+
+// Advance moves the Clock forward by $d.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+// Set moves the Clock to $now.
+func (c *Clock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = now
+}
+
+// WithFixedTimestamp freezes the Observer's clock at $t, so every record has the same time and log output is
+// deterministic in golden-file tests.
+func WithFixedTimestamp(t time.Time) go11y.Option {
+	return go11y.WithClock(NewClock(t))
+}
@@ -0,0 +1,27 @@
+package go11ytest_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cirruscomms/go11y"
+	"github.com/cirruscomms/go11y/go11ytest"
+)
+
+func TestClock(t *testing.T) {
+	clock := go11ytest.NewClock(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+
+	ctx, obs := go11ytest.New(t, go11y.LevelInfo, go11y.WithClock(clock))
+
+	err := go11y.Job(ctx, "clock_test", func(ctx context.Context, o *go11y.Observer) error {
+		clock.Advance(1500 * time.Millisecond)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("job failed: %v", err)
+	}
+
+	obs.AssertAttr(t, "job finished", go11y.FieldJobDuration, 1500)
+	obs.AssertAttr(t, "job finished", "time", "2026-01-02T03:04:06.5Z")
+}
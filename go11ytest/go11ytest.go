@@ -0,0 +1,208 @@
+// Package go11ytest provides an Observer backed by in-memory sinks for unit tests, so emitted log records and spans can
+// be asserted on directly instead of parsing JSON from a bytes.Buffer.
+package go11ytest
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	otelSDKTrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	otelTrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/cirruscomms/go11y"
+)
+
+// Record is a log record captured by the test Observer
+type Record struct {
+	Level string         // the level name as written by go11y, e.g. INFO or ERR
+	Msg   string         // the log message
+	Attrs map[string]any // every other field of the record, as decoded from JSON
+}
+
+// Observer is a go11y Observer whose log output and spans are captured in memory
+type Observer struct {
+	*go11y.Observer
+	sink     *memorySink
+	recorder *tracetest.SpanRecorder
+	provider *otelSDKTrace.TracerProvider
+}
+
+// New initialises an Observer at $level that captures its log records and spans in memory, and returns it with a
+// context holding it. $initialArgs are passed on to go11y.Initialise, so options such as go11y.WithSinks can be used.
+// The global OpenTelemetry tracer provider is replaced with the in-memory one for the duration of the test (and
+// restored when it ends), so code using otel.Tracer is captured as well - tests using New must not run in parallel.
+func New(t testing.TB, level slog.Level, initialArgs ...any) (ctxWithObserver context.Context, observer *Observer) {
+	t.Helper()
+
+	sink := &memorySink{}
+	recorder := tracetest.NewSpanRecorder()
+	provider := otelSDKTrace.NewTracerProvider(otelSDKTrace.WithSpanProcessor(recorder))
+
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+
+	cfg := go11y.CreateConfig(level, "", "", "", []string{}, []string{})
+
+	ctx, o, err := go11y.Initialise(context.Background(), cfg, sink, sink, initialArgs...)
+	if err != nil {
+		t.Fatalf("failed to initialise go11y observer: %v", err)
+	}
+
+	t.Cleanup(func() {
+		o.Close()
+		otel.SetTracerProvider(previous)
+		_ = provider.Shutdown(context.Background())
+	})
+
+	return ctx, &Observer{Observer: o, sink: sink, recorder: recorder, provider: provider}
+}
+
+// Tracer returns a tracer whose spans are captured by the Observer, for use with go11y.Span, go11y.StartSpan etc.
+func (o *Observer) Tracer(name string, opts ...otelTrace.TracerOption) otelTrace.Tracer {
+	return o.provider.Tracer(name, opts...)
+}
+
+// Records returns the log records written so far, in order.
+func (o *Observer) Records() []Record {
+	return o.sink.records()
+}
+
+// RecordsWithMsg returns the log records written so far with the message $msg.
+func (o *Observer) RecordsWithMsg(msg string) []Record {
+	matches := []Record{}
+
+	for _, r := range o.Records() {
+		if r.Msg == msg {
+			matches = append(matches, r)
+		}
+	}
+
+	return matches
+}
+
+// HasRecord reports whether a record with the message $msg has been written at any level.
+func (o *Observer) HasRecord(msg string) bool {
+	return len(o.RecordsWithMsg(msg)) != 0
+}
+
+// HasError reports whether a record with the message $msg has been written at error level or above.
+func (o *Observer) HasError(msg string) bool {
+	for _, r := range o.RecordsWithMsg(msg) {
+		if slices.Contains([]string{"ERR", "PANIC", "FATAL"}, r.Level) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Spans returns the spans that have ended so far, in the order they ended.
+func (o *Observer) Spans() []otelSDKTrace.ReadOnlySpan {
+	return o.recorder.Ended()
+}
+
+// SpanNames returns the names of the spans that have ended so far, in the order they ended.
+func (o *Observer) SpanNames() []string {
+	names := []string{}
+
+	for _, s := range o.Spans() {
+		names = append(names, s.Name())
+	}
+
+	return names
+}
+
+// AssertAttr fails the test unless a record with the message $msg has the attribute $key with a value equal to $want.
+// Values are compared by their formatted representation, so numbers match regardless of how JSON decoded them.
+func (o *Observer) AssertAttr(t testing.TB, msg, key string, want any) {
+	t.Helper()
+
+	records := o.RecordsWithMsg(msg)
+	if len(records) == 0 {
+		t.Errorf("expected a log record with message %q", msg)
+		return
+	}
+
+	for _, r := range records {
+		if got, ok := r.Attrs[key]; ok && fmt.Sprint(got) == fmt.Sprint(want) {
+			return
+		}
+	}
+
+	t.Errorf("expected a log record with message %q to have %s=%v, got %v", msg, key, want, records)
+}
+
+// AssertSpanAttr fails the test unless an ended span named $name has the attribute $key with a value equal to $want.
+func (o *Observer) AssertSpanAttr(t testing.TB, name, key string, want any) {
+	t.Helper()
+
+	found := false
+
+	for _, s := range o.Spans() {
+		if s.Name() != name {
+			continue
+		}
+
+		found = true
+
+		for _, kv := range s.Attributes() {
+			if string(kv.Key) == key && fmt.Sprint(kv.Value.AsInterface()) == fmt.Sprint(want) {
+				return
+			}
+		}
+	}
+
+	if !found {
+		t.Errorf("expected an ended span named %q, got %v", name, o.SpanNames())
+		return
+	}
+
+	t.Errorf("expected the span named %q to have %s=%v", name, key, want)
+}
+
+// memorySink decodes the JSON records written by go11y's handlers
+type memorySink struct {
+	mu      sync.Mutex
+	decoded []Record
+}
+
+func (s *memorySink) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	scanner := bufio.NewScanner(bytes.NewReader(p))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(p)+1)
+
+	for scanner.Scan() {
+		fields := map[string]any{}
+		if err := json.Unmarshal(scanner.Bytes(), &fields); err != nil {
+			return 0, fmt.Errorf("could not decode log record: %w", err)
+		}
+
+		r := Record{Attrs: fields}
+		r.Level, _ = fields["level"].(string)
+		r.Msg, _ = fields["msg"].(string)
+		delete(fields, "level")
+		delete(fields, "msg")
+
+		s.decoded = append(s.decoded, r)
+	}
+
+	return len(p), nil
+}
+
+func (s *memorySink) records() []Record {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.decoded)
+}
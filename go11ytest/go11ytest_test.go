@@ -0,0 +1,55 @@
+package go11ytest_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/cirruscomms/go11y"
+	"github.com/cirruscomms/go11y/go11ytest"
+)
+
+func TestObserver(t *testing.T) {
+	ctx, obs := go11ytest.New(t, go11y.LevelInfo, "service", "widgets")
+
+	obs.Info("widget created", "widget_id", 42)
+	obs.Debug("not captured at info level")
+
+	_, o, err := go11y.Span(ctx, obs.Tracer("test"), "create widget", go11y.SpanKindInternal)
+	if err != nil {
+		t.Fatalf("failed to start span: %v", err)
+	}
+	o.Error("widget not saved", errors.New("disk full"), go11y.SeverityHigh, "widget_id", 42)
+	o.End()
+
+	if len(obs.Records()) != 2 {
+		t.Errorf("expected 2 records, got %v", obs.Records())
+	}
+
+	if !obs.HasRecord("widget created") || obs.HasRecord("not captured at info level") {
+		t.Errorf("unexpected records: %v", obs.Records())
+	}
+
+	if !obs.HasError("widget not saved") || obs.HasError("widget created") {
+		t.Errorf("expected only the failure to be an error: %v", obs.Records())
+	}
+
+	obs.AssertAttr(t, "widget created", "widget_id", 42)
+	obs.AssertAttr(t, "widget created", "service", "widgets")
+	obs.AssertAttr(t, "widget not saved", "error", "disk full")
+
+	if !slices.Equal(obs.SpanNames(), []string{"create widget"}) {
+		t.Errorf("expected the span to be captured, got %v", obs.SpanNames())
+	}
+
+	obs.AssertSpanAttr(t, "create widget", "widget_id", 42)
+
+	if err := go11y.Job(ctx, "nightly", func(ctx context.Context, o *go11y.Observer) error { return nil }); err != nil {
+		t.Fatalf("job failed: %v", err)
+	}
+
+	if !slices.Contains(obs.SpanNames(), "job nightly") {
+		t.Errorf("expected spans from the global tracer provider to be captured, got %v", obs.SpanNames())
+	}
+}
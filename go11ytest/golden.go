@@ -0,0 +1,141 @@
+package go11ytest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// UpdateGoldenEnv is the environment variable that makes AssertGolden (re)write golden files instead of comparing
+// against them, e.g. GO11Y_UPDATE_GOLDEN=1 go test ./...
+const UpdateGoldenEnv = "GO11Y_UPDATE_GOLDEN"
+
+// volatileKeys are removed from every record by Normalize as they change from run to run, or with the toolchain
+var volatileKeys = []string{"time", "pid", "go_version"}
+
+// portRex matches the port of host:port pairs, which are usually random in tests (e.g. httptest servers)
+var portRex = regexp.MustCompile(`((?:localhost|\d{1,3}(?:\.\d{1,3}){3}|\[[0-9a-fA-F:]+\]):)\d{1,5}\b`)
+
+// Normalize rewrites go11y JSON log output so it can be compared across runs: the time, pid and go_version fields are
+// removed, ports of local addresses are replaced with PORT, source locations are reduced to the function name (so
+// editing a file doesn't change every golden file) and the fields of each record are sorted by key.
+// Lines that aren't JSON objects are kept as they are.
+func Normalize(output []byte) []byte {
+	normalized := bytes.Buffer{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(output)+1)
+
+	for scanner.Scan() {
+		line := scanner.Bytes()
+
+		fields := map[string]any{}
+		if err := json.Unmarshal(line, &fields); err != nil {
+			normalized.Write(line)
+			normalized.WriteByte('\n')
+			continue
+		}
+
+		for _, key := range volatileKeys {
+			delete(fields, key)
+		}
+
+		if source, ok := fields["source"].(map[string]any); ok {
+			fields["source"] = source["function"]
+		}
+
+		// maps are marshalled with sorted keys
+		b, _ := json.Marshal(normalizeValue(fields))
+		normalized.Write(b)
+		normalized.WriteByte('\n')
+	}
+
+	return normalized.Bytes()
+}
+
+func normalizeValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, val := range v {
+			v[key] = normalizeValue(val)
+		}
+		return v
+	case []any:
+		for i, val := range v {
+			v[i] = normalizeValue(val)
+		}
+		return v
+	case string:
+		return portRex.ReplaceAllString(v, "${1}PORT")
+	default:
+		return v
+	}
+}
+
+// AssertGolden normalizes $output (see Normalize) and compares it with the golden file at $goldenPath, failing the
+// test with a line by line diff if they differ. When the GO11Y_UPDATE_GOLDEN environment variable is set, the golden
+// file is written instead.
+func AssertGolden(t testing.TB, goldenPath string, output []byte) {
+	t.Helper()
+
+	got := Normalize(output)
+
+	if os.Getenv(UpdateGoldenEnv) != "" {
+		if err := os.MkdirAll(filepath.Dir(goldenPath), 0o755); err != nil {
+			t.Fatalf("could not create golden file directory: %v", err)
+		}
+
+		if err := os.WriteFile(goldenPath, got, 0o644); err != nil {
+			t.Fatalf("could not write golden file: %v", err)
+		}
+
+		return
+	}
+
+	want, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatalf("could not read golden file (set %s=1 to create it): %v", UpdateGoldenEnv, err)
+	}
+
+	if !bytes.Equal(got, want) {
+		t.Errorf("log output does not match %s (set %s=1 to update it):\n%s", goldenPath, UpdateGoldenEnv, diff(want, got))
+	}
+}
+
+// diff returns the lines that differ between $want and $got, prefixed with - and + respectively
+func diff(want, got []byte) string {
+	wantLines := strings.Split(strings.TrimSuffix(string(want), "\n"), "\n")
+	gotLines := strings.Split(strings.TrimSuffix(string(got), "\n"), "\n")
+
+	out := strings.Builder{}
+
+	for i := range max(len(wantLines), len(gotLines)) {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+
+		if w == g {
+			continue
+		}
+
+		fmt.Fprintf(&out, "line %d:\n", i+1)
+		if i < len(wantLines) {
+			fmt.Fprintf(&out, "- %s\n", w)
+		}
+		if i < len(gotLines) {
+			fmt.Fprintf(&out, "+ %s\n", g)
+		}
+	}
+
+	return out.String()
+}
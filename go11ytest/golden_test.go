@@ -0,0 +1,43 @@
+package go11ytest_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cirruscomms/go11y"
+	"github.com/cirruscomms/go11y/go11ytest"
+)
+
+func TestNormalize(t *testing.T) {
+	input := `{"time":"2026-01-02T03:04:05Z","pid":1234,"msg":"listening","addr":"127.0.0.1:54321","source":{"function":"main.run","file":"/src/main.go","line":42},"level":"INFO"}
+not json
+`
+	expected := `{"addr":"127.0.0.1:PORT","level":"INFO","msg":"listening","source":"main.run"}
+not json
+`
+
+	if got := string(go11ytest.Normalize([]byte(input))); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestFixedTimestamp(t *testing.T) {
+	out := new(bytes.Buffer)
+	fixed := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	cfg := go11y.CreateConfig(go11y.LevelInfo, "", "", "", []string{}, []string{})
+	_, o, err := go11y.Initialise(context.Background(), cfg, out, out, go11ytest.WithFixedTimestamp(fixed))
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	o.Info("first")
+	o.Info("second")
+
+	if strings.Count(out.String(), `"time":"2026-01-02T03:04:05Z"`) != 2 {
+		t.Errorf("expected both records to have the fixed time, got %s", out.String())
+	}
+}
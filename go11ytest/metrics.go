@@ -0,0 +1,188 @@
+package go11ytest
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+)
+
+// Labels selects series of a metric by label value. Labels not mentioned are ignored, so a subset of the labels
+// selects every matching series and their values are summed.
+type Labels map[string]string
+
+// Metrics is a snapshot of Prometheus metric families, gathered from a registry or scraped from a metrics endpoint
+type Metrics struct {
+	families map[string]*dto.MetricFamily
+}
+
+// GatherMetrics snapshots the metrics of $gatherer - pass prometheus.DefaultGatherer for the metrics registered by
+// go11y's middleware.
+func GatherMetrics(t testing.TB, gatherer prometheus.Gatherer) Metrics {
+	t.Helper()
+
+	mfs, err := gatherer.Gather()
+	if err != nil {
+		t.Fatalf("could not gather metrics: %v", err)
+	}
+
+	families := map[string]*dto.MetricFamily{}
+	for _, mf := range mfs {
+		families[mf.GetName()] = mf
+	}
+
+	return Metrics{families: families}
+}
+
+// ScrapeMetrics snapshots the metrics published at $url, e.g. the /internal/metrics endpoint of a running service.
+func ScrapeMetrics(t testing.TB, url string) Metrics {
+	t.Helper()
+
+	resp, err := http.Get(url) //nolint:gosec // the URL is provided by the test
+	if err != nil {
+		t.Fatalf("could not scrape metrics from %s: %v", url, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("scraping metrics from %s returned %d", url, resp.StatusCode)
+	}
+
+	parser := expfmt.NewTextParser(model.UTF8Validation)
+
+	families, err := parser.TextToMetricFamilies(resp.Body)
+	if err != nil {
+		t.Fatalf("could not parse metrics from %s: %v", url, err)
+	}
+
+	return Metrics{families: families}
+}
+
+// Value returns the summed value of the counter, gauge or untyped metric $name across the series matching $labels,
+// and whether any series matched. For histograms and summaries the observation count is returned.
+func (m Metrics) Value(name string, labels Labels) (value float64, found bool) {
+	for _, metric := range m.matching(name, labels) {
+		found = true
+
+		switch {
+		case metric.GetCounter() != nil:
+			value += metric.GetCounter().GetValue()
+		case metric.GetGauge() != nil:
+			value += metric.GetGauge().GetValue()
+		case metric.GetHistogram() != nil:
+			value += float64(metric.GetHistogram().GetSampleCount())
+		case metric.GetSummary() != nil:
+			value += float64(metric.GetSummary().GetSampleCount())
+		case metric.GetUntyped() != nil:
+			value += metric.GetUntyped().GetValue()
+		}
+	}
+
+	return value, found
+}
+
+// HistogramSum returns the summed observations of the histogram $name across the series matching $labels, and
+// whether any series matched.
+func (m Metrics) HistogramSum(name string, labels Labels) (sum float64, found bool) {
+	for _, metric := range m.matching(name, labels) {
+		if h := metric.GetHistogram(); h != nil {
+			found = true
+			sum += h.GetSampleSum()
+		}
+	}
+
+	return sum, found
+}
+
+// AssertValue fails the test unless the value of $name (see Value) across the series matching $labels is $want.
+func (m Metrics) AssertValue(t testing.TB, name string, labels Labels, want float64) {
+	t.Helper()
+
+	got, found := m.Value(name, labels)
+	if !found {
+		t.Errorf("expected a series of %s matching %v, got %s", name, labels, m.describe(name))
+		return
+	}
+
+	if got != want {
+		t.Errorf("expected %s%v to be %v, got %v", name, labels, want, got)
+	}
+}
+
+// AssertHistogramCount fails the test unless the histogram $name has $want observations across the series matching
+// $labels.
+func (m Metrics) AssertHistogramCount(t testing.TB, name string, labels Labels, want uint64) {
+	t.Helper()
+
+	m.AssertValue(t, name, labels, float64(want))
+}
+
+// AssertAbsent fails the test if any series of $name matches $labels.
+func (m Metrics) AssertAbsent(t testing.TB, name string, labels Labels) {
+	t.Helper()
+
+	if len(m.matching(name, labels)) != 0 {
+		t.Errorf("expected no series of %s matching %v", name, labels)
+	}
+}
+
+func (m Metrics) matching(name string, labels Labels) []*dto.Metric {
+	mf, ok := m.families[name]
+	if !ok {
+		return nil
+	}
+
+	matches := []*dto.Metric{}
+
+	for _, metric := range mf.GetMetric() {
+		if labelsMatch(metric, labels) {
+			matches = append(matches, metric)
+		}
+	}
+
+	return matches
+}
+
+func labelsMatch(metric *dto.Metric, labels Labels) bool {
+	for name, want := range labels {
+		matched := false
+
+		for _, lp := range metric.GetLabel() {
+			if lp.GetName() == name && lp.GetValue() == want {
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			return false
+		}
+	}
+
+	return true
+}
+
+// describe lists the label sets of $name's series for failure messages
+func (m Metrics) describe(name string) string {
+	mf, ok := m.families[name]
+	if !ok {
+		return "no such metric"
+	}
+
+	sets := []map[string]string{}
+	for _, metric := range mf.GetMetric() {
+		set := map[string]string{}
+		for _, lp := range metric.GetLabel() {
+			set[lp.GetName()] = lp.GetValue()
+		}
+		sets = append(sets, set)
+	}
+
+	return fmt.Sprintf("%v", sets)
+}
@@ -0,0 +1,50 @@
+package go11ytest_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/cirruscomms/go11y"
+	"github.com/cirruscomms/go11y/go11ytest"
+)
+
+func TestMetrics(t *testing.T) {
+	ctx, _ := go11ytest.New(t, go11y.LevelInfo)
+
+	router := mux.NewRouter()
+	mw, err := go11y.GetMetricsMiddlewareMux(ctx, go11y.MetricsMiddlewareMuxOpts{Service: "metrics_helpers", Router: router})
+	if err != nil {
+		t.Fatalf("failed to create metrics middleware: %v", err)
+	}
+
+	router.Handle("/widgets", mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})))
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	for range 3 {
+		resp, err := http.Post(server.URL+"/widgets", "application/json", nil)
+		if err != nil {
+			t.Fatalf("failed to execute request: %v", err)
+		}
+		_ = resp.Body.Close()
+	}
+
+	for name, metrics := range map[string]go11ytest.Metrics{
+		"gathered": go11ytest.GatherMetrics(t, prometheus.DefaultGatherer),
+		"scraped":  go11ytest.ScrapeMetrics(t, server.URL+"/internal/metrics"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			metrics.AssertValue(t, "metrics_helpers_requests_total", go11ytest.Labels{"endpoint": "/widgets", "status": "201"}, 3)
+			metrics.AssertHistogramCount(t, "metrics_helpers_requests_times", go11ytest.Labels{"method": http.MethodPost}, 3)
+			metrics.AssertValue(t, "metrics_helpers_requests_in_flight", go11ytest.Labels{"endpoint": "/widgets"}, 0)
+			metrics.AssertAbsent(t, "metrics_helpers_requests_total", go11ytest.Labels{"status": "500"})
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package go11y
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// FieldGoroutine is the structured log field name for "goroutine"
+const FieldGoroutine = "goroutine"
+
+// GoFunc is the work done by a goroutine started with Go or Group.Go. $ctx carries the goroutine's Observer.
+type GoFunc func(ctx context.Context) error
+
+// runGoroutine runs $fn in a child span named $name, with a child Observer with the goroutine field, returning its
+// error prefixed with $name, or an error if it panicked
+func runGoroutine(ctx context.Context, name string, fn GoFunc) (fault error) {
+	ctx, o, end, err := StartSpan(ctx, nil, name, SpanKindInternal, FieldGoroutine, name)
+	if err != nil {
+		// there is no Observer to start the span with, so the goroutine runs uninstrumented but still recovers
+		o, end = Default(), func() {}
+	}
+	defer end()
+
+	defer func() {
+		if r := recover(); r != nil {
+			fault = fmt.Errorf("goroutine %s panicked: %v", name, r)
+			o.Error("goroutine panicked", fault, SeverityHighest, "stack", string(debug.Stack()))
+			o.DumpCrash(fault.Error())
+		}
+	}()
+
+	if err := fn(ctx); err != nil {
+		if errors.Is(err, context.Canceled) {
+			// usually a sibling failed and the group's context was cancelled, which is logged by the sibling
+			o.Debug("goroutine cancelled", "error", err.Error())
+		} else {
+			o.Error("goroutine failed", err, SeverityHigh)
+		}
+
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	return nil
+}
+
+// Go runs $fn in a new goroutine named $name, in a child span of the span in $ctx and with a child of the Observer in
+// $ctx with the goroutine field, so its records and spans can be told apart from those of its siblings. A failure is
+// logged, and a panic is recovered, logged with its stack and returned as an error. The returned function waits for
+// the goroutine to finish and returns its error, prefixed with $name.
+func Go(ctx context.Context, name string, fn GoFunc) (wait func() error) {
+	done := make(chan struct{})
+
+	var err error
+	go func() {
+		defer close(done)
+		err = runGoroutine(ctx, name, fn)
+	}()
+
+	return func() error {
+		<-done
+		return err
+	}
+}
+
+// Group is an errgroup.Group whose goroutines are started like those of Go: each in its own child span, with its own
+// child Observer, and with panics recovered into errors. The context of the group is cancelled when a goroutine fails,
+// and Wait returns the errors of every goroutine that failed rather than only the first.
+type Group struct {
+	ctx  context.Context
+	g    *errgroup.Group
+	mu   sync.Mutex
+	errs []error
+}
+
+// NewGroup returns a Group and the context its goroutines are run with, derived from $ctx and cancelled when one of
+// them fails or Wait returns, like errgroup.WithContext.
+func NewGroup(ctx context.Context) (group *Group, ctxWithCancel context.Context) {
+	g, ctx := errgroup.WithContext(ctx)
+
+	return &Group{ctx: ctx, g: g}, ctx
+}
+
+// SetLimit limits the number of goroutines of the group running at once to $n, see errgroup.Group.SetLimit.
+func (g *Group) SetLimit(n int) {
+	g.g.SetLimit(n)
+}
+
+// Go runs $fn in a new goroutine named $name, see Go. It blocks while the group's limit is reached.
+func (g *Group) Go(name string, fn GoFunc) {
+	g.g.Go(func() error {
+		err := runGoroutine(g.ctx, name, fn)
+		if err != nil {
+			g.mu.Lock()
+			g.errs = append(g.errs, err)
+			g.mu.Unlock()
+		}
+
+		return err
+	})
+}
+
+// Wait waits for the goroutines of the group to finish, returning the errors of those that failed joined together,
+// in the order they failed, or nil if none did.
+func (g *Group) Wait() (fault error) {
+	_ = g.g.Wait()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return errors.Join(g.errs...)
+}
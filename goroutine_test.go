@@ -0,0 +1,108 @@
+package go11y_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/cirruscomms/go11y"
+)
+
+func TestGo(t *testing.T) {
+	buf := new(bytes.Buffer)
+	ctx, _, spans, err := go11y.InitialiseTestTracerInMemory(context.Background(), go11y.LevelInfo, buf, buf)
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	ctx, _, end, err := go11y.StartSpan(ctx, nil, "fan-out", go11y.SpanKindInternal)
+	if err != nil {
+		t.Fatalf("failed to start span: %v", err)
+	}
+
+	wait := go11y.Go(ctx, "fetch-prices", func(ctx context.Context) error {
+		go11y.FromContext(ctx).Info("fetching")
+		return nil
+	})
+	if err := wait(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	wait = go11y.Go(ctx, "fetch-stock", func(ctx context.Context) error {
+		panic("nil map")
+	})
+	if err := wait(); err == nil || !strings.Contains(err.Error(), "goroutine fetch-stock panicked: nil map") {
+		t.Errorf("expected the panic to be returned as an error, got %v", err)
+	}
+	end()
+
+	if !spans.IsChildOf("fetch-prices", "fan-out") || !spans.IsChildOf("fetch-stock", "fan-out") {
+		t.Errorf("expected the goroutine spans to be children of the caller's span, got %v", spans.Names())
+	}
+
+	if !strings.Contains(buf.String(), `"msg":"fetching","goroutine":"fetch-prices"`) {
+		t.Errorf("expected the goroutine's records to have the goroutine field, got %s", buf.String())
+	}
+
+	if !strings.Contains(buf.String(), `"msg":"goroutine panicked"`) {
+		t.Errorf("expected the panic to be logged, got %s", buf.String())
+	}
+}
+
+func TestGroup(t *testing.T) {
+	buf := new(bytes.Buffer)
+	ctx, _, err := go11y.InitialiseTestLogger(context.Background(), go11y.LevelDebug, buf, buf)
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	g, _ := go11y.NewGroup(ctx)
+	g.SetLimit(2)
+
+	mu := sync.Mutex{}
+	ran := []string{}
+	for _, name := range []string{"a", "b", "c"} {
+		g.Go(name, func(ctx context.Context) error {
+			mu.Lock()
+			ran = append(ran, name)
+			mu.Unlock()
+
+			if name == "b" {
+				return errors.New("upstream unavailable")
+			}
+
+			return nil
+		})
+	}
+
+	err = g.Wait()
+	if len(ran) != 3 {
+		t.Errorf("expected every goroutine to run, got %v", ran)
+	}
+
+	if err == nil || err.Error() != "b: upstream unavailable" {
+		t.Errorf("expected the failure of b, got %v", err)
+	}
+}
+
+func TestGroupAggregatesFailures(t *testing.T) {
+	ctx, _, err := go11y.InitialiseTestLogger(context.Background(), go11y.LevelInfo, new(bytes.Buffer), new(bytes.Buffer))
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	g, _ := go11y.NewGroup(ctx)
+
+	first := errors.New("first")
+	second := errors.New("second")
+	g.Go("one", func(ctx context.Context) error { return first })
+	g.Go("two", func(ctx context.Context) error { return second })
+
+	err = g.Wait()
+	if !errors.Is(err, first) || !errors.Is(err, second) {
+		t.Errorf("expected both failures, got %v", err)
+	}
+}
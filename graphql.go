@@ -0,0 +1,231 @@
+package go11y
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"sync"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	otelAttribute "go.opentelemetry.io/otel/attribute"
+	otelTrace "go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// GraphQLQuery is the type of a GraphQL query operation, including the shorthand "{ ... }" form
+	GraphQLQuery = "query"
+	// GraphQLMutation is the type of a GraphQL mutation operation
+	GraphQLMutation = "mutation"
+	// GraphQLSubscription is the type of a GraphQL subscription operation
+	GraphQLSubscription = "subscription"
+
+	// GraphQLAnonymous is the operation name used for GraphQL operations without one
+	GraphQLAnonymous = "anonymous"
+)
+
+// FieldGraphQLOperation is the structured log field name for "graphql_operation"
+const FieldGraphQLOperation = "graphql_operation"
+
+// FieldGraphQLOperationType is the structured log field name for "graphql_operation_type"
+const FieldGraphQLOperationType = "graphql_operation_type"
+
+// FieldGraphQLVariables is the structured log field name for "graphql_variables"
+const FieldGraphQLVariables = "graphql_variables"
+
+// GraphQLOperations is the metric for the number of GraphQL operations handled (role "server") or sent (role "client"),
+// by operation type, operation name and status code
+var GraphQLOperations = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "go11y_graphql_operations_total",
+	Help: "Number of GraphQL operations handled or sent",
+}, []string{"role", "type", "operation", "status"})
+
+// GraphQLOperationTimes is the metric for the time GraphQL operations take, by role, operation type and operation name
+var GraphQLOperationTimes = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Name: "go11y_graphql_operation_duration_seconds",
+	Help: "Time GraphQL operations take",
+}, []string{"role", "type", "operation"})
+
+var registerGraphQLMetricsOnce sync.Once
+
+func registerGraphQLMetrics() {
+	registerGraphQLMetricsOnce.Do(func() {
+		registerCollectors(GraphQLOperations, GraphQLOperationTimes)
+	})
+}
+
+// GraphQLOperation is the operation of a GraphQL request, as parsed by ParseGraphQLRequest
+type GraphQLOperation struct {
+	Name      string          // the operationName of the request, or the name of its only operation, or GraphQLAnonymous
+	Type      string          // GraphQLQuery, GraphQLMutation or GraphQLSubscription
+	Variables json.RawMessage // the variables of the request, unredacted
+}
+
+// SpanName returns the span name of the operation, e.g. "query GetUser"
+func (op GraphQLOperation) SpanName() string {
+	return op.Type + " " + op.Name
+}
+
+// LogArgs returns the operation's name and type, and its variables redacted per the redaction policy, as log args
+func (op GraphQLOperation) LogArgs() []any {
+	args := []any{FieldGraphQLOperation, op.Name, FieldGraphQLOperationType, op.Type}
+	if len(op.Variables) != 0 && !bytes.Equal(op.Variables, []byte("null")) {
+		args = append(args, FieldGraphQLVariables, json.RawMessage(RedactBody(op.Variables)))
+	}
+
+	return args
+}
+
+// spanAttributes returns the operation's name and type as OpenTelemetry semantic convention attributes
+func (op GraphQLOperation) spanAttributes() []otelAttribute.KeyValue {
+	return []otelAttribute.KeyValue{
+		otelAttribute.String("graphql.operation.name", op.Name),
+		otelAttribute.String("graphql.operation.type", op.Type),
+	}
+}
+
+// graphQLOperationRex matches the start of an operation definition, capturing its type and name. Fragments and the
+// shorthand query form don't match.
+var graphQLOperationRex = regexp.MustCompile(`\b(query|mutation|subscription)\b\s*([_A-Za-z][_0-9A-Za-z]*)?`)
+
+// graphQLIgnoredRex matches the comments and string values of a GraphQL document, which could contain keywords
+var graphQLIgnoredRex = regexp.MustCompile(`#[^\n]*|"""(?s:.*?)"""|"(?:[^"\\]|\\.)*"`)
+
+// ParseGraphQLRequest parses the operation of a GraphQL request $body, the JSON {"query", "operationName", "variables"}
+// object of the GraphQL over HTTP spec. The operation named by operationName is used if the document has several.
+func ParseGraphQLRequest(body []byte) (op GraphQLOperation, fault error) {
+	req := struct {
+		Query         string          `json:"query"`
+		OperationName string          `json:"operationName"`
+		Variables     json.RawMessage `json:"variables"`
+	}{}
+
+	if err := json.Unmarshal(body, &req); err != nil {
+		return GraphQLOperation{}, fmt.Errorf("could not parse graphql request: %w", err)
+	}
+
+	if req.Query == "" {
+		return GraphQLOperation{}, errors.New("could not parse graphql request: no query")
+	}
+
+	op = GraphQLOperation{Name: req.OperationName, Type: GraphQLQuery, Variables: req.Variables}
+
+	document := graphQLIgnoredRex.ReplaceAllString(req.Query, "")
+	matches := graphQLOperationRex.FindAllStringSubmatch(document, -1)
+
+	for _, m := range matches {
+		if req.OperationName == "" || m[2] == req.OperationName {
+			op.Type = m[1]
+			if op.Name == "" && len(matches) == 1 {
+				op.Name = m[2]
+			}
+
+			break
+		}
+	}
+
+	if op.Name == "" {
+		op.Name = GraphQLAnonymous
+	}
+
+	return op, nil
+}
+
+// readGraphQLOperation parses the GraphQL operation of $r's body, leaving the body to be read again
+func readGraphQLOperation(r *http.Request) (op GraphQLOperation, fault error) {
+	if r.Method != http.MethodPost || r.Body == nil {
+		return GraphQLOperation{}, errors.New("not a graphql post request")
+	}
+
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		return GraphQLOperation{}, fmt.Errorf("could not read request body: %w", err)
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(b))
+
+	return ParseGraphQLRequest(b)
+}
+
+// GraphQLMiddlewareMux returns a middleware for GraphQL endpoints that names the span in the request context (see
+// RequestLoggerMiddlewareMux) after the operation, e.g. "query GetUser", adds the operation's name and type to the
+// span's attributes and to the args of the Observer in the request context, logs the operation with its variables
+// redacted per the redaction policy at Debug level, and records the GraphQLOperations and GraphQLOperationTimes metrics
+// by operation rather than the single endpoint path. Requests that aren't GraphQL POST requests are passed through.
+// If the Observer cannot be retrieved from the provided context, an error is returned.
+func GraphQLMiddlewareMux(ctxWithObserver context.Context) (graphQLMiddleware mux.MiddlewareFunc, fault error) {
+	_, o, err := Get(ctxWithObserver)
+	if err != nil {
+		return nil, fmt.Errorf("could not get go11y observer from context: %w", err)
+	}
+
+	registerGraphQLMetrics()
+
+	mw := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			op, err := readGraphQLOperation(r)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			span := otelTrace.SpanFromContext(r.Context())
+			span.SetName(op.SpanName())
+			span.SetAttributes(op.spanAttributes()...)
+
+			ro := FromContext(r.Context())
+			ro.log(r.Context(), 3, LevelDebug, "graphql operation", op.LogArgs()...)
+			ro = ro.With(FieldGraphQLOperation, op.Name, FieldGraphQLOperationType, op.Type)
+
+			t0 := o.clock.Now()
+
+			mrw := newMiddlewareResponseWriter(w)
+			next.ServeHTTP(mrw, r.WithContext(AddToContext(r.Context(), ro)))
+
+			GraphQLOperations.WithLabelValues("server", op.Type, op.Name, strconv.Itoa(mrw.StatusCode())).Inc()
+			GraphQLOperationTimes.WithLabelValues("server", op.Type, op.Name).Observe(o.clock.Since(t0).Seconds())
+		})
+	}
+
+	return mw, nil
+}
+
+// graphQLRoundTripper starts a client span named after the GraphQL operation of each outbound call, logs the operation
+// and records the GraphQL metrics
+func graphQLRoundTripper(ctxWithObserver context.Context, next http.RoundTripper) http.RoundTripper {
+	return RoundTripperFunc(func(r *http.Request) (w *http.Response, fault error) {
+		op, err := readGraphQLOperation(r)
+		if err != nil {
+			return next.RoundTrip(r)
+		}
+
+		ctx, o, args := outboundObserver(ctxWithObserver, r, op.LogArgs())
+		o.log(ctx, 8, LevelDebug, "outbound graphql operation", args...)
+
+		ctx, span := o.spanTracer(nil).Start(ctx, op.SpanName(),
+			otelTrace.WithSpanKind(otelTrace.SpanKindClient),
+			otelTrace.WithAttributes(op.spanAttributes()...),
+		)
+		defer span.End()
+
+		start := o.clock.Now()
+
+		resp, err := next.RoundTrip(r.WithContext(ctx))
+
+		status := OutboundStatusError
+		if err == nil {
+			status = strconv.Itoa(resp.StatusCode)
+		}
+
+		GraphQLOperations.WithLabelValues("client", op.Type, op.Name, status).Inc()
+		GraphQLOperationTimes.WithLabelValues("client", op.Type, op.Name).Observe(o.clock.Since(start).Seconds())
+
+		return resp, err
+	})
+}
@@ -0,0 +1,153 @@
+package go11y_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/cirruscomms/go11y"
+)
+
+func TestParseGraphQLRequest(t *testing.T) {
+	testCases := map[string]struct {
+		body     string
+		expected go11y.GraphQLOperation
+		fails    bool
+	}{
+		"named query": {
+			body:     `{"query":"query GetUser($id: ID!) { user(id: $id) { name } }","variables":{"id":"1"}}`,
+			expected: go11y.GraphQLOperation{Name: "GetUser", Type: go11y.GraphQLQuery},
+		},
+		"shorthand query": {
+			body:     `{"query":"{ users { name } }"}`,
+			expected: go11y.GraphQLOperation{Name: go11y.GraphQLAnonymous, Type: go11y.GraphQLQuery},
+		},
+		"mutation chosen by operation name": {
+			body: `{"query":"query GetUser { user { name } } mutation RenameUser { rename { name } }",` +
+				`"operationName":"RenameUser"}`,
+			expected: go11y.GraphQLOperation{Name: "RenameUser", Type: go11y.GraphQLMutation},
+		},
+		"keywords in strings and comments are ignored": {
+			body:     `{"query":"# mutation Nope\nsubscription OnCall { calls(filter: \"query Fake\") { id } }"}`,
+			expected: go11y.GraphQLOperation{Name: "OnCall", Type: go11y.GraphQLSubscription},
+		},
+		"anonymous mutation": {
+			body:     `{"query":"mutation { reset }"}`,
+			expected: go11y.GraphQLOperation{Name: go11y.GraphQLAnonymous, Type: go11y.GraphQLMutation},
+		},
+		"not graphql": {body: `{"name":"fester"}`, fails: true},
+		"not json":    {body: `query { users }`, fails: true},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			op, err := go11y.ParseGraphQLRequest([]byte(tc.body))
+			if tc.fails {
+				if err == nil {
+					t.Errorf("expected parsing to fail, got %+v", op)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("failed to parse request: %v", err)
+			}
+
+			if op.Name != tc.expected.Name || op.Type != tc.expected.Type {
+				t.Errorf("expected %s, got %s", tc.expected.SpanName(), op.SpanName())
+			}
+		})
+	}
+}
+
+func TestGraphQLMiddlewareMux(t *testing.T) {
+	buf := new(bytes.Buffer)
+
+	ctx, o, spans, err := go11y.InitialiseTestTracerInMemory(context.Background(), go11y.LevelDebug, buf, buf)
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	mw, err := go11y.GraphQLMiddlewareMux(ctx)
+	if err != nil {
+		t.Fatalf("failed to create middleware: %v", err)
+	}
+
+	handler := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(b), "GetUser") {
+			t.Errorf("expected the handler to be able to read the body, got %s", b)
+		}
+
+		go11y.FromContext(r.Context()).Info("resolving")
+	}))
+
+	spanCtx, _, end, err := go11y.StartSpan(ctx, o.Tracer("test"), "HTTP POST /graphql", go11y.SpanKindServer)
+	if err != nil {
+		t.Fatalf("failed to start span: %v", err)
+	}
+
+	body := `{"query":"query GetUser($password: String) { user { name } }","variables":{"password":"DummyPasswordForTesting"}}`
+	r := httptest.NewRequestWithContext(spanCtx, http.MethodPost, "/graphql", strings.NewReader(body))
+
+	before := testutil.ToFloat64(go11y.GraphQLOperations.WithLabelValues("server", "query", "GetUser", "200"))
+
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+	end()
+
+	if names := spans.Names(); len(names) != 1 || names[0] != "query GetUser" {
+		t.Errorf("expected the span to be named after the operation, got %v", names)
+	}
+
+	if strings.Contains(buf.String(), "DummyPasswordForTesting") {
+		t.Errorf("expected the variables to be redacted, got %s", buf.String())
+	}
+
+	if !strings.Contains(buf.String(), `"msg":"resolving"`) || !strings.Contains(buf.String(), `"graphql_operation":"GetUser"`) {
+		t.Errorf("expected the handler's records to have the operation, got %s", buf.String())
+	}
+
+	if after := testutil.ToFloat64(go11y.GraphQLOperations.WithLabelValues("server", "query", "GetUser", "200")); after != before+1 {
+		t.Errorf("expected the operation to be counted, got %v", after-before)
+	}
+}
+
+func TestGraphQLClient(t *testing.T) {
+	ctx, _, spans, err := go11y.InitialiseTestTracerInMemory(context.Background(), go11y.LevelInfo, io.Discard, io.Discard)
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	client := &go11y.HTTPClient{Client: &http.Client{
+		Transport: go11y.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: r}, nil
+		}),
+	}}
+
+	if err := client.AddGraphQL(ctx); err != nil {
+		t.Fatalf("failed to add graphql to HTTP client: %v", err)
+	}
+
+	before := testutil.ToFloat64(go11y.GraphQLOperations.WithLabelValues("client", "mutation", "RenameUser", "200"))
+
+	resp, err := client.Post("http://graphql.test/graphql", "application/json",
+		strings.NewReader(`{"query":"mutation RenameUser { rename { name } }"}`))
+	if err != nil {
+		t.Fatalf("failed to execute request: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	if names := spans.Names(); len(names) != 1 || names[0] != "mutation RenameUser" {
+		t.Errorf("expected a client span named after the operation, got %v", names)
+	}
+
+	if after := testutil.ToFloat64(go11y.GraphQLOperations.WithLabelValues("client", "mutation", "RenameUser", "200")); after != before+1 {
+		t.Errorf("expected the operation to be counted, got %v", after-before)
+	}
+}
@@ -0,0 +1,218 @@
+package go11y
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// HostDNSUp is the metric for whether the host of a monitored third-party host resolves (1) or not (0)
+var HostDNSUp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "go11y_host_dns_up",
+	Help: "Whether a monitored third-party host resolves",
+}, []string{"host"})
+
+// HostCertExpiry is the metric for the expiry time of a monitored third-party host's TLS certificate, in seconds
+// since the epoch
+var HostCertExpiry = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "go11y_host_cert_expiry_timestamp_seconds",
+	Help: "Expiry time of a monitored third-party host's TLS certificate",
+}, []string{"host"})
+
+// HostTLSUp is the metric for whether a TLS handshake with a monitored third-party host succeeds (1) or not (0)
+var HostTLSUp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "go11y_host_tls_up",
+	Help: "Whether a TLS handshake with a monitored third-party host succeeds",
+}, []string{"host"})
+
+var registerHostMetricsOnce sync.Once
+
+// HostMonitorOpts are the options used to create a HostMonitor
+type HostMonitorOpts struct {
+	Hosts         []string      // optional - hosts to monitor from the start, as "host" or "host:port" (port defaults to 443)
+	Interval      time.Duration // optional - how often the hosts are checked, defaults to 1 hour
+	ExpiryWarning time.Duration // optional - warn when a certificate expires within this time, defaults to 14 days
+	Timeout       time.Duration // optional - timeout of each DNS lookup and TLS handshake, defaults to 10 seconds
+}
+
+// HostMonitor periodically checks the DNS resolution and TLS certificate expiry of third-party hosts, publishing the
+// HostDNSUp, HostTLSUp and HostCertExpiry metrics and logging a warning when a certificate nears expiry.
+// Hosts can be configured up front or learned from the calls made by an instrumented client (see
+// HTTPClient.AddHostMonitoring).
+type HostMonitor struct {
+	ctx           context.Context
+	o             *Observer
+	hosts         []string
+	interval      time.Duration
+	expiryWarning time.Duration
+	timeout       time.Duration
+	mu            sync.Mutex
+}
+
+// NewHostMonitor creates a HostMonitor logging with the Observer in $ctxWithObserver.
+// If the Observer cannot be retrieved from the provided context, an error is returned.
+func NewHostMonitor(ctxWithObserver context.Context, opts HostMonitorOpts) (monitor *HostMonitor, fault error) {
+	ctx, o, err := Get(ctxWithObserver)
+	if err != nil {
+		return nil, fmt.Errorf("could not get go11y observer from context: %w", err)
+	}
+
+	registerHostMetricsOnce.Do(func() {
+		registerCollectors(HostDNSUp, HostCertExpiry, HostTLSUp)
+	})
+
+	if opts.Interval <= 0 {
+		opts.Interval = time.Hour
+	}
+	if opts.ExpiryWarning <= 0 {
+		opts.ExpiryWarning = 14 * 24 * time.Hour
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+
+	m := &HostMonitor{
+		ctx:           ctx,
+		o:             o,
+		interval:      opts.Interval,
+		expiryWarning: opts.ExpiryWarning,
+		timeout:       opts.Timeout,
+	}
+
+	for _, h := range opts.Hosts {
+		m.AddHost(h)
+	}
+
+	return m, nil
+}
+
+// AddHost adds $host ("host" or "host:port") to the monitored hosts if it isn't already monitored.
+func (m *HostMonitor) AddHost(host string) {
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		host = net.JoinHostPort(host, "443")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !slices.Contains(m.hosts, host) {
+		m.hosts = append(m.hosts, host)
+	}
+}
+
+// Hosts returns the monitored hosts.
+func (m *HostMonitor) Hosts() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return slices.Clone(m.hosts)
+}
+
+// Run checks the monitored hosts every interval until $ctx is cancelled. Hosts are checked once straight away.
+func (m *HostMonitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		m.Check(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check checks the DNS resolution and TLS certificate of every monitored host once.
+func (m *HostMonitor) Check(ctx context.Context) {
+	for _, host := range m.Hosts() {
+		m.checkHost(ctx, host)
+	}
+}
+
+func (m *HostMonitor) checkHost(ctx context.Context, hostPort string) {
+	host, _, _ := net.SplitHostPort(hostPort)
+
+	lookupCtx, cancel := context.WithTimeout(ctx, m.timeout)
+	_, err := net.DefaultResolver.LookupHost(lookupCtx, host)
+	cancel()
+
+	if err != nil {
+		HostDNSUp.WithLabelValues(hostPort).Set(0)
+		HostTLSUp.WithLabelValues(hostPort).Set(0)
+		m.o.log(m.ctx, 4, LevelWarning, "monitored host does not resolve", "host", hostPort, "error", err.Error())
+		return
+	}
+
+	HostDNSUp.WithLabelValues(hostPort).Set(1)
+
+	dialer := &tls.Dialer{
+		NetDialer: &net.Dialer{Timeout: m.timeout},
+		Config: &tls.Config{
+			ServerName: host,
+			// the certificate is inspected rather than trusted - expiry must be reported even for untrusted chains
+			InsecureSkipVerify: true, //nolint:gosec
+		},
+	}
+
+	dialCtx, cancel := context.WithTimeout(ctx, m.timeout)
+	defer cancel()
+
+	conn, err := dialer.DialContext(dialCtx, "tcp", hostPort)
+	if err != nil {
+		HostTLSUp.WithLabelValues(hostPort).Set(0)
+		m.o.log(m.ctx, 4, LevelWarning, "could not complete TLS handshake with monitored host", "host", hostPort, "error", err.Error())
+		return
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	HostTLSUp.WithLabelValues(hostPort).Set(1)
+
+	certs := conn.(*tls.Conn).ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return
+	}
+
+	// the chain is only valid until its earliest expiring certificate expires
+	expiry := certs[0].NotAfter
+	for _, c := range certs[1:] {
+		if c.NotAfter.Before(expiry) {
+			expiry = c.NotAfter
+		}
+	}
+
+	HostCertExpiry.WithLabelValues(hostPort).Set(float64(expiry.Unix()))
+
+	if remaining := time.Until(expiry); remaining < m.expiryWarning {
+		m.o.log(m.ctx, 4, LevelWarning, "monitored host certificate nears expiry",
+			"host", hostPort,
+			"expires_at", expiry,
+			"expires_in", remaining.Round(time.Minute).String(),
+		)
+	}
+}
+
+// hostMonitorRoundTripper adds the host of every https call to $monitor
+func hostMonitorRoundTripper(monitor *HostMonitor, next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return RoundTripperFunc(func(r *http.Request) (w *http.Response, fault error) {
+		if r.URL.Scheme == "https" {
+			monitor.AddHost(r.URL.Host)
+		}
+
+		return next.RoundTrip(r)
+	})
+}
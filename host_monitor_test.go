@@ -0,0 +1,64 @@
+package go11y_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/cirruscomms/go11y"
+)
+
+func TestHostMonitor(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	out := new(bytes.Buffer)
+	cfg := go11y.CreateConfig(go11y.LevelInfo, "", "", "", []string{}, []string{})
+	ctx, _, err := go11y.Initialise(context.Background(), cfg, out, out)
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	// the test certificate is valid for years, so warn about anything expiring within a century
+	monitor, err := go11y.NewHostMonitor(ctx, go11y.HostMonitorOpts{ExpiryWarning: 100 * 365 * 24 * time.Hour})
+	if err != nil {
+		t.Fatalf("failed to create host monitor: %v", err)
+	}
+
+	client := &go11y.HTTPClient{Client: server.Client()}
+	if err := client.AddHostMonitoring(monitor); err != nil {
+		t.Fatalf("failed to add host monitoring to HTTP client: %v", err)
+	}
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("failed to execute request: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	host := strings.TrimPrefix(server.URL, "https://")
+	if hosts := monitor.Hosts(); len(hosts) != 1 || hosts[0] != host {
+		t.Fatalf("expected the monitor to learn %s, got %v", host, hosts)
+	}
+
+	monitor.Check(ctx)
+
+	if v := testutil.ToFloat64(go11y.HostTLSUp.WithLabelValues(host)); v != 1 {
+		t.Errorf("expected the TLS handshake to succeed, got %v", v)
+	}
+
+	expiry := testutil.ToFloat64(go11y.HostCertExpiry.WithLabelValues(host))
+	if expiry != float64(server.Certificate().NotAfter.Unix()) {
+		t.Errorf("expected the certificate expiry to be %d, got %v", server.Certificate().NotAfter.Unix(), expiry)
+	}
+
+	if !strings.Contains(out.String(), `"msg":"monitored host certificate nears expiry"`) {
+		t.Errorf("expected an expiry warning, got %s", out.String())
+	}
+}
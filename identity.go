@@ -0,0 +1,45 @@
+package go11y
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+
+	"go.opentelemetry.io/otel/baggage"
+)
+
+// IdentityExtractor returns the identity of the caller of $r, e.g. FieldTenantID, FieldUserID and FieldClientID read
+// from a verified token, as the fields added to the request's logs, span and baggage by RequestLoggerMiddlewareMux.
+// Returning an error logs a warning, the request is still handled.
+type IdentityExtractor func(r *http.Request) (identity Fields, fault error)
+
+// identityArgs returns $identity as key-value args in key order, so records and span attributes are stable
+func identityArgs(identity Fields) (args []any) {
+	args = make([]any, 0, 2*len(identity))
+	for _, k := range slices.Sorted(maps.Keys(identity)) {
+		args = append(args, k, identity[k])
+	}
+
+	return args
+}
+
+// withIdentityBaggage returns $ctx with $identity added to its baggage, so it is propagated to the services called
+// while handling the request. Members that aren't valid baggage are skipped.
+func withIdentityBaggage(ctx context.Context, identity Fields) context.Context {
+	b := baggage.FromContext(ctx)
+
+	for _, k := range slices.Sorted(maps.Keys(identity)) {
+		m, err := baggage.NewMemberRaw(k, fmt.Sprint(identity[k]))
+		if err != nil {
+			continue
+		}
+
+		if withMember, err := b.SetMember(m); err == nil {
+			b = withMember
+		}
+	}
+
+	return baggage.ContextWithBaggage(ctx, b)
+}
@@ -0,0 +1,199 @@
+package go11y
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DefaultIntegrationLatencyObjective is the latency objective of an Integration that doesn't set its own
+const DefaultIntegrationLatencyObjective = time.Second
+
+const (
+	// IntegrationOutcomeOK is the outcome label of a call to an integration that got a response other than a 5xx
+	IntegrationOutcomeOK = "ok"
+	// IntegrationOutcomeError is the outcome label of a call to an integration that failed without a response or got a
+	// 5xx response
+	IntegrationOutcomeError = "error"
+)
+
+const (
+	// BudgetSpentError is the reason label of the error budget spent by calls that failed, see IntegrationOutcomeError
+	BudgetSpentError = "error"
+	// BudgetSpentSlow is the reason label of the error budget spent by calls that succeeded but took longer than the
+	// latency objective of their integration
+	BudgetSpentSlow = "slow"
+)
+
+// IntegrationRequests is the metric for the number of calls made to each registered integration, by integration and
+// outcome (IntegrationOutcomeOK or IntegrationOutcomeError), the availability SLI of the integration
+var IntegrationRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "go11y_integration_requests_total",
+	Help: "Number of calls made to third-party integrations",
+}, []string{"integration", "outcome"})
+
+// IntegrationRequestTimes is the metric for the time calls to each registered integration take, by integration, the
+// latency SLI of the integration
+var IntegrationRequestTimes = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "go11y_integration_request_duration_seconds",
+	Help:    "Time calls to third-party integrations take",
+	Buckets: prometheus.ExponentialBuckets(0.01, 2, 12),
+}, []string{"integration"})
+
+// IntegrationBudgetSpent is the metric for the number of calls to each registered integration that spent its error
+// budget, by integration and reason (BudgetSpentError or BudgetSpentSlow). A call that failed is only counted as an
+// error, however long it took.
+var IntegrationBudgetSpent = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "go11y_integration_error_budget_spent_total",
+	Help: "Number of calls to third-party integrations that failed or missed their latency objective",
+}, []string{"integration", "reason"})
+
+// IntegrationObjective is the metric for the latency objective of each registered integration in seconds, so SLO
+// dashboards can draw it without per-service wiring
+var IntegrationObjective = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "go11y_integration_latency_objective_seconds",
+	Help: "Latency objective of third-party integrations",
+}, []string{"integration"})
+
+var registerIntegrationMetricsOnce sync.Once
+
+func registerIntegrationMetrics() {
+	registerIntegrationMetricsOnce.Do(func() {
+		registerCollectors(IntegrationRequests, IntegrationRequestTimes, IntegrationBudgetSpent, IntegrationObjective)
+	})
+}
+
+// Integration is a third-party API whose calls are measured as SLIs under its own name, see RegisterIntegrations
+type Integration struct {
+	Name  string   // the integration label of its metrics, e.g. "stripe"
+	Hosts []string // path.Match glob patterns of the hosts it is called on, e.g. "*.stripe.com"
+
+	// optional - calls slower than it spend the error budget, defaults to DefaultIntegrationLatencyObjective
+	LatencyObjective time.Duration
+}
+
+// integrationRegistry holds the registered integrations, in the order they were registered
+var integrationRegistry = struct {
+	mu           sync.RWMutex
+	integrations []Integration
+}{}
+
+// RegisterIntegrations adds $integrations to the registry of third-party APIs. Every call made through a roundtripper
+// added by HTTPClient.AddHostMetrics or HTTPClient.AddContextMetrics to a host matching one of the patterns of an
+// integration is then counted in the IntegrationRequests, IntegrationRequestTimes and IntegrationBudgetSpent metrics
+// with the integration's name, on top of the recorder's own metrics. When a host matches several integrations, the
+// first registered wins. Registering an integration with the name of one already registered replaces it.
+// It returns an error if an integration has no name, no hosts or an invalid host pattern, registering none of them.
+func RegisterIntegrations(integrations ...Integration) (fault error) {
+	for _, in := range integrations {
+		if in.Name == "" {
+			return fmt.Errorf("could not register integration: no name given for hosts %v", in.Hosts)
+		}
+
+		if len(in.Hosts) == 0 {
+			return fmt.Errorf("could not register integration %s: no hosts given", in.Name)
+		}
+
+		for _, pattern := range in.Hosts {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("could not register integration %s: invalid host pattern %q: %w", in.Name, pattern, err)
+			}
+		}
+	}
+
+	registerIntegrationMetrics()
+
+	integrationRegistry.mu.Lock()
+	defer integrationRegistry.mu.Unlock()
+
+	for _, in := range integrations {
+		if in.LatencyObjective <= 0 {
+			in.LatencyObjective = DefaultIntegrationLatencyObjective
+		}
+
+		in.Hosts = normaliseHosts(in.Hosts)
+
+		replaced := false
+		for i, existing := range integrationRegistry.integrations {
+			if existing.Name == in.Name {
+				integrationRegistry.integrations[i], replaced = in, true
+				break
+			}
+		}
+
+		if !replaced {
+			integrationRegistry.integrations = append(integrationRegistry.integrations, in)
+		}
+
+		IntegrationObjective.WithLabelValues(in.Name).Set(in.LatencyObjective.Seconds())
+	}
+
+	return nil
+}
+
+// normaliseHosts lowercases the host patterns in $hosts, as hosts are case-insensitive
+func normaliseHosts(hosts []string) (normalised []string) {
+	normalised = make([]string, 0, len(hosts))
+	for _, h := range hosts {
+		normalised = append(normalised, strings.ToLower(h))
+	}
+
+	return normalised
+}
+
+// IntegrationFor returns the registered integration called on $host (with or without a port), if there is one.
+func IntegrationFor(host string) (integration Integration, found bool) {
+	integrationRegistry.mu.RLock()
+	defer integrationRegistry.mu.RUnlock()
+
+	if len(integrationRegistry.integrations) == 0 {
+		return Integration{}, false
+	}
+
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(host)
+
+	for _, in := range integrationRegistry.integrations {
+		for _, pattern := range in.Hosts {
+			if ok, _ := path.Match(pattern, host); ok {
+				return in, true
+			}
+		}
+	}
+
+	return Integration{}, false
+}
+
+// recordIntegrationCall counts the call $r, answered with $status after $duration, against the SLIs of the integration
+// it was made to, if it was made to one
+func recordIntegrationCall(ctx context.Context, r *http.Request, status string, duration time.Duration) {
+	in, found := IntegrationFor(r.URL.Host)
+	if !found {
+		return
+	}
+
+	outcome := IntegrationOutcomeOK
+	if code, err := strconv.Atoi(status); err != nil || code >= http.StatusInternalServerError {
+		outcome = IntegrationOutcomeError
+	}
+
+	IntegrationRequests.WithLabelValues(in.Name, outcome).Inc()
+	observeWithExemplar(ctx, IntegrationRequestTimes.WithLabelValues(in.Name), duration.Seconds())
+
+	switch {
+	case outcome == IntegrationOutcomeError:
+		IntegrationBudgetSpent.WithLabelValues(in.Name, BudgetSpentError).Inc()
+	case duration > in.LatencyObjective:
+		IntegrationBudgetSpent.WithLabelValues(in.Name, BudgetSpentSlow).Inc()
+	}
+}
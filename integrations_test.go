@@ -0,0 +1,91 @@
+package go11y_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/cirruscomms/go11y"
+	"github.com/cirruscomms/go11y/go11ytest"
+)
+
+func TestIntegrations(t *testing.T) {
+	err := go11y.RegisterIntegrations(go11y.Integration{
+		Name:             "payments",
+		Hosts:            []string{"*.payments.test"},
+		LatencyObjective: 50 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("failed to register integration: %v", err)
+	}
+
+	client := &go11y.HTTPClient{Client: &http.Client{
+		Transport: go11y.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			switch r.URL.Path {
+			case "/unavailable":
+				return &http.Response{StatusCode: http.StatusBadGateway, Body: io.NopCloser(strings.NewReader(""))}, nil
+			case "/unreachable":
+				return nil, errors.New("connection refused")
+			case "/slow":
+				time.Sleep(60 * time.Millisecond)
+			}
+
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(""))}, nil
+		}),
+	}}
+
+	noop := func(context.Context, string, string, string, string, string, time.Time) {}
+	if err := client.AddContextMetrics(noop, nil); err != nil {
+		t.Fatalf("failed to add metrics: %v", err)
+	}
+
+	for _, target := range []string{
+		"https://api.payments.test/charge",
+		"https://API.payments.test:8443/charge",
+		"https://api.payments.test/unavailable",
+		"https://api.payments.test/unreachable",
+		"https://api.payments.test/slow",
+		"https://api.ledger.test/charge",
+	} {
+		resp, err := client.Get(target)
+		if err == nil {
+			resp.Body.Close()
+		}
+	}
+
+	metrics := go11ytest.GatherMetrics(t, prometheus.DefaultGatherer)
+	metrics.AssertValue(t, "go11y_integration_requests_total",
+		go11ytest.Labels{"integration": "payments", "outcome": go11y.IntegrationOutcomeOK}, 3)
+	metrics.AssertValue(t, "go11y_integration_requests_total",
+		go11ytest.Labels{"integration": "payments", "outcome": go11y.IntegrationOutcomeError}, 2)
+	metrics.AssertValue(t, "go11y_integration_error_budget_spent_total",
+		go11ytest.Labels{"integration": "payments", "reason": go11y.BudgetSpentError}, 2)
+	metrics.AssertValue(t, "go11y_integration_error_budget_spent_total",
+		go11ytest.Labels{"integration": "payments", "reason": go11y.BudgetSpentSlow}, 1)
+	metrics.AssertHistogramCount(t, "go11y_integration_request_duration_seconds",
+		go11ytest.Labels{"integration": "payments"}, 5)
+	metrics.AssertValue(t, "go11y_integration_latency_objective_seconds",
+		go11ytest.Labels{"integration": "payments"}, 0.05)
+
+	if _, found := go11y.IntegrationFor("api.ledger.test"); found {
+		t.Errorf("expected calls to unregistered hosts not to be attributed to an integration")
+	}
+}
+
+func TestRegisterIntegrationsInvalid(t *testing.T) {
+	for name, in := range map[string]go11y.Integration{
+		"no name":     {Hosts: []string{"api.example.test"}},
+		"no hosts":    {Name: "example"},
+		"bad pattern": {Name: "example", Hosts: []string{"[api.example.test"}},
+	} {
+		if err := go11y.RegisterIntegrations(in); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
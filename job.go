@@ -0,0 +1,111 @@
+package go11y
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel"
+	otelTrace "go.opentelemetry.io/otel/trace"
+)
+
+// JobRuns is the metric for the number of background job runs, by job name and outcome
+var JobRuns = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "go11y_job_runs_total",
+	Help: "Number of background job runs",
+}, []string{"job", "outcome"})
+
+// JobDuration is the metric for the time background job runs take, by job name and outcome
+var JobDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Name: "go11y_job_duration_seconds",
+	Help: "Time background job runs take",
+}, []string{"job", "outcome"})
+
+const (
+	// JobOutcomeSuccess is the outcome label used when a job returns no error
+	JobOutcomeSuccess = "success"
+	// JobOutcomeFailure is the outcome label used when a job returns an error
+	JobOutcomeFailure = "failure"
+	// JobOutcomePanic is the outcome label used when a job panics
+	JobOutcomePanic = "panic"
+)
+
+var registerJobMetricsOnce sync.Once
+
+// JobFunc is the work done by a job. $ctx carries the job's Observer, which is also passed as $o for convenience.
+type JobFunc func(ctx context.Context, o *Observer) error
+
+// Job runs $fn as an instrumented background job named $name, for cron jobs and queue workers.
+// Each run gets a new root span and a child Observer with job name and run ID fields, logs when it starts and
+// finishes, and records its duration and outcome as metrics. A panic in $fn is recovered, logged with its stack and
+// returned as an error.
+// If the Observer cannot be retrieved from $ctx, an error is returned and $fn is not run.
+func Job(ctx context.Context, name string, fn JobFunc) (fault error) {
+	registerJobMetricsOnce.Do(func() {
+		registerCollectors(JobRuns, JobDuration)
+	})
+
+	ctx, o, err := Get(ctx)
+	if err != nil {
+		return fmt.Errorf("could not get go11y observer from context: %w", err)
+	}
+
+	runID := uuid.NewString()
+
+	ctx, span := otel.Tracer(name).Start(ctx, "job "+name,
+		otelTrace.WithNewRoot(),
+		otelTrace.WithSpanKind(SpanKindInternal),
+	)
+
+	args := []any{FieldJobName, name, FieldJobRunID, runID}
+	if span.SpanContext().IsValid() {
+		args = append(args,
+			FieldSpanID, span.SpanContext().SpanID(),
+			FieldTraceID, span.SpanContext().TraceID(),
+		)
+	}
+
+	child := o.With(args...)
+	child.span = span
+	child.spans = nil
+
+	ctx = context.WithValue(ctx, obsKeyInstance, child)
+
+	t0 := child.clock.Now()
+	outcome := JobOutcomeSuccess
+
+	defer func() {
+		if r := recover(); r != nil {
+			outcome = JobOutcomePanic
+			fault = fmt.Errorf("job %s panicked: %v", name, r)
+			child.Error("job panicked", fault, SeverityHighest, "stack", string(debug.Stack()))
+			child.DumpCrash(fault.Error())
+		}
+
+		duration := child.clock.Since(t0)
+
+		JobRuns.WithLabelValues(name, outcome).Inc()
+		JobDuration.WithLabelValues(name, outcome).Observe(duration.Seconds())
+
+		if outcome == JobOutcomeSuccess {
+			child.Info("job finished", FieldJobDuration, duration.Milliseconds())
+		}
+
+		child.spanMu.Lock()
+		child.endSpan(span)
+		child.spanMu.Unlock()
+	}()
+
+	child.Info("job started")
+
+	if err := fn(ctx, child); err != nil {
+		outcome = JobOutcomeFailure
+		child.Error("job failed", err, SeverityHigh, FieldJobDuration, child.clock.Since(t0).Milliseconds())
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package go11y_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/cirruscomms/go11y"
+)
+
+func TestJob(t *testing.T) {
+	testCases := map[string]struct {
+		fn       go11y.JobFunc
+		outcome  string
+		wantErr  bool
+		expected string
+	}{
+		"success": {
+			fn: func(ctx context.Context, o *go11y.Observer) error {
+				o.Info("doing work")
+				return nil
+			},
+			outcome:  go11y.JobOutcomeSuccess,
+			expected: `"msg":"job finished"`,
+		},
+		"failure": {
+			fn: func(ctx context.Context, o *go11y.Observer) error {
+				return errors.New("nothing to do")
+			},
+			outcome:  go11y.JobOutcomeFailure,
+			wantErr:  true,
+			expected: `"msg":"job failed"`,
+		},
+		"panic": {
+			fn: func(ctx context.Context, o *go11y.Observer) error {
+				panic("oops")
+			},
+			outcome:  go11y.JobOutcomePanic,
+			wantErr:  true,
+			expected: `"msg":"job panicked"`,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			cfg := go11y.CreateConfig(go11y.LevelInfo, "", "", "", []string{}, []string{})
+			ctx, _, err := go11y.Initialise(context.Background(), cfg, out, out)
+			if err != nil {
+				t.Fatalf("failed to initialise observer: %v", err)
+			}
+
+			jobName := "test_job_" + name
+			before := testutil.ToFloat64(go11y.JobRuns.WithLabelValues(jobName, tc.outcome))
+
+			err = go11y.Job(ctx, jobName, tc.fn)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("expected error %v, got %v", tc.wantErr, err)
+			}
+
+			logs := out.String()
+			for _, want := range []string{`"msg":"job started"`, tc.expected, `"job_name":"` + jobName + `"`, `"job_run_id":`} {
+				if !strings.Contains(logs, want) {
+					t.Errorf("expected logs to contain %s, got %s", want, logs)
+				}
+			}
+
+			if v := testutil.ToFloat64(go11y.JobRuns.WithLabelValues(jobName, tc.outcome)) - before; v != 1 {
+				t.Errorf("expected 1 %s run, got %v", tc.outcome, v)
+			}
+		})
+	}
+}
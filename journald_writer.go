@@ -0,0 +1,120 @@
+package go11y
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// JournaldSocket is the path of the systemd journal's native protocol socket
+const JournaldSocket = "/run/systemd/journal/socket"
+
+// syslog priorities as used by journald's PRIORITY field
+const (
+	priorityAlert   = 1
+	priorityCrit    = 2
+	priorityErr     = 3
+	priorityWarning = 4
+	priorityNotice  = 5
+	priorityInfo    = 6
+	priorityDebug   = 7
+)
+
+// JournaldWriter is an io.Writer that sends go11y JSON log records to the systemd journal using its native protocol,
+// mapping the level of each record to the matching syslog priority.
+type JournaldWriter struct {
+	conn       *net.UnixConn
+	identifier string
+}
+
+// NewJournaldWriter connects to the journal socket. $identifier is used as SYSLOG_IDENTIFIER and defaults to the name
+// of the running binary if empty.
+func NewJournaldWriter(identifier string) (writer *JournaldWriter, fault error) {
+	if identifier == "" {
+		identifier = filepath.Base(os.Args[0])
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: JournaldSocket, Net: "unixgram"})
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to journald: %w", err)
+	}
+
+	return &JournaldWriter{
+		conn:       conn,
+		identifier: identifier,
+	}, nil
+}
+
+// Write sends the record in $p to the journal with the priority matching its level. The JSON record is sent as the
+// MESSAGE field and its message (under any FieldSchema) as GO11Y_MSG so it can be filtered on with journalctl.
+func (w *JournaldWriter) Write(p []byte) (int, error) {
+	record := trimNewline(p)
+
+	fields := map[string]any{}
+	_ = json.Unmarshal(record, &fields)
+
+	level, _ := recordField(fields, slog.LevelKey).(string)
+
+	buf := &bytes.Buffer{}
+	writeJournalField(buf, "PRIORITY", []byte(strconv.Itoa(journalPriority(StringToLevel(level)))))
+	writeJournalField(buf, "SYSLOG_IDENTIFIER", []byte(w.identifier))
+	writeJournalField(buf, "MESSAGE", record)
+
+	if msg, _ := recordField(fields, slog.MessageKey).(string); msg != "" {
+		writeJournalField(buf, "GO11Y_MSG", []byte(msg))
+	}
+
+	if _, err := w.conn.Write(buf.Bytes()); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// Close closes the connection to the journal.
+func (w *JournaldWriter) Close() error {
+	return w.conn.Close()
+}
+
+// writeJournalField writes a field in the journal native protocol format, using the binary length-prefixed form for
+// values that contain newlines.
+func writeJournalField(buf *bytes.Buffer, key string, value []byte) {
+	buf.WriteString(key)
+
+	if !bytes.ContainsRune(value, '\n') {
+		buf.WriteByte('=')
+		buf.Write(value)
+		buf.WriteByte('\n')
+		return
+	}
+
+	buf.WriteByte('\n')
+	_ = binary.Write(buf, binary.LittleEndian, uint64(len(value)))
+	buf.Write(value)
+	buf.WriteByte('\n')
+}
+
+func journalPriority(level slog.Level) int {
+	switch level {
+	case LevelFatal:
+		return priorityAlert
+	case LevelPanic:
+		return priorityCrit
+	case LevelError:
+		return priorityErr
+	case LevelWarning:
+		return priorityWarning
+	case LevelNotice:
+		return priorityNotice
+	case LevelInfo:
+		return priorityInfo
+	default:
+		return priorityDebug
+	}
+}
@@ -0,0 +1,306 @@
+package go11y
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// KafkaDelivered is the outcome label of records the Kafka producer delivered
+	KafkaDelivered = "delivered"
+	// KafkaFailed is the outcome label of records the Kafka producer failed to deliver
+	KafkaFailed = "failed"
+	// KafkaQueueFull is the outcome label of records not sent because the sink's queue was full
+	KafkaQueueFull = "queue_full"
+)
+
+// KafkaLevelHeader is the header of Kafka messages giving the level of their record, e.g. "error"
+const KafkaLevelHeader = "level"
+
+// KafkaMessages is the metric for the number of records a KafkaSink tried to deliver, by topic and outcome
+// (KafkaDelivered, KafkaFailed or KafkaQueueFull)
+var KafkaMessages = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "go11y_kafka_messages_total",
+	Help: "Number of log records sent to Kafka",
+}, []string{"topic", "outcome"})
+
+// KafkaBatchSize is the metric for the number of records in the batches a KafkaSink hands to its producer
+var KafkaBatchSize = prometheus.NewHistogram(prometheus.HistogramOpts{
+	Name:    "go11y_kafka_batch_size",
+	Help:    "Number of log records in each batch sent to Kafka",
+	Buckets: prometheus.ExponentialBuckets(1, 4, 7),
+})
+
+var registerKafkaMetricsOnce sync.Once
+
+func registerKafkaMetrics() {
+	registerKafkaMetricsOnce.Do(func() {
+		registerCollectors(KafkaMessages, KafkaBatchSize)
+	})
+}
+
+// KafkaMessage is a log record to be produced to Kafka
+type KafkaMessage struct {
+	Topic   string
+	Key     []byte            // the record's trace ID, so the records of a trace land in one partition, nil if none
+	Value   []byte            // the JSON record
+	Headers map[string]string // the KafkaLevelHeader of the record
+}
+
+// KafkaProducer produces batches of messages to Kafka, wrapping the Kafka client of the application's choice (e.g.
+// sarama, franz-go or kafka-go), so go11y doesn't depend on one. Compression and delivery retries are configured on
+// the client, which compresses and retries the batches it produces.
+type KafkaProducer interface {
+	// Produce delivers $messages, returning an error if any couldn't be delivered
+	Produce(ctx context.Context, messages []KafkaMessage) error
+}
+
+// KafkaProducerFunc is a function that implements KafkaProducer
+type KafkaProducerFunc func(ctx context.Context, messages []KafkaMessage) error
+
+// Produce calls f(ctx, messages)
+func (f KafkaProducerFunc) Produce(ctx context.Context, messages []KafkaMessage) error {
+	return f(ctx, messages)
+}
+
+// KafkaSinkOpts are the options used to create a KafkaSink
+type KafkaSinkOpts struct {
+	Producer KafkaProducer // produces the batches of records
+	Topic    string        // the topic records are produced to
+
+	// optional - produces the records of each level to their own topic, named Topic and the lower case level, e.g.
+	// "logs.error", defaults to producing every record to Topic, with its level in the record and KafkaLevelHeader
+	TopicPerLevel bool
+	// optional - the number of records batched before they are produced, defaults to 100
+	BatchSize int
+	// optional - the longest a record waits for its batch to fill up before it is produced, defaults to a second
+	Linger time.Duration
+	// optional - the number of records waiting to be produced, those written while it is full are dropped, defaults to
+	// 10000
+	QueueSize int
+	// optional - the time the producer has to deliver a batch, defaults to 10s
+	DeliveryTimeout time.Duration
+}
+
+// KafkaSink produces the JSON records of an Observer to Kafka, for pipelines that ingest logs from Kafka rather than
+// scraping stdout. It is an io.Writer of JSON records, added to an Observer with WithSinks(k.Sink()). Records are
+// queued and produced in batches in the background so logging never waits for Kafka; the outcome of each record is
+// counted in the KafkaMessages metric, as failures can't be logged without being produced in turn. The Observer flushes
+// the sink when it is closed; Close it after the Observer.
+type KafkaSink struct {
+	producer        KafkaProducer
+	topic           string
+	topicPerLevel   bool
+	batchSize       int
+	linger          time.Duration
+	deliveryTimeout time.Duration
+
+	mu     sync.Mutex
+	closed bool
+
+	queue   chan KafkaMessage
+	flushes chan chan struct{}
+	done    chan struct{}
+}
+
+// NewKafkaSink creates a KafkaSink with $opts, starting the goroutine that produces its batches. It returns an error
+// if the producer or topic are missing.
+func NewKafkaSink(opts KafkaSinkOpts) (sink *KafkaSink, fault error) {
+	switch {
+	case opts.Producer == nil:
+		return nil, errors.New("could not create kafka sink: no producer given")
+	case opts.Topic == "":
+		return nil, errors.New("could not create kafka sink: no topic given")
+	}
+
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 100
+	}
+
+	if opts.Linger <= 0 {
+		opts.Linger = time.Second
+	}
+
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = 10000
+	}
+
+	if opts.DeliveryTimeout <= 0 {
+		opts.DeliveryTimeout = 10 * time.Second
+	}
+
+	registerKafkaMetrics()
+
+	sink = &KafkaSink{
+		producer:        opts.Producer,
+		topic:           opts.Topic,
+		topicPerLevel:   opts.TopicPerLevel,
+		batchSize:       opts.BatchSize,
+		linger:          opts.Linger,
+		deliveryTimeout: opts.DeliveryTimeout,
+		queue:           make(chan KafkaMessage, opts.QueueSize),
+		flushes:         make(chan chan struct{}),
+		done:            make(chan struct{}),
+	}
+
+	go sink.run()
+
+	return sink, nil
+}
+
+// Sink returns the Sink to add to an Observer with WithSinks, receiving every JSON record the Observer logs
+func (k *KafkaSink) Sink() Sink {
+	return Sink{Writer: k, MinLevel: LevelDevelop, Format: SinkFormatJSON}
+}
+
+// Write queues the JSON record $p to be produced. It never blocks on Kafka, and never fails; records written while
+// the queue is full, or after Close, are dropped.
+func (k *KafkaSink) Write(p []byte) (n int, fault error) {
+	record := bytes.Clone(trimNewline(p))
+
+	fields := map[string]any{}
+	_ = json.Unmarshal(record, &fields)
+
+	levelName, _ := recordField(fields, slog.LevelKey).(string)
+	level := LevelToString(StringToLevel(levelName))
+
+	msg := KafkaMessage{
+		Topic:   k.topic,
+		Value:   record,
+		Headers: map[string]string{KafkaLevelHeader: level},
+	}
+
+	if k.topicPerLevel {
+		msg.Topic = k.topic + "." + level
+	}
+
+	if traceID, _ := recordField(fields, FieldTraceID).(string); traceID != "" {
+		msg.Key = []byte(traceID)
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if k.closed {
+		return len(p), nil
+	}
+
+	select {
+	case k.queue <- msg:
+	default:
+		KafkaMessages.WithLabelValues(msg.Topic, KafkaQueueFull).Inc()
+	}
+
+	return len(p), nil
+}
+
+// Flush blocks until every record queued so far has been produced or has failed.
+func (k *KafkaSink) Flush() error {
+	flushed := make(chan struct{})
+
+	select {
+	case k.flushes <- flushed:
+		<-flushed
+	case <-k.done:
+	}
+
+	return nil
+}
+
+// Close produces the records already queued and stops the goroutine producing them. It doesn't close the producer.
+func (k *KafkaSink) Close() error {
+	k.mu.Lock()
+	if k.closed {
+		k.mu.Unlock()
+		return nil
+	}
+
+	k.closed = true
+	close(k.queue)
+	k.mu.Unlock()
+
+	<-k.done
+
+	return nil
+}
+
+func (k *KafkaSink) run() {
+	defer close(k.done)
+
+	batch := make([]KafkaMessage, 0, k.batchSize)
+
+	linger := time.NewTimer(k.linger)
+	linger.Stop()
+
+	for {
+		select {
+		case msg, ok := <-k.queue:
+			if !ok {
+				k.produce(batch)
+				return
+			}
+
+			if len(batch) == 0 {
+				linger.Reset(k.linger)
+			}
+
+			batch = append(batch, msg)
+			if len(batch) >= k.batchSize {
+				linger.Stop()
+				batch = k.produce(batch)
+			}
+		case <-linger.C:
+			batch = k.produce(batch)
+		case flushed := <-k.flushes:
+			open := true
+			for open && len(k.queue) > 0 {
+				var msg KafkaMessage
+				if msg, open = <-k.queue; open {
+					batch = append(batch, msg)
+					if len(batch) >= k.batchSize {
+						batch = k.produce(batch)
+					}
+				}
+			}
+
+			linger.Stop()
+			batch = k.produce(batch)
+			close(flushed)
+
+			if !open {
+				return
+			}
+		}
+	}
+}
+
+// produce hands $batch to the producer, counting the outcome of its records, and returns a new batch, as the producer
+// may keep the messages of $batch
+func (k *KafkaSink) produce(batch []KafkaMessage) []KafkaMessage {
+	if len(batch) == 0 {
+		return batch
+	}
+
+	KafkaBatchSize.Observe(float64(len(batch)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), k.deliveryTimeout)
+	defer cancel()
+
+	outcome := KafkaDelivered
+	if err := k.producer.Produce(ctx, batch); err != nil {
+		outcome = KafkaFailed
+	}
+
+	for _, msg := range batch {
+		KafkaMessages.WithLabelValues(msg.Topic, outcome).Inc()
+	}
+
+	return make([]KafkaMessage, 0, k.batchSize)
+}
@@ -0,0 +1,147 @@
+package go11y_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/cirruscomms/go11y"
+)
+
+func TestKafkaSink(t *testing.T) {
+	t.Setenv("ENV", "test")
+
+	var mu sync.Mutex
+	var batches [][]go11y.KafkaMessage
+
+	producer := go11y.KafkaProducerFunc(func(ctx context.Context, messages []go11y.KafkaMessage) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		batches = append(batches, messages)
+		if strings.Contains(string(messages[0].Value), "unavailable") {
+			return errors.New("broker unavailable")
+		}
+
+		return nil
+	})
+
+	if _, err := go11y.NewKafkaSink(go11y.KafkaSinkOpts{Producer: producer}); err == nil {
+		t.Errorf("expected a kafka sink without a topic to be rejected")
+	}
+
+	kafka, err := go11y.NewKafkaSink(go11y.KafkaSinkOpts{
+		Producer:      producer,
+		Topic:         "logs",
+		TopicPerLevel: true,
+		BatchSize:     2,
+		Linger:        time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("failed to create kafka sink: %v", err)
+	}
+
+	cfg := go11y.CreateConfig(go11y.LevelInfo, "", "", "", []string{}, []string{})
+
+	_, o, err := go11y.Initialise(context.Background(), cfg, io.Discard, io.Discard, go11y.WithSinks(kafka.Sink()))
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	delivered := testutil.ToFloat64(go11y.KafkaMessages.WithLabelValues("logs.info", go11y.KafkaDelivered))
+	failed := testutil.ToFloat64(go11y.KafkaMessages.WithLabelValues("logs.error", go11y.KafkaFailed))
+
+	o.Info("first record")
+	o.Info("second record")
+	o.Error("unavailable", errors.New("TestKafkaSink"), go11y.SeverityLow)
+
+	o.Close()
+
+	mu.Lock()
+	if len(batches) != 2 || len(batches[0]) != 2 || len(batches[1]) != 1 {
+		t.Fatalf("expected a full batch and the rest flushed on close, got %v", batches)
+	}
+
+	first, last := batches[0][0], batches[1][0]
+	mu.Unlock()
+
+	if first.Topic != "logs.info" || first.Headers[go11y.KafkaLevelHeader] != "info" ||
+		!strings.Contains(string(first.Value), `"msg":"first record"`) || strings.HasSuffix(string(first.Value), "\n") {
+		t.Errorf("unexpected message: %s %v %s", first.Topic, first.Headers, first.Value)
+	}
+
+	if last.Topic != "logs.error" {
+		t.Errorf("expected the error to be produced to the error topic, got %s", last.Topic)
+	}
+
+	if got := testutil.ToFloat64(go11y.KafkaMessages.WithLabelValues("logs.info", go11y.KafkaDelivered)) - delivered; got != 2 {
+		t.Errorf("expected 2 delivered info records, got %v", got)
+	}
+
+	if got := testutil.ToFloat64(go11y.KafkaMessages.WithLabelValues("logs.error", go11y.KafkaFailed)) - failed; got != 1 {
+		t.Errorf("expected 1 failed error record, got %v", got)
+	}
+
+	if err := kafka.Close(); err != nil {
+		t.Fatalf("failed to close kafka sink: %v", err)
+	}
+
+	if _, err := kafka.Write([]byte(`{"level":"INFO","msg":"after close"}`)); err != nil {
+		t.Errorf("expected writes after close to be dropped, got %v", err)
+	}
+}
+
+func TestKafkaSinkFieldSchema(t *testing.T) {
+	for _, schema := range []go11y.FieldSchema{go11y.SchemaECS, go11y.SchemaOTel} {
+		t.Run(schema.String(), func(t *testing.T) {
+			var mu sync.Mutex
+			var messages []go11y.KafkaMessage
+
+			producer := go11y.KafkaProducerFunc(func(ctx context.Context, batch []go11y.KafkaMessage) error {
+				mu.Lock()
+				defer mu.Unlock()
+
+				messages = append(messages, batch...)
+				return nil
+			})
+
+			kafka, err := go11y.NewKafkaSink(go11y.KafkaSinkOpts{Producer: producer, Topic: "logs", TopicPerLevel: true})
+			if err != nil {
+				t.Fatalf("failed to create kafka sink: %v", err)
+			}
+
+			cfg := go11y.NewConfig(go11y.WithLogLevel(go11y.LevelInfo), go11y.WithFieldSchema(schema))
+
+			_, o, err := go11y.Initialise(context.Background(), cfg, io.Discard, io.Discard,
+				go11y.WithSinks(kafka.Sink()))
+			if err != nil {
+				t.Fatalf("failed to initialise observer: %v", err)
+			}
+
+			o.Warning("warning record")
+			o.Error("error record", errors.New("TestKafkaSinkFieldSchema"), go11y.SeverityLow)
+
+			if err := kafka.Close(); err != nil {
+				t.Fatalf("failed to close kafka sink: %v", err)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			levels := map[string]string{}
+			for _, m := range messages {
+				levels[m.Topic] = m.Headers[go11y.KafkaLevelHeader]
+			}
+
+			if levels["logs.warning"] != "warning" || levels["logs.error"] != "error" {
+				t.Errorf("expected the records to be routed by their level, got %v", levels)
+			}
+		})
+	}
+}
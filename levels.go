@@ -42,7 +42,7 @@ func StringToLevel(level string) slog.Level {
 		return LevelNotice
 	case "warning", "warn":
 		return LevelWarning
-	case "error":
+	case "error", "err":
 		return LevelError
 	case "panic":
 		return LevelPanic
@@ -52,3 +52,26 @@ func StringToLevel(level string) slog.Level {
 		return LevelDebug // default to debug if unknown level
 	}
 }
+
+// LevelToString maps a slog.Level to the lower-case name used by StringToLevel.
+// Levels between the named levels are rounded down to the nearest named level.
+func LevelToString(level slog.Level) string {
+	switch {
+	case level >= LevelFatal:
+		return "fatal"
+	case level >= LevelPanic:
+		return "panic"
+	case level >= LevelError:
+		return "error"
+	case level >= LevelWarning:
+		return "warning"
+	case level >= LevelNotice:
+		return "notice"
+	case level >= LevelInfo:
+		return "info"
+	case level >= LevelDebug:
+		return "debug"
+	default:
+		return "develop"
+	}
+}
@@ -2,20 +2,17 @@ package go11y
 
 import (
 	"context"
-	"fmt"
+	"log/slog"
 	"os"
-	"slices"
+	"sync"
 )
 
 // Develop logs a development-only message and adds an event to the span if available.
 // $msg is the message to log
 // $ephemeralArgs are any additional key-value pairs to include in the log and span attributes.
 func (o *Observer) Develop(msg string, ephemeralArgs ...any) {
-	logged := o.log(context.Background(), 3, LevelDevelop, msg, ephemeralArgs...)
-	if logged && o.span != nil {
-		attrs := argsToAttributes(append(o.stableArgs, ephemeralArgs)...)
-		o.span.SetAttributes(attrs...)
-		o.span.AddEvent(msg)
+	if o.log(context.Background(), 3, LevelDevelop, msg, ephemeralArgs...) {
+		o.addSpanEvent(o.currentSpan(), LevelDevelop, msg, ephemeralArgs)
 	}
 }
 
@@ -23,11 +20,8 @@ func (o *Observer) Develop(msg string, ephemeralArgs ...any) {
 // $msg is the message to log
 // $ephemeralArgs are any additional key-value pairs to include in the log and span attributes
 func (o *Observer) Debug(msg string, ephemeralArgs ...any) {
-	logged := o.log(context.Background(), 3, LevelDebug, msg, ephemeralArgs...)
-	if logged && o.span != nil {
-		attrs := argsToAttributes(append(o.stableArgs, ephemeralArgs)...)
-		o.span.SetAttributes(attrs...)
-		o.span.AddEvent(msg)
+	if o.log(context.Background(), 3, LevelDebug, msg, ephemeralArgs...) {
+		o.addSpanEvent(o.currentSpan(), LevelDebug, msg, ephemeralArgs)
 	}
 }
 
@@ -35,11 +29,8 @@ func (o *Observer) Debug(msg string, ephemeralArgs ...any) {
 // $msg is the message to log
 // $ephemeralArgs are any additional key-value pairs to include in the log and span attributes.
 func (o *Observer) Info(msg string, ephemeralArgs ...any) {
-	logged := o.log(context.Background(), 3, LevelInfo, msg, ephemeralArgs...)
-	if logged && o.span != nil {
-		attrs := argsToAttributes(append(o.stableArgs, ephemeralArgs)...)
-		o.span.SetAttributes(attrs...)
-		o.span.AddEvent(msg)
+	if o.log(context.Background(), 3, LevelInfo, msg, ephemeralArgs...) {
+		o.addSpanEvent(o.currentSpan(), LevelInfo, msg, ephemeralArgs)
 	}
 }
 
@@ -47,11 +38,8 @@ func (o *Observer) Info(msg string, ephemeralArgs ...any) {
 // $msg is the message to log
 // $ephemeralArgs are any additional key-value pairs to include in the log and span attributes.
 func (o *Observer) Notice(msg string, ephemeralArgs ...any) {
-	logged := o.log(context.Background(), 3, LevelNotice, msg, ephemeralArgs...)
-	if logged && o.span != nil {
-		attrs := argsToAttributes(append(o.stableArgs, ephemeralArgs)...)
-		o.span.SetAttributes(attrs...)
-		o.span.AddEvent(msg)
+	if o.log(context.Background(), 3, LevelNotice, msg, ephemeralArgs...) {
+		o.addSpanEvent(o.currentSpan(), LevelNotice, msg, ephemeralArgs)
 	}
 }
 
@@ -59,11 +47,8 @@ func (o *Observer) Notice(msg string, ephemeralArgs ...any) {
 // $msg is the message to log
 // $ephemeralArgs are any additional key-value pairs to include in the log and span attributes.
 func (o *Observer) Warning(msg string, ephemeralArgs ...any) {
-	logged := o.log(context.Background(), 3, LevelWarning, msg, ephemeralArgs...)
-	if logged && o.span != nil {
-		attrs := argsToAttributes(append(o.stableArgs, ephemeralArgs)...)
-		o.span.SetAttributes(attrs...)
-		o.span.AddEvent(msg)
+	if o.log(context.Background(), 3, LevelWarning, msg, ephemeralArgs...) {
+		o.addSpanEvent(o.currentSpan(), LevelWarning, msg, ephemeralArgs)
 	}
 }
 
@@ -71,42 +56,37 @@ func (o *Observer) Warning(msg string, ephemeralArgs ...any) {
 // $msg is the message to log
 // $ephemeralArgs are any additional key-value pairs to include in the log and span attributes.
 func (o *Observer) Warn(msg string, ephemeralArgs ...any) {
-	logged := o.log(context.Background(), 3, LevelWarning, msg, ephemeralArgs...)
-	if logged && o.span != nil {
-		attrs := argsToAttributes(append(o.stableArgs, ephemeralArgs)...)
-		o.span.SetAttributes(attrs...)
-		o.span.AddEvent(msg)
+	if o.log(context.Background(), 3, LevelWarning, msg, ephemeralArgs...) {
+		o.addSpanEvent(o.currentSpan(), LevelWarning, msg, ephemeralArgs)
 	}
 }
 
 // Error logs an error message, records the error in the span if available, and sets the severity.
+// Whether it alerts and sets the span's status is looked up in the severity registry, see LookupSeverity.
 // $msg is the message to log
 // $err is the error to record in the span and include in the log
-// $severity is a string representing the severity of the error (e.g., "low", "medium", "high")
+// $severity is a string representing the severity of the error (e.g., SeverityLow, SeverityMedium, SeverityHigh)
 // $ephemeralArgs are any additional key-value pairs to include in the log and span attributes.
 func (o *Observer) Error(msg string, err error, severity string, ephemeralArgs ...any) {
-	logged := o.error(context.Background(), 3, LevelError, msg, append(ephemeralArgs, "error", err.Error(), "severity", severity)...)
-	if logged && o.span != nil {
-		attrs := argsToAttributes(append(o.stableArgs, ephemeralArgs)...)
-		o.span.SetAttributes(attrs...)
-		o.span.RecordError(err)
+	errArgs, behaviour := errorArgs(err, severity, ephemeralArgs)
+	if o.error(context.Background(), 3, LevelError, msg, errArgs...) {
+		o.recordSpanError(o.currentSpan(), msg, err, ephemeralArgs, behaviour)
 	}
 }
 
-// Fatal logs a fatal error message with the highest severity, records the error in the span if available, and then
-// exits the application abruptly.
+// Fatal logs a fatal error message with the highest severity, records the error in the span if available, flushes the
+// Observer's outputs and then exits the application abruptly, with the exit code the severity registry gives the error
+// (1 by default).
 // $msg is the message to log
 // $err is the error to record in the span and include in the log
 // $ephemeralArgs are any additional key-value pairs to include in the log and span attributes.
 func (o *Observer) Fatal(msg string, err error, ephemeralArgs ...any) {
-	logged := o.error(context.Background(), 3, LevelFatal, msg, append(ephemeralArgs, "error", err.Error(), "severity", SeverityHighest)...)
-	if logged && o.span != nil {
-		attrs := argsToAttributes(append(o.stableArgs, ephemeralArgs)...)
-		o.span.SetAttributes(attrs...)
-		o.span.RecordError(err)
+	errArgs, behaviour := errorArgs(err, SeverityHighest, ephemeralArgs)
+	if o.error(context.Background(), 3, LevelFatal, msg, errArgs...) {
+		o.recordSpanError(o.currentSpan(), msg, err, ephemeralArgs, behaviour)
 	}
 
-	os.Exit(1)
+	o.exit(behaviour.exitCode())
 }
 
 // Panic logs a fatal error message with the highest severity, records the error in the span if available, and then
@@ -115,106 +95,183 @@ func (o *Observer) Fatal(msg string, err error, ephemeralArgs ...any) {
 // $err is the error to record in the span and include in the log
 // $ephemeralArgs are any additional key-value pairs to include in the log and span attributes.
 func (o *Observer) Panic(msg string, err error, ephemeralArgs ...any) {
-	logged := o.error(context.Background(), 3, LevelPanic, msg, append(ephemeralArgs, "error", err.Error(), "severity", SeverityHighest)...)
-	if logged && o.span != nil {
-		attrs := argsToAttributes(append(o.stableArgs, ephemeralArgs)...)
-		o.span.SetAttributes(attrs...)
-		o.span.RecordError(err)
+	errArgs, behaviour := errorArgs(err, SeverityHighest, ephemeralArgs)
+	if o.error(context.Background(), 3, LevelPanic, msg, errArgs...) {
+		o.recordSpanError(o.currentSpan(), msg, err, ephemeralArgs, behaviour)
 	}
 
+	o.DumpCrash("panic: " + msg)
 	panic(msg)
 }
 
-// Panic is intended to be called before the observer has been configured.
-// It will log the fatal error to stderr in the JSON format used by go11y and exit the application cleanly
+// PanicContext logs a fatal error message with the highest severity using the Observer in $ctx, or the default
+// Observer (see Default) if there isn't one, records the error in the Observer's span if available, and then panics.
 // $msg is the message to log
 // $err is the error to record in the span and include in the log
 // $ephemeralArgs are any additional key-value pairs to include in the log and span attributes.
-func Panic(msg string, err error, ephemeralArgs ...any) {
-	cfg := &Configuration{
-		logLevel:    LevelInfo,
-		otelURL:     "",
-		strLevel:    "info",
-		databaseURL: "",
-		serviceName: "",
-		trimModules: []string{},
-		trimPaths:   []string{},
-	}
-	ctx := context.Background()
-	_, o, _ := Initialise(ctx, cfg, nil, os.Stderr)
-	ephemeralArgs = append(ephemeralArgs, "error", err.Error(), "severity", SeverityHighest)
-	o.error(ctx, o.skipCallers, LevelPanic, msg, ephemeralArgs...)
+func PanicContext(ctx context.Context, msg string, err error, ephemeralArgs ...any) {
+	o := FromContext(ctx)
+	errArgs, behaviour := errorArgs(err, SeverityHighest, ephemeralArgs)
+	if o.error(ctx, 3, LevelPanic, msg, errArgs...) {
+		o.recordSpanError(o.currentSpan(), msg, err, ephemeralArgs, behaviour)
+	}
 
+	o.DumpCrash("panic: " + msg)
 	panic(msg)
 }
 
-// Fatal is intended to be called before the observer has been configured.
-// It will log the fatal error to stderr in the JSON format used by go11y and exit the application abruptly.
+// FatalContext logs a fatal error message with the highest severity using the Observer in $ctx, or the default
+// Observer (see Default) if there isn't one, records the error in the Observer's span if available, and then exits the
+// application abruptly.
 // $msg is the message to log
 // $err is the error to record in the span and include in the log
-// $exitCode is the code to exit the application with (defaults to 1 if less than 1)
+// $exitCode is the code to exit the application with (defaults to the exit code the severity registry gives $err if
+// less than 1)
 // $ephemeralArgs are any additional key-value pairs to include in the log and span attributes.
-func Fatal(msg string, err error, exitCode int, ephemeralArgs ...any) {
-	cfg := &Configuration{
-		logLevel:    LevelInfo,
-		otelURL:     "",
-		strLevel:    "info",
-		databaseURL: "",
-		serviceName: "",
-		trimModules: []string{},
-		trimPaths:   []string{},
-	}
-	ctx := context.Background()
-	_, o, _ := Initialise(ctx, cfg, nil, os.Stderr)
-	ephemeralArgs = append(ephemeralArgs, "error", err.Error(), "severity", SeverityHighest)
-	o.error(ctx, o.skipCallers, LevelFatal, msg, ephemeralArgs...)
+func FatalContext(ctx context.Context, msg string, err error, exitCode int, ephemeralArgs ...any) {
+	o := FromContext(ctx)
+	errArgs, behaviour := errorArgs(err, SeverityHighest, ephemeralArgs)
+	if o.error(ctx, 3, LevelFatal, msg, errArgs...) {
+		o.recordSpanError(o.currentSpan(), msg, err, ephemeralArgs, behaviour)
+	}
 
 	if exitCode < 1 {
-		exitCode = 1
+		exitCode = behaviour.exitCode()
 	}
 
-	os.Exit(exitCode)
+	o.exit(exitCode)
 }
 
-// Error is intended to be called before the observer has been configured.
-// It will log the error to stderr in the JSON format used by go11y.
+// ErrorContext logs an error message using the Observer in $ctx, or the default Observer (see Default) if there isn't
+// one, and records the error in the Observer's span if available.
 // $msg is the message to log
 // $err is the error to record in the span and include in the log
-// $severity is a string representing the severity of the error (e.g., "low", "medium", "high")
+// $severity is a string representing the severity of the error (e.g., SeverityLow, SeverityMedium, SeverityHigh)
 // $ephemeralArgs are any additional key-value pairs to include in the log and span attributes.
-func Error(msg string, err error, severity string, ephemeralArgs ...any) {
-	cfg := &Configuration{
-		logLevel:    LevelInfo,
-		otelURL:     "",
-		strLevel:    "info",
-		databaseURL: "",
-		serviceName: "",
-		trimModules: []string{},
-		trimPaths:   []string{},
+func ErrorContext(ctx context.Context, msg string, err error, severity string, ephemeralArgs ...any) {
+	o := FromContext(ctx)
+	errArgs, behaviour := errorArgs(err, severity, ephemeralArgs)
+	if o.error(ctx, 3, LevelError, msg, errArgs...) {
+		o.recordSpanError(o.currentSpan(), msg, err, ephemeralArgs, behaviour)
+	}
+}
+
+// Panic logs a fatal error message with the default Observer (see Default) and then panics.
+//
+// Deprecated: Panic used to initialise a new Observer, replacing slog's default logger and the redaction settings of
+// the configured one. Use PanicContext, or Default().Panic.
+func Panic(msg string, err error, ephemeralArgs ...any) {
+	o := Default()
+	errArgs, _ := errorArgs(err, SeverityHighest, ephemeralArgs)
+	o.error(context.Background(), 3, LevelPanic, msg, errArgs...)
+
+	o.DumpCrash("panic: " + msg)
+	panic(msg)
+}
+
+// Fatal logs a fatal error message with the default Observer (see Default) and then exits the application abruptly
+// with $exitCode, or the exit code the severity registry gives $err if it is less than 1.
+//
+// Deprecated: Fatal used to initialise a new Observer, replacing slog's default logger and the redaction settings of
+// the configured one. Use FatalContext, or Default().Fatal.
+func Fatal(msg string, err error, exitCode int, ephemeralArgs ...any) {
+	o := Default()
+	errArgs, behaviour := errorArgs(err, SeverityHighest, ephemeralArgs)
+	o.error(context.Background(), 3, LevelFatal, msg, errArgs...)
+
+	if exitCode < 1 {
+		exitCode = behaviour.exitCode()
 	}
 
-	ctx := context.Background()
-	_, o, _ := Initialise(ctx, cfg, nil, os.Stderr)
-	ephemeralArgs = append(ephemeralArgs, "error", err.Error(), "severity", severity)
-	o.error(ctx, o.skipCallers, LevelError, msg, ephemeralArgs...)
+	o.exit(exitCode)
 }
 
-// DeduplicateArgs removes duplicate keys from a list of key-value pairs.
+// Error logs an error message with the default Observer (see Default).
+//
+// Deprecated: Error used to initialise a new Observer, replacing slog's default logger and the redaction settings of
+// the configured one. Use ErrorContext, or Default().Error.
+func Error(msg string, err error, severity string, ephemeralArgs ...any) {
+	o := Default()
+	errArgs, _ := errorArgs(err, severity, ephemeralArgs)
+	o.error(context.Background(), 3, LevelError, msg, errArgs...)
+}
+
+// DeduplicateArgs removes duplicate keys from a list of key-value pairs, which may include Fields, keeping the first
+// value of each key.
 func DeduplicateArgs(args []any) (deduped []any) {
-	keys := []string{}
-	uniq := []any{}
+	args = argPairs(args)
+	deduped = make([]any, 0, len(args))
 
 	for i := 0; i < len(args); i += 2 {
-		if len(args) >= i+2 {
-			key := fmt.Sprintf("%v", args[i])
-			if slices.Contains(keys, key) {
-				continue
+		// argPairs only returns string keys, so they are compared without being formatted
+		seen := false
+		for j := 0; j < len(deduped); j += 2 {
+			if deduped[j] == args[i] {
+				seen = true
+				break
 			}
+		}
 
-			keys = append(keys, key)
-			uniq = append(uniq, args[i], args[i+1])
+		if !seen {
+			deduped = append(deduped, args[i], args[i+1])
 		}
 	}
 
-	return uniq
+	return deduped
+}
+
+// attrPool holds the buffers records' args are converted into, so logging with args doesn't allocate a buffer
+var attrPool = sync.Pool{
+	New: func() any {
+		attrs := make([]slog.Attr, 0, 16)
+		return &attrs
+	},
+}
+
+// appendDedupedAttrs appends $args to $attrs as slog.Attrs, keeping the first value of each key like DeduplicateArgs
+// but without converting the args to pairs first
+func appendDedupedAttrs(attrs []slog.Attr, args []any) []slog.Attr {
+	for i := 0; i < len(args); i++ {
+		var a slog.Attr
+
+		switch v := args[i].(type) {
+		case slog.Attr:
+			a = v
+		case string:
+			if i+1 == len(args) {
+				a = slog.Any(badKey, v)
+				break
+			}
+
+			a = slog.Any(v, args[i+1])
+			i++
+		default:
+			a = slog.Any(badKey, v)
+		}
+
+		if !hasAttr(attrs, a.Key) {
+			attrs = append(attrs, a)
+		}
+	}
+
+	return attrs
+}
+
+// hasAttr reports whether $attrs has an attr with the key $key
+func hasAttr(attrs []slog.Attr, key string) bool {
+	for _, a := range attrs {
+		if a.Key == key {
+			return true
+		}
+	}
+
+	return false
+}
+
+// exit writes the Observer's crash dump, if it keeps one, and flushes its outputs, so the records buffered by an
+// AsyncWriter or a sink aren't lost, and exits the application with $code
+func (o *Observer) exit(code int) {
+	o.DumpCrash("fatal error")
+	o.flushOutputs()
+	os.Exit(code)
 }
@@ -0,0 +1,115 @@
+package go11y_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	otelSDKTrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/cirruscomms/go11y"
+)
+
+func benchmarkObserver(b *testing.B, initialArgs ...any) (ctx context.Context, o *go11y.Observer) {
+	b.Helper()
+
+	cfg := go11y.NewConfig(go11y.WithLogLevel(go11y.LevelInfo))
+
+	ctx, o, err := go11y.Initialise(context.Background(), cfg, io.Discard, io.Discard, initialArgs...)
+	if err != nil {
+		b.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	return ctx, o
+}
+
+func BenchmarkInfo(b *testing.B) {
+	_, o := benchmarkObserver(b, "service", "bench", go11y.FieldRequestID, "4c2e9a1f")
+
+	b.ReportAllocs()
+	for b.Loop() {
+		o.Info("request handled")
+	}
+}
+
+func BenchmarkInfoWithArgs(b *testing.B) {
+	_, o := benchmarkObserver(b, "service", "bench", go11y.FieldRequestID, "4c2e9a1f")
+
+	b.ReportAllocs()
+	for b.Loop() {
+		o.Info("request handled", "status", 200, "path", "/api/v1/things", "bytes", 512, "cached", true)
+	}
+}
+
+func BenchmarkInfoWithFields(b *testing.B) {
+	_, o := benchmarkObserver(b, "service", "bench", go11y.FieldRequestID, "4c2e9a1f")
+
+	b.ReportAllocs()
+	for b.Loop() {
+		o.Info("request handled",
+			go11y.Int("status", 200),
+			go11y.String("path", "/api/v1/things"),
+			go11y.Int("bytes", 512),
+			go11y.Bool("cached", true),
+		)
+	}
+}
+
+func BenchmarkInfoInSpan(b *testing.B) {
+	ctx, _ := benchmarkObserver(b, "service", "bench", go11y.FieldRequestID, "4c2e9a1f")
+
+	tp := otelSDKTrace.NewTracerProvider()
+	_, o, err := go11y.Span(ctx, tp.Tracer("bench"), "BenchmarkInfoInSpan", go11y.SpanKindInternal)
+	if err != nil {
+		b.Fatalf("failed to start span: %v", err)
+	}
+	defer o.End()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		o.Info("request handled", "status", 200, "path", "/api/v1/things")
+	}
+}
+
+func BenchmarkDisabledLevel(b *testing.B) {
+	_, o := benchmarkObserver(b)
+
+	b.ReportAllocs()
+	for b.Loop() {
+		o.Debug("not logged", "status", 200)
+	}
+}
+
+func BenchmarkError(b *testing.B) {
+	_, o := benchmarkObserver(b, "service", "bench")
+	err := errors.New("failed")
+
+	b.ReportAllocs()
+	for b.Loop() {
+		o.Error("request failed", err, go11y.SeverityLow, "status", 500)
+	}
+}
+
+func BenchmarkDeduplicateArgs(b *testing.B) {
+	args := []any{"a", 1, "b", 2, "c", 3, "d", 4, "a", 5, "e", 6, "f", 7, "b", 8}
+
+	b.ReportAllocs()
+	for b.Loop() {
+		go11y.DeduplicateArgs(args)
+	}
+}
+
+func BenchmarkInfoSourceOff(b *testing.B) {
+	cfg := go11y.NewConfig(go11y.WithLogLevel(go11y.LevelInfo), go11y.WithLogSource(go11y.SourceOff))
+
+	_, o, err := go11y.Initialise(context.Background(), cfg, io.Discard, io.Discard, "service", "bench")
+	if err != nil {
+		b.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	b.ReportAllocs()
+	for b.Loop() {
+		o.Info("request handled", "status", 200, "path", "/api/v1/things")
+	}
+}
@@ -0,0 +1,67 @@
+package go11y
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+)
+
+// Debugf logs a debug message formatted from $template and $args (as fmt.Sprintf would) and adds an event to the span
+// if available. The template and args are also logged as the msg_template and msg_args fields, so records can be
+// aggregated by their template whatever the args.
+func (o *Observer) Debugf(template string, args ...any) {
+	o.logf(LevelDebug, template, args)
+}
+
+// Infof logs an informational message formatted from $template and $args and adds an event to the span if available.
+// See Debugf for the fields logged.
+func (o *Observer) Infof(template string, args ...any) {
+	o.logf(LevelInfo, template, args)
+}
+
+// Noticef logs a notice message formatted from $template and $args and adds an event to the span if available.
+// See Debugf for the fields logged.
+func (o *Observer) Noticef(template string, args ...any) {
+	o.logf(LevelNotice, template, args)
+}
+
+// Warningf logs a warning message formatted from $template and $args and adds an event to the span if available.
+// See Debugf for the fields logged.
+func (o *Observer) Warningf(template string, args ...any) {
+	o.logf(LevelWarning, template, args)
+}
+
+// Errorf logs an error message formatted from $template and $args, records $err in the span if available, and sets
+// the severity. See Debugf for the fields logged.
+func (o *Observer) Errorf(err error, severity string, template string, args ...any) {
+	msg, fields := formatArgs(template, args)
+
+	errArgs, behaviour := errorArgs(err, severity, slices.Clone(fields))
+	if o.error(context.Background(), 3, LevelError, msg, errArgs...) {
+		o.recordSpanError(o.currentSpan(), msg, err, fields, behaviour)
+	}
+}
+
+// logf logs the message formatted from $template and $args at $level, for the caller of its caller
+func (o *Observer) logf(level slog.Level, template string, args []any) {
+	msg, fields := formatArgs(template, args)
+
+	if o.log(context.Background(), 4, level, msg, fields...) {
+		o.addSpanEvent(o.currentSpan(), level, msg, fields)
+	}
+}
+
+// formatArgs formats $template with $args, returning the message and the msg_template and msg_args fields.
+// Errors in $args are logged as their message, as they would otherwise be logged as an empty object.
+func formatArgs(template string, args []any) (msg string, fields []any) {
+	msgArgs := make([]any, len(args))
+	for i, a := range args {
+		if err, ok := a.(error); ok {
+			a = err.Error()
+		}
+		msgArgs[i] = a
+	}
+
+	return fmt.Sprintf(template, args...), []any{FieldMsgTemplate, template, FieldMsgArgs, msgArgs}
+}
@@ -0,0 +1,55 @@
+package go11y_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/cirruscomms/go11y"
+)
+
+func TestFormattedLogging(t *testing.T) {
+	out, errOut := new(bytes.Buffer), new(bytes.Buffer)
+	cfg := go11y.NewConfig(go11y.WithLogLevel(go11y.LevelInfo))
+
+	_, o, err := go11y.Initialise(context.Background(), cfg, out, errOut)
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+	out.Reset()
+
+	o.Debugf("user %s not logged", "alice")
+	o.Infof("user %s not found after %d attempts", "bob", 3)
+	o.Errorf(errors.New("timeout"), go11y.SeverityLow, "lookup of %s failed: %v", "bob", errors.New("timeout"))
+
+	records := decodeRecords(t, out)
+	if len(records) != 1 {
+		t.Fatalf("expected 1 record in the output, got %d: %s", len(records), out.String())
+	}
+
+	info := records[0]
+	if info["msg"] != "user bob not found after 3 attempts" {
+		t.Errorf("expected the message to be formatted, got %v", info["msg"])
+	}
+	if info[go11y.FieldMsgTemplate] != "user %s not found after %d attempts" {
+		t.Errorf("expected the template to be logged, got %v", info[go11y.FieldMsgTemplate])
+	}
+	if args, _ := info[go11y.FieldMsgArgs].([]any); len(args) != 2 || args[0] != "bob" || args[1] != float64(3) {
+		t.Errorf("expected the args to be logged, got %v", info[go11y.FieldMsgArgs])
+	}
+
+	source, _ := info["source"].(map[string]any)
+	if source["function"] != "github.com/cirruscomms/go11y_test.TestFormattedLogging" {
+		t.Errorf("expected the source to be the caller, got %v", info["source"])
+	}
+
+	errRecords := decodeRecords(t, errOut)
+	last := errRecords[len(errRecords)-1]
+	if last["msg"] != "lookup of bob failed: timeout" || last["error"] != "timeout" {
+		t.Errorf("expected the formatted error in the error output, got %v", last)
+	}
+	if args, _ := last[go11y.FieldMsgArgs].([]any); len(args) != 2 || args[1] != "timeout" {
+		t.Errorf("expected errors in the args to be logged as their message, got %v", last[go11y.FieldMsgArgs])
+	}
+}
@@ -0,0 +1,76 @@
+package go11y
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// LogRecords is the metric for the number of log records written, by level
+var LogRecords = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "go11y_log_records_total",
+	Help: "Number of log records written by go11y",
+}, []string{"level"})
+
+// LogRecordsByComponent is the metric for the number of log records written, by level and the component stable arg of
+// the Observer that wrote them ("" if it has none), so error rates can be alerted on by component
+var LogRecordsByComponent = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "go11y_log_records_by_component_total",
+	Help: "Number of log records written by go11y, by the component stable arg",
+}, []string{"level", "component"})
+
+// LogRecordsDropped is the metric for the number of log records that were not written, by level and reason
+var LogRecordsDropped = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "go11y_log_records_dropped_total",
+	Help: "Number of log records go11y failed to write or deliberately discarded",
+}, []string{"level", "reason"})
+
+// LogHandlerErrors is the metric for the number of errors returned by the slog handlers
+var LogHandlerErrors = prometheus.NewCounter(prometheus.CounterOpts{
+	Name: "go11y_log_handler_errors_total",
+	Help: "Number of errors returned by go11y's log handlers",
+})
+
+// Redactions is the metric for the number of values redacted by go11y
+var Redactions = prometheus.NewCounter(prometheus.CounterOpts{
+	Name: "go11y_redactions_total",
+	Help: "Number of values redacted by go11y",
+})
+
+// DropReasonHandlerError is the reason label used when a record is dropped because the handler returned an error
+const DropReasonHandlerError = "handler_error"
+
+var registerPipelineMetricsOnce sync.Once
+
+// registerPipelineMetrics registers the logging pipeline metrics with the default Prometheus registerer so they are
+// published on the /internal/metrics endpoint alongside the request metrics.
+func registerPipelineMetrics() {
+	registerPipelineMetricsOnce.Do(func() {
+		registerCollectors(LogRecords, LogRecordsByComponent, LogRecordsDropped, LogHandlerErrors, Redactions, PIIRedactions,
+			LogCallsitesOverBudget)
+	})
+}
+
+// registerCollectors registers $collectors with the default Prometheus registerer, tolerating collectors that have
+// already been registered (e.g. by the calling service).
+func registerCollectors(collectors ...prometheus.Collector) {
+	for _, c := range collectors {
+		err := prometheus.Register(c)
+		if err != nil && !errors.As(err, &prometheus.AlreadyRegisteredError{}) {
+			panic(err)
+		}
+	}
+}
+
+// recordHandled updates the pipeline metrics for a record of $component passed to a handler.
+func recordHandled(levelName, component string, err error) {
+	if err != nil {
+		LogHandlerErrors.Inc()
+		LogRecordsDropped.WithLabelValues(levelName, DropReasonHandlerError).Inc()
+		return
+	}
+
+	LogRecords.WithLabelValues(levelName).Inc()
+	LogRecordsByComponent.WithLabelValues(levelName, component).Inc()
+}
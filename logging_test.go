@@ -3,17 +3,26 @@ package go11y_test
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"io"
+	"reflect"
+	"strings"
 	"testing"
 
-	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 
 	"github.com/cirruscomms/go11y"
+	"github.com/cirruscomms/go11y/go11ytest"
 )
 
 func TestLoggingContext(t *testing.T) {
 	t.Setenv("ENV", "test")
 	t.Setenv("LOG_LEVEL", "develop")
+	t.Setenv("SERVICE_VERSION", "v1.2.3")
+	t.Setenv("SERVICE_INSTANCE_ID", "api-7d9f-x2")
+	t.Setenv("GIT_SHA", "4f1c2ab9e07d")
+	t.Setenv("BUILD_TIME", "2026-01-02T03:04:05Z")
 
 	bufOut := new(bytes.Buffer)
 	bufErr := new(bytes.Buffer)
@@ -32,14 +41,14 @@ func TestLoggingContext(t *testing.T) {
 	}()
 
 	o.Error("Test Logging Context", errors.New("TestLoggingContext"), go11y.SeverityHighest, "fatal", 1)
-	ctx, o, _ = go11y.Extend(ctx, nil, "", go11y.FieldRequestID, uuid.New())
+	ctx, o, _ = go11y.Extend(ctx, nil, "", go11y.FieldRequestID, "4c2e9a1f-7d3b-4f6e-8a5c-1b9d0e2f3a4b")
 	o.Info("TestLoggingContext", nil, "info", 1)
 	ctx = AddFieldsToLoggerInContext(t, ctx, go11y.FieldRequestMethod, "GET", go11y.FieldRequestPath, "/api/v1/test")
 	_, o, _ = go11y.Get(ctx)
 	o.Info("TestLoggingContext", nil, "info", 2)
 
-	// @TODO: read the buffer and check the output matches expected log format
-	// and content
+	go11ytest.AssertGolden(t, "testdata/logging_context_out.golden", bufOut.Bytes())
+	go11ytest.AssertGolden(t, "testdata/logging_context_err.golden", bufErr.Bytes())
 }
 
 func AddFieldsToLoggerInContext(t *testing.T, ctx context.Context, args ...any) (modCtx context.Context) {
@@ -127,3 +136,317 @@ func TestDeduplication(t *testing.T) {
 		})
 	}
 }
+
+func TestAttrRedaction(t *testing.T) {
+	t.Setenv("ENV", "test")
+
+	testCases := map[string]struct {
+		options  []any
+		expected map[string]string
+	}{
+		"redaction enabled by default": {
+			options: []any{},
+			expected: map[string]string{
+				"password": "Dum[22]025",
+				"api":      "Ano[19]025",
+				"name":     "fester",
+			},
+		},
+		"redaction disabled": {
+			options: []any{go11y.WithAttrRedaction(false)},
+			expected: map[string]string{
+				"password": "DummyPasswordForTesting#2025",
+				"api":      "Ano[19]025",
+				"name":     "fester",
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			bufOut := new(bytes.Buffer)
+
+			cfg := go11y.CreateConfig(go11y.LevelInfo, "", "", "", []string{}, []string{})
+
+			_, o, err := go11y.Initialise(context.Background(), cfg, bufOut, bufOut, tc.options...)
+			if err != nil {
+				t.Fatalf("failed to initialise observer: %v", err)
+			}
+
+			o.Info(
+				"TestAttrRedaction",
+				"password", "DummyPasswordForTesting#2025",
+				"api", go11y.Secret("AnotherDummyPassword#2025"),
+				"name", "fester",
+			)
+
+			record := map[string]any{}
+			if err := json.Unmarshal(bufOut.Bytes(), &record); err != nil {
+				t.Fatalf("failed to unmarshal log record: %v", err)
+			}
+
+			for key, expected := range tc.expected {
+				if record[key] != expected {
+					t.Errorf("expected %s to be %q, got %q", key, expected, record[key])
+				}
+			}
+		})
+	}
+}
+
+func TestWith(t *testing.T) {
+	t.Setenv("ENV", "test")
+
+	bufOut := new(bytes.Buffer)
+
+	cfg := go11y.CreateConfig(go11y.LevelInfo, "", "", "", []string{}, []string{})
+
+	_, parent, err := go11y.Initialise(context.Background(), cfg, bufOut, bufOut, "service", "test")
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	child := parent.With("worker", 1)
+
+	child.Info("from child")
+	parent.Info("from parent")
+
+	dec := json.NewDecoder(bufOut)
+
+	childRecord := map[string]any{}
+	if err := dec.Decode(&childRecord); err != nil {
+		t.Fatalf("failed to decode child record: %v", err)
+	}
+
+	parentRecord := map[string]any{}
+	if err := dec.Decode(&parentRecord); err != nil {
+		t.Fatalf("failed to decode parent record: %v", err)
+	}
+
+	if childRecord["worker"] != float64(1) || childRecord["service"] != "test" {
+		t.Errorf("expected child record to contain worker and service fields, got %v", childRecord)
+	}
+
+	if _, ok := parentRecord["worker"]; ok || parentRecord["service"] != "test" {
+		t.Errorf("expected parent record to contain only the service field, got %v", parentRecord)
+	}
+}
+
+func TestChildContext(t *testing.T) {
+	buf := new(bytes.Buffer)
+
+	cfg := go11y.CreateConfig(go11y.LevelInfo, "", "", "", []string{}, []string{})
+
+	ctx, parent, err := go11y.Initialise(context.Background(), cfg, buf, buf, "service", "test")
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	childCtx, child, err := go11y.ChildContext(ctx, "request_id", "r-1")
+	if err != nil {
+		t.Fatalf("failed to create child context: %v", err)
+	}
+
+	if go11y.FromContext(childCtx) != child || go11y.FromContext(ctx) != parent {
+		t.Fatalf("expected the child to be bound to the returned context only")
+	}
+
+	// changes made through the child's context must not reach the parent
+	_, _, _ = go11y.Extend(childCtx, "user_id", 42)
+	go11y.FromContext(childCtx).Info("from child")
+	parent.Info("from parent")
+
+	childCtx = go11y.Reset(childCtx)
+	parent.Info("after reset")
+
+	records := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(records) != 3 {
+		t.Fatalf("expected 3 records, got %s", buf.String())
+	}
+
+	for _, expected := range []string{`"service":"test"`, `"request_id":"r-1"`, `"user_id":42`} {
+		if !strings.Contains(records[0], expected) {
+			t.Errorf("expected the child's record to contain %s, got %s", expected, records[0])
+		}
+	}
+
+	for _, record := range records[1:] {
+		if !strings.Contains(record, `"service":"test"`) || strings.Contains(record, "request_id") ||
+			strings.Contains(record, "user_id") {
+			t.Errorf("expected the parent's record to contain only the service field, got %s", record)
+		}
+	}
+
+	if _, _, err := go11y.ChildContext(context.Background()); err == nil {
+		t.Errorf("expected an error without an Observer in the context")
+	}
+}
+
+func TestAddArgs(t *testing.T) {
+	cfg := go11y.CreateConfig(go11y.LevelInfo, "", "", "", []string{}, []string{})
+
+	testCases := map[string]struct {
+		stable   []any
+		added    []any
+		expected []any
+	}{
+		"first seen order": {
+			stable:   []any{"c", 1, "a", 2},
+			added:    []any{"b", 3},
+			expected: []any{"c", 1, "a", 2, "b", 3},
+		},
+		"latest value wins in place": {
+			stable:   []any{"a", 1, "b", 2},
+			added:    []any{"a", 3},
+			expected: []any{"a", 3, "b", 2},
+		},
+		"maps merge": {
+			stable:   []any{"http", go11y.Fields{"method": "GET", "path": "/a"}},
+			added:    []any{"http", go11y.Fields{"path": "/b", "status": 200}},
+			expected: []any{"http", go11y.Fields{"method": "GET", "path": "/b", "status": 200}},
+		},
+		"dotted keys merge into maps": {
+			stable:   []any{"http", go11y.Fields{"method": "GET"}},
+			added:    []any{"http.status", 200},
+			expected: []any{"http", go11y.Fields{"method": "GET", "status": 200}},
+		},
+		"dotted keys without a map stay flat": {
+			stable:   []any{"http", "yes"},
+			added:    []any{"http.method", "GET"},
+			expected: []any{"http", "yes", "http.method", "GET"},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, o, err := go11y.Initialise(context.Background(), cfg, io.Discard, io.Discard, tc.stable...)
+			if err != nil {
+				t.Fatalf("failed to initialise observer: %v", err)
+			}
+
+			// run it twice to catch map iteration order
+			for range 2 {
+				if got := o.AddArgs(tc.added...); !reflect.DeepEqual(got, tc.expected) {
+					t.Errorf("expected %v, got %v", tc.expected, got)
+				}
+			}
+		})
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestPipelineMetrics(t *testing.T) {
+	cfg := go11y.CreateConfig(go11y.LevelInfo, "", "", "", []string{}, []string{})
+
+	_, o, err := go11y.Initialise(context.Background(), cfg, io.Discard, failingWriter{})
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	records := testutil.ToFloat64(go11y.LogRecords.WithLabelValues("info"))
+	dropped := testutil.ToFloat64(go11y.LogRecordsDropped.WithLabelValues("error", go11y.DropReasonHandlerError))
+	handlerErrors := testutil.ToFloat64(go11y.LogHandlerErrors)
+	redactions := testutil.ToFloat64(go11y.Redactions)
+
+	o.Info("TestPipelineMetrics", "token", "DummyPasswordForTesting#2025")
+	o.Error("TestPipelineMetrics", errors.New("TestPipelineMetrics"), go11y.SeverityLow)
+
+	if got := testutil.ToFloat64(go11y.LogRecords.WithLabelValues("info")) - records; got != 1 {
+		t.Errorf("expected 1 info record, got %v", got)
+	}
+
+	if got := testutil.ToFloat64(go11y.LogRecordsDropped.WithLabelValues("error", go11y.DropReasonHandlerError)) - dropped; got != 1 {
+		t.Errorf("expected 1 dropped error record, got %v", got)
+	}
+
+	if got := testutil.ToFloat64(go11y.LogHandlerErrors) - handlerErrors; got != 1 {
+		t.Errorf("expected 1 handler error, got %v", got)
+	}
+
+	if got := testutil.ToFloat64(go11y.Redactions) - redactions; got != 1 {
+		t.Errorf("expected 1 redaction, got %v", got)
+	}
+}
+
+func TestComponentMetrics(t *testing.T) {
+	cfg := go11y.CreateConfig(go11y.LevelInfo, "", "", "", []string{}, []string{})
+
+	_, o, err := go11y.Initialise(context.Background(), cfg, io.Discard, io.Discard)
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	billing := o.With(go11y.FieldComponent, "billing")
+
+	warnings := testutil.ToFloat64(go11y.LogRecordsByComponent.WithLabelValues("warning", "billing"))
+	errs := testutil.ToFloat64(go11y.LogRecordsByComponent.WithLabelValues("error", "billing"))
+	unlabelled := testutil.ToFloat64(go11y.LogRecordsByComponent.WithLabelValues("warning", ""))
+
+	billing.Warning("TestComponentMetrics")
+	billing.Warning("TestComponentMetrics")
+	billing.Error("TestComponentMetrics", errors.New("TestComponentMetrics"), go11y.SeverityLow)
+	o.Warning("TestComponentMetrics")
+
+	if got := testutil.ToFloat64(go11y.LogRecordsByComponent.WithLabelValues("warning", "billing")) - warnings; got != 2 {
+		t.Errorf("expected 2 billing warnings, got %v", got)
+	}
+
+	if got := testutil.ToFloat64(go11y.LogRecordsByComponent.WithLabelValues("error", "billing")) - errs; got != 1 {
+		t.Errorf("expected 1 billing error, got %v", got)
+	}
+
+	if got := testutil.ToFloat64(go11y.LogRecordsByComponent.WithLabelValues("warning", "")) - unlabelled; got != 1 {
+		t.Errorf("expected 1 warning without a component, got %v", got)
+	}
+}
+
+func TestSinks(t *testing.T) {
+	t.Setenv("ENV", "test")
+
+	bufOut := new(bytes.Buffer)
+	bufText := new(bytes.Buffer)
+	bufErrors := new(bytes.Buffer)
+
+	cfg := go11y.CreateConfig(go11y.LevelDebug, "", "", "", []string{}, []string{})
+
+	_, o, err := go11y.Initialise(context.Background(), cfg, bufOut, bufOut,
+		go11y.WithSinks(
+			go11y.Sink{Writer: bufText, MinLevel: go11y.LevelInfo, Format: go11y.SinkFormatText},
+			go11y.Sink{Writer: bufErrors, MinLevel: go11y.LevelError},
+		),
+		"service", "test",
+	)
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	o.Debug("debug message")
+	o.Info("info message")
+	o.Error("error message", errors.New("TestSinks"), go11y.SeverityLow)
+
+	if got := bytes.Count(bufOut.Bytes(), []byte("\n")); got != 6 {
+		t.Errorf("expected 5 records in the main output (including the 2 initialisation records), got %d", got)
+	}
+
+	if got := bytes.Count(bufText.Bytes(), []byte("\n")); got != 2 {
+		t.Errorf("expected 2 records in the text sink, got %d: %s", got, bufText.String())
+	}
+
+	if !bytes.Contains(bufText.Bytes(), []byte(`msg="info message"`)) || !bytes.Contains(bufText.Bytes(), []byte("service=test")) {
+		t.Errorf("expected text formatted info record with stable args, got %s", bufText.String())
+	}
+
+	record := map[string]any{}
+	if err := json.Unmarshal(bufErrors.Bytes(), &record); err != nil {
+		t.Fatalf("expected a single JSON record in the error sink: %v", err)
+	}
+
+	if record["msg"] != "error message" || record["level"] != "ERR" {
+		t.Errorf("unexpected record in the error sink: %v", record)
+	}
+}
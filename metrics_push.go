@@ -0,0 +1,385 @@
+package go11y
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+	dto "github.com/prometheus/client_model/go"
+	otlpMetricsService "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	otlpCommon "go.opentelemetry.io/proto/otlp/common/v1"
+	otlpMetrics "go.opentelemetry.io/proto/otlp/metrics/v1"
+	otlpResource "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// MetricsPushMode is where a MetricsPusher pushes metrics to
+type MetricsPushMode string
+
+const (
+	// MetricsPushPushgateway pushes metrics to a Prometheus Pushgateway, replacing those of the job's previous push
+	MetricsPushPushgateway MetricsPushMode = "pushgateway"
+	// MetricsPushOTLP pushes metrics to an OTLP/HTTP endpoint, such as an OTel collector, as cumulative data points
+	MetricsPushOTLP MetricsPushMode = "otlp"
+)
+
+// ExportSignalMetrics is the signal label used for metric pushes
+const ExportSignalMetrics = "metrics"
+
+// DefaultMetricsPushTimeout is the time a single push of metrics may take
+const DefaultMetricsPushTimeout = 10 * time.Second
+
+// MetricsPusherOpts are the options used to create a MetricsPusher
+type MetricsPusherOpts struct {
+	Mode     MetricsPushMode     // optional - where to push to, defaults to MetricsPushPushgateway
+	URL      string              // required - the Pushgateway URL, or the OTLP/HTTP URL, "/v1/metrics" if it has no path
+	Job      string              // optional - the job the metrics are grouped under, defaults to the service name
+	Grouping map[string]string   // optional - extra Pushgateway grouping labels, or OTLP resource attributes
+	Interval time.Duration       // optional - how often Run pushes, defaults to only pushing when it stops
+	Timeout  time.Duration       // optional - the time a push may take, defaults to DefaultMetricsPushTimeout
+	Gatherer prometheus.Gatherer // optional - the metrics to push, defaults to those served by MetricsHandler
+	Client   *http.Client        // optional - the client to push with, defaults to http.DefaultClient
+}
+
+// MetricsPusher pushes the collected metrics of CLI tools, migrations and cron jobs, which exit before they could be
+// scraped, to a Prometheus Pushgateway or an OTLP endpoint: when it is closed, and every interval while Run is running.
+type MetricsPusher struct {
+	ctx      context.Context
+	o        *Observer
+	mode     MetricsPushMode
+	url      string
+	job      string
+	grouping map[string]string
+	interval time.Duration
+	timeout  time.Duration
+	gatherer prometheus.Gatherer
+	client   *http.Client
+	started  time.Time
+	mu       sync.Mutex // serialises pushes, so an interval push and the final push don't race
+}
+
+// NewMetricsPusher creates a MetricsPusher logging with the Observer in $ctxWithObserver. Defer its Close so the
+// metrics are pushed when the program finishes, and call Run in a goroutine to also push them every interval.
+// If the Observer cannot be retrieved from the provided context or the options are invalid, an error is returned.
+func NewMetricsPusher(ctxWithObserver context.Context, opts MetricsPusherOpts) (pusher *MetricsPusher, fault error) {
+	ctx, o, err := Get(ctxWithObserver)
+	if err != nil {
+		return nil, fmt.Errorf("could not get go11y observer from context: %w", err)
+	}
+
+	registerExportMetricsOnce.Do(func() {
+		registerCollectors(ExportFailures, ExportSpansDropped, ExportDegraded)
+	})
+
+	if opts.Mode == "" {
+		opts.Mode = MetricsPushPushgateway
+	}
+	if opts.Mode != MetricsPushPushgateway && opts.Mode != MetricsPushOTLP {
+		return nil, fmt.Errorf("could not create metrics pusher: unknown mode %q", opts.Mode)
+	}
+
+	u, err := url.Parse(opts.URL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("could not create metrics pusher: invalid url %q", opts.URL)
+	}
+	if opts.Mode == MetricsPushOTLP && (u.Path == "" || u.Path == "/") {
+		u.Path = "/v1/metrics"
+	}
+
+	if opts.Job == "" && o.cfg != nil {
+		opts.Job = o.cfg.ServiceName()
+	}
+	if opts.Job == "" {
+		return nil, errors.New("could not create metrics pusher: no job name or service name")
+	}
+
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultMetricsPushTimeout
+	}
+	if opts.Gatherer == nil {
+		opts.Gatherer = serviceGatherer{prometheus.DefaultGatherer}
+	}
+	if opts.Client == nil {
+		opts.Client = http.DefaultClient
+	}
+
+	return &MetricsPusher{
+		ctx:      ctx,
+		o:        o,
+		mode:     opts.Mode,
+		url:      u.String(),
+		job:      opts.Job,
+		grouping: opts.Grouping,
+		interval: opts.Interval,
+		timeout:  opts.Timeout,
+		gatherer: opts.Gatherer,
+		client:   opts.Client,
+		started:  o.clock.Now(),
+	}, nil
+}
+
+// Run pushes the metrics every interval until $ctx is cancelled. Failed pushes are logged, counted in the
+// ExportFailures metric and retried at the next interval. If no interval was set, Run only waits for $ctx.
+func (p *MetricsPusher) Run(ctx context.Context) {
+	if p.interval <= 0 {
+		<-ctx.Done()
+		return
+	}
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_ = p.Push(ctx)
+		}
+	}
+}
+
+// Close pushes the metrics a final time. It implements io.Closer so it can be deferred alongside Observer.Close.
+func (p *MetricsPusher) Close() (fault error) {
+	return p.Push(context.Background())
+}
+
+// Push pushes the metrics once, logging and returning an error if it fails.
+func (p *MetricsPusher) Push(ctx context.Context) (fault error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	var err error
+	switch p.mode {
+	case MetricsPushOTLP:
+		err = p.pushOTLP(ctx)
+	default:
+		err = p.pushGateway(ctx)
+	}
+
+	if err != nil {
+		ExportFailures.WithLabelValues(ExportSignalMetrics, ExportFailureExporter).Inc()
+		err = fmt.Errorf("could not push metrics: %w", err)
+		p.o.log(p.ctx, 3, LevelWarning, err.Error(), "mode", string(p.mode), "job", p.job)
+		return err
+	}
+
+	p.o.log(p.ctx, 3, LevelDebug, "metrics pushed", "mode", string(p.mode), "job", p.job)
+
+	return nil
+}
+
+// pushGateway replaces the metrics of the job's group on the Pushgateway with the gathered metrics
+func (p *MetricsPusher) pushGateway(ctx context.Context) (fault error) {
+	pusher := push.New(p.url, p.job).Gatherer(p.gatherer).Client(p.client)
+	for k, v := range p.grouping {
+		pusher = pusher.Grouping(k, v)
+	}
+
+	return pusher.PushContext(ctx)
+}
+
+// pushOTLP posts the gathered metrics to the OTLP/HTTP endpoint as a protobuf ExportMetricsServiceRequest
+func (p *MetricsPusher) pushOTLP(ctx context.Context) (fault error) {
+	families, err := p.gatherer.Gather()
+	if err != nil {
+		return fmt.Errorf("could not gather metrics: %w", err)
+	}
+
+	body, err := proto.Marshal(p.otlpRequest(families, p.o.clock.Now()))
+	if err != nil {
+		return fmt.Errorf("could not encode metrics: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("could not create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+
+	return nil
+}
+
+// otlpRequest converts the gathered metric $families to an OTLP request, as cumulative data points from the time the
+// pusher was created to $now. The job, the service version and instance ID, and the grouping labels become attributes
+// of the request's resource.
+func (p *MetricsPusher) otlpRequest(families []*dto.MetricFamily, now time.Time) *otlpMetricsService.ExportMetricsServiceRequest {
+	resource := []*otlpCommon.KeyValue{otlpString("service.name", p.job)}
+	if hostname, err := os.Hostname(); err == nil {
+		resource = append(resource, otlpString("host.name", hostname))
+	}
+	for _, kv := range configServiceIdentity(p.o.cfg).resourceAttributes()[1:] {
+		resource = append(resource, otlpString(string(kv.Key), kv.Value.AsString()))
+	}
+	for k, v := range p.grouping {
+		resource = append(resource, otlpString(k, v))
+	}
+
+	start := uint64(p.started.UnixNano())
+	ts := uint64(now.UnixNano())
+
+	metrics := make([]*otlpMetrics.Metric, 0, len(families))
+	for _, mf := range families {
+		if m := otlpMetric(mf, start, ts); m != nil {
+			metrics = append(metrics, m)
+		}
+	}
+
+	return &otlpMetricsService.ExportMetricsServiceRequest{
+		ResourceMetrics: []*otlpMetrics.ResourceMetrics{{
+			Resource: &otlpResource.Resource{Attributes: resource},
+			ScopeMetrics: []*otlpMetrics.ScopeMetrics{{
+				Scope:   &otlpCommon.InstrumentationScope{Name: "github.com/cirruscomms/go11y"},
+				Metrics: metrics,
+			}},
+		}},
+	}
+}
+
+// otlpMetric converts the metric family $mf to an OTLP metric with data points from $start to $ts, or returns nil if
+// its type isn't supported
+func otlpMetric(mf *dto.MetricFamily, start, ts uint64) *otlpMetrics.Metric {
+	m := &otlpMetrics.Metric{Name: mf.GetName(), Description: mf.GetHelp()}
+
+	switch mf.GetType() {
+	case dto.MetricType_COUNTER:
+		points := make([]*otlpMetrics.NumberDataPoint, 0, len(mf.GetMetric()))
+		for _, pm := range mf.GetMetric() {
+			points = append(points, otlpNumberPoint(pm, pm.GetCounter().GetValue(), start, ts))
+		}
+		m.Data = &otlpMetrics.Metric_Sum{Sum: &otlpMetrics.Sum{
+			DataPoints:             points,
+			AggregationTemporality: otlpMetrics.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+			IsMonotonic:            true,
+		}}
+	case dto.MetricType_GAUGE, dto.MetricType_UNTYPED:
+		points := make([]*otlpMetrics.NumberDataPoint, 0, len(mf.GetMetric()))
+		for _, pm := range mf.GetMetric() {
+			value := pm.GetGauge().GetValue()
+			if mf.GetType() == dto.MetricType_UNTYPED {
+				value = pm.GetUntyped().GetValue()
+			}
+			points = append(points, otlpNumberPoint(pm, value, start, ts))
+		}
+		m.Data = &otlpMetrics.Metric_Gauge{Gauge: &otlpMetrics.Gauge{DataPoints: points}}
+	case dto.MetricType_HISTOGRAM:
+		points := make([]*otlpMetrics.HistogramDataPoint, 0, len(mf.GetMetric()))
+		for _, pm := range mf.GetMetric() {
+			points = append(points, otlpHistogramPoint(pm, start, ts))
+		}
+		m.Data = &otlpMetrics.Metric_Histogram{Histogram: &otlpMetrics.Histogram{
+			DataPoints:             points,
+			AggregationTemporality: otlpMetrics.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+		}}
+	case dto.MetricType_SUMMARY:
+		points := make([]*otlpMetrics.SummaryDataPoint, 0, len(mf.GetMetric()))
+		for _, pm := range mf.GetMetric() {
+			s := pm.GetSummary()
+			quantiles := make([]*otlpMetrics.SummaryDataPoint_ValueAtQuantile, 0, len(s.GetQuantile()))
+			for _, q := range s.GetQuantile() {
+				quantiles = append(quantiles, &otlpMetrics.SummaryDataPoint_ValueAtQuantile{
+					Quantile: q.GetQuantile(),
+					Value:    q.GetValue(),
+				})
+			}
+			points = append(points, &otlpMetrics.SummaryDataPoint{
+				Attributes:        otlpLabels(pm),
+				StartTimeUnixNano: start,
+				TimeUnixNano:      ts,
+				Count:             s.GetSampleCount(),
+				Sum:               s.GetSampleSum(),
+				QuantileValues:    quantiles,
+			})
+		}
+		m.Data = &otlpMetrics.Metric_Summary{Summary: &otlpMetrics.Summary{DataPoints: points}}
+	default:
+		return nil
+	}
+
+	return m
+}
+
+// otlpNumberPoint returns the OTLP data point of the counter, gauge or untyped metric $pm with $value
+func otlpNumberPoint(pm *dto.Metric, value float64, start, ts uint64) *otlpMetrics.NumberDataPoint {
+	return &otlpMetrics.NumberDataPoint{
+		Attributes:        otlpLabels(pm),
+		StartTimeUnixNano: start,
+		TimeUnixNano:      ts,
+		Value:             &otlpMetrics.NumberDataPoint_AsDouble{AsDouble: value},
+	}
+}
+
+// otlpHistogramPoint returns the OTLP data point of the histogram metric $pm. Prometheus buckets are cumulative and
+// include the +Inf bucket implicitly, whereas OTLP bucket counts are per bucket with an explicit overflow bucket.
+func otlpHistogramPoint(pm *dto.Metric, start, ts uint64) *otlpMetrics.HistogramDataPoint {
+	h := pm.GetHistogram()
+	sum := h.GetSampleSum()
+
+	bounds := make([]float64, 0, len(h.GetBucket()))
+	counts := make([]uint64, 0, len(h.GetBucket())+1)
+	previous := uint64(0)
+	for _, b := range h.GetBucket() {
+		if math.IsInf(b.GetUpperBound(), 1) {
+			continue
+		}
+		bounds = append(bounds, b.GetUpperBound())
+		counts = append(counts, b.GetCumulativeCount()-previous)
+		previous = b.GetCumulativeCount()
+	}
+	counts = append(counts, h.GetSampleCount()-previous)
+
+	return &otlpMetrics.HistogramDataPoint{
+		Attributes:        otlpLabels(pm),
+		StartTimeUnixNano: start,
+		TimeUnixNano:      ts,
+		Count:             h.GetSampleCount(),
+		Sum:               &sum,
+		BucketCounts:      counts,
+		ExplicitBounds:    bounds,
+	}
+}
+
+// otlpLabels returns the labels of $pm as OTLP attributes
+func otlpLabels(pm *dto.Metric) []*otlpCommon.KeyValue {
+	attrs := make([]*otlpCommon.KeyValue, 0, len(pm.GetLabel()))
+	for _, l := range pm.GetLabel() {
+		attrs = append(attrs, otlpString(l.GetName(), l.GetValue()))
+	}
+
+	return attrs
+}
+
+// otlpString returns an OTLP string attribute
+func otlpString(key, value string) *otlpCommon.KeyValue {
+	return &otlpCommon.KeyValue{
+		Key:   key,
+		Value: &otlpCommon.AnyValue{Value: &otlpCommon.AnyValue_StringValue{StringValue: value}},
+	}
+}
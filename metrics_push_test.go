@@ -0,0 +1,202 @@
+package go11y_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	otlpMetricsService "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	otlpCommon "go.opentelemetry.io/proto/otlp/common/v1"
+	otlpMetrics "go.opentelemetry.io/proto/otlp/metrics/v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/cirruscomms/go11y"
+)
+
+// pushTestRegistry returns a registry with a counter and a histogram that have been observed
+func pushTestRegistry() *prometheus.Registry {
+	reg := prometheus.NewRegistry()
+
+	rows := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "migration_rows_total"}, []string{"table"})
+	rows.WithLabelValues("users").Add(42)
+
+	times := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "migration_step_seconds", Buckets: []float64{1, 5}})
+	times.Observe(0.5)
+	times.Observe(3)
+	times.Observe(10)
+
+	reg.MustRegister(rows, times)
+
+	return reg
+}
+
+func TestMetricsPusherPushgateway(t *testing.T) {
+	buf := new(bytes.Buffer)
+	ctx, _, err := go11y.InitialiseTestLogger(context.Background(), go11y.LevelDebug, buf, buf)
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	var method, path, body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		method, path, body = r.Method, r.URL.Path, string(b)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	pusher, err := go11y.NewMetricsPusher(ctx, go11y.MetricsPusherOpts{
+		URL:      srv.URL,
+		Job:      "migrate",
+		Grouping: map[string]string{"env": "test"},
+		Gatherer: pushTestRegistry(),
+	})
+	if err != nil {
+		t.Fatalf("failed to create metrics pusher: %v", err)
+	}
+
+	if err := pusher.Close(); err != nil {
+		t.Fatalf("failed to push metrics: %v", err)
+	}
+
+	if method != http.MethodPut || path != "/metrics/job/migrate/env/test" {
+		t.Errorf("expected a PUT to the job's group, got %s %s", method, path)
+	}
+
+	if !strings.Contains(body, "migration_rows_total") {
+		t.Errorf("expected the gathered metrics to be pushed, got %q", body)
+	}
+}
+
+func TestMetricsPusherOTLP(t *testing.T) {
+	buf := new(bytes.Buffer)
+	ctx, _, err := go11y.InitialiseTestLogger(context.Background(), go11y.LevelDebug, buf, buf)
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	req := &otlpMetricsService.ExportMetricsServiceRequest{}
+	var path, contentType string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		path, contentType = r.URL.Path, r.Header.Get("Content-Type")
+		if err := proto.Unmarshal(b, req); err != nil {
+			t.Errorf("failed to decode otlp request: %v", err)
+		}
+	}))
+	defer srv.Close()
+
+	pusher, err := go11y.NewMetricsPusher(ctx, go11y.MetricsPusherOpts{
+		Mode:     go11y.MetricsPushOTLP,
+		URL:      srv.URL,
+		Job:      "migrate",
+		Gatherer: pushTestRegistry(),
+	})
+	if err != nil {
+		t.Fatalf("failed to create metrics pusher: %v", err)
+	}
+
+	if err := pusher.Push(context.Background()); err != nil {
+		t.Fatalf("failed to push metrics: %v", err)
+	}
+
+	if path != "/v1/metrics" || contentType != "application/x-protobuf" {
+		t.Errorf("expected a protobuf post to /v1/metrics, got %s to %s", contentType, path)
+	}
+
+	if len(req.GetResourceMetrics()) != 1 || len(req.GetResourceMetrics()[0].GetScopeMetrics()) != 1 {
+		t.Fatalf("expected one resource and scope, got %v", req)
+	}
+
+	rm := req.GetResourceMetrics()[0]
+	if !slices.ContainsFunc(rm.GetResource().GetAttributes(), func(kv *otlpCommon.KeyValue) bool {
+		return kv.GetKey() == "service.name" && kv.GetValue().GetStringValue() == "migrate"
+	}) {
+		t.Errorf("expected the job as the service name, got %v", rm.GetResource().GetAttributes())
+	}
+
+	metrics := map[string]*otlpMetrics.Metric{}
+	for _, m := range rm.GetScopeMetrics()[0].GetMetrics() {
+		metrics[m.GetName()] = m
+	}
+
+	sum := metrics["migration_rows_total"].GetSum()
+	if !sum.GetIsMonotonic() || len(sum.GetDataPoints()) != 1 || sum.GetDataPoints()[0].GetAsDouble() != 42 {
+		t.Errorf("expected a monotonic sum of 42, got %v", sum)
+	}
+
+	hist := metrics["migration_step_seconds"].GetHistogram()
+	if len(hist.GetDataPoints()) != 1 {
+		t.Fatalf("expected one histogram data point, got %v", hist)
+	}
+
+	point := hist.GetDataPoints()[0]
+	if !slices.Equal(point.GetExplicitBounds(), []float64{1, 5}) || !slices.Equal(point.GetBucketCounts(), []uint64{1, 1, 1}) {
+		t.Errorf("expected bounds [1 5] and counts [1 1 1], got %v and %v", point.GetExplicitBounds(), point.GetBucketCounts())
+	}
+}
+
+func TestMetricsPusherFailure(t *testing.T) {
+	buf := new(bytes.Buffer)
+	ctx, _, err := go11y.InitialiseTestLogger(context.Background(), go11y.LevelDebug, buf, buf)
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "collector unavailable", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	pusher, err := go11y.NewMetricsPusher(ctx, go11y.MetricsPusherOpts{
+		Mode:     go11y.MetricsPushOTLP,
+		URL:      srv.URL,
+		Job:      "migrate",
+		Gatherer: pushTestRegistry(),
+	})
+	if err != nil {
+		t.Fatalf("failed to create metrics pusher: %v", err)
+	}
+
+	failures := go11y.ExportFailures.WithLabelValues(go11y.ExportSignalMetrics, go11y.ExportFailureExporter)
+	before := testutil.ToFloat64(failures)
+
+	if err := pusher.Push(context.Background()); err == nil {
+		t.Fatalf("expected the push to fail")
+	}
+
+	if after := testutil.ToFloat64(failures); after != before+1 {
+		t.Errorf("expected the failure to be counted, got %v", after-before)
+	}
+
+	if !strings.Contains(buf.String(), "collector unavailable") {
+		t.Errorf("expected the failure to be logged, got %s", buf.String())
+	}
+}
+
+func TestNewMetricsPusherInvalid(t *testing.T) {
+	ctx, _, err := go11y.InitialiseTestLogger(context.Background(), go11y.LevelInfo, io.Discard, io.Discard)
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	testCases := map[string]go11y.MetricsPusherOpts{
+		"no url":       {Job: "migrate"},
+		"unknown mode": {Mode: "statsd", URL: "http://localhost:9091", Job: "migrate"},
+	}
+
+	for name, opts := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := go11y.NewMetricsPusher(ctx, opts); err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
@@ -1,12 +1,17 @@
 package go11y
 
 import (
+	"bufio"
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
-	"time"
+	"path"
+	"regexp"
+	"slices"
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/getkin/kin-openapi/routers"
@@ -14,9 +19,10 @@ import (
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.opentelemetry.io/otel"
+	otelAttribute "go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/propagation"
+	otelSemConv "go.opentelemetry.io/otel/semconv/v1.4.0"
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -41,12 +47,25 @@ func GetRequestID(ctx context.Context) string {
 	return ""
 }
 
+// validRequestID matches request IDs we are willing to accept from an incoming request header - UUIDs and other
+// short opaque tokens, but nothing that could be used to inject content into logs or headers
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// ValidRequestID reports whether the request ID is acceptable for use from an incoming request header.
+func ValidRequestID(requestID string) bool {
+	return validRequestID.MatchString(requestID)
+}
+
 // SetRequestIDMiddleware is a middleware that sets a unique request ID for each incoming HTTP request
-// It generates a new UUID for the request ID, sets it in the request context, and adds it to the response headers
+// If the request carries a valid request ID in the RequestIDHeader it is reused so the ID flows across services,
+// otherwise a new UUID is generated. The ID is set in the request context and added to the response headers.
 func SetRequestIDMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Generate a new request ID
-		requestID := uuid.New().String()
+		// Reuse the caller's request ID if it is valid, otherwise generate a new one
+		requestID := r.Header.Get(RequestIDHeader)
+		if !ValidRequestID(requestID) {
+			requestID = uuid.New().String()
+		}
 
 		// Set the request ID in the context
 		ctx := context.WithValue(r.Context(), RequestIDInstance, requestID)
@@ -91,26 +110,50 @@ type Origin struct {
 }
 
 // RequestLoggerMiddlewareMux is a middleware that logs incoming HTTP requests and their details
-// It extracts tracing information from the request headers and starts a new span for the request
+// It extracts tracing information from the request headers and starts a new span for the request, parented by the
+// caller's span if there is one, whose trace and span IDs are logged as FieldRemoteTraceID and FieldRemoteSpanID
+// If an IdentityExtractor is given, the identity it returns is added to the Observer's stable args, the span's
+// attributes and the baggage of the request context for the rest of the request.
 // It also logs the request details using go11y, adding the go11y Observer to the request context in the process
 // If the Observer cannot be retrieved from the provided context, an error is returned.
-// If the request context does not already contain a go11y Observer, it is added to the context.
-func RequestLoggerMiddlewareMux(ctxWithObserver context.Context) (loggerMiddleware mux.MiddlewareFunc, fault error) {
+// Each request is logged with its own child of the Observer (see ChildContext), which is added to the request context,
+// so the request's args are never seen by concurrent requests.
+// $opts is optional - only the first RequestLoggerMiddlewareMuxOpts provided is used.
+func RequestLoggerMiddlewareMux(
+	ctxWithObserver context.Context,
+	opts ...RequestLoggerMiddlewareMuxOpts,
+) (
+	loggerMiddleware mux.MiddlewareFunc,
+	fault error,
+) {
 	_, o, err := Get(ctxWithObserver)
 	if err != nil {
 		return nil, fmt.Errorf("could not get go11y observer from context: %w", err)
 	}
 
+	lOpts := RequestLoggerMiddlewareMuxOpts{}
+	if len(opts) > 0 {
+		lOpts = opts[0]
+	}
+
+	exclusions, err := newRouteExclusions(lOpts.ExcludePaths, lOpts.ExcludeOperations, lOpts.Swagger)
+	if err != nil {
+		return nil, err
+	}
+
 	mw := func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if exclusions.excluded(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
 			// Log&Trace the request
 			prop := otel.GetTextMapPropagator()
 
 			rCtx := prop.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
 			requestID := GetRequestID(rCtx)
 
-			ctxWithObserver = Reset(ctxWithObserver)
-
 			args := []any{
 				"origin",
 				Origin{
@@ -122,17 +165,37 @@ func RequestLoggerMiddlewareMux(ctxWithObserver context.Context) (loggerMiddlewa
 				FieldRequestID, requestID,
 			}
 
-			var span trace.Span
+			if remote := trace.SpanContextFromContext(rCtx); remote.IsValid() && remote.IsRemote() {
+				args = append(args,
+					FieldRemoteTraceID, remote.TraceID(),
+					FieldRemoteSpanID, remote.SpanID(),
+				)
+			}
+
+			if lOpts.IdentityExtractor != nil {
+				identity, err := lOpts.IdentityExtractor(r)
+				if err != nil {
+					o.Warning("could not extract identity in request logger middleware", "error", err.Error())
+				}
 
-			if o.cfg.OtelURL() != "" {
-				tracer := otel.Tracer(requestID)
+				args = append(args, identityArgs(identity)...)
+				rCtx = withIdentityBaggage(rCtx, identity)
+			}
+
+			var span trace.Span
 
-				// tracer
+			if o.traceProvider != nil {
 				opts := []trace.SpanStartOption{
 					trace.WithSpanKind(trace.SpanKindServer),
 					trace.WithAttributes(argsToAttributes(args...)...),
 				}
-				_, span = tracer.Start(ctxWithObserver, "HTTP "+r.Method+" "+r.URL.Path, opts...)
+				// the server span is started from the extracted context so it is parented by the caller's span, and is
+				// carried by the request context so the handler's spans are parented by it
+				rCtx, span = o.spanTracer(nil).Start(rCtx, "HTTP "+r.Method+" "+r.URL.Path, opts...)
+
+				if r.Header.Get(KeepTraceHeader) != "" {
+					keepTrace(span, KeepReasonUpstream)
+				}
 
 				args = append(args,
 					FieldSpanID, span.SpanContext().SpanID(),
@@ -140,16 +203,30 @@ func RequestLoggerMiddlewareMux(ctxWithObserver context.Context) (loggerMiddlewa
 				)
 			}
 
-			_, o, err = Extend(ctxWithObserver, args...)
+			// each request gets its own child Observer, so concurrent requests don't clobber each other's args
+			_, ro, err := ChildContext(ctxWithObserver, args...)
 			if err != nil {
-				Error("could not extend go11y observer in request logger middleware", err, SeverityHighest)
+				ErrorContext(r.Context(), "could not create go11y observer in request logger middleware", err, SeverityHighest)
 				http.Error(w, "internal server error", http.StatusInternalServerError)
 				return
 			}
 
+			if span != nil {
+				// the request's Observer owns the server span, so the errors its handler logs fail the span and mark
+				// the trace as "must keep"
+				ro.spanMu.Lock()
+				ro.span = span
+				ro.spans = nil
+				ro.spanMu.Unlock()
+			}
+
+			if lOpts.FlightRecorder != nil {
+				ro.flight = newFlightRecorder(*lOpts.FlightRecorder)
+			}
+
 			b, err := io.ReadAll(r.Body)
 			if err != nil {
-				Error("could not read request body in request logger middleware", err, SeverityMedium)
+				ro.Error("could not read request body in request logger middleware", err, SeverityMedium)
 				http.Error(w, "could not read request body", http.StatusBadRequest)
 				return
 			}
@@ -157,29 +234,55 @@ func RequestLoggerMiddlewareMux(ctxWithObserver context.Context) (loggerMiddlewa
 			// Restore the io.ReadCloser to its original state
 			r.Body = io.NopCloser(io.MultiReader(bytes.NewBuffer(b), r.Body))
 
-			o.Debug("request received", "request_body", RedactBody(b))
+			ro.Debug("request received", "request_body", RedactBodyByContentType(r.Header.Get("Content-Type"), b))
 
-			if !InContext(rCtx) {
-				rCtx = AddToContext(rCtx, o)
-			}
+			r = r.WithContext(AddToContext(rCtx, ro))
 
-			r = r.WithContext(rCtx)
+			t0 := ro.clock.Now()
 
 			hw := NewHTTPWriter(w)
 			// Call the next handler
 			next.ServeHTTP(hw, r)
 
-			moreArgs := []any{}
-			if resp, ok := hw.(*HTTPWriter); ok {
-				moreArgs = append(moreArgs, "response_body", RedactBody(resp.body))
-				moreArgs = append(moreArgs, "response_status", resp.statusCode)
+			duration := ro.clock.Since(t0)
+
+			// e.g. the client disconnected or a timeout middleware gave up on the request
+			ro.WhyDone(r.Context())
+
+			// the HTTPWriter is wrapped in an HTTPWriterFlusher if w is a Flusher
+			resp, ok := hw.(*HTTPWriter)
+			if !ok {
+				resp = hw.(*HTTPWriterFlusher).HTTPWriter
 			}
 
 			// Log the response
-			o.Debug("request processed", moreArgs...)
+			ro.Debug("request processed",
+				FieldStatusCode, resp.StatusCode(),
+				FieldResponseSize, resp.BytesWritten(),
+				FieldRequestDuration, duration.Milliseconds(),
+				FieldResponseBody, RedactBodyByContentType(resp.Header().Get("Content-Type"), resp.body),
+			)
+
+			if lOpts.FlightRecorder != nil {
+				ro.flushFlight(r.Context(), resp.StatusCode() >= http.StatusInternalServerError, duration,
+					lOpts.FlightRecorder.Latency)
+			}
+
+			if span != nil {
+				span.SetAttributes(
+					otelSemConv.HTTPStatusCodeKey.Int(resp.StatusCode()),
+					otelSemConv.HTTPResponseContentLengthKey.Int64(resp.BytesWritten()),
+					otelAttribute.Float64("http.server.duration_ms", float64(duration.Microseconds())/1000),
+				)
 
-			if o.cfg.OtelURL() != "" {
-				span.End()
+				if resp.StatusCode() >= http.StatusInternalServerError {
+					ro.failSpan(http.StatusText(resp.StatusCode()))
+					keepTrace(span, KeepReasonServerError)
+				}
+
+				ro.spanMu.Lock()
+				ro.endSpan(span)
+				ro.spanMu.Unlock()
 			}
 		})
 	}
@@ -187,12 +290,31 @@ func RequestLoggerMiddlewareMux(ctxWithObserver context.Context) (loggerMiddlewa
 	return mw, nil
 }
 
+// RequestLoggerMiddlewareMuxOpts are the options used to initialise the request logger middleware for a mux.Router
+type RequestLoggerMiddlewareMuxOpts struct {
+	ExcludePaths      []string    // optional - path.Match glob patterns for request paths that are not logged, e.g. "/health*"
+	ExcludeOperations []string    // optional - OpenAPI operation IDs that are not logged. Requires Swagger.
+	Swagger           *openapi3.T // optional - the swagger spec used to resolve operation IDs
+
+	IdentityExtractor IdentityExtractor // optional - adds the caller's identity to the request's logs, span and baggage
+
+	// optional - buffers each request's records below the log level, writing them only if it fails or is slow, see
+	// FlightRecorderOpts, defaults to discarding them
+	FlightRecorder *FlightRecorderOpts
+}
+
 // Requests is the metric for the number of requests the calling service has handled
 var Requests *prometheus.CounterVec
 
 // RequestTimes is the metric for the amount of time the calling service has taken to handle requests
 var RequestTimes *prometheus.HistogramVec
 
+// ResponseSizes is the metric for the size in bytes of the response bodies the calling service has written
+var ResponseSizes *prometheus.HistogramVec
+
+// InFlightRequests is the metric for the number of requests the calling service is currently handling
+var InFlightRequests *prometheus.GaugeVec
+
 // RuntimeOpts are the options used to initialise the metrics middleware
 var RuntimeOpts MetricsMiddlewareMuxOpts
 
@@ -203,6 +325,9 @@ type MetricsMiddlewareMuxOpts struct {
 	PathMaskFunc PathMask       // required - function to remove variable parts of the path for metrics aggregation. If nil, the path for metrics will not me masked
 	Swagger      *openapi3.T    // optional - the swagger spec for the service being instrumented. This is used to get the endpoint names. If nil, the raw request paths are used.
 	validRouter  routers.Router // the validated router created from the swagger spec
+
+	ExcludePaths      []string // optional - path.Match glob patterns for request paths that are not recorded, e.g. "/internal/metrics"
+	ExcludeOperations []string // optional - OpenAPI operation IDs that are not recorded. Requires Swagger.
 }
 
 // PathMask is a function that takes a path string and returns a masked path string
@@ -210,8 +335,9 @@ type MetricsMiddlewareMuxOpts struct {
 type PathMask func(path string) (maskedPath string)
 
 // GetMetricsMiddlewareMux initialises a promhttp metrics route on the provided mux router with a path of
-// /internal/metrics and returns a mux middleware that records request-count and request-time Prometheus metrics for
-// incoming HTTP requests and publishes the values on the endpoint/route.
+// /internal/metrics and returns a mux middleware that records request-count, request-time, response-size and in-flight
+// request Prometheus metrics for incoming HTTP requests and publishes the values on the endpoint/route.
+// Request times are observed with an exemplar of the request's trace ID, served to scrapers asking for OpenMetrics.
 func GetMetricsMiddlewareMux(ctx context.Context, opts MetricsMiddlewareMuxOpts) (metricsMiddleware mux.MiddlewareFunc, fault error) {
 	_, o, err := Get(ctx)
 	if err != nil {
@@ -228,11 +354,24 @@ func GetMetricsMiddlewareMux(ctx context.Context, opts MetricsMiddlewareMuxOpts)
 		Help: fmt.Sprintf("Time %s service takes to handle requests", opts.Service),
 	}, []string{"endpoint", "method", "status"})
 
+	ResponseSizes = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    fmt.Sprintf("%s_response_size_bytes", opts.Service),
+		Help:    fmt.Sprintf("Size of the response bodies the %s service has written", opts.Service),
+		Buckets: prometheus.ExponentialBuckets(100, 10, 7),
+	}, []string{"endpoint", "method", "status"})
+
+	InFlightRequests = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: fmt.Sprintf("%s_requests_in_flight", opts.Service),
+		Help: fmt.Sprintf("Number of requests the %s service is currently handling", opts.Service),
+	}, []string{"endpoint", "method"})
+
 	// Register the metrics on Prometheus endpoint
 	prometheus.MustRegister(Requests)
 	prometheus.MustRegister(RequestTimes)
+	prometheus.MustRegister(ResponseSizes)
+	prometheus.MustRegister(InFlightRequests)
 
-	opts.Router.Handle("/internal/metrics", promhttp.Handler()).Methods(http.MethodGet)
+	opts.Router.Handle("/internal/metrics", MetricsHandler()).Methods(http.MethodGet)
 
 	if opts.Swagger != nil {
 		vr, err := oapimux.NewRouter(opts.Swagger)
@@ -244,13 +383,19 @@ func GetMetricsMiddlewareMux(ctx context.Context, opts MetricsMiddlewareMuxOpts)
 		opts.validRouter = vr
 	}
 
+	exclusions, err := newRouteExclusions(opts.ExcludePaths, opts.ExcludeOperations, opts.Swagger)
+	if err != nil {
+		return nil, err
+	}
+
 	mw := func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			t0 := time.Now()
+			if exclusions.excluded(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
 
-			mrw := newMiddlewareResponseWriter(w)
-			// Call the next handler
-			next.ServeHTTP(mrw, r)
+			t0 := o.clock.Now()
 
 			path := r.URL.Path
 
@@ -269,20 +414,31 @@ func GetMetricsMiddlewareMux(ctx context.Context, opts MetricsMiddlewareMuxOpts)
 				path = opts.PathMaskFunc(path)
 			}
 
-			requestTime := time.Since(t0)
-			Requests.WithLabelValues(path, r.Method, fmt.Sprintf("%d", mrw.statusCode)).Inc()
-			RequestTimes.WithLabelValues(path, r.Method, fmt.Sprintf("%d", mrw.statusCode)).Observe(requestTime.Seconds())
+			inFlight := InFlightRequests.WithLabelValues(path, r.Method)
+			inFlight.Inc()
+			defer inFlight.Dec()
+
+			mrw := newMiddlewareResponseWriter(w)
+			// Call the next handler
+			next.ServeHTTP(mrw, r)
+
+			requestTime := o.clock.Since(t0)
+			status := fmt.Sprintf("%d", mrw.statusCode)
+			Requests.WithLabelValues(path, r.Method, status).Inc()
+			observeWithExemplar(requestTraceContext(r), RequestTimes.WithLabelValues(path, r.Method, status), requestTime.Seconds())
+			ResponseSizes.WithLabelValues(path, r.Method, status).Observe(float64(mrw.bytesWritten))
 		})
 	}
 
 	return mw, nil
 }
 
-// MiddlewareResponseWriter is a custom http.ResponseWriter that captures the status code of the response.
+// MiddlewareResponseWriter is a custom http.ResponseWriter that captures the status code and body size of the response.
 type MiddlewareResponseWriter struct {
 	http.ResponseWriter
 	statusCode    int
 	headerWritten bool
+	bytesWritten  int64
 }
 
 // WriteHeader sends an HTTP response header with the provided status code.
@@ -301,9 +457,140 @@ func (mrw *MiddlewareResponseWriter) Write(b []byte) (int, error) {
 	if !mrw.headerWritten {
 		mrw.WriteHeader(http.StatusOK)
 	}
-	return mrw.ResponseWriter.Write(b)
+	n, err := mrw.ResponseWriter.Write(b)
+	mrw.bytesWritten += int64(n)
+	return n, err
+}
+
+// StatusCode returns the status code sent to the client, http.StatusOK if none was set explicitly.
+func (mrw *MiddlewareResponseWriter) StatusCode() int {
+	return mrw.statusCode
+}
+
+// BytesWritten returns the number of response body bytes written to the client.
+func (mrw *MiddlewareResponseWriter) BytesWritten() int64 {
+	return mrw.bytesWritten
+}
+
+// Flush sends any buffered data to the client, so streamed responses (e.g. Server-Sent Events) work behind the
+// middleware. It does nothing if the wrapped http.ResponseWriter does not support flushing.
+func (mrw *MiddlewareResponseWriter) Flush() {
+	if !mrw.headerWritten {
+		mrw.WriteHeader(http.StatusOK)
+	}
+
+	if f, ok := mrw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack lets the handler take over the connection, e.g. for WebSocket upgrades. The status code is recorded as
+// http.StatusSwitchingProtocols as the response is written by the handler directly to the connection.
+func (mrw *MiddlewareResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := mrw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("could not hijack connection: %w", http.ErrNotSupported)
+	}
+
+	conn, rw, err := h.Hijack()
+	if err == nil && !mrw.headerWritten {
+		mrw.statusCode = http.StatusSwitchingProtocols
+		mrw.headerWritten = true
+	}
+
+	return conn, rw, err
+}
+
+// ReadFrom copies from $r to the response, using the wrapped http.ResponseWriter's io.ReaderFrom (e.g. sendfile) if
+// it has one. The bytes copied are counted as written.
+func (mrw *MiddlewareResponseWriter) ReadFrom(r io.Reader) (int64, error) {
+	if !mrw.headerWritten {
+		mrw.WriteHeader(http.StatusOK)
+	}
+
+	var n int64
+	var err error
+
+	if rf, ok := mrw.ResponseWriter.(io.ReaderFrom); ok {
+		n, err = rf.ReadFrom(r)
+	} else {
+		// hide our own ReadFrom from io.Copy to avoid recursing
+		n, err = io.Copy(struct{ io.Writer }{mrw.ResponseWriter}, r)
+	}
+
+	mrw.bytesWritten += n
+
+	return n, err
+}
+
+// Push initiates an HTTP/2 server push if the wrapped http.ResponseWriter supports it, otherwise it returns
+// http.ErrNotSupported.
+func (mrw *MiddlewareResponseWriter) Push(target string, opts *http.PushOptions) error {
+	if p, ok := mrw.ResponseWriter.(http.Pusher); ok {
+		return p.Push(target, opts)
+	}
+
+	return http.ErrNotSupported
+}
+
+// Unwrap returns the wrapped http.ResponseWriter so http.ResponseController can reach its optional methods.
+func (mrw *MiddlewareResponseWriter) Unwrap() http.ResponseWriter {
+	return mrw.ResponseWriter
 }
 
 func newMiddlewareResponseWriter(w http.ResponseWriter) *MiddlewareResponseWriter {
 	return &MiddlewareResponseWriter{ResponseWriter: w, statusCode: http.StatusOK}
 }
+
+// routeExclusions decides which requests are skipped by the logging and metrics middleware
+type routeExclusions struct {
+	paths       []string
+	operations  []string
+	validRouter routers.Router
+}
+
+func newRouteExclusions(paths, operations []string, swagger *openapi3.T) (exclusions routeExclusions, fault error) {
+	for _, p := range paths {
+		if _, err := path.Match(p, "/"); err != nil {
+			return routeExclusions{}, fmt.Errorf("invalid exclusion pattern %q: %w", p, err)
+		}
+	}
+
+	exclusions = routeExclusions{
+		paths:      paths,
+		operations: operations,
+	}
+
+	if len(operations) > 0 {
+		if swagger == nil {
+			return routeExclusions{}, errors.New("excluding operations requires a swagger spec")
+		}
+
+		vr, err := oapimux.NewRouter(swagger)
+		if err != nil {
+			return routeExclusions{}, fmt.Errorf("could not create oapi validation router: %w", err)
+		}
+
+		exclusions.validRouter = vr
+	}
+
+	return exclusions, nil
+}
+
+// excluded reports whether the request matches any of the excluded path patterns or operation IDs
+func (e routeExclusions) excluded(r *http.Request) bool {
+	for _, p := range e.paths {
+		if matched, _ := path.Match(p, r.URL.Path); matched {
+			return true
+		}
+	}
+
+	if e.validRouter != nil {
+		route, _, err := e.validRouter.FindRoute(r)
+		if err == nil && route != nil && route.Operation != nil {
+			return slices.Contains(e.operations, route.Operation.OperationID)
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,601 @@
+package go11y_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cirruscomms/go11y"
+	"github.com/cirruscomms/go11y/go11ytest"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/codes"
+)
+
+func TestSetRequestIDMiddleware(t *testing.T) {
+	testCases := map[string]struct {
+		incoming string
+		reused   bool
+	}{
+		"no incoming request ID":      {incoming: "", reused: false},
+		"valid incoming request ID":   {incoming: "2f1c9a3e-0d7b-4c1e-9f3a-6b8e2d4c1a5f", reused: true},
+		"invalid incoming request ID": {incoming: "abc\r\ninjected: header", reused: false},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			var seen string
+			handler := go11y.SetRequestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				seen = go11y.GetRequestID(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(go11y.RequestIDHeader, tc.incoming)
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if seen == "" {
+				t.Fatalf("expected a request ID in the context")
+			}
+
+			if tc.reused != (seen == tc.incoming) {
+				t.Errorf("expected reuse of incoming request ID to be %v, got %q", tc.reused, seen)
+			}
+
+			if rec.Header().Get(go11y.RequestIDHeader) != seen {
+				t.Errorf("expected response header %q, got %q", seen, rec.Header().Get(go11y.RequestIDHeader))
+			}
+		})
+	}
+}
+
+func TestRequestIDTransport(t *testing.T) {
+	var received string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get(go11y.RequestIDHeader)
+	}))
+	defer srv.Close()
+
+	ctx, _, err := go11y.InitialiseTestLogger(context.Background(), go11y.LevelInfo, io.Discard, io.Discard)
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	client := &go11y.HTTPClient{Client: &http.Client{Transport: http.DefaultTransport}}
+	if err := client.AddRequestID(ctx); err != nil {
+		t.Fatalf("failed to add request ID to HTTP client: %v", err)
+	}
+
+	reqCtx := context.WithValue(ctx, go11y.RequestIDInstance, "request-123")
+
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("failed to execute request: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	if received != "request-123" {
+		t.Errorf("expected request ID to be forwarded, got %q", received)
+	}
+
+	if req.Header.Get(go11y.RequestIDHeader) != "" {
+		t.Errorf("expected the caller's request headers to be left untouched")
+	}
+}
+
+func TestRequestLoggerExclusions(t *testing.T) {
+	buf := new(bytes.Buffer)
+
+	ctx, _, err := go11y.InitialiseTestLogger(context.Background(), go11y.LevelDebug, buf, buf)
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	mw, err := go11y.RequestLoggerMiddlewareMux(ctx, go11y.RequestLoggerMiddlewareMuxOpts{
+		ExcludePaths: []string{"/health*", "/internal/*"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create middleware: %v", err)
+	}
+
+	handler := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	testCases := map[string]struct {
+		path   string
+		logged bool
+	}{
+		"health check":     {path: "/healthz", logged: false},
+		"metrics endpoint": {path: "/internal/metrics", logged: false},
+		"api call":         {path: "/api/v1/users", logged: true},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			buf.Reset()
+
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+			logged := bytes.Contains(buf.Bytes(), []byte("request received"))
+			if logged != tc.logged {
+				t.Errorf("expected request to %s to be logged: %v, got %v", tc.path, tc.logged, logged)
+			}
+		})
+	}
+
+	_, err = go11y.RequestLoggerMiddlewareMux(ctx, go11y.RequestLoggerMiddlewareMuxOpts{
+		ExcludeOperations: []string{"getHealth"},
+	})
+	if err == nil {
+		t.Errorf("expected an error when excluding operations without a swagger spec")
+	}
+}
+
+func TestRequestLoggerResponse(t *testing.T) {
+	buf := new(bytes.Buffer)
+
+	ctx, _, err := go11y.InitialiseTestLogger(context.Background(), go11y.LevelDebug, buf, buf)
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	mw, err := go11y.RequestLoggerMiddlewareMux(ctx)
+	if err != nil {
+		t.Fatalf("failed to create middleware: %v", err)
+	}
+
+	testCases := map[string]struct {
+		handler http.HandlerFunc
+		status  float64
+		size    float64
+	}{
+		"implicit ok": {
+			handler: func(w http.ResponseWriter, r *http.Request) { _, _ = w.Write([]byte("hello")) },
+			status:  http.StatusOK,
+			size:    5,
+		},
+		"explicit status": {
+			handler: func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusTeapot) },
+			status:  http.StatusTeapot,
+			size:    0,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			buf.Reset()
+
+			// httptest.ResponseRecorder is a Flusher, so the response is captured by an HTTPWriterFlusher
+			mw(tc.handler).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/users", nil))
+
+			var processed map[string]any
+			for line := range strings.Lines(buf.String()) {
+				record := map[string]any{}
+				if err := json.Unmarshal([]byte(line), &record); err == nil && record["msg"] == "request processed" {
+					processed = record
+				}
+			}
+
+			if processed == nil {
+				t.Fatalf("expected a request processed record, got %s", buf.String())
+			}
+
+			if processed[go11y.FieldStatusCode] != tc.status || processed[go11y.FieldResponseSize] != tc.size {
+				t.Errorf("expected status %v and size %v, got %v and %v", tc.status, tc.size,
+					processed[go11y.FieldStatusCode], processed[go11y.FieldResponseSize])
+			}
+
+			if _, ok := processed[go11y.FieldRequestDuration]; !ok {
+				t.Errorf("expected the request duration to be logged")
+			}
+		})
+	}
+}
+
+func TestRequestLoggerRemoteParent(t *testing.T) {
+	buf := new(bytes.Buffer)
+
+	ctx, _, err := go11y.InitialiseTestLogger(context.Background(), go11y.LevelDebug, buf, buf)
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	mw, err := go11y.RequestLoggerMiddlewareMux(ctx)
+	if err != nil {
+		t.Fatalf("failed to create middleware: %v", err)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/users", nil)
+	r.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})).ServeHTTP(httptest.NewRecorder(), r)
+
+	for _, expected := range []string{
+		`"` + go11y.FieldRemoteTraceID + `":"4bf92f3577b34da6a3ce929d0e0e4736"`,
+		`"` + go11y.FieldRemoteSpanID + `":"00f067aa0ba902b7"`,
+	} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("expected %s to be logged, got %s", expected, buf.String())
+		}
+	}
+}
+
+func TestRequestLoggerIdentity(t *testing.T) {
+	buf := new(bytes.Buffer)
+
+	ctx, _, err := go11y.InitialiseTestLogger(context.Background(), go11y.LevelDebug, buf, buf)
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	mw, err := go11y.RequestLoggerMiddlewareMux(ctx, go11y.RequestLoggerMiddlewareMuxOpts{
+		IdentityExtractor: func(r *http.Request) (go11y.Fields, error) {
+			if r.Header.Get("Authorization") == "" {
+				return nil, errors.New("no token")
+			}
+
+			return go11y.Fields{go11y.FieldTenantID: "acme", go11y.FieldUserID: 42}, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create middleware: %v", err)
+	}
+
+	var member string
+	handler := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		member = baggage.FromContext(r.Context()).Member(go11y.FieldTenantID).Value()
+		go11y.FromContext(r.Context()).Info("handling")
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/users", nil)
+	r.Header.Set("Authorization", "Bearer token")
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	for _, expected := range []string{`"tenant_id":"acme"`, `"user_id":42`} {
+		if !strings.Contains(buf.String(), `"msg":"handling"`) || !strings.Contains(buf.String(), expected) {
+			t.Errorf("expected the handler's records to contain %s, got %s", expected, buf.String())
+		}
+	}
+
+	if member != "acme" {
+		t.Errorf("expected the tenant to be in the request's baggage, got %q", member)
+	}
+
+	buf.Reset()
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/users", nil))
+
+	if !strings.Contains(buf.String(), "could not extract identity") || !strings.Contains(buf.String(), "request processed") {
+		t.Errorf("expected a warning and the request to be handled, got %s", buf.String())
+	}
+}
+
+func TestRequestLoggerIsolation(t *testing.T) {
+	buf := &lockedBuffer{}
+
+	cfg := go11y.CreateConfig(go11y.LevelInfo, "", "", "", []string{}, []string{})
+
+	ctx, o, err := go11y.Initialise(context.Background(), cfg, buf, buf, "service", "test")
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	mw, err := go11y.RequestLoggerMiddlewareMux(ctx)
+	if err != nil {
+		t.Fatalf("failed to create middleware: %v", err)
+	}
+
+	handler := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, ro, _ := go11y.Extend(r.Context(), "order", r.URL.Query().Get("order"))
+		ro.Info("handling")
+	}))
+
+	wg := sync.WaitGroup{}
+	for _, order := range []string{"1", "2", "3", "4"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders?order="+order, nil))
+		}()
+	}
+	wg.Wait()
+
+	for _, record := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if strings.Count(record, `"order":`) != 1 || !strings.Contains(record, `"service":"test"`) {
+			t.Errorf("expected each request's record to contain its own order and the service field, got %s", record)
+		}
+	}
+
+	handled := buf.String()
+	o.Info("after requests")
+
+	if after := strings.TrimPrefix(buf.String(), handled); strings.Contains(after, "order") ||
+		strings.Contains(after, "origin") {
+		t.Errorf("expected the shared Observer to be untouched by the requests, got %s", after)
+	}
+}
+
+func TestRequestLoggerSpanStatus(t *testing.T) {
+	ctx, _, spans, err := go11y.InitialiseTestTracerInMemory(context.Background(), go11y.LevelInfo, io.Discard, io.Discard)
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	mw, err := go11y.RequestLoggerMiddlewareMux(ctx)
+	if err != nil {
+		t.Fatalf("failed to create middleware: %v", err)
+	}
+
+	handler := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, ro, _ := go11y.Get(r.Context())
+
+		switch r.URL.Path {
+		case "/error":
+			ro.Error("failed", errors.New("TestRequestLoggerSpanStatus"), go11y.SeverityLow)
+		case "/unavailable":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+
+	for path, expected := range map[string]codes.Code{
+		"/ok":          codes.Unset,
+		"/error":       codes.Error,
+		"/unavailable": codes.Error,
+	} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+
+		if _, found := spans.Find("HTTP GET " + path); !found {
+			t.Fatalf("expected a server span for %s, got %v", path, spans.Names())
+		}
+
+		if status := spans.Status("HTTP GET " + path); status != expected {
+			t.Errorf("expected the server span of %s to have the status %v, got %v", path, expected, status)
+		}
+	}
+}
+
+func TestRequestLoggerFlightRecorder(t *testing.T) {
+	buf := &lockedBuffer{}
+
+	cfg := go11y.CreateConfig(go11y.LevelInfo, "", "", "", []string{}, []string{})
+
+	ctx, _, err := go11y.Initialise(context.Background(), cfg, buf, buf, "service", "test")
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	mw, err := go11y.RequestLoggerMiddlewareMux(ctx, go11y.RequestLoggerMiddlewareMuxOpts{
+		FlightRecorder: &go11y.FlightRecorderOpts{Latency: 20 * time.Millisecond},
+	})
+	if err != nil {
+		t.Fatalf("failed to create middleware: %v", err)
+	}
+
+	handler := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, ro, _ := go11y.Extend(r.Context(), "case", r.URL.Path)
+		ro.Debug("step")
+
+		switch r.URL.Path {
+		case "/error":
+			ro.Error("failed", errors.New("TestRequestLoggerFlightRecorder"), go11y.SeverityLow)
+		case "/unavailable":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case "/slow":
+			time.Sleep(30 * time.Millisecond)
+		}
+	}))
+
+	for path, flushed := range map[string]bool{"/ok": false, "/error": true, "/unavailable": true, "/slow": true} {
+		before := buf.String()
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+		written := strings.TrimPrefix(buf.String(), before)
+
+		step := strings.Contains(written, `"msg":"step"`) && strings.Contains(written, `"case":"`+path+`"`) &&
+			strings.Contains(written, `"flight_recorded":true`)
+		if step != flushed {
+			t.Errorf("expected the debug records of %s to be written: %v, got %s", path, flushed, written)
+		}
+
+		if flushed && !strings.Contains(written, `"msg":"request processed"`) {
+			t.Errorf("expected the request processed record of %s to be written, got %s", path, written)
+		}
+	}
+}
+
+func TestMetricsMiddlewareMux(t *testing.T) {
+	cfg := go11y.CreateConfig(go11y.LevelInfo, "", "", "", []string{}, []string{})
+	ctx, _, err := go11y.Initialise(context.Background(), cfg, io.Discard, io.Discard)
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	router := mux.NewRouter()
+	mw, err := go11y.GetMetricsMiddlewareMux(ctx, go11y.MetricsMiddlewareMuxOpts{
+		Service: "metrics_middleware_test",
+		Router:  router,
+	})
+	if err != nil {
+		t.Fatalf("failed to create middleware: %v", err)
+	}
+
+	var inFlight float64
+	handler := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		inFlight = testutil.ToFloat64(go11y.InFlightRequests.WithLabelValues("/things", http.MethodGet))
+		_, _ = w.Write([]byte("0123456789"))
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/things", nil))
+
+	if inFlight != 1 {
+		t.Errorf("expected 1 in-flight request while handling, got %v", inFlight)
+	}
+
+	if after := testutil.ToFloat64(go11y.InFlightRequests.WithLabelValues("/things", http.MethodGet)); after != 0 {
+		t.Errorf("expected 0 in-flight requests after handling, got %v", after)
+	}
+
+	expected := `
+# HELP metrics_middleware_test_response_size_bytes Size of the response bodies the metrics_middleware_test service has written
+# TYPE metrics_middleware_test_response_size_bytes histogram
+metrics_middleware_test_response_size_bytes_bucket{endpoint="/things",method="GET",status="200",le="100"} 1
+metrics_middleware_test_response_size_bytes_bucket{endpoint="/things",method="GET",status="200",le="1000"} 1
+metrics_middleware_test_response_size_bytes_bucket{endpoint="/things",method="GET",status="200",le="10000"} 1
+metrics_middleware_test_response_size_bytes_bucket{endpoint="/things",method="GET",status="200",le="100000"} 1
+metrics_middleware_test_response_size_bytes_bucket{endpoint="/things",method="GET",status="200",le="1e+06"} 1
+metrics_middleware_test_response_size_bytes_bucket{endpoint="/things",method="GET",status="200",le="1e+07"} 1
+metrics_middleware_test_response_size_bytes_bucket{endpoint="/things",method="GET",status="200",le="1e+08"} 1
+metrics_middleware_test_response_size_bytes_bucket{endpoint="/things",method="GET",status="200",le="+Inf"} 1
+metrics_middleware_test_response_size_bytes_sum{endpoint="/things",method="GET",status="200"} 10
+metrics_middleware_test_response_size_bytes_count{endpoint="/things",method="GET",status="200"} 1
+`
+	if err := testutil.CollectAndCompare(go11y.ResponseSizes, strings.NewReader(expected)); err != nil {
+		t.Errorf("unexpected response size metrics: %v", err)
+	}
+
+	traced := httptest.NewRequest(http.MethodGet, "/traced", nil)
+	traced.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	handler.ServeHTTP(httptest.NewRecorder(), traced)
+
+	scrape := httptest.NewRequest(http.MethodGet, "/internal/metrics", nil)
+	scrape.Header.Set("Accept", "application/openmetrics-text; version=1.0.0")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, scrape)
+
+	exemplar := `metrics_middleware_test_requests_times_bucket{endpoint="/traced",method="GET",status="200",le="0.005"} 1 # {`
+	if !strings.Contains(rec.Body.String(), exemplar) ||
+		!strings.Contains(rec.Body.String(), `trace_id="4bf92f3577b34da6a3ce929d0e0e4736"`) {
+		t.Errorf("expected the request time to have an exemplar of the trace ID, got %s", rec.Body.String())
+	}
+}
+
+func TestMetricsMiddlewareMuxExclusions(t *testing.T) {
+	cfg := go11y.CreateConfig(go11y.LevelInfo, "", "", "", []string{}, []string{})
+	ctx, _, err := go11y.Initialise(context.Background(), cfg, io.Discard, io.Discard)
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	swagger, err := openapi3.NewLoader().LoadFromData([]byte(`{
+		"openapi": "3.0.0",
+		"info": {"title": "metrics exclusions", "version": "1.0.0"},
+		"paths": {
+			"/healthz": {"get": {"operationId": "getHealth", "responses": {"200": {"description": "ok"}}}},
+			"/things": {"get": {"operationId": "listThings", "responses": {"200": {"description": "ok"}}}}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("failed to load swagger spec: %v", err)
+	}
+
+	mw, err := go11y.GetMetricsMiddlewareMux(ctx, go11y.MetricsMiddlewareMuxOpts{
+		Service:           "metrics_exclusions_test",
+		Router:            mux.NewRouter(),
+		Swagger:           swagger,
+		ExcludePaths:      []string{"/internal/*"},
+		ExcludeOperations: []string{"getHealth"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create middleware: %v", err)
+	}
+
+	handler := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	for _, path := range []string{"/healthz", "/internal/status", "/things"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	metrics := go11ytest.GatherMetrics(t, prometheus.DefaultGatherer)
+
+	for _, endpoint := range []string{"getHealth", "/healthz", "/internal/status"} {
+		metrics.AssertAbsent(t, "metrics_exclusions_test_requests_total", go11ytest.Labels{"endpoint": endpoint})
+		metrics.AssertAbsent(t, "metrics_exclusions_test_requests_times", go11ytest.Labels{"endpoint": endpoint})
+	}
+
+	things := go11ytest.Labels{"endpoint": "listThings"}
+	metrics.AssertValue(t, "metrics_exclusions_test_requests_total", things, 1)
+	metrics.AssertHistogramCount(t, "metrics_exclusions_test_requests_times", things, 1)
+}
+
+func TestMiddlewareResponseWriterPassthrough(t *testing.T) {
+	cfg := go11y.CreateConfig(go11y.LevelInfo, "", "", "", []string{}, []string{})
+	ctx, _, err := go11y.Initialise(context.Background(), cfg, io.Discard, io.Discard)
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	mw, err := go11y.AccessLogMiddlewareMux(ctx, go11y.AccessLogMiddlewareMuxOpts{Output: io.Discard})
+	if err != nil {
+		t.Fatalf("failed to create middleware: %v", err)
+	}
+
+	t.Run("flush", func(t *testing.T) {
+		handler := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			f, ok := w.(http.Flusher)
+			if !ok {
+				t.Fatalf("expected the response writer to implement http.Flusher")
+			}
+			_, _ = w.Write([]byte("data: hello\n\n"))
+			f.Flush()
+		}))
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/events", nil))
+
+		if !rec.Flushed {
+			t.Errorf("expected the underlying recorder to be flushed")
+		}
+	})
+
+	t.Run("hijack", func(t *testing.T) {
+		hijacked := make(chan error, 1)
+		server := httptest.NewServer(mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			conn, _, err := http.NewResponseController(w).Hijack()
+			if err == nil {
+				_, _ = conn.Write([]byte("HTTP/1.1 101 Switching Protocols\r\nConnection: close\r\n\r\n"))
+				_ = conn.Close()
+			}
+			hijacked <- err
+		})))
+		defer server.Close()
+
+		resp, err := http.Get(server.URL)
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+
+		if err := <-hijacked; err != nil {
+			t.Errorf("expected the connection to be hijacked: %v", err)
+		}
+	})
+
+	t.Run("read from", func(t *testing.T) {
+		handler := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rf, ok := w.(io.ReaderFrom)
+			if !ok {
+				t.Fatalf("expected the response writer to implement io.ReaderFrom")
+			}
+			_, _ = rf.ReadFrom(strings.NewReader("streamed body"))
+		}))
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/file", nil))
+
+		if rec.Body.String() != "streamed body" {
+			t.Errorf("expected body %q, got %q", "streamed body", rec.Body.String())
+		}
+	})
+}
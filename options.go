@@ -1,14 +1,101 @@
 package go11y
 
 import (
+	"io"
 	"log/slog"
+	"time"
 )
 
-func defaultOptions(cfg Configurator) *slog.HandlerOptions {
+// Option configures optional behaviour of an Observer. Options can be passed to Initialise alongside the initial
+// key-value args - they are applied to the Observer and are not added to the log fields.
+type Option func(o *Observer)
+
+// WithAttrRedaction enables or disables redaction of the string values of log attributes whose keys name a secret:
+// authorization, cookie, password, secret, token and api_key, or keys ending in them as words such as access_token or
+// db_password (but not token_count or cache_key). Numbers, bools, times and durations are never redacted.
+// Redaction is enabled by default, which changes the output of services logging secrets under such keys. Span
+// attributes are redacted the same way whatever this option is set to.
+func WithAttrRedaction(enabled bool) Option {
+	return func(o *Observer) {
+		o.redactAttrs = enabled
+	}
+}
+
+// WithSpanStatusOK marks spans with an OK status when they are ended via End or Close, unless an error has been
+// logged against them with Error, Fatal or Panic (which always set an Error status).
+func WithSpanStatusOK(enabled bool) Option {
+	return func(o *Observer) {
+		o.markSpansOK = enabled
+	}
+}
+
+// WithSinks adds extra outputs that receive every record at or above their own minimum level, in addition to the log
+// and error outputs passed to Initialise.
+func WithSinks(sinks ...Sink) Option {
+	return func(o *Observer) {
+		o.sinks = append(o.sinks, sinks...)
+	}
+}
+
+// WithSampling rate-limits repetitive records, writing at most $limit records with the same message and level per
+// $interval. When records have been suppressed, a summary record with the suppressed count is written with the first
+// record logged after the window has ended, whatever its message (or when the Observer is closed).
+func WithSampling(limit int, interval time.Duration) Option {
+	return func(o *Observer) {
+		o.sampler = newSampler(limit, interval)
+	}
+}
+
+// splitOptions separates any Options from the key-value args passed to Initialise.
+func splitOptions(args []any) (options []Option, remainingArgs []any) {
+	remainingArgs = make([]any, 0, len(args))
+
+	for _, arg := range args {
+		if opt, ok := arg.(Option); ok {
+			options = append(options, opt)
+			continue
+		}
+
+		remainingArgs = append(remainingArgs, arg)
+	}
+
+	return options, remainingArgs
+}
+
+// newHandler creates the handler for the Observer's loggers, writing JSON to $primary and fanning out to any sinks.
+func (o *Observer) newHandler(primary io.Writer) slog.Handler {
+	return o.newLevelHandler(primary, o.level)
+}
+
+// newLevelHandler creates a handler like newHandler, enabled from $level rather than the Observer's level.
+func (o *Observer) newLevelHandler(primary io.Writer, level slog.Level) slog.Handler {
+	opts := defaultOptions(o)
+	opts.Level = level
+
+	h := slog.Handler(slog.NewJSONHandler(primary, opts))
+	if o.console {
+		h = slog.NewTextHandler(primary, opts)
+	}
+
+	if len(o.sinks) == 0 {
+		return h
+	}
+
+	handlers, minLevels := []slog.Handler{h}, []slog.Level{LevelDevelop}
+	for _, s := range o.sinks {
+		handlers = append(handlers, s.handler(opts))
+		minLevels = append(minLevels, s.MinLevel)
+	}
+
+	return &teeHandler{handlers: handlers, minLevels: minLevels}
+}
+
+func defaultOptions(o *Observer) *slog.HandlerOptions {
 	ho := &slog.HandlerOptions{
-		AddSource:   true,
-		Level:       cfg.LogLevel(),
-		ReplaceAttr: defaultReplacer(cfg.TrimModules(), cfg.TrimPaths()),
+		AddSource: o.sourceMode != SourceOff,
+		Level:     o.level,
+		ReplaceAttr: schemaReplacer(o.fieldSchema,
+			defaultReplacer(o.cfg.TrimModules(), o.cfg.TrimPaths(), o.redactAttrs, o.sourceMode)),
 	}
 
 	return ho
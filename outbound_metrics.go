@@ -0,0 +1,140 @@
+package go11y
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// OutboundErrors is the metric for the number of outbound calls that failed at the transport level (no response was
+// received), by host, method and reason
+var OutboundErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "go11y_outbound_errors_total",
+	Help: "Number of outbound calls that failed without a response",
+}, []string{"host", "method", "reason"})
+
+const (
+	// OutboundErrorTimeout is the reason label used when an outbound call timed out or hit its deadline
+	OutboundErrorTimeout = "timeout"
+	// OutboundErrorCanceled is the reason label used when the caller cancelled an outbound call
+	OutboundErrorCanceled = "canceled"
+	// OutboundErrorDNS is the reason label used when the host of an outbound call could not be resolved
+	OutboundErrorDNS = "dns"
+	// OutboundErrorConnection is the reason label used when a connection for an outbound call could not be made
+	OutboundErrorConnection = "connection"
+	// OutboundErrorOther is the reason label used for any other transport error
+	OutboundErrorOther = "other"
+)
+
+// OutboundRequests is the metric for the number of outbound calls made, by host, scheme, method, path and status
+var OutboundRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "go11y_outbound_requests_total",
+	Help: "Number of outbound calls made",
+}, []string{"host", "scheme", "method", "path", "status"})
+
+// OutboundRequestTimes is the metric for the time outbound calls take, by host, scheme, method, path and status
+var OutboundRequestTimes = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Name: "go11y_outbound_request_duration_seconds",
+	Help: "Time outbound calls take",
+}, []string{"host", "scheme", "method", "path", "status"})
+
+// OutboundWithoutDeadline is the metric for the number of outbound calls made without a deadline in their context, by
+// host and method, counted by the roundtripper added by HTTPClient.AddTimeouts
+var OutboundWithoutDeadline = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "go11y_outbound_requests_without_deadline_total",
+	Help: "Number of outbound calls made without a deadline",
+}, []string{"host", "method"})
+
+// OutboundStatusError is the status label used for outbound calls that failed without a response
+const OutboundStatusError = "error"
+
+var registerOutboundMetricsOnce sync.Once
+
+func registerOutboundMetrics() {
+	registerOutboundMetricsOnce.Do(func() {
+		registerCollectors(OutboundErrors, OutboundPhaseDuration, OutboundRequests, OutboundRequestTimes,
+			OutboundWithoutDeadline)
+	})
+}
+
+// recordedError marks a transport error that has already been counted, so stacked roundtrippers count it once
+type recordedError struct {
+	error
+}
+
+func (e recordedError) Unwrap() error {
+	return e.error
+}
+
+// recordOutboundError counts $err against the request's host and method, unless a roundtripper further down the chain
+// already has, and returns it marked as counted.
+func recordOutboundError(r *http.Request, err error) error {
+	if errors.As(err, &recordedError{}) {
+		return err
+	}
+
+	OutboundErrors.WithLabelValues(r.URL.Host, r.Method, outboundErrorReason(err)).Inc()
+
+	return recordedError{err}
+}
+
+// outboundErrorReason classifies a transport error for the reason label
+func outboundErrorReason(err error) string {
+	var dnsErr *net.DNSError
+	var opErr *net.OpError
+	var netErr net.Error
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return OutboundErrorCanceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return OutboundErrorTimeout
+	case errors.As(err, &dnsErr):
+		return OutboundErrorDNS
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return OutboundErrorTimeout
+	case errors.As(err, &opErr) && opErr.Op == "dial":
+		return OutboundErrorConnection
+	default:
+		return OutboundErrorOther
+	}
+}
+
+// OutboundPhaseDuration is the metric for the time spent in each connection phase of outbound calls (dns, connect,
+// tls and ttfb), by host and phase
+var OutboundPhaseDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "go11y_outbound_phase_duration_seconds",
+	Help:    "Time spent in each connection phase of outbound calls",
+	Buckets: prometheus.ExponentialBuckets(0.001, 2, 14),
+}, []string{"host", "phase"})
+
+// PrometheusMetricsRecorder returns a HostMetricsRecorder for HTTPClient.AddHostMetrics that records the
+// OutboundRequests and OutboundRequestTimes metrics, registering them with the default Prometheus registerer.
+// Use PrometheusContextMetricsRecorder with HTTPClient.AddContextMetrics to record exemplars too.
+func PrometheusMetricsRecorder() HostMetricsRecorder {
+	recorder := PrometheusContextMetricsRecorder()
+
+	return func(status, method, scheme, host, path string, startTime time.Time) {
+		recorder(context.Background(), status, method, scheme, host, path, startTime)
+	}
+}
+
+// PrometheusContextMetricsRecorder returns a ContextMetricsRecorder for HTTPClient.AddContextMetrics that records the
+// OutboundRequests and OutboundRequestTimes metrics, the latter with an exemplar of the trace ID of the span in the
+// request's context, registering them with the default Prometheus registerer. Durations are measured with the Clock of
+// the Observer in the request's context, or of the default Observer (see Default) if there isn't one.
+func PrometheusContextMetricsRecorder() ContextMetricsRecorder {
+	registerOutboundMetrics()
+
+	return func(ctx context.Context, status, method, scheme, host, path string, startTime time.Time) {
+		OutboundRequests.WithLabelValues(host, scheme, method, path, status).Inc()
+
+		duration := FromContext(ctx).clock.Since(startTime)
+		observeWithExemplar(ctx, OutboundRequestTimes.WithLabelValues(host, scheme, method, path, status), duration.Seconds())
+	}
+}
@@ -0,0 +1,29 @@
+package go11y
+
+import (
+	"log/slog"
+)
+
+// DefaultErrorOutputLevel is the level from which records are written to the error output passed to Initialise
+// rather than the log output, see WithErrorOutputLevel
+const DefaultErrorOutputLevel = LevelError
+
+// WithErrorOutputLevel routes the records at or above $level to the error output passed to Initialise (stderr by
+// default) and those below it to the log output (stdout by default), whichever method logged them, defaults to
+// DefaultErrorOutputLevel. Pass LevelDevelop to write every record to the error output, or a level above LevelFatal to
+// write every record to the log output.
+func WithErrorOutputLevel(level slog.Level) Option {
+	return func(o *Observer) {
+		o.errorLevel = level
+	}
+}
+
+// logger returns the logger records at $level are written with: the error logger at or above the Observer's error
+// output level, the log logger below it
+func (o *Observer) logger(level slog.Level) *slog.Logger {
+	if level >= o.errorLevel {
+		return o.errLogger
+	}
+
+	return o.outLogger
+}
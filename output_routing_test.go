@@ -0,0 +1,77 @@
+package go11y_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/cirruscomms/go11y"
+)
+
+func TestOutputRouting(t *testing.T) {
+	testCases := []struct {
+		name        string
+		args        []any
+		expectedOut []string
+		expectedErr []string
+	}{
+		{
+			name:        "default",
+			expectedOut: []string{"info record", "warning record"},
+			expectedErr: []string{"error record"},
+		},
+		{
+			name:        "warnings to the error output",
+			args:        []any{go11y.WithErrorOutputLevel(go11y.LevelWarning)},
+			expectedOut: []string{"info record"},
+			expectedErr: []string{"warning record", "error record"},
+		},
+		{
+			name:        "everything to the log output",
+			args:        []any{go11y.WithErrorOutputLevel(go11y.LevelFatal + 1)},
+			expectedOut: []string{"info record", "warning record", "error record"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			out, errOut := new(bytes.Buffer), new(bytes.Buffer)
+
+			cfg := go11y.CreateConfig(go11y.LevelInfo, "", "", "", []string{}, []string{})
+			ctx, _, err := go11y.Initialise(context.Background(), cfg, out, errOut, tc.args...)
+			if err != nil {
+				t.Fatalf("failed to initialise observer: %v", err)
+			}
+
+			// Reset rebuilds the loggers, which must keep writing to their own outputs
+			ctx = go11y.Reset(ctx)
+			o := go11y.FromContext(ctx)
+
+			o.Info("info record")
+			o.Warn("warning record")
+			o.Error("error record", errors.New("boom"), go11y.SeverityLow)
+
+			assertRouted(t, "log", out.String(), tc.expectedOut, tc.expectedErr)
+			assertRouted(t, "error", errOut.String(), tc.expectedErr, tc.expectedOut)
+		})
+	}
+}
+
+// assertRouted checks that $output contains each of the messages in $expected and none of those in $unexpected
+func assertRouted(t *testing.T, name, output string, expected, unexpected []string) {
+	t.Helper()
+
+	for _, msg := range expected {
+		if !strings.Contains(output, `"msg":"`+msg+`"`) {
+			t.Errorf("expected %q in the %s output, got %s", msg, name, output)
+		}
+	}
+
+	for _, msg := range unexpected {
+		if strings.Contains(output, `"msg":"`+msg+`"`) {
+			t.Errorf("expected %q not to be in the %s output, got %s", msg, name, output)
+		}
+	}
+}
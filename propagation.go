@@ -0,0 +1,117 @@
+package go11y
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"go.opentelemetry.io/contrib/propagators/aws/xray"
+	"go.opentelemetry.io/contrib/propagators/b3"
+	"go.opentelemetry.io/contrib/propagators/jaeger"
+	"go.opentelemetry.io/contrib/propagators/ot"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// DefaultPropagators are the propagators installed when none are configured, the W3C trace context and baggage
+var DefaultPropagators = []string{"tracecontext", "baggage"}
+
+// PropagatorsNone is the propagator name that stops go11y installing a global propagator, for services that install
+// their own
+const PropagatorsNone = "none"
+
+// PropagatorConfigurator is implemented by Configurators that choose the propagators installed as the global
+// TextMapPropagator, used by the request logger middleware and the roundtrippers. Configuration implements it, other
+// Configurators install DefaultPropagators.
+type PropagatorConfigurator interface {
+	Propagators() []string
+}
+
+// WithPropagators sets the propagators installed as the global TextMapPropagator by Initialise, named as in
+// OTEL_PROPAGATORS: "tracecontext", "baggage", "b3" (the single b3 header), "b3multi" (the X-B3-* headers), "jaeger",
+// "xray" and "ottrace", or PropagatorsNone to leave the global propagator alone.
+func WithPropagators(propagators ...string) ConfigOption {
+	return func(c *Configuration) {
+		c.propagators = propagators
+	}
+}
+
+// Propagators returns the propagators installed as the global TextMapPropagator.
+// This method is part of the PropagatorConfigurator interface.
+func (c *Configuration) Propagators() []string {
+	return c.propagators
+}
+
+// ParsePropagators parses a comma-separated list of propagator names, as in OTEL_PROPAGATORS
+func ParsePropagators(propagators string) (names []string) {
+	names = []string{}
+	for name := range strings.SplitSeq(propagators, ",") {
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// configPropagators returns the propagators $cfg installs
+func configPropagators(cfg Configurator) []string {
+	if pc, ok := cfg.(PropagatorConfigurator); ok && len(pc.Propagators()) != 0 {
+		return pc.Propagators()
+	}
+
+	return DefaultPropagators
+}
+
+// textMapPropagator returns the propagator composed of the propagators $names, or nil for PropagatorsNone
+func textMapPropagator(names []string) (propagator propagation.TextMapPropagator, fault error) {
+	if slices.Contains(names, PropagatorsNone) {
+		return nil, nil
+	}
+
+	propagators := make([]propagation.TextMapPropagator, 0, len(names))
+	for _, name := range names {
+		switch name {
+		case "tracecontext":
+			propagators = append(propagators, propagation.TraceContext{})
+		case "baggage":
+			propagators = append(propagators, propagation.Baggage{})
+		case "b3":
+			propagators = append(propagators, b3.New(b3.WithInjectEncoding(b3.B3SingleHeader)))
+		case "b3multi":
+			propagators = append(propagators, b3.New(b3.WithInjectEncoding(b3.B3MultipleHeader)))
+		case "jaeger":
+			propagators = append(propagators, jaeger.Jaeger{})
+		case "xray":
+			propagators = append(propagators, xray.Propagator{})
+		case "ottrace":
+			propagators = append(propagators, ot.OT{})
+		default:
+			return nil, fmt.Errorf("unsupported propagator '%s', expected tracecontext, baggage, b3, b3multi, jaeger, "+
+				"xray, ottrace or none", name)
+		}
+	}
+
+	return propagation.NewCompositeTextMapPropagator(propagators...), nil
+}
+
+// installPropagator installs the propagators of $cfg as the global TextMapPropagator, returning their names, or
+// PropagatorsNone if the global propagator was left alone
+func installPropagator(cfg Configurator) (installed string) {
+	names := configPropagators(cfg)
+
+	propagator, err := textMapPropagator(names)
+	if err != nil {
+		// reported by validateConfig, the defaults are installed instead
+		names = DefaultPropagators
+		propagator, _ = textMapPropagator(names)
+	}
+
+	if propagator == nil {
+		return PropagatorsNone
+	}
+
+	otel.SetTextMapPropagator(propagator)
+
+	return strings.Join(names, ",")
+}
@@ -0,0 +1,86 @@
+package go11y_test
+
+import (
+	"context"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+
+	"github.com/cirruscomms/go11y"
+)
+
+func TestPropagators(t *testing.T) {
+	previous := otel.GetTextMapPropagator()
+	t.Cleanup(func() { otel.SetTextMapPropagator(previous) })
+
+	testCases := map[string]struct {
+		cfg       *go11y.Configuration
+		fields    []string
+		installed string
+	}{
+		"defaults": {
+			cfg:       go11y.NewConfig(),
+			fields:    []string{"baggage", "traceparent", "tracestate"},
+			installed: "tracecontext,baggage",
+		},
+		"trace context only": {
+			cfg:       go11y.NewConfig(go11y.WithPropagators("tracecontext")),
+			fields:    []string{"traceparent", "tracestate"},
+			installed: "tracecontext",
+		},
+		"b3 and jaeger": {
+			cfg:       go11y.NewConfig(go11y.WithPropagators("b3", "jaeger")),
+			fields:    []string{"b3", "uber-trace-id"},
+			installed: "b3,jaeger",
+		},
+		"b3multi and xray": {
+			cfg:       go11y.NewConfig(go11y.WithPropagators("b3multi", "xray")),
+			fields:    []string{"X-Amzn-Trace-Id", "x-b3-flags", "x-b3-sampled", "x-b3-spanid", "x-b3-traceid"},
+			installed: "b3multi,xray",
+		},
+		"none leaves the global propagator alone": {
+			cfg:       go11y.NewConfig(go11y.WithPropagators(go11y.PropagatorsNone)),
+			fields:    []string{"custom"},
+			installed: go11y.PropagatorsNone,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			otel.SetTextMapPropagator(customPropagator{})
+
+			_, o, err := go11y.Initialise(context.Background(), tc.cfg, io.Discard, io.Discard)
+			if err != nil {
+				t.Fatalf("failed to initialise observer: %v", err)
+			}
+
+			fields := otel.GetTextMapPropagator().Fields()
+			slices.Sort(fields)
+			if !slices.Equal(fields, tc.fields) {
+				t.Errorf("expected the global propagator to propagate %v, got %v", tc.fields, fields)
+			}
+
+			if installed := o.ConfigSnapshot().Propagators; installed != tc.installed {
+				t.Errorf("expected %q to be reported as installed, got %q", tc.installed, installed)
+			}
+		})
+	}
+
+	err := go11y.ValidateConfig(context.Background(), go11y.NewConfig(go11y.WithPropagators("zipkin")), 0)
+	if err == nil || !strings.Contains(err.Error(), "OTEL_PROPAGATORS") {
+		t.Errorf("expected an unsupported propagator to be a configuration problem, got %v", err)
+	}
+}
+
+// customPropagator is a propagator installed by the service itself
+type customPropagator struct {
+	propagation.TraceContext
+}
+
+func (customPropagator) Fields() []string {
+	return []string{"custom"}
+}
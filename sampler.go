@@ -0,0 +1,188 @@
+package go11y
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// DropReasonSampled is the reason label used when a record is discarded by the sampler
+const DropReasonSampled = "sampled"
+
+type samplerKey struct {
+	level slog.Level
+	msg   string
+}
+
+type samplerWindow struct {
+	start      time.Time
+	count      int
+	suppressed int
+}
+
+// maxSamplerWindows caps the number of windows a sampler keeps, so a service logging many distinct messages (e.g. with
+// IDs in them) doesn't grow the sampler without limit between sweeps
+const maxSamplerWindows = 10000
+
+// sampler rate-limits repetitive records, allowing up to limit records with the same message and level per interval.
+// Expired windows are swept once per interval, or as soon as there are maxWindows of them.
+type sampler struct {
+	limit      int
+	interval   time.Duration
+	maxWindows int
+	windows    map[samplerKey]*samplerWindow
+	lastSweep  time.Time
+	mu         sync.Mutex
+}
+
+// suppressedSummary describes how many records with the same message and level were suppressed in a window.
+type suppressedSummary struct {
+	level      slog.Level
+	msg        string
+	suppressed int
+}
+
+// SuppressedMessage is the message of the summary records emitted when the sampler has suppressed records
+const SuppressedMessage = "log messages suppressed by sampling"
+
+func newSampler(limit int, interval time.Duration) *sampler {
+	return &sampler{
+		limit:      limit,
+		interval:   interval,
+		maxWindows: maxSamplerWindows,
+		windows:    map[samplerKey]*samplerWindow{},
+	}
+}
+
+// allow reports whether a record with the level and message should be written. The summaries of the windows that had
+// records suppressed and have been swept, or have expired for this key, are returned to be written first.
+func (s *sampler) allow(now time.Time, level slog.Level, msg string) (allowed bool, summaries []suppressedSummary) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastSweep) >= s.interval || len(s.windows) >= s.maxWindows {
+		summaries = s.sweep(now)
+	}
+
+	key := samplerKey{level: level, msg: msg}
+
+	w, ok := s.windows[key]
+	if !ok || now.Sub(w.start) >= s.interval {
+		if ok && w.suppressed > 0 {
+			summaries = append(summaries, suppressedSummary{level: level, msg: msg, suppressed: w.suppressed})
+		}
+
+		s.windows[key] = &samplerWindow{start: now, count: 1}
+
+		return true, summaries
+	}
+
+	if w.count < s.limit {
+		w.count++
+		return true, summaries
+	}
+
+	w.suppressed++
+
+	return false, summaries
+}
+
+// sweep removes the windows that have expired at $now and, if there are still maxWindows of them, the oldest ones,
+// returning the summaries of those that had records suppressed.
+func (s *sampler) sweep(now time.Time) (summaries []suppressedSummary) {
+	s.lastSweep = now
+
+	evict := func(key samplerKey, w *samplerWindow) {
+		if w.suppressed > 0 {
+			summaries = append(summaries, suppressedSummary{level: key.level, msg: key.msg, suppressed: w.suppressed})
+		}
+
+		delete(s.windows, key)
+	}
+
+	for key, w := range s.windows {
+		if now.Sub(w.start) >= s.interval {
+			evict(key, w)
+		}
+	}
+
+	if len(s.windows) < s.maxWindows {
+		return summaries
+	}
+
+	keys := slices.SortedFunc(maps.Keys(s.windows), func(a, b samplerKey) int {
+		return s.windows[a].start.Compare(s.windows[b].start)
+	})
+
+	// half of the windows are evicted, so a burst of new messages doesn't sort them again for every record
+	for _, key := range keys[:len(keys)-s.maxWindows/2] {
+		evict(key, s.windows[key])
+	}
+
+	return summaries
+}
+
+// drain returns summaries for all windows with suppressed records and resets them.
+func (s *sampler) drain() (summaries []suppressedSummary) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, w := range s.windows {
+		if w.suppressed > 0 {
+			summaries = append(summaries, suppressedSummary{level: key.level, msg: key.msg, suppressed: w.suppressed})
+		}
+	}
+
+	s.windows = map[samplerKey]*samplerWindow{}
+
+	return summaries
+}
+
+// sample applies the Observer's sampler, if any, to a record about to be written by $logger, first writing summary
+// records for the windows that ended with records suppressed.
+func (o *Observer) sample(ctx context.Context, logger *slog.Logger, level slog.Level, msg string) (allowed bool) {
+	if o.sampler == nil {
+		return true
+	}
+
+	allowed, summaries := o.sampler.allow(o.clock.Now(), level, msg)
+	for _, summary := range summaries {
+		if summary.level == level && summary.msg == msg {
+			o.writeSummary(ctx, logger, summary)
+		} else {
+			o.writeSummary(context.Background(), o.logger(summary.level), summary)
+		}
+	}
+
+	if !allowed {
+		LogRecordsDropped.WithLabelValues(LevelToString(level), DropReasonSampled).Inc()
+	}
+
+	return allowed
+}
+
+func (o *Observer) writeSummary(ctx context.Context, logger *slog.Logger, summary suppressedSummary) {
+	r := slog.NewRecord(o.clock.Now(), summary.level, SuppressedMessage, 0)
+	r.Add(
+		"sampled_msg", summary.msg,
+		"suppressed", summary.suppressed,
+		"interval", o.sampler.interval.String(),
+	)
+
+	err := logger.Handler().Handle(ctx, r)
+	recordHandled(LevelToString(summary.level), o.component, err)
+}
+
+// flushSampler writes summaries of any records suppressed in the current windows.
+func (o *Observer) flushSampler() {
+	if o.sampler == nil {
+		return
+	}
+
+	for _, summary := range o.sampler.drain() {
+		o.writeSummary(context.Background(), o.logger(summary.level), summary)
+	}
+}
@@ -0,0 +1,111 @@
+package go11y
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestSampler(t *testing.T) {
+	s := newSampler(2, time.Second)
+	start := time.Now()
+
+	allowed := 0
+	for i := range 5 {
+		ok, summaries := s.allow(start.Add(time.Duration(i)*time.Millisecond), LevelInfo, "retrying")
+		if ok {
+			allowed++
+		}
+		if len(summaries) != 0 {
+			t.Errorf("unexpected summary within the first window: %v", summaries)
+		}
+	}
+
+	if allowed != 2 {
+		t.Errorf("expected 2 records to be allowed, got %d", allowed)
+	}
+
+	if ok, _ := s.allow(start, LevelError, "retrying"); !ok {
+		t.Errorf("expected a record with a different level to be allowed")
+	}
+
+	ok, summaries := s.allow(start.Add(time.Second), LevelInfo, "retrying")
+	if !ok {
+		t.Errorf("expected the first record of a new window to be allowed")
+	}
+
+	if len(summaries) != 1 || summaries[0].suppressed != 3 || summaries[0].msg != "retrying" {
+		t.Errorf("expected a summary of 3 suppressed records, got %v", summaries)
+	}
+}
+
+func TestSamplerEviction(t *testing.T) {
+	s := newSampler(1, time.Second)
+	start := time.Now()
+
+	for range 3 {
+		s.allow(start, LevelWarning, "storm")
+	}
+
+	// the storm is over, its summary is written with the next record rather than when it happens again
+	_, summaries := s.allow(start.Add(time.Second), LevelInfo, "calm")
+	if len(summaries) != 1 || summaries[0].msg != "storm" || summaries[0].suppressed != 2 {
+		t.Errorf("expected a summary of the expired storm, got %v", summaries)
+	}
+
+	if _, ok := s.windows[samplerKey{level: LevelWarning, msg: "storm"}]; ok || len(s.windows) != 1 {
+		t.Errorf("expected the expired window to be evicted, got %v", s.windows)
+	}
+
+	s.maxWindows = 4
+	for i := range 10 {
+		s.allow(start.Add(time.Second+time.Duration(i)*time.Millisecond), LevelInfo, fmt.Sprintf("request %d", i))
+
+		if len(s.windows) > s.maxWindows {
+			t.Fatalf("expected at most %d windows, got %d", s.maxWindows, len(s.windows))
+		}
+	}
+
+	if _, ok := s.windows[samplerKey{level: LevelInfo, msg: "request 9"}]; !ok {
+		t.Errorf("expected the newest window to be kept, got %v", s.windows)
+	}
+}
+
+func TestSamplingOption(t *testing.T) {
+	t.Setenv("ENV", "test")
+
+	buf := new(bytes.Buffer)
+	cfg := CreateConfig(LevelInfo, "", "", "", []string{}, []string{})
+
+	_, o, err := Initialise(context.Background(), cfg, buf, buf, WithSampling(1, time.Hour))
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	for range 3 {
+		o.Info("retrying")
+	}
+
+	o.Close()
+
+	dec := json.NewDecoder(buf)
+	records := []map[string]any{}
+	for dec.More() {
+		r := map[string]any{}
+		if err := dec.Decode(&r); err != nil {
+			t.Fatalf("failed to decode record: %v", err)
+		}
+		records = append(records, r)
+	}
+
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d: %v", len(records), records)
+	}
+
+	if records[1]["msg"] != SuppressedMessage || records[1]["suppressed"] != float64(2) || records[1]["sampled_msg"] != "retrying" {
+		t.Errorf("unexpected summary record: %v", records[1])
+	}
+}
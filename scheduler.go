@@ -0,0 +1,173 @@
+package go11y
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// JobRunsSkipped is the metric for the number of scheduled job runs skipped because the previous run was still going
+var JobRunsSkipped = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "go11y_job_runs_skipped_total",
+	Help: "Number of scheduled job runs skipped because the previous run had not finished",
+}, []string{"job"})
+
+// JobOverruns is the metric for the number of scheduled job runs that were still going when their next run was due
+var JobOverruns = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "go11y_job_overruns_total",
+	Help: "Number of scheduled job runs that were still running when their next run was due",
+}, []string{"job"})
+
+// ErrSchedulerStarted is returned when adding a job to, or starting, a Scheduler that has already been started
+var ErrSchedulerStarted = errors.New("scheduler already started")
+
+var registerSchedulerMetricsOnce sync.Once
+
+// Schedule decides when a scheduled job runs next. Implement it to plug in cron expressions or other calendars.
+type Schedule interface {
+	// Next returns the next time the job should run after $t
+	Next(t time.Time) time.Time
+}
+
+// Every returns a Schedule that runs a job every $interval.
+func Every(interval time.Duration) Schedule {
+	return everySchedule(interval)
+}
+
+type everySchedule time.Duration
+
+func (e everySchedule) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(e))
+}
+
+type scheduledJob struct {
+	name     string
+	schedule Schedule
+	fn       JobFunc
+	running  bool
+	overran  bool // the current run has already been counted as an overrun
+	mu       sync.Mutex
+}
+
+// Scheduler runs periodic jobs with Job, so each run gets its own trace, run ID log fields, duration and outcome
+// metrics. A run that is due while the previous run of the same job is still going is skipped rather than run
+// concurrently, and counted in JobRunsSkipped and JobOverruns.
+type Scheduler struct {
+	jobs    []*scheduledJob
+	started bool
+	mu      sync.Mutex
+	wg      sync.WaitGroup
+}
+
+// NewScheduler creates an empty Scheduler.
+func NewScheduler() *Scheduler {
+	registerSchedulerMetricsOnce.Do(func() {
+		registerCollectors(JobRunsSkipped, JobOverruns)
+	})
+
+	return &Scheduler{}
+}
+
+// Add registers $fn to run as the job $name according to $schedule. Jobs must be added before Start is called.
+func (s *Scheduler) Add(name string, schedule Schedule, fn JobFunc) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return fmt.Errorf("could not add job %s: %w", name, ErrSchedulerStarted)
+	}
+
+	s.jobs = append(s.jobs, &scheduledJob{name: name, schedule: schedule, fn: fn})
+
+	return nil
+}
+
+// Start runs the scheduled jobs in the background until $ctxWithObserver is cancelled. Call Wait to block until the
+// scheduler and any in-flight runs have stopped.
+// If the Observer cannot be retrieved from the provided context, an error is returned.
+func (s *Scheduler) Start(ctxWithObserver context.Context) error {
+	if _, _, err := Get(ctxWithObserver); err != nil {
+		return fmt.Errorf("could not get go11y observer from context: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return ErrSchedulerStarted
+	}
+
+	s.started = true
+
+	for _, j := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(ctxWithObserver, j)
+	}
+
+	return nil
+}
+
+// Wait blocks until the scheduler has stopped and all in-flight runs have finished.
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, j *scheduledJob) {
+	defer s.wg.Done()
+
+	clock := FromContext(ctx).clock
+	next := j.schedule.Next(clock.Now())
+
+	for {
+		timer := time.NewTimer(next.Sub(clock.Now()))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		next = j.schedule.Next(next)
+		if now := clock.Now(); next.Before(now) {
+			// don't try to catch up on runs missed while the process was paused
+			next = j.schedule.Next(now)
+		}
+
+		j.mu.Lock()
+		if j.running {
+			JobRunsSkipped.WithLabelValues(j.name).Inc()
+			if !j.overran {
+				j.overran = true
+				JobOverruns.WithLabelValues(j.name).Inc()
+			}
+			j.mu.Unlock()
+
+			if _, o, err := Get(ctx); err == nil {
+				o.Warning("scheduled job run skipped as the previous run is still going", FieldJobName, j.name)
+			}
+
+			continue
+		}
+		j.running = true
+		j.overran = false
+		j.mu.Unlock()
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer func() {
+				j.mu.Lock()
+				j.running = false
+				j.mu.Unlock()
+			}()
+
+			// failures are logged and counted by Job
+			_ = Job(ctx, j.name, j.fn)
+		}()
+	}
+}
@@ -0,0 +1,77 @@
+package go11y_test
+
+import (
+	"context"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/cirruscomms/go11y"
+)
+
+func TestScheduler(t *testing.T) {
+	cfg := go11y.CreateConfig(go11y.LevelInfo, "", "", "", []string{}, []string{})
+	ctx, _, err := go11y.Initialise(context.Background(), cfg, io.Discard, io.Discard)
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	var fast, slow atomic.Int64
+	release := make(chan struct{})
+
+	s := go11y.NewScheduler()
+
+	skippedBefore := testutil.ToFloat64(go11y.JobRunsSkipped.WithLabelValues("scheduler_test_slow"))
+	overrunsBefore := testutil.ToFloat64(go11y.JobOverruns.WithLabelValues("scheduler_test_slow"))
+
+	err = s.Add("scheduler_test_fast", go11y.Every(10*time.Millisecond), func(ctx context.Context, o *go11y.Observer) error {
+		fast.Add(1)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to add job: %v", err)
+	}
+
+	err = s.Add("scheduler_test_slow", go11y.Every(10*time.Millisecond), func(ctx context.Context, o *go11y.Observer) error {
+		slow.Add(1)
+		<-release
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to add job: %v", err)
+	}
+
+	if err := s.Start(ctx); err != nil {
+		t.Fatalf("failed to start scheduler: %v", err)
+	}
+
+	if err := s.Add("too_late", go11y.Every(time.Second), nil); err == nil {
+		t.Errorf("expected adding a job to a started scheduler to fail")
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	cancel()
+	s.Wait()
+
+	if fast.Load() < 2 {
+		t.Errorf("expected the fast job to run repeatedly, ran %d times", fast.Load())
+	}
+
+	if slow.Load() != 1 {
+		t.Errorf("expected the slow job to run once without overlapping, ran %d times", slow.Load())
+	}
+
+	if v := testutil.ToFloat64(go11y.JobRunsSkipped.WithLabelValues("scheduler_test_slow")) - skippedBefore; v < 1 {
+		t.Errorf("expected skipped runs of the slow job, got %v", v)
+	}
+
+	if v := testutil.ToFloat64(go11y.JobOverruns.WithLabelValues("scheduler_test_slow")) - overrunsBefore; v != 1 {
+		t.Errorf("expected 1 overrun of the slow job, got %v", v)
+	}
+}
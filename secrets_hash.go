@@ -0,0 +1,117 @@
+package go11y
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"sync/atomic"
+)
+
+// RedactionMode controls how RedactSecret, and so every redaction go11y performs, replaces secrets
+type RedactionMode int
+
+const (
+	// RedactLength replaces secrets with character-length-character notation, e.g. "a[11]y", the default
+	RedactLength RedactionMode = iota
+	// RedactHash replaces secrets with a prefix of their salted hash, e.g. "sha256:3f2a9c81d04e", so the same secret
+	// can be correlated across log lines and stored calls without being recoverable from them
+	RedactHash
+)
+
+// redactionHashLength is the number of hex characters of the hash kept by RedactHash, 48 bits being plenty to
+// correlate secrets without making the hash worth attacking
+const redactionHashLength = 12
+
+// errRedactionSaltMissing is the problem of a Configuration using RedactHash without a salt
+var errRedactionSaltMissing = errors.New("a salt is required when REDACTION_MODE is hash")
+
+// ParseRedactionMode maps "length" and "hash" to a RedactionMode, defaulting to RedactLength.
+func ParseRedactionMode(mode string) RedactionMode {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case "hash", "sha256":
+		return RedactHash
+	default:
+		return RedactLength
+	}
+}
+
+// String returns the name of the mode as accepted by ParseRedactionMode
+func (m RedactionMode) String() string {
+	if m == RedactHash {
+		return "hash"
+	}
+
+	return "length"
+}
+
+// RedactionConfigurator is implemented by Configurators that choose how secrets are redacted.
+// Configuration implements it, other Configurators use RedactLength.
+type RedactionConfigurator interface {
+	RedactionMode() RedactionMode
+	RedactionSalt() string
+}
+
+// WithRedactionMode sets how secrets are redacted and, for RedactHash, the salt they are hashed with. The salt should
+// be the same for every instance of a service, so secrets can be correlated across them, and kept secret, so hashes
+// can't be checked against guessed secrets. The redaction functions are package-level, so the mode of the
+// Configuration of the last Observer initialised applies to the whole process.
+func WithRedactionMode(mode RedactionMode, salt string) ConfigOption {
+	return func(c *Configuration) {
+		c.redactionMode = mode
+		c.redactionSalt = salt
+	}
+}
+
+// RedactionMode returns how secrets are redacted.
+// This method is part of the RedactionConfigurator interface.
+func (c *Configuration) RedactionMode() RedactionMode {
+	return c.redactionMode
+}
+
+// RedactionSalt returns the salt secrets are hashed with by RedactHash.
+// This method is part of the RedactionConfigurator interface.
+func (c *Configuration) RedactionSalt() string {
+	return c.redactionSalt
+}
+
+// configRedaction returns how $cfg redacts secrets
+func configRedaction(cfg Configurator) (mode RedactionMode, salt string) {
+	if rc, ok := cfg.(RedactionConfigurator); ok {
+		return rc.RedactionMode(), rc.RedactionSalt()
+	}
+
+	return RedactLength, ""
+}
+
+// redactionHashKey is the salt secrets are hashed with, nil unless RedactHash is in use
+var redactionHashKey atomic.Pointer[[]byte]
+
+// setRedaction sets how the package's redaction functions redact secrets. RedactHash without a salt (only possible
+// when the configuration isn't strictly validated) hashes with a random one, so secrets can only be correlated within
+// the process.
+func setRedaction(mode RedactionMode, salt string) {
+	if mode != RedactHash {
+		redactionHashKey.Store(nil)
+
+		return
+	}
+
+	key := []byte(salt)
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		_, _ = rand.Read(key)
+	}
+
+	redactionHashKey.Store(&key)
+}
+
+// hashSecret returns the redacted form of $secretStr under RedactHash with the salt $key
+func hashSecret(secretStr string, key []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(secretStr))
+
+	return "sha256:" + hex.EncodeToString(mac.Sum(nil))[:redactionHashLength]
+}
@@ -0,0 +1,210 @@
+package go11y
+
+import (
+	"regexp"
+	"strings"
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PIIDetectors is a set of value-based detectors of personal data, redacting matching values in headers and bodies
+// (see RedactHeaders, RedactBody, RedactForm and RedactMultipart) whatever key they are under.
+// Detectors are combined with |, e.g. PIICardNumbers|PIIEmails.
+type PIIDetectors uint8
+
+const (
+	// PIICardNumbers detects payment card numbers of 13-19 digits, optionally grouped with spaces or dashes, that
+	// pass the Luhn check
+	PIICardNumbers PIIDetectors = 1 << iota
+	// PIIEmails detects email addresses
+	PIIEmails
+	// PIINationalIDs detects national-insurance-style IDs: two letters, six digits and a letter A-D, optionally
+	// grouped with spaces (e.g. "AB 12 34 56 C")
+	PIINationalIDs
+	// PIIJWTs detects JSON Web Tokens, three base64url segments of which the first two are JSON objects
+	PIIJWTs
+
+	// PIINone disables value-based detection, the default
+	PIINone PIIDetectors = 0
+	// PIIAll enables every detector
+	PIIAll = PIICardNumbers | PIIEmails | PIINationalIDs | PIIJWTs
+)
+
+// piiDetector is a value-based detector of personal data
+type piiDetector struct {
+	flag  PIIDetectors
+	name  string
+	rex   *regexp.Regexp
+	valid func(match string) bool // optional - further checks of a match of rex, e.g. the Luhn check of card numbers
+}
+
+// piiDetectorList is the detectors in the order they are applied, JWTs first as their segments could contain anything
+var piiDetectorList = []piiDetector{
+	{
+		flag: PIIJWTs,
+		name: "jwt",
+		rex:  regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`),
+	},
+	{
+		flag: PIIEmails,
+		name: "email",
+		rex:  regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+	},
+	{
+		flag:  PIICardNumbers,
+		name:  "card",
+		rex:   regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`),
+		valid: luhnValid,
+	},
+	{
+		flag: PIINationalIDs,
+		name: "national_id",
+		rex:  regexp.MustCompile(`(?i)\b[A-CEGHJ-PR-TW-Z]{2} ?\d{2} ?\d{2} ?\d{2} ?[A-D]\b`),
+	},
+}
+
+// PIIRedactions is the metric for the number of values redacted by the PII detectors, by detector
+var PIIRedactions = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "go11y_pii_redactions_total",
+	Help: "Number of values redacted by go11y's PII detectors",
+}, []string{"detector"})
+
+// enabledPIIDetectors is the set of detectors applied by the package's redaction functions, set by Initialise
+var enabledPIIDetectors atomic.Uint32
+
+// ParsePIIDetectors maps a comma-separated list of detector names ("card", "email", "national_id", "jwt", "all" or
+// "none") to PIIDetectors, ignoring names it doesn't know.
+func ParsePIIDetectors(detectors string) (enabled PIIDetectors) {
+	for name := range strings.SplitSeq(detectors, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch name {
+		case "all":
+			enabled |= PIIAll
+		case "nino", "national_insurance":
+			enabled |= PIINationalIDs
+		default:
+			for _, d := range piiDetectorList {
+				if d.name == name {
+					enabled |= d.flag
+				}
+			}
+		}
+	}
+
+	return enabled
+}
+
+// String returns the names of the detectors in the set as accepted by ParsePIIDetectors
+func (d PIIDetectors) String() string {
+	names := []string{}
+	for _, detector := range piiDetectorList {
+		if d&detector.flag != 0 {
+			names = append(names, detector.name)
+		}
+	}
+
+	if len(names) == 0 {
+		return "none"
+	}
+
+	return strings.Join(names, ",")
+}
+
+// PIIConfigurator is implemented by Configurators that enable value-based PII detection.
+// Configuration implements it, other Configurators only redact values by the name of their key.
+type PIIConfigurator interface {
+	PIIDetectors() PIIDetectors
+}
+
+// WithPIIDetectors enables value-based detection of personal data in headers and bodies, on top of the redaction of
+// values by the name of their key. The redaction functions are package-level, so the detectors enabled by the
+// Configuration of the last Observer initialised apply to the whole process.
+func WithPIIDetectors(detectors PIIDetectors) ConfigOption {
+	return func(c *Configuration) {
+		c.piiDetectors = detectors
+	}
+}
+
+// PIIDetectors returns the value-based detectors of personal data that are enabled.
+// This method is part of the PIIConfigurator interface.
+func (c *Configuration) PIIDetectors() PIIDetectors {
+	return c.piiDetectors
+}
+
+// configPIIDetectors returns the value-based detectors of personal data enabled by $cfg
+func configPIIDetectors(cfg Configurator) PIIDetectors {
+	if pc, ok := cfg.(PIIConfigurator); ok {
+		return pc.PIIDetectors()
+	}
+
+	return PIINone
+}
+
+// setPIIDetectors sets the detectors applied by the package's redaction functions
+func setPIIDetectors(detectors PIIDetectors) {
+	enabledPIIDetectors.Store(uint32(detectors))
+}
+
+// redactPII redacts the personal data found by the enabled detectors in $value. It costs a single atomic load when
+// no detector is enabled.
+func redactPII(value string) (redacted string, changed bool) {
+	enabled := PIIDetectors(enabledPIIDetectors.Load())
+	if enabled == PIINone || value == "" {
+		return value, false
+	}
+
+	redacted = value
+	for _, d := range piiDetectorList {
+		if enabled&d.flag == 0 {
+			continue
+		}
+
+		redacted = d.rex.ReplaceAllStringFunc(redacted, func(match string) string {
+			if d.valid != nil && !d.valid(match) {
+				return match
+			}
+
+			changed = true
+			PIIRedactions.WithLabelValues(d.name).Inc()
+
+			return RedactSecret(match, 0)
+		})
+	}
+
+	return redacted, changed
+}
+
+// luhnValid reports whether the digits of $number, ignoring spaces and dashes, pass the Luhn check
+func luhnValid(number string) bool {
+	sum, double := 0, false
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c == ' ' || c == '-' {
+			continue
+		}
+
+		digit := int(c - '0')
+		if double {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+
+		sum += digit
+		double = !double
+	}
+
+	return sum%10 == 0
+}
+
+// redactPIIBytes redacts the personal data found by the enabled detectors in $blob, returning it unmodified if none
+// is found
+func redactPIIBytes(blob []byte) []byte {
+	if redacted, changed := redactPII(string(blob)); changed {
+		return []byte(redacted)
+	}
+
+	return blob
+}
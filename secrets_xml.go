@@ -0,0 +1,131 @@
+package go11y
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+)
+
+// isXMLMediaType reports whether $mediaType is an XML media type, e.g. text/xml or application/soap+xml
+func isXMLMediaType(mediaType string) bool {
+	return mediaType == "text/xml" || mediaType == "application/xml" || strings.HasSuffix(mediaType, "+xml")
+}
+
+// RedactXML redacts an XML document, such as a SOAP envelope, per the redaction policy: the text of elements and the
+// values of attributes whose local names are forbidden keys are redacted as in RedactBody, and values matching the
+// enabled PII detectors are redacted everywhere. The rest of the document is kept byte for byte, so namespace
+// prefixes survive. If $xmlBlob can't be parsed, only PII is redacted.
+func RedactXML(xmlBlob []byte) []byte {
+	d := xml.NewDecoder(bytes.NewReader(xmlBlob))
+	d.Strict = false
+
+	out := bytes.Buffer{}
+	out.Grow(len(xmlBlob))
+
+	// whether each open element is forbidden, innermost last
+	forbidden := []bool{}
+	offset := int64(0)
+
+	for {
+		tok, err := d.RawToken()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return redactPIIBytes(xmlBlob)
+		}
+
+		raw := xmlBlob[offset:d.InputOffset()]
+		offset = d.InputOffset()
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			raw = redactXMLAttrs(raw, t)
+
+			// self-closing elements are followed by an EndElement read from no input
+			forbidden = append(forbidden, forbiddenKey(t.Name.Local))
+		case xml.EndElement:
+			if len(forbidden) > 0 {
+				forbidden = forbidden[:len(forbidden)-1]
+			}
+		case xml.CharData:
+			if text, ok := redactXMLText(string(t), len(forbidden) > 0 && forbidden[len(forbidden)-1]); ok {
+				escaped := bytes.Buffer{}
+				_ = xml.EscapeText(&escaped, []byte(text))
+				raw = escaped.Bytes()
+			}
+		}
+
+		out.Write(raw)
+	}
+
+	return out.Bytes()
+}
+
+// redactXMLText returns $text redacted, and whether it was: all of it if it is the text of a $forbidden element,
+// otherwise the values matching the enabled PII detectors
+func redactXMLText(text string, forbidden bool) (redacted string, ok bool) {
+	if forbidden {
+		if strings.TrimSpace(text) == "" {
+			return text, false
+		}
+
+		return RedactSecret(text, 6), true
+	}
+
+	return redactPII(text)
+}
+
+// redactXMLAttrs returns the $raw start element $t with the values of its forbidden attributes redacted, or $raw
+// unchanged if it has none
+func redactXMLAttrs(raw []byte, t xml.StartElement) []byte {
+	redact := false
+	for _, a := range t.Attr {
+		if forbiddenKey(a.Name.Local) {
+			redact = true
+			break
+		}
+	}
+
+	if !redact {
+		if redacted, ok := redactPII(string(raw)); ok {
+			return []byte(redacted)
+		}
+
+		return raw
+	}
+
+	b := bytes.Buffer{}
+	b.WriteString("<" + xmlName(t.Name))
+	for _, a := range t.Attr {
+		value := a.Value
+		if forbiddenKey(a.Name.Local) {
+			value = RedactSecret(value, 6)
+		} else {
+			value, _ = redactPII(value)
+		}
+
+		b.WriteString(" " + xmlName(a.Name) + `="`)
+		_ = xml.EscapeText(&b, []byte(value))
+		b.WriteString(`"`)
+	}
+
+	if bytes.HasSuffix(raw, []byte("/>")) {
+		b.WriteString("/>")
+	} else {
+		b.WriteString(">")
+	}
+
+	return b.Bytes()
+}
+
+// xmlName returns the prefixed name of $n as written in the document, as read by RawToken
+func xmlName(n xml.Name) string {
+	if n.Space == "" {
+		return n.Local
+	}
+
+	return n.Space + ":" + n.Local
+}
@@ -0,0 +1,123 @@
+package go11y
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ServerConnections is the metric for the number of connections to an http.Server wrapped by WrapServer, by server
+// address and connection state
+var ServerConnections = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "go11y_server_connections",
+	Help: "Number of connections to the HTTP server by state",
+}, []string{"addr", "state"})
+
+// ServerConnectionsTotal is the metric for the number of connections accepted by an http.Server wrapped by
+// WrapServer, by server address
+var ServerConnectionsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "go11y_server_connections_total",
+	Help: "Number of connections accepted by the HTTP server",
+}, []string{"addr"})
+
+var registerServerMetricsOnce sync.Once
+
+// WrapServer gives $srv the Observer in $ctx: server errors are logged through it at LevelWarning (unless ErrorLog is
+// already set), connections are counted by state in ServerConnections, request contexts carry the Observer and the
+// start and end of a graceful shutdown are logged. Existing ConnState and BaseContext hooks are still called.
+// An error is returned if $ctx doesn't hold an Observer.
+func WrapServer(srv *http.Server, ctx context.Context) (fault error) {
+	_, o, err := Get(ctx)
+	if err != nil {
+		return fmt.Errorf("could not wrap server: %w", err)
+	}
+
+	registerServerMetricsOnce.Do(func() {
+		registerCollectors(ServerConnections, ServerConnectionsTotal)
+	})
+
+	if srv.ErrorLog == nil {
+		srv.ErrorLog = o.StdLogger(LevelWarning)
+	}
+
+	baseContext := srv.BaseContext
+	srv.BaseContext = func(l net.Listener) context.Context {
+		if baseContext != nil {
+			return AddToContext(baseContext(l), o)
+		}
+
+		return ctx
+	}
+
+	tracker := &connTracker{o: o, addr: srv.Addr, states: map[net.Conn]http.ConnState{}}
+
+	connState := srv.ConnState
+	srv.ConnState = func(conn net.Conn, state http.ConnState) {
+		tracker.transition(conn, state)
+
+		if connState != nil {
+			connState(conn, state)
+		}
+	}
+
+	srv.RegisterOnShutdown(tracker.shutdown)
+
+	return nil
+}
+
+// connTracker follows the state of an http.Server's connections for WrapServer
+type connTracker struct {
+	o            *Observer
+	addr         string
+	mu           sync.Mutex
+	states       map[net.Conn]http.ConnState
+	shuttingDown atomic.Bool
+}
+
+// transition moves $conn to $state, logging when the last connection closes during a graceful shutdown
+func (t *connTracker) transition(conn net.Conn, state http.ConnState) {
+	t.mu.Lock()
+	previous, known := t.states[conn]
+
+	switch state {
+	case http.StateHijacked, http.StateClosed:
+		delete(t.states, conn)
+	default:
+		t.states[conn] = state
+	}
+
+	open := len(t.states)
+	t.mu.Unlock()
+
+	if known {
+		ServerConnections.WithLabelValues(t.addr, previous.String()).Dec()
+	}
+
+	switch state {
+	case http.StateNew:
+		ServerConnectionsTotal.WithLabelValues(t.addr).Inc()
+		ServerConnections.WithLabelValues(t.addr, state.String()).Inc()
+	case http.StateActive, http.StateIdle:
+		ServerConnections.WithLabelValues(t.addr, state.String()).Inc()
+	}
+
+	if known && open == 0 && t.shuttingDown.Load() {
+		t.o.log(context.Background(), 4, LevelInfo, "http server drained", "addr", t.addr)
+	}
+}
+
+// shutdown logs the start of a graceful shutdown with the number of connections still open
+func (t *connTracker) shutdown() {
+	t.shuttingDown.Store(true)
+
+	t.mu.Lock()
+	open := len(t.states)
+	t.mu.Unlock()
+
+	t.o.log(context.Background(), 4, LevelInfo, "http server shutting down", "addr", t.addr, "connections", open)
+}
@@ -0,0 +1,105 @@
+package go11y_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/cirruscomms/go11y"
+)
+
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
+
+func TestWrapServer(t *testing.T) {
+	out := &lockedBuffer{}
+	cfg := go11y.NewConfig(go11y.WithLogLevel(go11y.LevelInfo))
+
+	ctx, _, err := go11y.Initialise(context.Background(), cfg, out, out)
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	srv := &http.Server{
+		Addr: listener.Addr().String(),
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !go11y.InContext(r.Context()) {
+				t.Errorf("expected the request context to carry the Observer")
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}),
+		ReadHeaderTimeout: time.Second,
+	}
+
+	if err := go11y.WrapServer(srv, context.Background()); err == nil {
+		t.Errorf("expected wrapping without an Observer to fail")
+	}
+	if err := go11y.WrapServer(srv, ctx); err != nil {
+		t.Fatalf("failed to wrap server: %v", err)
+	}
+
+	served := make(chan error, 1)
+	go func() {
+		served <- srv.Serve(listener)
+	}()
+
+	client := &http.Client{Transport: &http.Transport{}}
+	res, err := client.Get("http://" + srv.Addr)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	_ = res.Body.Close()
+
+	if got := testutil.ToFloat64(go11y.ServerConnectionsTotal.WithLabelValues(srv.Addr)); got != 1 {
+		t.Errorf("expected 1 accepted connection, got %v", got)
+	}
+
+	if err := srv.Shutdown(context.Background()); err != nil {
+		t.Fatalf("failed to shut down server: %v", err)
+	}
+	if err := <-served; !errors.Is(err, http.ErrServerClosed) {
+		t.Errorf("expected the server to be closed, got %v", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for !strings.Contains(out.String(), "http server shutting down") && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !strings.Contains(out.String(), "http server shutting down") {
+		t.Errorf("expected the shutdown to be logged, got %s", out.String())
+	}
+
+	srv.ErrorLog.Print("http: TLS handshake error from 10.0.0.1:5000: EOF")
+	if !strings.Contains(out.String(), `"msg":"http: TLS handshake error from 10.0.0.1:5000: EOF"`) {
+		t.Errorf("expected the server's errors to be logged, got %s", out.String())
+	}
+}
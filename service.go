@@ -0,0 +1,145 @@
+package go11y
+
+import (
+	"os"
+	"runtime/debug"
+	"slices"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	otelAttribute "go.opentelemetry.io/otel/attribute"
+	otelSemConv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"google.golang.org/protobuf/proto"
+)
+
+// ServiceConfigurator is implemented by Configurators that know the version and instance of the service, which are
+// added to every signal alongside the service name. Configuration implements it, Configurators that don't only get
+// the service name added.
+type ServiceConfigurator interface {
+	// ServiceVersion is the version of the service, e.g. a release tag or commit
+	ServiceVersion() string
+	// InstanceID identifies the instance of the service, e.g. the hostname of its pod
+	InstanceID() string
+}
+
+// serviceIdentity is the service name, version and instance ID added to every signal, empty values are left out
+type serviceIdentity struct {
+	name       string
+	version    string
+	instanceID string
+}
+
+// configServiceIdentity returns the service identity of $cfg
+func configServiceIdentity(cfg Configurator) (id serviceIdentity) {
+	if cfg == nil {
+		return serviceIdentity{}
+	}
+
+	id.name = cfg.ServiceName()
+	if sc, ok := cfg.(ServiceConfigurator); ok {
+		id.version = sc.ServiceVersion()
+		id.instanceID = sc.InstanceID()
+	}
+
+	return id
+}
+
+// fields returns the identity as field names and values
+func (id serviceIdentity) fields() (keys, values []string) {
+	for _, f := range [][2]string{
+		{FieldServiceName, id.name},
+		{FieldServiceVersion, id.version},
+		{FieldServiceInstanceID, id.instanceID},
+	} {
+		if f[1] != "" {
+			keys = append(keys, f[0])
+			values = append(values, f[1])
+		}
+	}
+
+	return keys, values
+}
+
+// args returns the identity as log args, leaving out those already in $args
+func (id serviceIdentity) args(args []any) (identityArgs []any) {
+	keys, values := id.fields()
+	for i, k := range keys {
+		if !slices.Contains(args, any(k)) {
+			identityArgs = append(identityArgs, k, values[i])
+		}
+	}
+
+	return identityArgs
+}
+
+// resourceAttributes returns the identity as OTel resource attributes
+func (id serviceIdentity) resourceAttributes() []otelAttribute.KeyValue {
+	attrs := []otelAttribute.KeyValue{otelSemConv.ServiceNameKey.String(id.name)}
+	if id.version != "" {
+		attrs = append(attrs, otelSemConv.ServiceVersionKey.String(id.version))
+	}
+	if id.instanceID != "" {
+		attrs = append(attrs, otelSemConv.ServiceInstanceIDKey.String(id.instanceID))
+	}
+
+	return attrs
+}
+
+// buildVersion returns the version of the main module the binary was built from, or the VCS revision it was built at
+// if it wasn't built from a tagged module, or "" if neither is known
+func buildVersion() string {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+
+	if bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+		return bi.Main.Version
+	}
+
+	for _, s := range bi.Settings {
+		if s.Key == "vcs.revision" {
+			return s.Value[:min(len(s.Value), 12)]
+		}
+	}
+
+	return ""
+}
+
+// defaultInstanceID returns the hostname, which is the pod name on Kubernetes, or "" if it can't be read
+func defaultInstanceID() string {
+	hostname, _ := os.Hostname()
+	return hostname
+}
+
+// serviceGatherer adds the service identity of the default Observer (see Default) to every metric gathered by the
+// wrapped Gatherer as constant labels, unless the metric already has a label of the same name, so the metrics served by
+// MetricsHandler and pushed by a MetricsPusher are attributable without every metric declaring them.
+type serviceGatherer struct {
+	prometheus.Gatherer
+}
+
+// Gather implements prometheus.Gatherer
+func (g serviceGatherer) Gather() (families []*dto.MetricFamily, fault error) {
+	families, err := g.Gatherer.Gather()
+
+	keys, values := configServiceIdentity(Default().cfg).fields()
+	if len(keys) == 0 {
+		return families, err
+	}
+
+	for _, mf := range families {
+		for _, m := range mf.GetMetric() {
+			for i, k := range keys {
+				if !slices.ContainsFunc(m.GetLabel(), func(l *dto.LabelPair) bool { return l.GetName() == k }) {
+					m.Label = append(m.Label, &dto.LabelPair{Name: proto.String(k), Value: proto.String(values[i])})
+				}
+			}
+
+			slices.SortFunc(m.Label, func(a, b *dto.LabelPair) int { return strings.Compare(a.GetName(), b.GetName()) })
+		}
+	}
+
+	return families, err
+}
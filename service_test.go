@@ -0,0 +1,67 @@
+package go11y_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cirruscomms/go11y"
+)
+
+func TestServiceIdentity(t *testing.T) {
+	buf := new(bytes.Buffer)
+	cfg := go11y.NewConfig(
+		go11y.WithLogLevel(go11y.LevelInfo),
+		go11y.WithServiceName("billing"),
+		go11y.WithServiceVersion("v1.2.3"),
+		go11y.WithInstanceID("billing-7d9f-x2"),
+	)
+
+	ctx, o, err := go11y.Initialise(context.Background(), cfg, buf, buf)
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+	defer o.Close()
+
+	go11y.FromContext(ctx).Info("attributable")
+
+	record := map[string]any{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("failed to parse log record: %v", err)
+	}
+
+	expected := map[string]string{
+		go11y.FieldServiceName:       "billing",
+		go11y.FieldServiceVersion:    "v1.2.3",
+		go11y.FieldServiceInstanceID: "billing-7d9f-x2",
+	}
+	for k, v := range expected {
+		if record[k] != v {
+			t.Errorf("expected %s %q in the log record, got %v", k, v, record[k])
+		}
+	}
+
+	w := httptest.NewRecorder()
+	go11y.MetricsHandler().ServeHTTP(w, httptest.NewRequest("GET", "/internal/metrics", nil))
+
+	body, _ := io.ReadAll(w.Body)
+	for line := range strings.SplitSeq(string(body), "\n") {
+		if !strings.HasPrefix(line, "go11y_log_records_total{") {
+			continue
+		}
+
+		for _, label := range []string{`service_name="billing"`, `service_version="v1.2.3"`, `service_instance_id="billing-7d9f-x2"`} {
+			if !strings.Contains(line, label) {
+				t.Errorf("expected %s on every metric, got %s", label, line)
+			}
+		}
+
+		return
+	}
+
+	t.Errorf("expected go11y_log_records_total to be served, got %s", body)
+}
@@ -1,5 +1,10 @@
 package go11y
 
+import (
+	"errors"
+	"sync"
+)
+
 // SeverityLowest errors pose no threat to system/process operation - the user can fix this themselves and continue this
 // one operation
 const SeverityLowest string = "lowest"
@@ -20,3 +25,130 @@ const SeverityHigh string = "high"
 //
 //	will need to be fixed, and there may be wider implications for the system/process as a whole
 const SeverityHighest string = "highest"
+
+// SeverityBehaviour is what go11y does with the errors of a severity or error code, see RegisterSeverity and
+// RegisterErrorCode
+type SeverityBehaviour struct {
+	// optional - the code Fatal exits with, defaults to 1
+	ExitCode int
+	// optional - whether the error is logged with "alert": true, for log-based alerting to pick up, defaults to false
+	Alert bool
+	// optional - whether the span's status is left alone (the error is still recorded on the span), defaults to false
+	// so the span's status is set to Error
+	KeepSpanStatus bool
+}
+
+// exitCode returns the code Fatal exits with
+func (b SeverityBehaviour) exitCode() int {
+	if b.ExitCode < 1 {
+		return 1
+	}
+
+	return b.ExitCode
+}
+
+var (
+	severityRegistry = map[string]SeverityBehaviour{
+		SeverityLowest:  {},
+		SeverityLow:     {},
+		SeverityMedium:  {},
+		SeverityHigh:    {Alert: true},
+		SeverityHighest: {Alert: true},
+	}
+	errorCodeRegistry = map[string]SeverityBehaviour{}
+	registryMu        sync.RWMutex
+)
+
+// RegisterSeverity sets the behaviour of errors logged at $severity, replacing the behaviour of the built-in
+// severities or adding a severity of the service's own. Severities that aren't registered behave like SeverityLowest.
+func RegisterSeverity(severity string, behaviour SeverityBehaviour) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	severityRegistry[severity] = behaviour
+}
+
+// RegisterErrorCode sets the behaviour of errors with the code $code (see WithErrorCode), which takes precedence over
+// the behaviour of the severity they are logged at.
+func RegisterErrorCode(code string, behaviour SeverityBehaviour) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	errorCodeRegistry[code] = behaviour
+}
+
+// LookupSeverity returns the behaviour of $err logged at $severity: that of its error code if it has a registered
+// one, otherwise that of the severity.
+func LookupSeverity(severity string, err error) (behaviour SeverityBehaviour) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	if code := ErrorCode(err); code != "" {
+		if behaviour, ok := errorCodeRegistry[code]; ok {
+			return behaviour
+		}
+	}
+
+	return severityRegistry[severity]
+}
+
+// CodedError is implemented by errors that carry a code from the service's error taxonomy, logged as the error_code
+// field and used to look up their behaviour, see RegisterErrorCode
+type CodedError interface {
+	error
+	ErrorCode() string
+}
+
+// codedError is an error with a code, see WithErrorCode
+type codedError struct {
+	err  error
+	code string
+}
+
+func (e *codedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *codedError) Unwrap() error {
+	return e.err
+}
+
+// ErrorCode returns the code of the error
+func (e *codedError) ErrorCode() string {
+	return e.code
+}
+
+// WithErrorCode wraps $err with the code $code, see CodedError
+func WithErrorCode(err error, code string) error {
+	if err == nil {
+		return nil
+	}
+
+	return &codedError{err: err, code: code}
+}
+
+// ErrorCode returns the code of the first CodedError in the chain of $err, or "" if there isn't one
+func ErrorCode(err error) string {
+	var coded CodedError
+	if errors.As(err, &coded) {
+		return coded.ErrorCode()
+	}
+
+	return ""
+}
+
+// errorArgs returns $args with the fields logged for $err at $severity, and the behaviour of the error
+func errorArgs(err error, severity string, args []any) (errArgs []any, behaviour SeverityBehaviour) {
+	behaviour = LookupSeverity(severity, err)
+
+	errArgs = append(args, "error", err.Error(), "severity", severity)
+	if code := ErrorCode(err); code != "" {
+		errArgs = append(errArgs, FieldErrorCode, code)
+	}
+
+	if behaviour.Alert {
+		errArgs = append(errArgs, FieldAlert, true)
+	}
+
+	return errArgs, behaviour
+}
@@ -0,0 +1,105 @@
+package go11y
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+	otelSDKTrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSeverityRegistry(t *testing.T) {
+	RegisterSeverity("customer", SeverityBehaviour{KeepSpanStatus: true})
+	RegisterErrorCode("CARD_DECLINED", SeverityBehaviour{ExitCode: 3})
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(severityRegistry, "customer")
+		delete(errorCodeRegistry, "CARD_DECLINED")
+		registryMu.Unlock()
+	})
+
+	declined := WithErrorCode(errors.New("card declined"), "CARD_DECLINED")
+
+	testCases := map[string]struct {
+		severity   string
+		err        error
+		alert      bool
+		errorCode  string
+		spanStatus codes.Code
+	}{
+		"built-in severity": {
+			severity:   SeverityLow,
+			err:        errors.New("bad input"),
+			spanStatus: codes.Error,
+		},
+		"alerting severity": {
+			severity:   SeverityHigh,
+			err:        errors.New("database down"),
+			alert:      true,
+			spanStatus: codes.Error,
+		},
+		"registered severity": {
+			severity:   "customer",
+			err:        errors.New("bad input"),
+			spanStatus: codes.Unset,
+		},
+		"error code takes precedence": {
+			severity:   SeverityHighest,
+			err:        fmt.Errorf("could not take payment: %w", declined),
+			errorCode:  "CARD_DECLINED",
+			spanStatus: codes.Error,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			recorder := tracetest.NewSpanRecorder()
+			tp := otelSDKTrace.NewTracerProvider(otelSDKTrace.WithSpanProcessor(recorder))
+
+			ctx, _, err := Initialise(context.Background(), NewConfig(WithLogLevel(LevelInfo)), io.Discard, buf)
+			if err != nil {
+				t.Fatalf("failed to initialise observer: %v", err)
+			}
+
+			_, o, err := Span(ctx, tp.Tracer("test"), "TestSeverityRegistry", SpanKindInternal)
+			if err != nil {
+				t.Fatalf("failed to start span: %v", err)
+			}
+
+			o.Error("failed", tc.err, tc.severity)
+			o.End()
+
+			record := map[string]any{}
+			if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+				t.Fatalf("failed to decode the record: %v", err)
+			}
+
+			if alert, _ := record[FieldAlert].(bool); alert != tc.alert {
+				t.Errorf("expected alert to be %t, got %v", tc.alert, record[FieldAlert])
+			}
+
+			if code, _ := record[FieldErrorCode].(string); code != tc.errorCode {
+				t.Errorf("expected error_code %q, got %v", tc.errorCode, record[FieldErrorCode])
+			}
+
+			if status := recorder.Ended()[0].Status().Code; status != tc.spanStatus {
+				t.Errorf("expected span status %s, got %s", tc.spanStatus, status)
+			}
+		})
+	}
+
+	if code := LookupSeverity(SeverityHighest, fmt.Errorf("wrapped: %w", declined)).exitCode(); code != 3 {
+		t.Errorf("expected the exit code of the error code, got %d", code)
+	}
+
+	if code := LookupSeverity("unregistered", errors.New("failure")).exitCode(); code != 1 {
+		t.Errorf("expected unregistered severities to exit with 1, got %d", code)
+	}
+}
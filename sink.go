@@ -0,0 +1,241 @@
+package go11y
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SinkFormat is the output format of a Sink
+type SinkFormat int
+
+const (
+	// SinkFormatJSON writes records as JSON, the same as the Observer's main outputs
+	SinkFormatJSON SinkFormat = iota
+	// SinkFormatText writes records as logfmt style key=value text
+	SinkFormatText
+)
+
+// Sink is an additional output for an Observer's log records, added with WithSinks.
+// Records below MinLevel, or below the Observer's configured level, are not written to the sink, except that the
+// records written late by the flight recorder (see FlightRecorderOpts) only need to be at or above MinLevel.
+type Sink struct {
+	Writer   io.Writer
+	MinLevel slog.Level
+	Format   SinkFormat
+}
+
+func (s Sink) handler(opts *slog.HandlerOptions) slog.Handler {
+	sinkOpts := *opts
+	if opts.Level == nil || s.MinLevel > opts.Level.Level() {
+		sinkOpts.Level = s.MinLevel
+	}
+
+	if s.Format == SinkFormatText {
+		return slog.NewTextHandler(s.Writer, &sinkOpts)
+	}
+
+	return slog.NewJSONHandler(s.Writer, &sinkOpts)
+}
+
+// teeHandler is a slog.Handler that passes each record on to every handler enabled for its level.
+type teeHandler struct {
+	handlers  []slog.Handler
+	minLevels []slog.Level // the level each handler takes records written by the flight recorder from
+}
+
+// Enabled reports whether any of the handlers is enabled for the level.
+func (h *teeHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, handler := range h.handlers {
+		if handler.Enabled(ctx, level) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Handle passes the record to every handler enabled for its level, returning the joined errors of any that failed.
+func (h *teeHandler) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+
+	flushed := flightFlushing(ctx)
+
+	for i, handler := range h.handlers {
+		if !handler.Enabled(ctx, r.Level) && (!flushed || r.Level < h.minLevels[i]) {
+			continue
+		}
+
+		if err := handler.Handle(ctx, r.Clone()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// WithAttrs returns a teeHandler whose handlers all have the attributes added.
+func (h *teeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make([]slog.Handler, len(h.handlers))
+	for i, handler := range h.handlers {
+		handlers[i] = handler.WithAttrs(attrs)
+	}
+
+	return &teeHandler{handlers: handlers, minLevels: h.minLevels}
+}
+
+// WithGroup returns a teeHandler whose handlers all have the group added.
+func (h *teeHandler) WithGroup(name string) slog.Handler {
+	handlers := make([]slog.Handler, len(h.handlers))
+	for i, handler := range h.handlers {
+		handlers[i] = handler.WithGroup(name)
+	}
+
+	return &teeHandler{handlers: handlers, minLevels: h.minLevels}
+}
+
+// recordLevel extracts the level from a go11y JSON log record written with any FieldSchema, returning LevelDebug if it
+// cannot be determined.
+func recordLevel(record []byte) slog.Level {
+	return StringToLevel(recordString(record, slog.LevelKey))
+}
+
+// recordString extracts $field from a go11y JSON log record as a string, returning "" if it has none.
+func recordString(record []byte, field string) string {
+	r := map[string]any{}
+	if err := json.Unmarshal(record, &r); err != nil {
+		return ""
+	}
+
+	v := recordField(r, field)
+	if v == nil {
+		return ""
+	}
+
+	return fmt.Sprint(v)
+}
+
+// trimNewline removes the trailing newline slog handlers add to each record.
+func trimNewline(record []byte) []byte {
+	return bytes.TrimSuffix(record, []byte("\n"))
+}
+
+// LogOutputConfigurator is implemented by Configurators that choose the log output opened when none is passed to
+// Initialise. Configuration implements it, other Configurators log to os.Stdout.
+type LogOutputConfigurator interface {
+	LogOutput() string
+}
+
+// configLogOutput returns the log output of $cfg, see OpenLogOutput
+func configLogOutput(cfg Configurator) string {
+	if lc, ok := cfg.(LogOutputConfigurator); ok {
+		return lc.LogOutput()
+	}
+
+	return "stdout"
+}
+
+// OpenLogOutput opens the log output described by $spec, as used by the LOG_OUTPUT environment variable:
+//   - "" or "stdout" writes to os.Stdout
+//   - "stderr" writes to os.Stderr
+//   - "syslog" writes to the local syslog daemon, "syslog:udp://host:514" or "syslog:tcp://host:514" to a remote one
+//   - "journald" writes to the systemd journal
+//   - "file:/var/log/app.log" writes to a RotatingFileWriter, configured with the optional query parameters
+//     max_size_mb, max_age (a time.Duration), max_backups and compress, e.g.
+//     "file:/var/log/app.log?max_size_mb=100&max_age=24h&max_backups=7&compress=true"
+func OpenLogOutput(spec string) (output io.Writer, fault error) {
+	switch {
+	case spec == "", spec == "stdout":
+		return os.Stdout, nil
+	case spec == "stderr":
+		return os.Stderr, nil
+	case spec == "syslog":
+		// writers are only returned without an error, so a failure isn't a non-nil io.Writer holding a nil pointer
+		writer, err := NewSyslogWriter("", "", "")
+		if err != nil {
+			return nil, err
+		}
+
+		return writer, nil
+	case strings.HasPrefix(spec, "syslog:"):
+		u, err := url.Parse(strings.TrimPrefix(spec, "syslog:"))
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("invalid syslog address in log output %q", spec)
+		}
+
+		writer, err := NewSyslogWriter(u.Scheme, u.Host, "")
+		if err != nil {
+			return nil, err
+		}
+
+		return writer, nil
+	case spec == "journald":
+		writer, err := NewJournaldWriter("")
+		if err != nil {
+			return nil, err
+		}
+
+		return writer, nil
+	case strings.HasPrefix(spec, "file:"):
+		path, rawQuery, _ := strings.Cut(strings.TrimPrefix(spec, "file:"), "?")
+		if path == "" {
+			return nil, fmt.Errorf("no path provided in log output %q", spec)
+		}
+
+		query, err := url.ParseQuery(rawQuery)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse options in log output %q: %w", spec, err)
+		}
+
+		opts := RotatingFileOptions{
+			MaxSize:    100 * 1024 * 1024,
+			MaxAge:     24 * time.Hour,
+			MaxBackups: 7,
+			Compress:   true,
+		}
+
+		if v := query.Get("max_size_mb"); v != "" {
+			mb, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid max_size_mb in log output %q: %w", spec, err)
+			}
+			opts.MaxSize = mb * 1024 * 1024
+		}
+
+		if v := query.Get("max_age"); v != "" {
+			if opts.MaxAge, err = time.ParseDuration(v); err != nil {
+				return nil, fmt.Errorf("invalid max_age in log output %q: %w", spec, err)
+			}
+		}
+
+		if v := query.Get("max_backups"); v != "" {
+			if opts.MaxBackups, err = strconv.Atoi(v); err != nil {
+				return nil, fmt.Errorf("invalid max_backups in log output %q: %w", spec, err)
+			}
+		}
+
+		if v := query.Get("compress"); v != "" {
+			if opts.Compress, err = strconv.ParseBool(v); err != nil {
+				return nil, fmt.Errorf("invalid compress in log output %q: %w", spec, err)
+			}
+		}
+
+		writer, err := NewRotatingFileWriter(path, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		return writer, nil
+	default:
+		return nil, fmt.Errorf("unsupported log output %q", spec)
+	}
+}
@@ -0,0 +1,176 @@
+package go11y
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecordLevel(t *testing.T) {
+	testCases := map[string]struct {
+		record   string
+		expected int
+	}{
+		"debug":   {record: `{"level":"DEBUG","msg":"m"}`, expected: priorityDebug},
+		"info":    {record: `{"level":"INFO","msg":"m"}`, expected: priorityInfo},
+		"notice":  {record: `{"level":"NOTICE","msg":"m"}`, expected: priorityNotice},
+		"warn":    {record: `{"level":"WARN","msg":"m"}`, expected: priorityWarning},
+		"error":   {record: `{"level":"ERR","msg":"m"}`, expected: priorityErr},
+		"panic":   {record: `{"level":"PANIC","msg":"m"}`, expected: priorityCrit},
+		"fatal":   {record: `{"level":"FATAL","msg":"m"}`, expected: priorityAlert},
+		"invalid": {record: `not json`, expected: priorityDebug},
+		"ecs":     {record: `{"log.level":"ERR","message":"m"}`, expected: priorityErr},
+		"otel":    {record: `{"severity_text":"WARN","body":"m"}`, expected: priorityWarning},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got := journalPriority(recordLevel([]byte(tc.record)))
+			if got != tc.expected {
+				t.Errorf("expected priority %d, got %d", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestWriteJournalField(t *testing.T) {
+	buf := &bytes.Buffer{}
+	writeJournalField(buf, "MESSAGE", []byte("single line"))
+
+	if buf.String() != "MESSAGE=single line\n" {
+		t.Errorf("unexpected single line field: %q", buf.String())
+	}
+
+	buf.Reset()
+	writeJournalField(buf, "MESSAGE", []byte("two\nlines"))
+
+	expected := &bytes.Buffer{}
+	expected.WriteString("MESSAGE\n")
+	_ = binary.Write(expected, binary.LittleEndian, uint64(9))
+	expected.WriteString("two\nlines\n")
+
+	if !bytes.Equal(buf.Bytes(), expected.Bytes()) {
+		t.Errorf("unexpected multi-line field: %q", buf.String())
+	}
+}
+
+// levelWriter records the level recordLevel reads from each record written to it
+type levelWriter struct {
+	levels []slog.Level
+}
+
+func (w *levelWriter) Write(p []byte) (int, error) {
+	w.levels = append(w.levels, recordLevel(p))
+	return len(p), nil
+}
+
+func TestSinkFieldSchema(t *testing.T) {
+	for _, schema := range []FieldSchema{SchemaGo11y, SchemaECS, SchemaOTel} {
+		t.Run(schema.String(), func(t *testing.T) {
+			sink := &levelWriter{}
+			cfg := NewConfig(WithLogLevel(LevelInfo), WithFieldSchema(schema))
+
+			_, o, err := Initialise(context.Background(), cfg, new(bytes.Buffer), new(bytes.Buffer),
+				WithSinks(Sink{Writer: sink, MinLevel: LevelWarning}))
+			if err != nil {
+				t.Fatalf("failed to initialise observer: %v", err)
+			}
+
+			o.Warning("warning")
+			o.Error("error", errors.New("TestSinkFieldSchema"), SeverityLow)
+
+			expected := []slog.Level{LevelWarning, LevelError}
+			if !slices.Equal(sink.levels, expected) {
+				t.Errorf("expected the sink to read levels %v, got %v", expected, sink.levels)
+			}
+		})
+	}
+}
+
+// listenUnixgram listens on a unixgram socket in a temporary directory short enough for a socket path, returning it
+// and its path
+func listenUnixgram(t *testing.T) (conn *net.UnixConn, path string) {
+	t.Helper()
+
+	dir, err := os.MkdirTemp("", "go11y")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %v", err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	path = filepath.Join(dir, "socket")
+
+	conn, err = net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatalf("failed to listen on %s: %v", path, err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return conn, path
+}
+
+// readDatagrams returns the datagrams received on $conn until none arrive for a moment
+func readDatagrams(t *testing.T, conn *net.UnixConn) (datagrams []string) {
+	t.Helper()
+
+	buf := make([]byte, 64*1024)
+	for {
+		_ = conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+
+		n, err := conn.Read(buf)
+		if err != nil {
+			return datagrams
+		}
+
+		datagrams = append(datagrams, string(buf[:n]))
+	}
+}
+
+func TestJournaldWriterFieldSchema(t *testing.T) {
+	for _, schema := range []FieldSchema{SchemaGo11y, SchemaECS, SchemaOTel} {
+		t.Run(schema.String(), func(t *testing.T) {
+			journal, path := listenUnixgram(t)
+
+			conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: path, Net: "unixgram"})
+			if err != nil {
+				t.Fatalf("failed to connect to %s: %v", path, err)
+			}
+
+			w := &JournaldWriter{conn: conn, identifier: "go11y-test"}
+			defer func() { _ = w.Close() }()
+
+			cfg := NewConfig(WithLogLevel(LevelInfo), WithFieldSchema(schema))
+
+			_, o, err := Initialise(context.Background(), cfg, w, w)
+			if err != nil {
+				t.Fatalf("failed to initialise observer: %v", err)
+			}
+
+			o.Warning("disk filling up")
+
+			found := false
+			for _, d := range readDatagrams(t, journal) {
+				if strings.Contains(d, "GO11Y_MSG=disk filling up\n") {
+					found = true
+
+					if !strings.HasPrefix(d, "PRIORITY=4\n") {
+						t.Errorf("expected the warning to have priority 4, got %q", d)
+					}
+				}
+			}
+
+			if !found {
+				t.Errorf("expected the warning to be sent with its message as GO11Y_MSG")
+			}
+		})
+	}
+}
@@ -0,0 +1,348 @@
+package go11y
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// DefaultAvailabilityObjective is the ratio of requests that must not be answered with a 5xx, used by the rules of
+	// SLORulesOpts that don't set their own
+	DefaultAvailabilityObjective = 0.999
+	// DefaultLatencyObjective is the 99th percentile request time the rules of SLORulesOpts alert above, unless they set
+	// their own
+	DefaultLatencyObjective = time.Second
+)
+
+// burnRateFast is the rate the error budget is spent at that pages: at 14.4 times the sustainable rate, 2% of a 30 day
+// budget is spent in an hour
+const burnRateFast = 14.4
+
+// metricNameRex matches the service names that can prefix Prometheus metric names
+var metricNameRex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// SLORulesOpts are the options used to generate the Prometheus rules and Grafana dashboard of a service from the
+// metrics go11y registers, see GeneratePrometheusRules and GenerateGrafanaDashboard
+type SLORulesOpts struct {
+	Service string // the service name passed to GetMetricsMiddlewareMux, which prefixes the request metrics
+
+	// optional - the ratio of requests that must not be answered with a 5xx, defaults to DefaultAvailabilityObjective
+	AvailabilityObjective float64
+	// optional - the 99th percentile request time alerted above, defaults to DefaultLatencyObjective
+	LatencyObjective time.Duration
+	// optional - the ratio of calls to each integration that must not spend its error budget, defaults to
+	// DefaultAvailabilityObjective
+	IntegrationObjective float64
+}
+
+// withDefaults returns the options with the defaults applied, or an error if the service name can't prefix metrics
+func (opts SLORulesOpts) withDefaults() (withDefaults SLORulesOpts, fault error) {
+	if !metricNameRex.MatchString(opts.Service) {
+		return opts, fmt.Errorf("could not generate rules: service %q is not a valid metric name prefix", opts.Service)
+	}
+
+	if opts.AvailabilityObjective <= 0 || opts.AvailabilityObjective >= 1 {
+		opts.AvailabilityObjective = DefaultAvailabilityObjective
+	}
+
+	if opts.LatencyObjective <= 0 {
+		opts.LatencyObjective = DefaultLatencyObjective
+	}
+
+	if opts.IntegrationObjective <= 0 || opts.IntegrationObjective >= 1 {
+		opts.IntegrationObjective = DefaultAvailabilityObjective
+	}
+
+	return opts, nil
+}
+
+// sloQueries are the PromQL queries of the SLIs of a service, shared by its rules and its dashboard
+type sloQueries struct {
+	requestRate         string
+	errorRatio          func(window string) string
+	latencyP99          string
+	outboundErrorRatio  string
+	outboundLatencyP99  string
+	integrationErrors   func(window string) string
+	integrationLatency  string
+	integrationBudget   func(window string) string
+	integrationRequests string
+}
+
+func newSLOQueries(service string) sloQueries {
+	return sloQueries{
+		requestRate: fmt.Sprintf(`sum by (endpoint) (rate(%s_requests_total[5m]))`, service),
+		errorRatio: func(window string) string {
+			return fmt.Sprintf(`sum(rate(%[1]s_requests_total{status=~"5.."}[%[2]s])) / `+
+				`sum(rate(%[1]s_requests_total[%[2]s]))`, service, window)
+		},
+		latencyP99: fmt.Sprintf(`histogram_quantile(0.99, sum by (le) (rate(%s_requests_times_bucket[5m])))`, service),
+		outboundErrorRatio: `sum by (host) (rate(go11y_outbound_requests_total{status=~"5..|error"}[5m])) / ` +
+			`sum by (host) (rate(go11y_outbound_requests_total[5m]))`,
+		outboundLatencyP99: `histogram_quantile(0.99, sum by (host, le) ` +
+			`(rate(go11y_outbound_request_duration_seconds_bucket[5m])))`,
+		integrationErrors: func(window string) string {
+			return fmt.Sprintf(`sum by (integration) (rate(go11y_integration_requests_total{outcome="error"}[%[1]s])) / `+
+				`sum by (integration) (rate(go11y_integration_requests_total[%[1]s]))`, window)
+		},
+		integrationLatency: `histogram_quantile(0.99, sum by (integration, le) ` +
+			`(rate(go11y_integration_request_duration_seconds_bucket[5m])))`,
+		integrationBudget: func(window string) string {
+			return fmt.Sprintf(`sum by (integration) (rate(go11y_integration_error_budget_spent_total[%[1]s])) / `+
+				`sum by (integration) (rate(go11y_integration_requests_total[%[1]s]))`, window)
+		},
+		integrationRequests: `sum by (integration, outcome) (rate(go11y_integration_requests_total[5m]))`,
+	}
+}
+
+// promRuleFile is a Prometheus rule file
+type promRuleFile struct {
+	Groups []promRuleGroup `yaml:"groups"`
+}
+
+type promRuleGroup struct {
+	Name  string     `yaml:"name"`
+	Rules []promRule `yaml:"rules"`
+}
+
+type promRule struct {
+	Record      string            `yaml:"record,omitempty"`
+	Alert       string            `yaml:"alert,omitempty"`
+	Expr        string            `yaml:"expr"`
+	For         string            `yaml:"for,omitempty"`
+	Labels      map[string]string `yaml:"labels,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+}
+
+// GeneratePrometheusRules returns a Prometheus rule file (YAML) with recording rules for the SLIs of the service
+// described by $opts - its request rate, error ratio and 99th percentile request time, and the error ratios and
+// latencies of its outbound calls and integrations (see RegisterIntegrations) - and alerting rules firing when the
+// error budget of the service or of an integration burns too fast, when requests are too slow and when telemetry
+// export is degraded. Every service using go11y gets the same rules by generating them in its build, or with
+// cmd/go11y-rules.
+func GeneratePrometheusRules(opts SLORulesOpts) (rules []byte, fault error) {
+	opts, err := opts.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+
+	q := newSLOQueries(opts.Service)
+	service := opts.Service
+	errorBudget := 1 - opts.AvailabilityObjective
+	integrationBudget := 1 - opts.IntegrationObjective
+
+	file := promRuleFile{Groups: []promRuleGroup{
+		{
+			Name: service + ".slis",
+			Rules: []promRule{
+				{Record: service + ":requests:rate5m", Expr: q.requestRate},
+				{Record: service + ":request_errors:ratio_rate5m", Expr: q.errorRatio("5m")},
+				{Record: service + ":request_errors:ratio_rate1h", Expr: q.errorRatio("1h")},
+				{Record: service + ":request_duration_seconds:p99_5m", Expr: q.latencyP99},
+				{Record: "host:outbound_errors:ratio_rate5m", Expr: q.outboundErrorRatio},
+				{Record: "host:outbound_duration_seconds:p99_5m", Expr: q.outboundLatencyP99},
+				{Record: "integration:errors:ratio_rate5m", Expr: q.integrationErrors("5m")},
+				{Record: "integration:error_budget_spent:ratio_rate5m", Expr: q.integrationBudget("5m")},
+				{Record: "integration:error_budget_spent:ratio_rate1h", Expr: q.integrationBudget("1h")},
+				{Record: "integration:duration_seconds:p99_5m", Expr: q.integrationLatency},
+			},
+		},
+		{
+			Name: service + ".alerts",
+			Rules: []promRule{
+				{
+					Alert: "ErrorBudgetBurn",
+					Expr: fmt.Sprintf("%[1]s:request_errors:ratio_rate1h > %[2]s and "+
+						"%[1]s:request_errors:ratio_rate5m > %[2]s", service, formatFloat(burnRateFast*errorBudget)),
+					For:    "2m",
+					Labels: map[string]string{"service": service, "severity": SeverityHigh},
+					Annotations: map[string]string{
+						"summary": fmt.Sprintf("%s is spending its %s availability error budget too fast", service,
+							formatPercent(opts.AvailabilityObjective)),
+					},
+				},
+				{
+					Alert: "HighRequestLatency",
+					Expr: fmt.Sprintf("%s:request_duration_seconds:p99_5m > %s", service,
+						formatFloat(opts.LatencyObjective.Seconds())),
+					For:    "10m",
+					Labels: map[string]string{"service": service, "severity": SeverityMedium},
+					Annotations: map[string]string{
+						"summary": fmt.Sprintf("99th percentile request time of %s is above %s", service, opts.LatencyObjective),
+					},
+				},
+				{
+					Alert: "IntegrationErrorBudgetBurn",
+					Expr: fmt.Sprintf("integration:error_budget_spent:ratio_rate1h > %[1]s and "+
+						"integration:error_budget_spent:ratio_rate5m > %[1]s", formatFloat(burnRateFast*integrationBudget)),
+					For:    "2m",
+					Labels: map[string]string{"service": service, "severity": SeverityMedium},
+					Annotations: map[string]string{
+						"summary": "calls from " + service + " to {{ $labels.integration }} are failing or slow",
+					},
+				},
+				{
+					Alert:  "TelemetryExportDegraded",
+					Expr:   "max(go11y_export_degraded) > 0",
+					For:    "5m",
+					Labels: map[string]string{"service": service, "severity": SeverityLow},
+					Annotations: map[string]string{
+						"summary": service + " is failing to export its telemetry",
+					},
+				},
+			},
+		},
+	}}
+
+	buf := new(bytes.Buffer)
+	enc := yaml.NewEncoder(buf)
+	enc.SetIndent(2)
+
+	if err := enc.Encode(file); err != nil {
+		return nil, fmt.Errorf("could not marshal rules: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// grafanaDashboard is the subset of the Grafana dashboard model GenerateGrafanaDashboard writes
+type grafanaDashboard struct {
+	UID           string            `json:"uid"`
+	Title         string            `json:"title"`
+	Tags          []string          `json:"tags"`
+	SchemaVersion int               `json:"schemaVersion"`
+	Time          map[string]string `json:"time"`
+	Refresh       string            `json:"refresh"`
+	Templating    grafanaTemplating `json:"templating"`
+	Panels        []grafanaPanel    `json:"panels"`
+}
+
+type grafanaTemplating struct {
+	List []map[string]any `json:"list"`
+}
+
+type grafanaPanel struct {
+	ID          int               `json:"id"`
+	Type        string            `json:"type"`
+	Title       string            `json:"title"`
+	GridPos     map[string]int    `json:"gridPos"`
+	Datasource  map[string]string `json:"datasource"`
+	Targets     []grafanaTarget   `json:"targets"`
+	FieldConfig map[string]any    `json:"fieldConfig"`
+}
+
+type grafanaTarget struct {
+	RefID        string `json:"refId"`
+	Expr         string `json:"expr"`
+	LegendFormat string `json:"legendFormat,omitempty"`
+}
+
+// GenerateGrafanaDashboard returns a Grafana dashboard (JSON) with panels for the SLIs of the service described by
+// $opts, using the same queries as the recording rules of GeneratePrometheusRules, against a Prometheus data source
+// chosen with the dashboard's datasource variable.
+func GenerateGrafanaDashboard(opts SLORulesOpts) (dashboard []byte, fault error) {
+	opts, err := opts.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+
+	q := newSLOQueries(opts.Service)
+
+	panels := []struct {
+		title     string
+		unit      string
+		threshold float64
+		targets   []grafanaTarget
+	}{
+		{"Requests", "reqps", 0, []grafanaTarget{{Expr: q.requestRate, LegendFormat: "{{endpoint}}"}}},
+		{"Error ratio", "percentunit", 1 - opts.AvailabilityObjective, []grafanaTarget{
+			{Expr: q.errorRatio("5m"), LegendFormat: "5m"},
+			{Expr: q.errorRatio("1h"), LegendFormat: "1h"},
+		}},
+		{"Request time p99", "s", opts.LatencyObjective.Seconds(), []grafanaTarget{
+			{Expr: q.latencyP99, LegendFormat: "p99"},
+		}},
+		{"Outbound error ratio", "percentunit", 0, []grafanaTarget{
+			{Expr: q.outboundErrorRatio, LegendFormat: "{{host}}"},
+		}},
+		{"Outbound call time p99", "s", 0, []grafanaTarget{
+			{Expr: q.outboundLatencyP99, LegendFormat: "{{host}}"},
+		}},
+		{"Integration calls", "reqps", 0, []grafanaTarget{
+			{Expr: q.integrationRequests, LegendFormat: "{{integration}} {{outcome}}"},
+		}},
+		{"Integration error budget spent", "percentunit", 1 - opts.IntegrationObjective, []grafanaTarget{
+			{Expr: q.integrationBudget("5m"), LegendFormat: "{{integration}}"},
+		}},
+		{"Integration call time p99", "s", 0, []grafanaTarget{
+			{Expr: q.integrationLatency, LegendFormat: "{{integration}}"},
+		}},
+	}
+
+	d := grafanaDashboard{
+		UID:           "go11y-" + opts.Service,
+		Title:         opts.Service + " SLOs",
+		Tags:          []string{"go11y", opts.Service},
+		SchemaVersion: 39,
+		Time:          map[string]string{"from": "now-6h", "to": "now"},
+		Refresh:       "1m",
+		Templating: grafanaTemplating{List: []map[string]any{{
+			"name":  "datasource",
+			"label": "Data source",
+			"type":  "datasource",
+			"query": "prometheus",
+		}}},
+	}
+
+	for i, p := range panels {
+		for j := range p.targets {
+			p.targets[j].RefID = string(rune('A' + j))
+		}
+
+		defaults := map[string]any{"unit": p.unit}
+		if p.threshold > 0 {
+			defaults["thresholds"] = map[string]any{
+				"mode": "absolute",
+				"steps": []map[string]any{
+					{"color": "green", "value": nil},
+					{"color": "red", "value": p.threshold},
+				},
+			}
+			defaults["custom"] = map[string]any{"thresholdsStyle": map[string]string{"mode": "line"}}
+		}
+
+		d.Panels = append(d.Panels, grafanaPanel{
+			ID:          i + 1,
+			Type:        "timeseries",
+			Title:       p.title,
+			GridPos:     map[string]int{"h": 8, "w": 12, "x": (i % 2) * 12, "y": (i / 2) * 8},
+			Datasource:  map[string]string{"type": "prometheus", "uid": "${datasource}"},
+			Targets:     p.targets,
+			FieldConfig: map[string]any{"defaults": defaults, "overrides": []any{}},
+		})
+	}
+
+	dashboard, err = json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal dashboard: %w", err)
+	}
+
+	return dashboard, nil
+}
+
+// formatFloat formats $f for a PromQL expression, rounded to 9 decimal places to drop floating point noise
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(math.Round(f*1e9)/1e9, 'f', -1, 64)
+}
+
+// formatPercent formats the ratio $f as a percentage, e.g. 99.9%
+func formatPercent(f float64) string {
+	return formatFloat(f*100) + "%"
+}
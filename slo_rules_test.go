@@ -0,0 +1,107 @@
+package go11y_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/cirruscomms/go11y"
+)
+
+func TestGeneratePrometheusRules(t *testing.T) {
+	out, err := go11y.GeneratePrometheusRules(go11y.SLORulesOpts{
+		Service:               "orders",
+		AvailabilityObjective: 0.99,
+		LatencyObjective:      250 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("failed to generate rules: %v", err)
+	}
+
+	file := struct {
+		Groups []struct {
+			Name  string `yaml:"name"`
+			Rules []struct {
+				Record string            `yaml:"record"`
+				Alert  string            `yaml:"alert"`
+				Expr   string            `yaml:"expr"`
+				Labels map[string]string `yaml:"labels"`
+			} `yaml:"rules"`
+		} `yaml:"groups"`
+	}{}
+	if err := yaml.Unmarshal(out, &file); err != nil {
+		t.Fatalf("failed to parse rules %s: %v", out, err)
+	}
+
+	exprs := map[string]string{}
+	for _, g := range file.Groups {
+		for _, r := range g.Rules {
+			exprs[r.Record+r.Alert] = r.Expr
+		}
+	}
+
+	expected := map[string]string{
+		"orders:request_errors:ratio_rate5m":          `orders_requests_total{status=~"5.."}[5m]`,
+		"orders:request_duration_seconds:p99_5m":      "orders_requests_times_bucket",
+		"host:outbound_errors:ratio_rate5m":           "go11y_outbound_requests_total",
+		"integration:error_budget_spent:ratio_rate1h": "go11y_integration_error_budget_spent_total[1h]",
+		"ErrorBudgetBurn":                             "orders:request_errors:ratio_rate1h > 0.144",
+		"HighRequestLatency":                          "orders:request_duration_seconds:p99_5m > 0.25",
+		"IntegrationErrorBudgetBurn":                  "integration:error_budget_spent:ratio_rate5m > 0.0144",
+		"TelemetryExportDegraded":                     "go11y_export_degraded",
+		"integration:duration_seconds:p99_5m":         "go11y_integration_request_duration_seconds_bucket",
+		"host:outbound_duration_seconds:p99_5m":       "go11y_outbound_request_duration_seconds_bucket",
+		"integration:error_budget_spent:ratio_rate5m": "go11y_integration_requests_total[5m]",
+		"orders:request_errors:ratio_rate1h":          "orders_requests_total[1h]",
+		"orders:requests:rate5m":                      "orders_requests_total[5m]",
+		"integration:errors:ratio_rate5m":             `outcome="error"`,
+	}
+	for name, contains := range expected {
+		if !strings.Contains(exprs[name], contains) {
+			t.Errorf("expected rule %s to contain %s, got %q", name, contains, exprs[name])
+		}
+	}
+}
+
+func TestGenerateGrafanaDashboard(t *testing.T) {
+	out, err := go11y.GenerateGrafanaDashboard(go11y.SLORulesOpts{Service: "orders"})
+	if err != nil {
+		t.Fatalf("failed to generate dashboard: %v", err)
+	}
+
+	dashboard := struct {
+		UID    string `json:"uid"`
+		Panels []struct {
+			Title   string `json:"title"`
+			Targets []struct {
+				RefID string `json:"refId"`
+				Expr  string `json:"expr"`
+			} `json:"targets"`
+		} `json:"panels"`
+	}{}
+	if err := json.Unmarshal(out, &dashboard); err != nil {
+		t.Fatalf("failed to parse dashboard %s: %v", out, err)
+	}
+
+	if dashboard.UID != "go11y-orders" || len(dashboard.Panels) == 0 {
+		t.Fatalf("expected a dashboard of the orders service, got %s", out)
+	}
+
+	if p := dashboard.Panels[1]; p.Title != "Error ratio" || len(p.Targets) != 2 || p.Targets[1].RefID != "B" ||
+		!strings.Contains(p.Targets[0].Expr, "orders_requests_total") {
+		t.Errorf("expected the error ratio panel to query the orders request metrics, got %+v", p)
+	}
+}
+
+func TestGenerateRulesInvalidService(t *testing.T) {
+	if _, err := go11y.GeneratePrometheusRules(go11y.SLORulesOpts{Service: "orders-api"}); err == nil {
+		t.Errorf("expected an error for a service name that can't prefix metrics")
+	}
+
+	if _, err := go11y.GenerateGrafanaDashboard(go11y.SLORulesOpts{}); err == nil {
+		t.Errorf("expected an error without a service name")
+	}
+}
@@ -0,0 +1,286 @@
+package go11y
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"runtime/pprof"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	otelAttribute "go.opentelemetry.io/otel/attribute"
+	otelTrace "go.opentelemetry.io/otel/trace"
+)
+
+// DefaultSlowRequestThreshold is the duration after which a request is reported by SlowRequestMiddlewareMux
+const DefaultSlowRequestThreshold = time.Second
+
+// DefaultRequestDeadline is the duration after which a request still in flight is reported by a RequestWatchdog
+const DefaultRequestDeadline = 30 * time.Second
+
+// SlowRequests is the metric for the number of requests that took longer than the slow request threshold
+var SlowRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "go11y_slow_requests_total",
+	Help: "Number of requests that took longer than the slow request threshold",
+}, []string{"endpoint", "method"})
+
+// LongRunningRequests is the metric for the number of requests in flight beyond the deadline of a RequestWatchdog,
+// as of its last check
+var LongRunningRequests = prometheus.NewGauge(prometheus.GaugeOpts{
+	Name: "go11y_long_running_requests",
+	Help: "Number of requests in flight beyond the request watchdog's deadline",
+})
+
+var registerSlowRequestMetricsOnce sync.Once
+
+func registerSlowRequestMetrics() {
+	registerSlowRequestMetricsOnce.Do(func() {
+		registerCollectors(SlowRequests, LongRunningRequests)
+	})
+}
+
+// endpointLabel returns the endpoint of $r for metrics: the path masked by $mask if there is one, otherwise the
+// template of the matched mux route, or "unmatched"
+func endpointLabel(r *http.Request, mask PathMask) string {
+	if mask != nil {
+		return mask(r.URL.Path)
+	}
+
+	if route := mux.CurrentRoute(r); route != nil {
+		if tpl, err := route.GetPathTemplate(); err == nil {
+			return tpl
+		}
+	}
+
+	return "unmatched"
+}
+
+// SlowRequestMiddlewareMuxOpts are the options used to initialise the slow request middleware for a mux.Router
+type SlowRequestMiddlewareMuxOpts struct {
+	Threshold    time.Duration // optional - requests taking longer are reported, defaults to DefaultSlowRequestThreshold
+	PathMaskFunc PathMask      // optional - masks the path for the SlowRequests endpoint label, defaults to the route template
+}
+
+// SlowRequestMiddlewareMux returns a middleware that reports requests taking longer than the threshold once they
+// complete: a warning is logged with the request ID and duration, the SlowRequests metric is incremented and a
+// "slow request" event is added to the span in the request context (see RequestLoggerMiddlewareMux).
+// If the Observer cannot be retrieved from the provided context, an error is returned.
+func SlowRequestMiddlewareMux(ctxWithObserver context.Context, opts SlowRequestMiddlewareMuxOpts) (slowRequestMiddleware mux.MiddlewareFunc, fault error) {
+	_, o, err := Get(ctxWithObserver)
+	if err != nil {
+		return nil, fmt.Errorf("could not get go11y observer from context: %w", err)
+	}
+
+	registerSlowRequestMetrics()
+
+	if opts.Threshold <= 0 {
+		opts.Threshold = DefaultSlowRequestThreshold
+	}
+
+	mw := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t0 := o.clock.Now()
+
+			next.ServeHTTP(w, r)
+
+			duration := o.clock.Since(t0)
+			if duration <= opts.Threshold {
+				return
+			}
+
+			endpoint := endpointLabel(r, opts.PathMaskFunc)
+			SlowRequests.WithLabelValues(endpoint, r.Method).Inc()
+
+			otelTrace.SpanFromContext(r.Context()).AddEvent("slow request", otelTrace.WithAttributes(
+				otelAttribute.Float64("http.server.duration_ms", float64(duration.Microseconds())/1000),
+				otelAttribute.Float64("threshold_ms", float64(opts.Threshold.Microseconds())/1000),
+			))
+
+			FromContext(r.Context()).log(r.Context(), 3, LevelWarning, "slow request",
+				FieldRequestID, GetRequestID(r.Context()),
+				FieldRequestMethod, r.Method,
+				FieldRequestPath, r.URL.Path,
+				FieldRequestDuration, duration.Milliseconds(),
+				"threshold", opts.Threshold.String(),
+			)
+		})
+	}
+
+	return mw, nil
+}
+
+// RequestWatchdogOpts are the options used to create a RequestWatchdog
+type RequestWatchdogOpts struct {
+	Deadline time.Duration // optional - requests in flight for longer are reported, defaults to DefaultRequestDeadline
+	Interval time.Duration // optional - how often the requests in flight are checked, defaults to the deadline
+}
+
+// requestWatchdogLabel is the pprof label the goroutines handling requests are tagged with, so their stacks can be
+// found in a goroutine profile
+const requestWatchdogLabel = "go11y_request"
+
+// inFlightRequest is a request being handled, as tracked by a RequestWatchdog
+type inFlightRequest struct {
+	requestID string
+	method    string
+	path      string
+	started   time.Time
+}
+
+// RequestWatchdog reports requests that are still in flight beyond a deadline, e.g. handlers stuck on a lock or a
+// call without a timeout, which SlowRequestMiddlewareMux can only report once they complete. Every interval, a
+// warning is logged for each such request with its request ID, how long it has been running and the stacks of the
+// goroutines handling it, and the LongRunningRequests metric is updated.
+type RequestWatchdog struct {
+	ctx      context.Context
+	o        *Observer
+	deadline time.Duration
+	interval time.Duration
+	next     atomic.Uint64
+	mu       sync.Mutex
+	inFlight map[string]inFlightRequest // by the value of the request's requestWatchdogLabel
+}
+
+// NewRequestWatchdog creates a RequestWatchdog logging with the Observer in $ctxWithObserver. Add its Middleware to
+// the router and call Run to start checking.
+// If the Observer cannot be retrieved from the provided context, an error is returned.
+func NewRequestWatchdog(ctxWithObserver context.Context, opts RequestWatchdogOpts) (watchdog *RequestWatchdog, fault error) {
+	ctx, o, err := Get(ctxWithObserver)
+	if err != nil {
+		return nil, fmt.Errorf("could not get go11y observer from context: %w", err)
+	}
+
+	registerSlowRequestMetrics()
+
+	if opts.Deadline <= 0 {
+		opts.Deadline = DefaultRequestDeadline
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = opts.Deadline
+	}
+
+	return &RequestWatchdog{
+		ctx:      ctx,
+		o:        o,
+		deadline: opts.Deadline,
+		interval: opts.Interval,
+		inFlight: map[string]inFlightRequest{},
+	}, nil
+}
+
+// Middleware tracks the requests it handles until they complete, tagging the goroutine handling each with a pprof
+// label so its stack can be reported. It can be passed to mux.Router.Use.
+func (wd *RequestWatchdog) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := strconv.FormatUint(wd.next.Add(1), 10)
+
+		wd.mu.Lock()
+		wd.inFlight[id] = inFlightRequest{
+			requestID: GetRequestID(r.Context()),
+			method:    r.Method,
+			path:      r.URL.Path,
+			started:   wd.o.clock.Now(),
+		}
+		wd.mu.Unlock()
+
+		defer func() {
+			wd.mu.Lock()
+			delete(wd.inFlight, id)
+			wd.mu.Unlock()
+		}()
+
+		pprof.Do(r.Context(), pprof.Labels(requestWatchdogLabel, id), func(ctx context.Context) {
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	})
+}
+
+// Run checks the requests in flight every interval until $ctx is cancelled.
+func (wd *RequestWatchdog) Run(ctx context.Context) {
+	ticker := time.NewTicker(wd.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			wd.Check()
+		}
+	}
+}
+
+// Check reports the requests in flight beyond the deadline once, returning how many there were.
+func (wd *RequestWatchdog) Check() (overdue int) {
+	now := wd.o.clock.Now()
+
+	wd.mu.Lock()
+	ids := []string{}
+	for id, req := range wd.inFlight {
+		if now.Sub(req.started) > wd.deadline {
+			ids = append(ids, id)
+		}
+	}
+	requests := make([]inFlightRequest, 0, len(ids))
+	slices.SortFunc(ids, func(a, b string) int { return strings.Compare(a, b) })
+	for _, id := range ids {
+		requests = append(requests, wd.inFlight[id])
+	}
+	wd.mu.Unlock()
+
+	LongRunningRequests.Set(float64(len(requests)))
+
+	if len(requests) == 0 {
+		return 0
+	}
+
+	stacks := goroutineStacks()
+
+	for i, req := range requests {
+		wd.o.log(wd.ctx, 3, LevelWarning, "request still in flight beyond deadline",
+			FieldRequestID, req.requestID,
+			FieldRequestMethod, req.method,
+			FieldRequestPath, req.path,
+			FieldRequestDuration, now.Sub(req.started).Milliseconds(),
+			"deadline", wd.deadline.String(),
+			"stack", stacks[ids[i]],
+		)
+	}
+
+	return len(requests)
+}
+
+// goroutineStacks returns the stacks of the goroutines tagged with requestWatchdogLabel, by the label's value, read
+// from a goroutine profile. Goroutines started by a handler inherit its label, so a request may have several.
+func goroutineStacks() (stacks map[string]string) {
+	stacks = map[string]string{}
+
+	buf := bytes.Buffer{}
+	if err := pprof.Lookup("goroutine").WriteTo(&buf, 1); err != nil {
+		return stacks
+	}
+
+	// in the debug=1 format each group of identical stacks is a block, with a "# labels: {...}" line if it has labels
+	prefix := `"` + requestWatchdogLabel + `":"`
+	for block := range strings.SplitSeq(buf.String(), "\n\n") {
+		_, labelled, ok := strings.Cut(block, prefix)
+		if !ok {
+			continue
+		}
+
+		id, _, _ := strings.Cut(labelled, `"`)
+		if stacks[id] != "" {
+			stacks[id] += "\n\n"
+		}
+		stacks[id] += strings.TrimSpace(block)
+	}
+
+	return stacks
+}
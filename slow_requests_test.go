@@ -0,0 +1,109 @@
+package go11y
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestSlowRequestMiddlewareMux(t *testing.T) {
+	buf := new(bytes.Buffer)
+	clock := &stepClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	cfg := CreateConfig(LevelInfo, "", "", "", []string{}, []string{})
+	ctx, _, err := Initialise(context.Background(), cfg, buf, buf, WithClock(clock))
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	mw, err := SlowRequestMiddlewareMux(ctx, SlowRequestMiddlewareMuxOpts{Threshold: 500 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("failed to create middleware: %v", err)
+	}
+
+	router := mux.NewRouter()
+	router.Use(mw)
+	router.HandleFunc("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		d, _ := time.ParseDuration(r.URL.Query().Get("takes"))
+		clock.now = clock.now.Add(d)
+	})
+
+	before := testutil.ToFloat64(SlowRequests.WithLabelValues("/users/{id}", http.MethodGet))
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/1?takes=100ms", nil))
+	if strings.Contains(buf.String(), "slow request") {
+		t.Errorf("expected a fast request not to be reported, got %s", buf.String())
+	}
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/2?takes=2s", nil))
+	if !strings.Contains(buf.String(), `"msg":"slow request"`) || !strings.Contains(buf.String(), `"request_duration":2000`) {
+		t.Errorf("expected the slow request to be logged with its duration, got %s", buf.String())
+	}
+
+	if after := testutil.ToFloat64(SlowRequests.WithLabelValues("/users/{id}", http.MethodGet)); after != before+1 {
+		t.Errorf("expected the slow request to be counted against its route, got %v", after-before)
+	}
+}
+
+func TestRequestWatchdog(t *testing.T) {
+	buf := new(bytes.Buffer)
+	clock := &stepClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	cfg := CreateConfig(LevelInfo, "", "", "", []string{}, []string{})
+	ctx, _, err := Initialise(context.Background(), cfg, buf, buf, WithClock(clock))
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	wd, err := NewRequestWatchdog(ctx, RequestWatchdogOpts{Deadline: 10 * time.Second})
+	if err != nil {
+		t.Fatalf("failed to create watchdog: %v", err)
+	}
+
+	release := make(chan struct{})
+	done := make(chan struct{})
+	handler := wd.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+
+	go func() {
+		defer close(done)
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/stuck", nil))
+	}()
+
+	for {
+		wd.mu.Lock()
+		n := len(wd.inFlight)
+		wd.mu.Unlock()
+		if n == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if overdue := wd.Check(); overdue != 0 {
+		t.Errorf("expected no overdue requests before the deadline, got %d", overdue)
+	}
+
+	clock.now = clock.now.Add(time.Minute)
+
+	if overdue := wd.Check(); overdue != 1 || testutil.ToFloat64(LongRunningRequests) != 1 {
+		t.Errorf("expected 1 overdue request, got %d", overdue)
+	}
+
+	if !strings.Contains(buf.String(), "request still in flight beyond deadline") || !strings.Contains(buf.String(), "TestRequestWatchdog") {
+		t.Errorf("expected the overdue request to be logged with the handler's stack, got %s", buf.String())
+	}
+
+	close(release)
+	<-done
+
+	if overdue := wd.Check(); overdue != 0 {
+		t.Errorf("expected no overdue requests once the request completed, got %d", overdue)
+	}
+}
@@ -0,0 +1,176 @@
+package go11y
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	otelAttribute "go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	otelTrace "go.opentelemetry.io/otel/trace"
+)
+
+// SOAPUnknownOperation is the operation label used for SOAP calls whose operation can't be found
+const SOAPUnknownOperation = "unknown"
+
+// SOAPStatusFault is the status label used for SOAP calls answered with a SOAP Fault
+const SOAPStatusFault = "fault"
+
+// FieldSOAPOperation is the structured log field name for "soap_operation"
+const FieldSOAPOperation = "soap_operation"
+
+// SOAPCalls is the metric for the number of outbound SOAP calls made, by host, operation and status: the status code,
+// SOAPStatusFault if the response was a SOAP Fault, or OutboundStatusError if no response was received
+var SOAPCalls = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "go11y_soap_calls_total",
+	Help: "Number of outbound SOAP calls made",
+}, []string{"host", "operation", "status"})
+
+// SOAPCallTimes is the metric for the time outbound SOAP calls take, by host and operation
+var SOAPCallTimes = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Name: "go11y_soap_call_duration_seconds",
+	Help: "Time outbound SOAP calls take",
+}, []string{"host", "operation"})
+
+var registerSOAPMetricsOnce sync.Once
+
+func registerSOAPMetrics() {
+	registerSOAPMetricsOnce.Do(func() {
+		registerCollectors(SOAPCalls, SOAPCallTimes)
+	})
+}
+
+// SOAPOperation returns the operation of a SOAP call with the headers $header and envelope $body: the last segment of
+// the SOAPAction header (SOAP 1.1) or of the action parameter of the Content-Type (SOAP 1.2), e.g. "GetBalance" for
+// "http://example.com/billing#GetBalance", otherwise the local name of the first element of the envelope's Body.
+// SOAPUnknownOperation is returned if there is neither.
+func SOAPOperation(header http.Header, body []byte) (operation string) {
+	action := strings.Trim(header.Get("SOAPAction"), `"`)
+	if action == "" {
+		if _, params, err := mime.ParseMediaType(header.Get("Content-Type")); err == nil {
+			action = params["action"]
+		}
+	}
+
+	if action = action[strings.LastIndexAny(action, "/#:")+1:]; action != "" {
+		return action
+	}
+
+	if name := soapBodyElement(body); name != "" {
+		return name
+	}
+
+	return SOAPUnknownOperation
+}
+
+// soapBodyElement returns the local name of the first element in the Body of the SOAP envelope $body, or "" if there
+// isn't one
+func soapBodyElement(body []byte) (name string) {
+	d := xml.NewDecoder(bytes.NewReader(body))
+	d.Strict = false
+
+	inBody := false
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return ""
+		}
+
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		if inBody {
+			return start.Name.Local
+		}
+
+		inBody = start.Name.Local == "Body"
+	}
+}
+
+// readBody reads $body, returning its content and a reader of the same content to replace it with
+func readBody(body io.ReadCloser) (content []byte, replacement io.ReadCloser, fault error) {
+	if body == nil || body == http.NoBody {
+		return nil, body, nil
+	}
+
+	defer func() {
+		_ = body.Close()
+	}()
+
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return content, io.NopCloser(bytes.NewReader(content)), nil
+}
+
+// soapRoundTripper starts a client span named after the SOAP operation of each outbound call, marking it as failed if
+// the response is a SOAP Fault, and records the SOAP metrics
+func soapRoundTripper(ctxWithObserver context.Context, next http.RoundTripper) http.RoundTripper {
+	return RoundTripperFunc(func(r *http.Request) (w *http.Response, fault error) {
+		reqBody, replacement, err := readBody(r.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+		r.Body = replacement
+
+		operation := SOAPOperation(r.Header, reqBody)
+
+		ctx, o, _ := outboundObserver(ctxWithObserver, r, nil)
+
+		ctx, span := o.spanTracer(nil).Start(ctx, "SOAP "+operation,
+			otelTrace.WithSpanKind(otelTrace.SpanKindClient),
+			otelTrace.WithAttributes(
+				otelAttribute.String("rpc.system", "soap"),
+				otelAttribute.String("rpc.method", operation),
+				otelAttribute.String("server.address", r.URL.Host),
+			),
+		)
+		defer span.End()
+
+		start := o.clock.Now()
+
+		resp, err := next.RoundTrip(r.WithContext(ctx))
+
+		status := OutboundStatusError
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			err = recordOutboundError(r, err)
+		} else {
+			status = strconv.Itoa(resp.StatusCode)
+
+			var respBody []byte
+			respBody, resp.Body, err = readBody(resp.Body)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read response body: %w", err)
+			}
+
+			if soapBodyElement(respBody) == "Fault" {
+				status = SOAPStatusFault
+				span.SetStatus(codes.Error, "SOAP Fault")
+				o.log(ctx, 8, LevelWarning, "outbound call - soap fault",
+					FieldSOAPOperation, operation,
+					FieldRequestURL, RedactURL(r.URL),
+					FieldStatusCode, resp.StatusCode,
+					FieldResponseBody, string(RedactXML(respBody)),
+				)
+			}
+		}
+
+		SOAPCalls.WithLabelValues(r.URL.Host, operation, status).Inc()
+		SOAPCallTimes.WithLabelValues(r.URL.Host, operation).Observe(o.clock.Since(start).Seconds())
+
+		return resp, err
+	})
+}
@@ -0,0 +1,112 @@
+package go11y_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/cirruscomms/go11y"
+)
+
+const soapEnvelope = `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">` +
+	`<soap:Body><m:GetBalance xmlns:m="urn:billing"><m:msisdn>447700900123</m:msisdn></m:GetBalance></soap:Body>` +
+	`</soap:Envelope>`
+
+func TestSOAPOperation(t *testing.T) {
+	testCases := map[string]struct {
+		header   http.Header
+		body     string
+		expected string
+	}{
+		"soap 1.1 action": {
+			header:   http.Header{"Soapaction": {`"http://example.com/billing#TopUp"`}},
+			body:     soapEnvelope,
+			expected: "TopUp",
+		},
+		"soap 1.2 action": {
+			header:   http.Header{"Content-Type": {`application/soap+xml; charset=utf-8; action="urn:billing/TopUp"`}},
+			body:     soapEnvelope,
+			expected: "TopUp",
+		},
+		"envelope body": {
+			header:   http.Header{"Soapaction": {`""`}},
+			body:     soapEnvelope,
+			expected: "GetBalance",
+		},
+		"unknown": {
+			header:   http.Header{},
+			body:     "not xml",
+			expected: go11y.SOAPUnknownOperation,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if operation := go11y.SOAPOperation(tc.header, []byte(tc.body)); operation != tc.expected {
+				t.Errorf("expected operation %q, got %q", tc.expected, operation)
+			}
+		})
+	}
+}
+
+func TestSOAPClient(t *testing.T) {
+	buf := new(bytes.Buffer)
+	ctx, _, spans, err := go11y.InitialiseTestTracerInMemory(context.Background(), go11y.LevelInfo, buf, buf)
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	fault := `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><soap:Fault>` +
+		`<faultcode>soap:Client</faultcode><faultstring>unknown subscriber</faultstring></soap:Fault></soap:Body>` +
+		`</soap:Envelope>`
+
+	client := &go11y.HTTPClient{Client: &http.Client{
+		Transport: go11y.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			b, _ := io.ReadAll(r.Body)
+			if string(b) != soapEnvelope {
+				t.Errorf("expected the envelope to be passed on, got %s", b)
+			}
+
+			return &http.Response{StatusCode: http.StatusInternalServerError, Body: io.NopCloser(strings.NewReader(fault)), Request: r}, nil
+		}),
+	}}
+
+	if err := client.AddSOAP(ctx); err != nil {
+		t.Fatalf("failed to add soap to HTTP client: %v", err)
+	}
+
+	before := testutil.ToFloat64(go11y.SOAPCalls.WithLabelValues("billing.test", "GetBalance", go11y.SOAPStatusFault))
+
+	resp, err := client.Post("http://billing.test/ws", "text/xml", strings.NewReader(soapEnvelope))
+	if err != nil {
+		t.Fatalf("failed to execute request: %v", err)
+	}
+
+	b, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if string(b) != fault {
+		t.Errorf("expected the fault to be returned to the caller, got %s", b)
+	}
+
+	if names := spans.Names(); len(names) != 1 || names[0] != "SOAP GetBalance" {
+		t.Errorf("expected a client span named after the operation, got %v", names)
+	}
+
+	if spans.Status("SOAP GetBalance") != codes.Error {
+		t.Errorf("expected the fault to fail the span")
+	}
+
+	if !strings.Contains(buf.String(), "unknown subscriber") {
+		t.Errorf("expected the fault to be logged, got %s", buf.String())
+	}
+
+	if after := testutil.ToFloat64(go11y.SOAPCalls.WithLabelValues("billing.test", "GetBalance", go11y.SOAPStatusFault)); after != before+1 {
+		t.Errorf("expected the fault to be counted, got %v", after-before)
+	}
+}
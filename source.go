@@ -0,0 +1,80 @@
+package go11y
+
+import (
+	"log/slog"
+	"strings"
+)
+
+// SourceMode controls what go11y logs as the source of records, see WithLogSource
+type SourceMode int
+
+const (
+	// SourceFull logs the function, file and line of the caller, the default
+	SourceFull SourceMode = iota
+	// SourceFunction logs only the (trimmed) function of the caller, as a string
+	SourceFunction
+	// SourceOff doesn't log the source at all, and skips finding the caller of every record
+	SourceOff
+)
+
+// ParseSourceMode maps "full", "function" and "off" to a SourceMode, defaulting to SourceFull.
+func ParseSourceMode(mode string) SourceMode {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case "function", "func":
+		return SourceFunction
+	case "off", "none", "disabled":
+		return SourceOff
+	default:
+		return SourceFull
+	}
+}
+
+// String returns the name of the mode as accepted by ParseSourceMode
+func (m SourceMode) String() string {
+	switch m {
+	case SourceFunction:
+		return "function"
+	case SourceOff:
+		return "off"
+	default:
+		return "full"
+	}
+}
+
+// SourceConfigurator is implemented by Configurators that choose what is logged as the source of records.
+// Configuration implements it, other Configurators log the full source.
+type SourceConfigurator interface {
+	LogSource() SourceMode
+}
+
+// WithLogSource sets what is logged as the source of records. Finding the caller of every record is measurable at
+// high volumes, so services that don't need the source can turn it off.
+func WithLogSource(mode SourceMode) ConfigOption {
+	return func(c *Configuration) {
+		c.sourceMode = mode
+	}
+}
+
+// LogSource returns what is logged as the source of records.
+// This method is part of the SourceConfigurator interface.
+func (c *Configuration) LogSource() SourceMode {
+	return c.sourceMode
+}
+
+// configSourceMode returns what $cfg logs as the source of records
+func configSourceMode(cfg Configurator) SourceMode {
+	if sc, ok := cfg.(SourceConfigurator); ok {
+		return sc.LogSource()
+	}
+
+	return SourceFull
+}
+
+// functionSource returns the source attr $a as just the function of its source, for SourceFunction
+func functionSource(a slog.Attr) slog.Attr {
+	if source, ok := a.Value.Any().(*slog.Source); ok {
+		return slog.String(a.Key, source.Function)
+	}
+
+	return a
+}
@@ -0,0 +1,68 @@
+package go11y
+
+import (
+	"log/slog"
+
+	otelTrace "go.opentelemetry.io/otel/trace"
+)
+
+// SpanEventMode is how records logged in a span are added to it, see WithSpanEvents
+type SpanEventMode int
+
+const (
+	// SpanEventsAttributes sets the args of records as span attributes and adds an event with just the message, the
+	// default
+	SpanEventsAttributes SpanEventMode = iota
+	// SpanEventsWithArgs adds an event with the args of the record as its attributes, leaving the span's attributes
+	// alone, so args logged by different records don't overwrite each other
+	SpanEventsWithArgs
+	// SpanEventsOff doesn't add records to spans, except that errors are still recorded and fail the span
+	SpanEventsOff
+)
+
+// WithSpanEvents sets how records logged in a span are added to it. Only records at or above $minLevel are added;
+// errors are always recorded on the span (with their args as event attributes unless the mode is
+// SpanEventsAttributes) and fail it.
+func WithSpanEvents(mode SpanEventMode, minLevel slog.Level) Option {
+	return func(o *Observer) {
+		o.spanEventMode = mode
+		o.spanEventLevel = minLevel
+	}
+}
+
+// addSpanEvent adds a record logged at $level with $args to $span, as configured by WithSpanEvents
+func (o *Observer) addSpanEvent(span otelTrace.Span, level slog.Level, msg string, args []any) {
+	if span == nil || level < o.spanEventLevel {
+		return
+	}
+
+	switch o.spanEventMode {
+	case SpanEventsAttributes:
+		span.SetAttributes(o.spanAttributes(args)...)
+		span.AddEvent(msg)
+	case SpanEventsWithArgs:
+		span.AddEvent(msg, otelTrace.WithAttributes(o.spanLimits.apply(argsToAttributes(args...))...))
+	}
+}
+
+// recordSpanError records $err, logged with $msg and $args, on $span as configured by WithSpanEvents, and fails it
+// unless $behaviour keeps the span's status, and marks its trace as "must keep" (see KeepTrace)
+func (o *Observer) recordSpanError(span otelTrace.Span, msg string, err error, args []any, behaviour SeverityBehaviour) {
+	if span == nil {
+		return
+	}
+
+	switch o.spanEventMode {
+	case SpanEventsAttributes:
+		span.SetAttributes(o.spanAttributes(args)...)
+		span.RecordError(err)
+	default:
+		span.RecordError(err, otelTrace.WithAttributes(o.spanLimits.apply(argsToAttributes(args...))...))
+	}
+
+	if !behaviour.KeepSpanStatus {
+		o.failSpan(msg)
+	}
+
+	keepTrace(span, KeepReasonError)
+}
@@ -0,0 +1,114 @@
+package go11y
+
+import (
+	"slices"
+	"unicode/utf8"
+
+	otelAttribute "go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	// DefaultMaxSpanAttributes is the default cap on the attributes set on a span by a single record
+	DefaultMaxSpanAttributes = 64
+	// DefaultMaxSpanAttributeLength is the default cap on the length, in bytes, of string span attribute values
+	DefaultMaxSpanAttributeLength = 2048
+)
+
+// DefaultSpanAttributeDenyKeys are the keys never set as span attributes by default: request and response bodies
+// and headers are logged, redacted, by the transports and middleware but are too big and sensitive for tracing.
+var DefaultSpanAttributeDenyKeys = []string{
+	FieldRequestBody,
+	FieldResponseBody,
+	FieldRequestHeaders,
+	FieldResponseHeaders,
+}
+
+// SpanAttributeLimits caps the span attributes set from the args of records logged in a span, so verbose logging
+// can't push spans over the size limits of tracing backends. The limits only apply to span attributes, records are
+// always logged in full.
+type SpanAttributeLimits struct {
+	MaxAttributes  int      // optional - attributes set per record, the rest are dropped, defaults to 64, -1 for no cap
+	MaxValueLength int      // optional - bytes of string values before truncation, defaults to 2048, -1 for no cap
+	DenyKeys       []string // optional - keys never set as attributes, defaults to DefaultSpanAttributeDenyKeys
+}
+
+// WithSpanAttributeLimits sets the limits on the span attributes set from the args of records logged in a span.
+// Pass an empty, non-nil DenyKeys to set every key as an attribute.
+func WithSpanAttributeLimits(limits SpanAttributeLimits) Option {
+	return func(o *Observer) {
+		o.spanLimits = limits.withDefaults()
+	}
+}
+
+// withDefaults returns the limits with the defaults of unset fields applied
+func (l SpanAttributeLimits) withDefaults() SpanAttributeLimits {
+	if l.MaxAttributes == 0 {
+		l.MaxAttributes = DefaultMaxSpanAttributes
+	}
+
+	if l.MaxValueLength == 0 {
+		l.MaxValueLength = DefaultMaxSpanAttributeLength
+	}
+
+	if l.DenyKeys == nil {
+		l.DenyKeys = DefaultSpanAttributeDenyKeys
+	}
+
+	return l
+}
+
+// apply returns $attrs without denied keys, with string values truncated and capped at MaxAttributes. $attrs is not
+// modified.
+func (l SpanAttributeLimits) apply(attrs []otelAttribute.KeyValue) (limited []otelAttribute.KeyValue) {
+	limited = make([]otelAttribute.KeyValue, 0, len(attrs))
+
+	for _, a := range attrs {
+		if l.MaxAttributes >= 0 && len(limited) == l.MaxAttributes {
+			break
+		}
+
+		if slices.Contains(l.DenyKeys, string(a.Key)) {
+			continue
+		}
+
+		limited = append(limited, l.truncate(a))
+	}
+
+	return limited
+}
+
+// truncate returns $a with its string values truncated to MaxValueLength bytes
+func (l SpanAttributeLimits) truncate(a otelAttribute.KeyValue) otelAttribute.KeyValue {
+	if l.MaxValueLength < 0 {
+		return a
+	}
+
+	switch a.Value.Type() {
+	case otelAttribute.STRING:
+		if len(a.Value.AsString()) > l.MaxValueLength {
+			return a.Key.String(truncateString(a.Value.AsString(), l.MaxValueLength))
+		}
+	case otelAttribute.STRINGSLICE:
+		values := a.Value.AsStringSlice()
+		for i, v := range values {
+			values[i] = truncateString(v, l.MaxValueLength)
+		}
+
+		return a.Key.StringSlice(values)
+	}
+
+	return a
+}
+
+// truncateString truncates $s to at most $n bytes without splitting a multi-byte character
+func truncateString(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+
+	return s[:n]
+}
@@ -0,0 +1,219 @@
+package go11y
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// SSEEvents is the metric for the number of Server-Sent Events written, by stream and event type
+var SSEEvents = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "go11y_sse_events_total",
+	Help: "Number of Server-Sent Events written",
+}, []string{"stream", "event"})
+
+// SSEConnections is the metric for the number of open Server-Sent Events connections, by stream
+var SSEConnections = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "go11y_sse_connections",
+	Help: "Number of open Server-Sent Events connections",
+}, []string{"stream"})
+
+// SSEConnectionDuration is the metric for how long Server-Sent Events connections stay open, by stream
+var SSEConnectionDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "go11y_sse_connection_duration_seconds",
+	Help:    "Time Server-Sent Events connections stay open",
+	Buckets: prometheus.ExponentialBuckets(1, 4, 8),
+}, []string{"stream"})
+
+// SSEDisconnects is the metric for the number of closed Server-Sent Events connections, by stream and reason
+var SSEDisconnects = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "go11y_sse_disconnects_total",
+	Help: "Number of closed Server-Sent Events connections",
+}, []string{"stream", "reason"})
+
+const (
+	// SSEDisconnectClient is the reason label used when the client went away
+	SSEDisconnectClient = "client"
+	// SSEDisconnectServer is the reason label used when the server closed the stream
+	SSEDisconnectServer = "server"
+	// SSEDisconnectError is the reason label used when writing an event failed
+	SSEDisconnectError = "error"
+)
+
+// ErrSSEClosed is returned when sending an event on an SSEWriter that has been closed
+var ErrSSEClosed = errors.New("sse stream is closed")
+
+var registerSSEMetricsOnce sync.Once
+
+// SSEWriter writes Server-Sent Events to a client, recording events sent, connection duration and disconnects as
+// metrics and logging when the stream is opened and closed. It works behind the go11y middleware as
+// MiddlewareResponseWriter passes flushes through.
+type SSEWriter struct {
+	ctx      context.Context
+	o        *Observer
+	w        http.ResponseWriter
+	rc       *http.ResponseController
+	stream   string
+	start    time.Time
+	events   int64
+	closed   bool
+	mu       sync.Mutex
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewSSEWriter writes the Server-Sent Events response headers to $w and returns an SSEWriter for the stream named
+// $stream. $r's context must hold an Observer (e.g. added by ObserverMiddleware) and the stream is treated as
+// disconnected by the client when $r's context is done.
+// An error is returned if $w cannot be flushed, as events would never reach the client.
+func NewSSEWriter(w http.ResponseWriter, r *http.Request, stream string) (writer *SSEWriter, fault error) {
+	registerSSEMetricsOnce.Do(func() {
+		registerCollectors(SSEEvents, SSEConnections, SSEConnectionDuration, SSEDisconnects)
+	})
+
+	ctx, o, err := Get(r.Context())
+	if err != nil {
+		return nil, fmt.Errorf("could not get go11y observer from request context: %w", err)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	rc := http.NewResponseController(w)
+	if err := rc.Flush(); err != nil {
+		return nil, fmt.Errorf("could not flush sse response: %w", err)
+	}
+
+	sw := &SSEWriter{
+		ctx:    ctx,
+		o:      o,
+		w:      w,
+		rc:     rc,
+		stream: stream,
+		start:  o.clock.Now(),
+		done:   make(chan struct{}),
+	}
+
+	SSEConnections.WithLabelValues(stream).Inc()
+	o.log(ctx, 3, LevelInfo, "sse stream opened", "sse_stream", stream)
+
+	go func() {
+		select {
+		case <-r.Context().Done():
+			sw.close(SSEDisconnectClient)
+		case <-sw.done:
+		}
+	}()
+
+	return sw, nil
+}
+
+// Send writes an event of type $event with $id and $data to the client and flushes it. $event and $id are optional.
+// Multi-line data is split into multiple data fields as required by the SSE format.
+func (sw *SSEWriter) Send(event, id, data string) error {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+
+	if sw.closed {
+		return ErrSSEClosed
+	}
+
+	b := strings.Builder{}
+	if event != "" {
+		b.WriteString("event: " + event + "\n")
+	}
+	if id != "" {
+		b.WriteString("id: " + id + "\n")
+	}
+	for line := range strings.SplitSeq(data, "\n") {
+		b.WriteString("data: " + line + "\n")
+	}
+	b.WriteString("\n")
+
+	if err := sw.write(b.String()); err != nil {
+		sw.closeLocked(SSEDisconnectError)
+		return fmt.Errorf("could not send sse event: %w", err)
+	}
+
+	if event == "" {
+		event = "message"
+	}
+
+	sw.events++
+	SSEEvents.WithLabelValues(sw.stream, event).Inc()
+
+	return nil
+}
+
+// Comment writes an SSE comment line, typically used as a keep-alive. Comments are not counted as events.
+func (sw *SSEWriter) Comment(comment string) error {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+
+	if sw.closed {
+		return ErrSSEClosed
+	}
+
+	if err := sw.write(": " + comment + "\n\n"); err != nil {
+		sw.closeLocked(SSEDisconnectError)
+		return fmt.Errorf("could not send sse comment: %w", err)
+	}
+
+	return nil
+}
+
+// Done returns a channel that is closed when the stream is closed, either by the client disconnecting or by Close.
+func (sw *SSEWriter) Done() <-chan struct{} {
+	return sw.done
+}
+
+// Close ends the stream from the server side. It is safe to call more than once and after the client has gone away.
+func (sw *SSEWriter) Close() {
+	sw.close(SSEDisconnectServer)
+}
+
+func (sw *SSEWriter) write(s string) error {
+	if _, err := sw.w.Write([]byte(s)); err != nil {
+		return err
+	}
+
+	return sw.rc.Flush()
+}
+
+func (sw *SSEWriter) close(reason string) {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+
+	sw.closeLocked(reason)
+}
+
+// closeLocked records the end of the stream - the caller must hold sw.mu
+func (sw *SSEWriter) closeLocked(reason string) {
+	if sw.closed {
+		return
+	}
+
+	sw.closed = true
+	sw.stopOnce.Do(func() { close(sw.done) })
+
+	duration := sw.o.clock.Since(sw.start)
+
+	SSEConnections.WithLabelValues(sw.stream).Dec()
+	SSEConnectionDuration.WithLabelValues(sw.stream).Observe(duration.Seconds())
+	SSEDisconnects.WithLabelValues(sw.stream, reason).Inc()
+
+	sw.o.log(sw.ctx, 4, LevelInfo, "sse stream closed",
+		"sse_stream", sw.stream,
+		"sse_events", sw.events,
+		"sse_disconnect_reason", reason,
+		FieldRequestDuration, duration.Milliseconds(),
+	)
+}
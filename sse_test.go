@@ -0,0 +1,158 @@
+package go11y_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cirruscomms/go11y"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestSSEWriter(t *testing.T) {
+	cfg := go11y.CreateConfig(go11y.LevelInfo, "", "", "", []string{}, []string{})
+	ctx, _, err := go11y.Initialise(context.Background(), cfg, io.Discard, io.Discard)
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	observerMiddleware, err := go11y.ObserverMiddleware(ctx)
+	if err != nil {
+		t.Fatalf("failed to create observer middleware: %v", err)
+	}
+
+	closed := make(chan struct{})
+	server := httptest.NewServer(observerMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(closed)
+
+		sw, err := go11y.NewSSEWriter(w, r, "sse_test")
+		if err != nil {
+			t.Errorf("failed to create sse writer: %v", err)
+			return
+		}
+
+		if err := sw.Send("greeting", "1", "hello\nworld"); err != nil {
+			t.Errorf("failed to send event: %v", err)
+		}
+
+		select {
+		case <-sw.Done():
+		case <-time.After(5 * time.Second):
+			t.Errorf("expected the client disconnect to close the stream")
+		}
+
+		if err := sw.Send("", "", "too late"); err == nil {
+			t.Errorf("expected sending on a closed stream to fail")
+		}
+	})))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+
+	if resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Errorf("expected an event stream, got %q", resp.Header.Get("Content-Type"))
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	event := ""
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("failed to read event: %v", err)
+		}
+		if line == "\n" {
+			break
+		}
+		event += line
+	}
+
+	expected := "event: greeting\nid: 1\ndata: hello\ndata: world\n"
+	if event != expected {
+		t.Errorf("expected event %q, got %q", expected, event)
+	}
+
+	_ = resp.Body.Close()
+	<-closed
+
+	if v := testutil.ToFloat64(go11y.SSEEvents.WithLabelValues("sse_test", "greeting")); v != 1 {
+		t.Errorf("expected 1 event sent, got %v", v)
+	}
+
+	if v := testutil.ToFloat64(go11y.SSEDisconnects.WithLabelValues("sse_test", go11y.SSEDisconnectClient)); v != 1 {
+		t.Errorf("expected 1 client disconnect, got %v", v)
+	}
+
+	if v := testutil.ToFloat64(go11y.SSEConnections.WithLabelValues("sse_test")); v != 0 {
+		t.Errorf("expected no open connections, got %v", v)
+	}
+}
+
+func TestSSEWriterRequestLogger(t *testing.T) {
+	buf := &lockedBuffer{}
+
+	ctx, _, err := go11y.InitialiseTestLogger(context.Background(), go11y.LevelDebug, buf, buf)
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	mw, err := go11y.RequestLoggerMiddlewareMux(ctx)
+	if err != nil {
+		t.Fatalf("failed to create middleware: %v", err)
+	}
+
+	server := httptest.NewServer(mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sw, err := go11y.NewSSEWriter(w, r, "sse_request_logger_test")
+		if err != nil {
+			t.Errorf("failed to create sse writer: %v", err)
+			return
+		}
+
+		for i := range 3 {
+			if err := sw.Send("tick", strconv.Itoa(i), "streamed-event-data"); err != nil {
+				t.Errorf("failed to send event: %v", err)
+			}
+		}
+	})))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		t.Fatalf("failed to read the stream: %v", err)
+	}
+
+	var processed map[string]any
+	for _, line := range strings.Split(buf.String(), "\n") {
+		record := map[string]any{}
+		if json.Unmarshal([]byte(line), &record) == nil && record["msg"] == "request processed" {
+			processed = record
+		}
+	}
+
+	if processed == nil {
+		t.Fatalf("expected the request to be logged, got %s", buf.String())
+	}
+
+	if b := processed[go11y.FieldResponseBody]; b != nil && b != "" {
+		t.Errorf("expected the event stream not to be captured as the response body, got %v", processed)
+	}
+
+	if size, _ := processed[go11y.FieldResponseSize].(float64); int(size) != len(body) {
+		t.Errorf("expected the response size to be %d, got %v", len(body), processed[go11y.FieldResponseSize])
+	}
+}
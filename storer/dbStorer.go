@@ -1,10 +1,17 @@
 // Package storer provides functionality to store API request and response details in a PostgreSQL database for use by
 // go11y's AddDBStorer transport middleware.
+//
+// The remote_api_requests table it writes to is created by the migrations in Migrations. Tables created before the
+// error column was added (0002_transport_errors.sql) still work, but calls that failed at the transport level are
+// only stored once that migration has been applied.
 package storer
 
 import (
 	"context"
+	"fmt"
+	"sync"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -12,6 +19,7 @@ import (
 // StoreRequest struct for storing API request and response details
 type StoreRequest struct {
 	pool            *pgxpool.Pool
+	encryptor       Encryptor
 	URL             string      `db:"url" json:"url"`
 	Method          string      `db:"method" json:"method"`
 	RequestHeaders  []byte      `db:"request_headers" json:"request_headers"`
@@ -20,6 +28,10 @@ type StoreRequest struct {
 	ResponseHeaders []byte      `db:"response_headers" json:"response_headers"`
 	ResponseBody    pgtype.Text `db:"response_body" json:"response_body"`
 	StatusCode      int32       `db:"status_code" json:"status_code"`
+	Error           pgtype.Text `db:"error" json:"error"`
+
+	schemaMu    sync.Mutex
+	errorColumn *bool // whether remote_api_requests has the error column, checked on the first Exec
 }
 
 // New creates a new StoreRequest instance with a database connection pool
@@ -41,15 +53,40 @@ func NewWithPool(pool *pgxpool.Pool) (dbStore *StoreRequest, fault error) {
 	}, nil
 }
 
-// Exec executes the database insert for the StoreRequest
-func (s *StoreRequest) Exec(ctx context.Context) error {
-	tx, err := s.pool.Begin(ctx)
-	if err != nil {
-		return err
-	}
-	defer tx.Rollback(ctx)
+// Pool returns the database connection pool used by the StoreRequest, e.g. for monitoring with go11y.NewDBPoolMonitor
+func (s *StoreRequest) Pool() *pgxpool.Pool {
+	return s.pool
+}
 
-	sql := `INSERT INTO remote_api_requests (
+// querier is implemented by both pgxpool.Pool and pgx.Tx
+type querier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+const (
+	insertSQL = `INSERT INTO remote_api_requests (
+	url,
+	method,
+	request_headers,
+	request_body,
+	response_time_ms,
+	response_headers,
+	response_body,
+	status_code,
+	error
+) VALUES (
+	$1,
+	$2,
+	$3,
+	$4,
+	$5,
+	$6,
+	$7,
+	$8,
+	$9
+);`
+
+	insertWithoutErrorSQL = `INSERT INTO remote_api_requests (
 	url,
 	method,
 	request_headers,
@@ -69,7 +106,54 @@ func (s *StoreRequest) Exec(ctx context.Context) error {
 	$8
 );`
 
-	_, err = tx.Exec(ctx, sql, s.URL, s.Method, s.RequestHeaders, s.RequestBody, s.ResponseTimeMs, s.ResponseHeaders, s.ResponseBody, s.StatusCode)
+	errorColumnSQL = `SELECT EXISTS (
+	SELECT 1
+	FROM information_schema.columns
+	WHERE table_schema = ANY (current_schemas(false))
+	AND table_name = 'remote_api_requests'
+	AND column_name = 'error'
+);`
+)
+
+// Exec executes the database insert for the StoreRequest.
+// The error column is only written if the table has it (see Migrations); without it, calls that failed at the
+// transport level are not stored, as status_code can't be null.
+func (s *StoreRequest) Exec(ctx context.Context) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	errorColumn, err := s.hasErrorColumn(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	if !errorColumn && s.Error.Valid {
+		return nil
+	}
+
+	reqBody, err := s.encrypt(s.RequestBody)
+	if err != nil {
+		return err
+	}
+
+	respBody, err := s.encrypt(s.ResponseBody)
+	if err != nil {
+		return err
+	}
+
+	if errorColumn {
+		// calls that failed at the transport level never got a status code
+		statusCode := pgtype.Int4{Int32: s.StatusCode, Valid: !s.Error.Valid}
+
+		_, err = tx.Exec(ctx, insertSQL, s.URL, s.Method, s.RequestHeaders, reqBody, s.ResponseTimeMs, s.ResponseHeaders,
+			respBody, statusCode, s.Error)
+	} else {
+		_, err = tx.Exec(ctx, insertWithoutErrorSQL, s.URL, s.Method, s.RequestHeaders, reqBody, s.ResponseTimeMs,
+			s.ResponseHeaders, respBody, s.StatusCode)
+	}
 	if err != nil {
 		return err
 	}
@@ -82,6 +166,45 @@ func (s *StoreRequest) Exec(ctx context.Context) error {
 	return nil
 }
 
+// hasErrorColumn reports whether the remote_api_requests table has the error column added by
+// 0002_transport_errors.sql, querying the schema on the first call only.
+func (s *StoreRequest) hasErrorColumn(ctx context.Context, db querier) (exists bool, fault error) {
+	s.schemaMu.Lock()
+	defer s.schemaMu.Unlock()
+
+	if s.errorColumn != nil {
+		return *s.errorColumn, nil
+	}
+
+	err := db.QueryRow(ctx, errorColumnSQL).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("could not check for the error column of remote_api_requests: %w", err)
+	}
+
+	s.errorColumn = &exists
+
+	return exists, nil
+}
+
+// SetEncryptor enables encryption of the request and response bodies before they are stored. Passing nil disables
+// encryption.
+func (s *StoreRequest) SetEncryptor(encryptor Encryptor) {
+	s.encryptor = encryptor
+}
+
+func (s *StoreRequest) encrypt(body pgtype.Text) (encrypted pgtype.Text, fault error) {
+	if s.encryptor == nil || !body.Valid {
+		return body, nil
+	}
+
+	ciphertext, err := s.encryptor.Encrypt([]byte(body.String))
+	if err != nil {
+		return pgtype.Text{}, fmt.Errorf("could not encrypt body: %w", err)
+	}
+
+	return pgtype.Text{String: ciphertext, Valid: true}, nil
+}
+
 // SetURL sets the URL field of the StoreRequest
 func (s *StoreRequest) SetURL(input string) {
 	s.URL = input
@@ -121,3 +244,35 @@ func (s *StoreRequest) SetResponseBody(input pgtype.Text) {
 func (s *StoreRequest) SetStatusCode(input int32) {
 	s.StatusCode = input
 }
+
+// SetError sets the Error field of the StoreRequest. A valid error is stored with a NULL status code, as the call
+// failed before a response was received.
+func (s *StoreRequest) SetError(input pgtype.Text) {
+	s.Error = input
+}
+
+// RecordStart records a start of version $version of service $service in the service_starts table, returning how many
+// times that version has started, including this one. It implements go11y.RestartRecorder.
+func (s *StoreRequest) RecordStart(ctx context.Context, service, version string) (starts int64, fault error) {
+	sql := `INSERT INTO service_starts (
+	service,
+	version,
+	starts,
+	last_started_at
+) VALUES (
+	$1,
+	$2,
+	1,
+	CURRENT_TIMESTAMP
+)
+ON CONFLICT (service, version) DO UPDATE SET
+	starts = service_starts.starts + 1,
+	last_started_at = EXCLUDED.last_started_at
+RETURNING starts;`
+
+	if err := s.pool.QueryRow(ctx, sql, service, version).Scan(&starts); err != nil {
+		return 0, fmt.Errorf("could not record service start: %w", err)
+	}
+
+	return starts, nil
+}
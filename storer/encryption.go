@@ -0,0 +1,116 @@
+package storer
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/caarlos0/env/v10"
+)
+
+// encryptedPrefix marks a stored body as encrypted so that plaintext rows written before encryption was enabled can
+// still be read back transparently.
+const encryptedPrefix = "enc:v1:"
+
+// ErrNotEncrypted is returned by Decrypt when the stored value was not written by an encrypting StoreRequest.
+var ErrNotEncrypted = errors.New("value is not encrypted")
+
+// Encryptor encrypts and decrypts request/response bodies before they are written to or after they are read from the
+// database. Implementations may be backed by a local key (see NewAESEncryptor) or a KMS.
+type Encryptor interface {
+	Encrypt(plaintext []byte) (ciphertext string, fault error)
+	Decrypt(ciphertext string) (plaintext []byte, fault error)
+}
+
+// AESEncryptor encrypts bodies using AES-GCM with a 128, 192 or 256 bit key.
+type AESEncryptor struct {
+	aead cipher.AEAD
+}
+
+// NewAESEncryptor creates a new AESEncryptor with the provided key, which must be 16, 24 or 32 bytes long.
+func NewAESEncryptor(key []byte) (encryptor *AESEncryptor, fault error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("could not create cipher: %w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("could not create GCM: %w", err)
+	}
+
+	return &AESEncryptor{
+		aead: aead,
+	}, nil
+}
+
+type encryptionConfig struct {
+	Key string `env:"STORE_ENCRYPTION_KEY" envDefault:""`
+}
+
+// EncryptorFromEnv creates an AESEncryptor from the base64 encoded key in the STORE_ENCRYPTION_KEY environment
+// variable. If the variable is not set, a nil Encryptor is returned and bodies will be stored in plaintext.
+func EncryptorFromEnv() (encryptor Encryptor, fault error) {
+	c := encryptionConfig{}
+	if err := env.Parse(&c); err != nil {
+		return nil, fmt.Errorf("could not load encryption config: %w", err)
+	}
+
+	if c.Key == "" {
+		return nil, nil
+	}
+
+	key, err := base64.StdEncoding.DecodeString(c.Key)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode STORE_ENCRYPTION_KEY: %w", err)
+	}
+
+	aes, err := NewAESEncryptor(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return aes, nil
+}
+
+// Encrypt encrypts the plaintext and returns it as a prefixed, base64 encoded string of the nonce and ciphertext.
+func (e *AESEncryptor) Encrypt(plaintext []byte) (ciphertext string, fault error) {
+	nonce := make([]byte, e.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", fmt.Errorf("could not generate nonce: %w", err)
+	}
+
+	sealed := e.aead.Seal(nonce, nonce, plaintext, nil)
+
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt decrypts a value created by Encrypt. Values without the encryption prefix return ErrNotEncrypted.
+func (e *AESEncryptor) Decrypt(ciphertext string) (plaintext []byte, fault error) {
+	if !strings.HasPrefix(ciphertext, encryptedPrefix) {
+		return nil, ErrNotEncrypted
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(ciphertext, encryptedPrefix))
+	if err != nil {
+		return nil, fmt.Errorf("could not decode ciphertext: %w", err)
+	}
+
+	if len(sealed) < e.aead.NonceSize() {
+		return nil, errors.New("ciphertext is too short")
+	}
+
+	nonce, sealed := sealed[:e.aead.NonceSize()], sealed[e.aead.NonceSize():]
+
+	plaintext, err = e.aead.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not decrypt ciphertext: %w", err)
+	}
+
+	return plaintext, nil
+}
@@ -0,0 +1,104 @@
+package storer
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestEncryptionRoundTrip(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+
+	enc, err := NewAESEncryptor(key)
+	if err != nil {
+		t.Fatalf("failed to create encryptor: %v", err)
+	}
+
+	s := &StoreRequest{}
+	s.SetEncryptor(enc)
+
+	plain := pgtype.Text{String: `{"card":"4111111111111111"}`, Valid: true}
+
+	encrypted, err := s.encrypt(plain)
+	if err != nil {
+		t.Fatalf("failed to encrypt body: %v", err)
+	}
+
+	if !strings.HasPrefix(encrypted.String, encryptedPrefix) {
+		t.Errorf("expected encrypted body to start with %q, got %q", encryptedPrefix, encrypted.String)
+	}
+
+	if strings.Contains(encrypted.String, "4111111111111111") {
+		t.Errorf("encrypted body contains plaintext: %q", encrypted.String)
+	}
+
+	decrypted, err := s.decrypt(encrypted)
+	if err != nil {
+		t.Fatalf("failed to decrypt body: %v", err)
+	}
+
+	if decrypted != plain {
+		t.Errorf("expected %v, got %v", plain, decrypted)
+	}
+
+	legacy := pgtype.Text{String: "stored before encryption", Valid: true}
+
+	decrypted, err = s.decrypt(legacy)
+	if err != nil {
+		t.Fatalf("failed to read plaintext body: %v", err)
+	}
+
+	if decrypted != legacy {
+		t.Errorf("expected %v, got %v", legacy, decrypted)
+	}
+
+	null := pgtype.Text{}
+
+	encrypted, err = s.encrypt(null)
+	if err != nil {
+		t.Fatalf("failed to encrypt null body: %v", err)
+	}
+
+	if encrypted.Valid {
+		t.Errorf("expected null body to remain null, got %v", encrypted)
+	}
+}
+
+func TestEncryptorFromEnv(t *testing.T) {
+	t.Setenv("STORE_ENCRYPTION_KEY", "")
+
+	enc, err := EncryptorFromEnv()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if enc != nil {
+		t.Errorf("expected nil encryptor when no key is configured")
+	}
+
+	t.Setenv("STORE_ENCRYPTION_KEY", base64.StdEncoding.EncodeToString([]byte("0123456789abcdef")))
+
+	enc, err = EncryptorFromEnv()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err = enc.Decrypt("not encrypted")
+	if !errors.Is(err, ErrNotEncrypted) {
+		t.Errorf("expected ErrNotEncrypted, got %v", err)
+	}
+
+	t.Setenv("STORE_ENCRYPTION_KEY", base64.StdEncoding.EncodeToString([]byte("short")))
+
+	enc, err = EncryptorFromEnv()
+	if err == nil {
+		t.Errorf("expected an error for an invalid key length")
+	}
+
+	if enc != nil {
+		t.Errorf("expected a nil encryptor with the error, got %#v", enc)
+	}
+}
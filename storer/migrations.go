@@ -0,0 +1,15 @@
+package storer
+
+import "embed"
+
+// Migrations contains the migrations creating the remote_api_requests table written to by Exec and the service_starts
+// table written to by RecordStart, in tern's format (the up migration, then "---- create above / drop below ----" and
+// the down migration), for services to apply with their own migrator or copy into their migrations:
+//
+//   - 0001_init.sql creates the remote_api_requests table
+//   - 0002_transport_errors.sql adds the error column and makes status_code nullable, so calls that failed at the
+//     transport level (DNS failures, timeouts) can be stored
+//   - 0003_service_starts.sql creates the service_starts table counting the starts of each version of a service
+//
+//go:embed migrations/*.sql
+var Migrations embed.FS
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS service_starts (
+    service TEXT NOT NULL,
+    version TEXT NOT NULL,
+    starts BIGINT NOT NULL,
+    last_started_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    PRIMARY KEY (service, version)
+);
+
+---- create above / drop below ----
+
+DROP TABLE IF EXISTS service_starts;
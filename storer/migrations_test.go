@@ -0,0 +1,36 @@
+package storer
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+)
+
+func TestMigrations(t *testing.T) {
+	files, err := fs.Glob(Migrations, "migrations/*.sql")
+	if err != nil {
+		t.Fatalf("failed to list migrations: %v", err)
+	}
+
+	if len(files) != 3 {
+		t.Fatalf("expected 3 migrations, got %v", files)
+	}
+
+	tables := map[string]string{
+		"migrations/0001_init.sql":             "remote_api_requests",
+		"migrations/0002_transport_errors.sql": "remote_api_requests",
+		"migrations/0003_service_starts.sql":   "service_starts",
+	}
+
+	for _, name := range files {
+		contents, err := fs.ReadFile(Migrations, name)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", name, err)
+		}
+
+		if !strings.Contains(string(contents), tables[name]) ||
+			!strings.Contains(string(contents), "---- create above / drop below ----") {
+			t.Errorf("expected %s to be a tern migration of %s, got %s", name, tables[name], contents)
+		}
+	}
+}
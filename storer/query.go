@@ -0,0 +1,118 @@
+package storer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Record is a single stored API request and response, as read back from the database.
+type Record struct {
+	ID              int32       `db:"id" json:"id"`
+	URL             string      `db:"url" json:"url"`
+	Method          string      `db:"method" json:"method"`
+	RequestHeaders  []byte      `db:"request_headers" json:"request_headers"`
+	RequestBody     pgtype.Text `db:"request_body" json:"request_body"`
+	ResponseTimeMs  int64       `db:"response_time_ms" json:"response_time_ms"`
+	ResponseHeaders []byte      `db:"response_headers" json:"response_headers"`
+	ResponseBody    pgtype.Text `db:"response_body" json:"response_body"`
+	StatusCode      pgtype.Int4 `db:"status_code" json:"status_code"` // NULL if the call failed at the transport level
+	Error           pgtype.Text `db:"error" json:"error"`
+	CreatedAt       time.Time   `db:"created_at" json:"created_at"`
+}
+
+// Query returns up to $limit records created at or after $since, newest first.
+// If an Encryptor has been set, encrypted bodies are decrypted transparently; bodies stored before encryption was
+// enabled are returned as-is. Error is NULL for every record if the table doesn't have the error column yet.
+func (s *StoreRequest) Query(ctx context.Context, since time.Time, limit int) (records []Record, fault error) {
+	errorColumn, err := s.hasErrorColumn(ctx, s.pool)
+	if err != nil {
+		return nil, err
+	}
+
+	errorExpr := "NULL::TEXT"
+	if errorColumn {
+		errorExpr = "error"
+	}
+
+	sql := `SELECT
+	id,
+	url,
+	method,
+	request_headers,
+	request_body,
+	response_time_ms,
+	response_headers,
+	response_body,
+	status_code,
+	` + errorExpr + `,
+	created_at
+FROM remote_api_requests
+WHERE created_at >= $1
+ORDER BY created_at DESC
+LIMIT $2;`
+
+	rows, err := s.pool.Query(ctx, sql, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		r := Record{}
+
+		err = rows.Scan(
+			&r.ID,
+			&r.URL,
+			&r.Method,
+			&r.RequestHeaders,
+			&r.RequestBody,
+			&r.ResponseTimeMs,
+			&r.ResponseHeaders,
+			&r.ResponseBody,
+			&r.StatusCode,
+			&r.Error,
+			&r.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		r.RequestBody, err = s.decrypt(r.RequestBody)
+		if err != nil {
+			return nil, fmt.Errorf("could not decrypt request body of record %d: %w", r.ID, err)
+		}
+
+		r.ResponseBody, err = s.decrypt(r.ResponseBody)
+		if err != nil {
+			return nil, fmt.Errorf("could not decrypt response body of record %d: %w", r.ID, err)
+		}
+
+		records = append(records, r)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+func (s *StoreRequest) decrypt(body pgtype.Text) (decrypted pgtype.Text, fault error) {
+	if s.encryptor == nil || !body.Valid {
+		return body, nil
+	}
+
+	plaintext, err := s.encryptor.Decrypt(body.String)
+	if errors.Is(err, ErrNotEncrypted) {
+		return body, nil
+	}
+	if err != nil {
+		return pgtype.Text{}, err
+	}
+
+	return pgtype.Text{String: string(plaintext), Valid: true}, nil
+}
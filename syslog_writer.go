@@ -0,0 +1,62 @@
+//go:build !windows && !plan9
+
+package go11y
+
+import (
+	"fmt"
+	"log/syslog"
+)
+
+// SyslogWriter is an io.Writer that sends go11y JSON log records to syslog, mapping the level of each record to the
+// matching syslog priority.
+type SyslogWriter struct {
+	writer *syslog.Writer
+}
+
+// NewSyslogWriter connects to the syslog daemon. An empty $network and $addr connect to the local daemon, otherwise
+// $network is "udp" or "tcp" and $addr is the "host:port" of a remote daemon. $tag defaults to os.Args[0] if empty.
+func NewSyslogWriter(network, addr, tag string) (writer *SyslogWriter, fault error) {
+	w, err := syslog.Dial(network, addr, syslog.LOG_INFO|syslog.LOG_USER, tag)
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to syslog: %w", err)
+	}
+
+	return &SyslogWriter{
+		writer: w,
+	}, nil
+}
+
+// Write sends the record in $p to syslog with the priority matching its level.
+func (w *SyslogWriter) Write(p []byte) (int, error) {
+	msg := string(trimNewline(p))
+
+	var err error
+
+	switch recordLevel(p) {
+	case LevelFatal:
+		err = w.writer.Alert(msg)
+	case LevelPanic:
+		err = w.writer.Crit(msg)
+	case LevelError:
+		err = w.writer.Err(msg)
+	case LevelWarning:
+		err = w.writer.Warning(msg)
+	case LevelNotice:
+		err = w.writer.Notice(msg)
+	case LevelInfo:
+		err = w.writer.Info(msg)
+	default:
+		err = w.writer.Debug(msg)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// Close closes the connection to syslog.
+func (w *SyslogWriter) Close() error {
+	return w.writer.Close()
+}
@@ -0,0 +1,25 @@
+//go:build windows || plan9
+
+package go11y
+
+import (
+	"errors"
+)
+
+// SyslogWriter is not supported on this platform.
+type SyslogWriter struct{}
+
+// NewSyslogWriter always returns an error as syslog is not supported on this platform.
+func NewSyslogWriter(network, addr, tag string) (writer *SyslogWriter, fault error) {
+	return nil, errors.New("syslog is not supported on this platform")
+}
+
+// Write always returns an error as syslog is not supported on this platform.
+func (w *SyslogWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("syslog is not supported on this platform")
+}
+
+// Close does nothing as syslog is not supported on this platform.
+func (w *SyslogWriter) Close() error {
+	return nil
+}
@@ -0,0 +1,52 @@
+//go:build !windows && !plan9
+
+package go11y
+
+import (
+	"context"
+	"fmt"
+	"log/syslog"
+	"strings"
+	"testing"
+)
+
+func TestSyslogWriterFieldSchema(t *testing.T) {
+	for _, schema := range []FieldSchema{SchemaGo11y, SchemaECS, SchemaOTel} {
+		t.Run(schema.String(), func(t *testing.T) {
+			daemon, path := listenUnixgram(t)
+
+			w, err := NewSyslogWriter("unixgram", path, "go11y-test")
+			if err != nil {
+				t.Fatalf("failed to connect to syslog: %v", err)
+			}
+			defer func() { _ = w.Close() }()
+
+			cfg := NewConfig(WithLogLevel(LevelInfo), WithFieldSchema(schema))
+
+			_, o, err := Initialise(context.Background(), cfg, w, w)
+			if err != nil {
+				t.Fatalf("failed to initialise observer: %v", err)
+			}
+
+			o.Warning("disk filling up")
+
+			// the priority is the facility and the severity, see RFC 3164
+			priority := fmt.Sprintf("<%d>", syslog.LOG_USER|syslog.LOG_WARNING)
+
+			found := false
+			for _, d := range readDatagrams(t, daemon) {
+				if strings.Contains(d, "disk filling up") {
+					found = true
+
+					if !strings.HasPrefix(d, priority) {
+						t.Errorf("expected the warning to be sent with the priority %s, got %q", priority, d)
+					}
+				}
+			}
+
+			if !found {
+				t.Errorf("expected the warning to be sent to syslog")
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package go11y
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	otelAttribute "go.opentelemetry.io/otel/attribute"
+	otelTrace "go.opentelemetry.io/otel/trace"
+)
+
+// KeepTraceHeader is the HTTP header sent with outbound requests made in a trace marked as "must keep" (see
+// KeepTrace), so the services called mark their spans of the trace too
+const KeepTraceHeader string = "X-Swoop-KeepTrace"
+
+const (
+	// AttributeKeepTrace is the span attribute set to true on the spans of traces marked as "must keep", for
+	// tail-sampling policies such as the OTel collector's boolean_attribute policy
+	AttributeKeepTrace = "sampling.keep"
+	// AttributeKeepTraceReason is the span attribute giving why the trace was marked as "must keep"
+	AttributeKeepTraceReason = "sampling.keep_reason"
+	// AttributeSamplingPriority is the span attribute set to 1 on the spans of traces marked as "must keep", the
+	// sampling priority understood by OpenTracing and Datadog compatible collectors
+	AttributeSamplingPriority = "sampling.priority"
+)
+
+const (
+	// KeepReasonError is the reason of traces kept because an error was recorded in them, see Error
+	KeepReasonError = "error"
+	// KeepReasonServerError is the reason of traces kept because a request was answered with a 5xx
+	KeepReasonServerError = "server_error"
+	// KeepReasonUpstream is the reason of traces kept because the calling service marked them, see KeepTraceHeader
+	KeepReasonUpstream = "upstream"
+)
+
+// keptTraceTTL is how long a trace is remembered as kept, so outbound requests made in it send KeepTraceHeader
+const keptTraceTTL = 10 * time.Minute
+
+// TracesKept is the metric for the number of spans marked as belonging to a "must keep" trace, by reason
+var TracesKept = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "go11y_traces_kept_total",
+	Help: "Number of spans marked for tail-sampling collectors to keep their trace",
+}, []string{"reason"})
+
+var registerTailSamplingMetricsOnce sync.Once
+
+func registerTailSamplingMetrics() {
+	registerTailSamplingMetricsOnce.Do(func() {
+		registerCollectors(TracesKept)
+	})
+}
+
+// keptTraces are the traces marked as "must keep" in the last keptTraceTTL, by trace ID
+var keptTraces = struct {
+	sync.Mutex
+	until  map[otelTrace.TraceID]time.Time
+	pruned time.Time // when the expired traces were last deleted
+}{until: map[otelTrace.TraceID]time.Time{}}
+
+// KeepTrace marks the trace of the Observer's current span as "must keep" for tail-sampling collectors, with
+// $reason: the span gets the AttributeKeepTrace, AttributeKeepTraceReason and AttributeSamplingPriority attributes,
+// and outbound requests made in the trace with a client with AddPropagation send KeepTraceHeader, so the spans of the
+// services called are marked too. Traces are marked automatically when an error is recorded in them (see Error,
+// Fatal and Panic), and by the request logger middleware when a request is answered with a 5xx.
+func (o *Observer) KeepTrace(reason string) {
+	span := o.currentSpan()
+
+	keepTrace(span, reason)
+}
+
+// TraceKept reports whether the trace of the span in $ctx has been marked as "must keep" recently, see KeepTrace
+func TraceKept(ctx context.Context) bool {
+	sc := otelTrace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return false
+	}
+
+	keptTraces.Lock()
+	defer keptTraces.Unlock()
+
+	return time.Now().Before(keptTraces.until[sc.TraceID()])
+}
+
+// keepTrace marks $span, and its trace, as "must keep" with $reason
+func keepTrace(span otelTrace.Span, reason string) {
+	if span == nil || !span.SpanContext().IsValid() {
+		return
+	}
+
+	registerTailSamplingMetrics()
+
+	span.SetAttributes(
+		otelAttribute.Bool(AttributeKeepTrace, true),
+		otelAttribute.String(AttributeKeepTraceReason, reason),
+		otelAttribute.Int(AttributeSamplingPriority, 1),
+	)
+
+	TracesKept.WithLabelValues(reason).Inc()
+
+	now := time.Now()
+
+	keptTraces.Lock()
+	defer keptTraces.Unlock()
+
+	if now.Sub(keptTraces.pruned) >= time.Minute {
+		keptTraces.pruned = now
+		for id, until := range keptTraces.until {
+			if now.After(until) {
+				delete(keptTraces.until, id)
+			}
+		}
+	}
+
+	keptTraces.until[span.SpanContext().TraceID()] = now.Add(keptTraceTTL)
+}
@@ -0,0 +1,118 @@
+package go11y_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cirruscomms/go11y"
+)
+
+func TestKeepTrace(t *testing.T) {
+	buf := new(bytes.Buffer)
+	ctx, _, spans, err := go11y.InitialiseTestTracerInMemory(context.Background(), go11y.LevelInfo, buf, buf)
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	keptHeaders := []string{}
+	client := &go11y.HTTPClient{Client: &http.Client{
+		Transport: go11y.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			keptHeaders = append(keptHeaders, r.Header.Get(go11y.KeepTraceHeader))
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(""))}, nil
+		}),
+	}}
+	if err := client.AddPropagation(ctx); err != nil {
+		t.Fatalf("failed to add propagation: %v", err)
+	}
+
+	call := func(ctx context.Context) {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.test/", nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("failed to call: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	browseCtx, _, endBrowse, err := go11y.StartSpan(ctx, nil, "browse", go11y.SpanKindInternal)
+	if err != nil {
+		t.Fatalf("failed to start span: %v", err)
+	}
+	call(browseCtx)
+	endBrowse()
+
+	checkoutCtx, o, endCheckout, err := go11y.StartSpan(ctx, nil, "checkout", go11y.SpanKindInternal)
+	if err != nil {
+		t.Fatalf("failed to start span: %v", err)
+	}
+	o.Error("payment failed", errors.New("TestKeepTrace"), go11y.SeverityLow)
+	call(checkoutCtx)
+	endCheckout()
+
+	if go11y.TraceKept(browseCtx) || !go11y.TraceKept(checkoutCtx) {
+		t.Errorf("expected only the trace with an error to be kept")
+	}
+
+	if len(keptHeaders) != 2 || keptHeaders[0] != "" || keptHeaders[1] != "1" {
+		t.Errorf("expected only the call in the kept trace to send %s, got %q", go11y.KeepTraceHeader, keptHeaders)
+	}
+
+	browse, _ := spans.Find("browse")
+	for _, a := range browse.Attributes {
+		if a.Key == go11y.AttributeKeepTrace {
+			t.Errorf("expected the span without an error not to be marked, got %v", browse.Attributes)
+		}
+	}
+
+	checkout, _ := spans.Find("checkout")
+	attrs := map[string]string{}
+	for _, a := range checkout.Attributes {
+		attrs[string(a.Key)] = a.Value.Emit()
+	}
+
+	if attrs[go11y.AttributeKeepTrace] != "true" || attrs[go11y.AttributeKeepTraceReason] != go11y.KeepReasonError ||
+		attrs[go11y.AttributeSamplingPriority] != "1" {
+		t.Errorf("expected the span with an error to be marked as must keep, got %v", attrs)
+	}
+}
+
+func TestKeepTraceRequestLogger(t *testing.T) {
+	ctx, _, spans, err := go11y.InitialiseTestTracerInMemory(context.Background(), go11y.LevelInfo, io.Discard, io.Discard)
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	mw, err := go11y.RequestLoggerMiddlewareMux(ctx)
+	if err != nil {
+		t.Fatalf("failed to create middleware: %v", err)
+	}
+
+	handler := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/error" {
+			_, ro, _ := go11y.Get(r.Context())
+			ro.Error("lookup failed", errors.New("TestKeepTraceRequestLogger"), go11y.SeverityLow)
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for path, reason := range map[string]any{"/ok": nil, "/error": go11y.KeepReasonError} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected %s to be answered with 200, got %d", path, rec.Code)
+		}
+
+		got, _ := spans.Attribute("HTTP GET "+path, go11y.AttributeKeepTraceReason)
+		if got != reason {
+			t.Errorf("expected the server span of %s to be kept for %v, got %v", path, reason, got)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package go11y
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+)
+
+// TenantRoute is where a TenantRouter writes the records of a tenant
+type TenantRoute struct {
+	Writer   io.Writer
+	MinLevel slog.Level // optional - records of the tenant below it are discarded, defaults to LevelInfo
+}
+
+// TenantRouterOpts are the options used to create a TenantRouter
+type TenantRouterOpts struct {
+	// optional - the stable arg holding the tenant of records, defaults to FieldTenantID
+	Field string
+	// optional - the routes of the tenants known upfront, by tenant
+	Routes map[string]TenantRoute
+	// optional - opens the route of a tenant without one the first time it logs, e.g. a file per customer, defaults to
+	// using Default. The tenant is as logged, so it must be validated before being used in a path.
+	OpenRoute func(tenant string) (route TenantRoute, fault error)
+	// optional - the route of records without a tenant, or of tenants without a route, defaults to discarding them
+	Default *TenantRoute
+}
+
+// TenantRouter writes JSON records to a different writer per tenant, e.g. separate files or buckets per customer of a
+// white-label deployment, by the tenant stable arg of the records (see IdentityExtractor). Each tenant can have its
+// own level. It is added to an Observer with WithSinks(router.Sink()); as with every sink, it only receives the
+// records at or above the Observer's level, so a tenant level below it has no effect.
+type TenantRouter struct {
+	field     string
+	openRoute func(tenant string) (route TenantRoute, fault error)
+	fallback  *TenantRoute
+
+	mu     sync.Mutex
+	routes map[string]TenantRoute
+	opened []io.Writer // the writers opened by OpenRoute, closed by Close
+}
+
+// NewTenantRouter creates a TenantRouter with $opts
+func NewTenantRouter(opts TenantRouterOpts) (router *TenantRouter) {
+	if opts.Field == "" {
+		opts.Field = FieldTenantID
+	}
+
+	routes := make(map[string]TenantRoute, len(opts.Routes))
+	for tenant, route := range opts.Routes {
+		routes[tenant] = route
+	}
+
+	return &TenantRouter{
+		field:     opts.Field,
+		openRoute: opts.OpenRoute,
+		fallback:  opts.Default,
+		routes:    routes,
+	}
+}
+
+// Sink returns the Sink to add to an Observer with WithSinks, receiving every JSON record the Observer logs
+func (t *TenantRouter) Sink() Sink {
+	return Sink{Writer: t, MinLevel: LevelDevelop, Format: SinkFormatJSON}
+}
+
+// Write writes the JSON record $p to the route of its tenant, or the default route, if it is at or above the route's
+// level.
+func (t *TenantRouter) Write(p []byte) (n int, fault error) {
+	record := map[string]any{}
+	_ = json.Unmarshal(p, &record)
+
+	tenant := ""
+	if v := recordField(record, t.field); v != nil {
+		tenant = fmt.Sprint(v)
+	}
+
+	route, err := t.route(tenant)
+	if err != nil {
+		return 0, err
+	}
+
+	level, _ := recordField(record, slog.LevelKey).(string)
+	if route == nil || route.Writer == nil || StringToLevel(level) < route.MinLevel {
+		return len(p), nil
+	}
+
+	return route.Writer.Write(p)
+}
+
+// route returns the route of $tenant, opening it if the tenant has none yet, or the default route
+func (t *TenantRouter) route(tenant string) (route *TenantRoute, fault error) {
+	if tenant == "" {
+		return t.fallback, nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if r, ok := t.routes[tenant]; ok {
+		return &r, nil
+	}
+
+	if t.openRoute == nil {
+		return t.fallback, nil
+	}
+
+	r, err := t.openRoute(tenant)
+	if err != nil {
+		return nil, fmt.Errorf("could not open the log route of tenant %s: %w", tenant, err)
+	}
+
+	t.routes[tenant] = r
+	t.opened = append(t.opened, r.Writer)
+
+	return &r, nil
+}
+
+// Flush flushes the writers of every route that buffer records, such as an AsyncWriter.
+func (t *TenantRouter) Flush() error {
+	var errs []error
+	for _, w := range t.writers() {
+		if f, ok := w.(interface{ Flush() error }); ok {
+			errs = append(errs, f.Flush())
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Close flushes the writers of every route and closes those opened by OpenRoute.
+func (t *TenantRouter) Close() error {
+	errs := []error{t.Flush()}
+
+	t.mu.Lock()
+	opened := t.opened
+	t.opened = nil
+	t.mu.Unlock()
+
+	for _, w := range opened {
+		if c, ok := w.(io.Closer); ok {
+			errs = append(errs, c.Close())
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// writers returns the writers of every route
+func (t *TenantRouter) writers() (writers []io.Writer) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, r := range t.routes {
+		writers = append(writers, r.Writer)
+	}
+
+	if t.fallback != nil {
+		writers = append(writers, t.fallback.Writer)
+	}
+
+	return writers
+}
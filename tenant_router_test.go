@@ -0,0 +1,82 @@
+package go11y_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/cirruscomms/go11y"
+)
+
+type closingBuffer struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (b *closingBuffer) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestTenantRouter(t *testing.T) {
+	t.Setenv("ENV", "test")
+
+	for _, schema := range []go11y.FieldSchema{go11y.SchemaGo11y, go11y.SchemaECS, go11y.SchemaOTel} {
+		t.Run(schema.String(), func(t *testing.T) {
+			acme := new(bytes.Buffer)
+			fallback := new(bytes.Buffer)
+			opened := map[string]*closingBuffer{}
+
+			router := go11y.NewTenantRouter(go11y.TenantRouterOpts{
+				Routes: map[string]go11y.TenantRoute{
+					"acme": {Writer: acme, MinLevel: go11y.LevelWarning},
+				},
+				OpenRoute: func(tenant string) (go11y.TenantRoute, error) {
+					opened[tenant] = &closingBuffer{}
+					return go11y.TenantRoute{Writer: opened[tenant], MinLevel: go11y.LevelDebug}, nil
+				},
+				Default: &go11y.TenantRoute{Writer: fallback},
+			})
+
+			cfg := go11y.NewConfig(go11y.WithLogLevel(go11y.LevelDebug), go11y.WithFieldSchema(schema))
+
+			ctx, o, err := go11y.Initialise(context.Background(), cfg, io.Discard, io.Discard, go11y.WithSinks(router.Sink()))
+			if err != nil {
+				t.Fatalf("failed to initialise observer: %v", err)
+			}
+
+			_, acmeObserver, _ := go11y.ChildContext(ctx, go11y.FieldTenantID, "acme")
+			acmeObserver.Info("acme info")
+			acmeObserver.Warning("acme warning")
+
+			_, globexObserver, _ := go11y.ChildContext(ctx, go11y.FieldTenantID, "globex")
+			globexObserver.Debug("globex debug")
+
+			o.Info("no tenant")
+
+			if strings.Contains(acme.String(), "acme info") || !strings.Contains(acme.String(), "acme warning") {
+				t.Errorf("expected only acme's warning to be routed to acme, got %s", acme.String())
+			}
+
+			globex := opened["globex"]
+			if len(opened) != 1 || globex == nil || !strings.Contains(globex.String(), "globex debug") {
+				t.Fatalf("expected a route to be opened for globex, got %v", opened)
+			}
+
+			if !strings.Contains(fallback.String(), "no tenant") || strings.Contains(fallback.String(), "acme") ||
+				strings.Contains(fallback.String(), "globex") {
+				t.Errorf("expected only the record without a tenant to be routed to the default route, got %s", fallback.String())
+			}
+
+			if err := router.Close(); err != nil {
+				t.Fatalf("failed to close router: %v", err)
+			}
+
+			if !globex.closed {
+				t.Errorf("expected the opened route to be closed")
+			}
+		})
+	}
+}
@@ -5,6 +5,11 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+
+	"go.opentelemetry.io/otel/codes"
+	otelSDKTrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	otelTrace "go.opentelemetry.io/otel/trace"
 )
 
 // InitialiseTestLogger set up a logger for use in tests - no tracing, no db logging
@@ -30,3 +35,100 @@ func InitialiseTestTracer(ctx context.Context, level slog.Level, logOut, logErr
 
 	return ctx, o, nil
 }
+
+// TestSpans gives access to the spans exported by an Observer created with InitialiseTestTracerInMemory
+type TestSpans struct {
+	exporter *tracetest.InMemoryExporter
+}
+
+// InitialiseTestTracerInMemory set up a tracer for use in tests that exports spans to memory rather than a collector -
+// with tracing, but no db logging. Spans are exported as soon as they end and can be inspected with the returned
+// TestSpans. Spans started without a tracer use the in-memory provider, as do tracers from o.Tracer.
+// Closing the Observer shuts the exporter down and discards the spans, so assert on them first.
+func InitialiseTestTracerInMemory(ctx context.Context, level slog.Level, logOut, logErr io.Writer) (ctxWithObserver context.Context, observer *Observer, spans *TestSpans, fault error) {
+	ctx, o, err := InitialiseTestLogger(ctx, level, logOut, logErr)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	exporter := tracetest.NewInMemoryExporter()
+	o.traceProvider = otelSDKTrace.NewTracerProvider(otelSDKTrace.WithSyncer(exporter))
+	scope, version := instrumentationScope()
+	o.tracer = o.Tracer(scope, otelTrace.WithInstrumentationVersion(version))
+
+	return ctx, o, &TestSpans{exporter: exporter}, nil
+}
+
+// Spans returns the spans exported so far, in the order they ended.
+func (ts *TestSpans) Spans() tracetest.SpanStubs {
+	return ts.exporter.GetSpans()
+}
+
+// Names returns the names of the spans exported so far, in the order they ended.
+func (ts *TestSpans) Names() []string {
+	names := []string{}
+
+	for _, s := range ts.Spans() {
+		names = append(names, s.Name)
+	}
+
+	return names
+}
+
+// Find returns the first exported span named $name, and whether there was one.
+func (ts *TestSpans) Find(name string) (span tracetest.SpanStub, found bool) {
+	for _, s := range ts.Spans() {
+		if s.Name == name {
+			return s, true
+		}
+	}
+
+	return tracetest.SpanStub{}, false
+}
+
+// Attribute returns the value of the attribute $key of the first exported span named $name, and whether it was set.
+func (ts *TestSpans) Attribute(name, key string) (value any, found bool) {
+	s, ok := ts.Find(name)
+	if !ok {
+		return nil, false
+	}
+
+	for _, kv := range s.Attributes {
+		if string(kv.Key) == key {
+			return kv.Value.AsInterface(), true
+		}
+	}
+
+	return nil, false
+}
+
+// Status returns the status code of the first exported span named $name, codes.Unset if there is no such span.
+func (ts *TestSpans) Status(name string) codes.Code {
+	s, ok := ts.Find(name)
+	if !ok {
+		return codes.Unset
+	}
+
+	return s.Status.Code
+}
+
+// IsChildOf reports whether the first exported span named $child has the first exported span named $parent as its
+// parent.
+func (ts *TestSpans) IsChildOf(child, parent string) bool {
+	c, ok := ts.Find(child)
+	if !ok {
+		return false
+	}
+
+	p, ok := ts.Find(parent)
+	if !ok {
+		return false
+	}
+
+	return c.Parent.SpanID() == p.SpanContext.SpanID() && c.Parent.TraceID() == p.SpanContext.TraceID()
+}
+
+// Reset discards the spans exported so far.
+func (ts *TestSpans) Reset() {
+	ts.exporter.Reset()
+}
@@ -2,42 +2,136 @@ package containers
 
 import (
 	"context"
+	"log/slog"
 	"testing"
+	"time"
 
+	"github.com/cirruscomms/go11y"
+	"github.com/docker/go-connections/nat"
 	"github.com/testcontainers/testcontainers-go"
 	grafanalgtm "github.com/testcontainers/testcontainers-go/modules/grafana-lgtm"
+	"github.com/testcontainers/testcontainers-go/wait"
 )
 
-// LGTM starts a Grafana LGTM container for testing purposes.
-func LGTM(t *testing.T, ctx context.Context) (ctr *grafanalgtm.GrafanaLGTMContainer, fault error) {
+const (
+	// LGTMImage is the default Grafana LGTM image used by LGTM.
+	LGTMImage = "grafana/otel-lgtm:0.6.0"
+
+	// LGTMServiceName is the default service name placed in the Configurator returned by LGTM.
+	LGTMServiceName = "go11y-test"
+
+	// LGTMReadyTimeout is the default time LGTM waits for the collector and Grafana to accept requests.
+	LGTMReadyTimeout = 2 * time.Minute
+)
+
+// LGTMOpts holds the options for starting a Grafana LGTM container.
+// The zero value starts the default image with the collector listening on its standard OTLP ports.
+type LGTMOpts struct {
+	// Image is the container image to run, defaulting to LGTMImage
+	Image string
+	// ServiceName is the service name placed in the returned Configurator, defaulting to LGTMServiceName
+	ServiceName string
+	// OtlpHTTPPort is the container port the collector accepts OTLP over HTTP on, defaulting to 4318/tcp
+	OtlpHTTPPort string
+	// OtlpGRPCPort is the container port the collector accepts OTLP over gRPC on, defaulting to 4317/tcp
+	OtlpGRPCPort string
+	// LogLevel is the log level placed in the returned Configurator, defaulting to slog.LevelInfo
+	LogLevel slog.Level
+	// ReadyTimeout bounds how long to wait for the stack to become ready, defaulting to LGTMReadyTimeout
+	ReadyTimeout time.Duration
+}
+
+// LGTMContainer wraps the GrafanaLGTMContainer with the endpoints a test needs to export to and query it.
+type LGTMContainer struct {
+	*grafanalgtm.GrafanaLGTMContainer
+	// OtelURL is the OTLP HTTP traces URL (scheme, host, port, path) suitable for go11y.Configurator.OtelURL
+	OtelURL string
+	// OtlpGRPCEndpoint is the host:port the collector accepts OTLP over gRPC on
+	OtlpGRPCEndpoint string
+	// GrafanaURL is the base URL of the Grafana UI
+	GrafanaURL string
+	// TempoURL is the base URL of the Tempo query API
+	TempoURL string
+	// Config is a ready-made configuration pointing go11y at the container
+	Config go11y.Configurator
+}
+
+// LGTM starts a Grafana LGTM container for testing purposes and waits until the OTLP receivers and Grafana are ready.
+// The OTEL_URL and OTEL_SERVICE_NAME environment variables are set for the duration of the test so go11y.LoadConfig
+// also points at the container.
+func LGTM(t *testing.T, ctx context.Context, opts LGTMOpts) (ctr *LGTMContainer, fault error) {
 	t.Helper()
 	t.Log("Starting Grafana LGTM container for testing...")
 
+	if opts.Image == "" {
+		opts.Image = LGTMImage
+	}
+	if opts.ServiceName == "" {
+		opts.ServiceName = LGTMServiceName
+	}
+	if opts.OtlpHTTPPort == "" {
+		opts.OtlpHTTPPort = grafanalgtm.OtlpHttpPort
+	}
+	if opts.OtlpGRPCPort == "" {
+		opts.OtlpGRPCPort = grafanalgtm.OtlpGrpcPort
+	}
+	if opts.ReadyTimeout <= 0 {
+		opts.ReadyTimeout = LGTMReadyTimeout
+	}
+
+	httpPort := nat.Port(opts.OtlpHTTPPort)
+	grpcPort := nat.Port(opts.OtlpGRPCPort)
+
 	c, err := grafanalgtm.Run(
 		ctx,
-		"grafana/otel-lgtm:0.6.0",
-		testcontainers.WithExposedPorts("8318/tcp", "8317/tcp"),
+		opts.Image,
+		testcontainers.WithExposedPorts(string(httpPort), string(grpcPort)),
 		grafanalgtm.WithAdminCredentials("admin", "admin"),
+		testcontainers.WithAdditionalWaitStrategyAndDeadline(
+			opts.ReadyTimeout,
+			wait.ForListeningPort(httpPort),
+			wait.ForListeningPort(grpcPort),
+			wait.ForHTTP("/api/health").WithPort(grafanalgtm.GrafanaPort),
+		),
 	)
 	if err != nil {
 		t.Errorf("failed to start Grafana LGTM container: %s", err)
 		return nil, err
 	}
 
-	host, err := c.Host(ctx)
+	httpEndpoint, err := c.PortEndpoint(ctx, httpPort, "http")
+	if err != nil {
+		t.Fatalf("failed to get OTLP HTTP endpoint: %v", err)
+	}
+
+	grpcEndpoint, err := c.PortEndpoint(ctx, grpcPort, "")
 	if err != nil {
-		t.Fatalf("failed to get container host: %v", err)
+		t.Fatalf("failed to get OTLP gRPC endpoint: %v", err)
 	}
 
-	port, err := c.MappedPort(ctx, "4318/tcp")
+	grafanaURL, err := c.HttpEndpoint(ctx)
 	if err != nil {
-		t.Fatalf("failed to get mapped port: %v", err)
+		t.Fatalf("failed to get Grafana endpoint: %v", err)
+	}
+
+	tempoURL, err := c.TempoEndpoint(ctx)
+	if err != nil {
+		t.Fatalf("failed to get Tempo endpoint: %v", err)
+	}
+
+	ctr = &LGTMContainer{
+		GrafanaLGTMContainer: c,
+		OtelURL:              httpEndpoint + "/v1/traces",
+		OtlpGRPCEndpoint:     grpcEndpoint,
+		GrafanaURL:           grafanaURL,
+		TempoURL:             tempoURL,
 	}
+	ctr.Config = go11y.CreateConfig(opts.LogLevel, ctr.OtelURL, "", opts.ServiceName, nil, nil)
 
-	t.Setenv("OTEL_HOST", host)
-	t.Setenv("OTEL_PORT", port.Port())
+	t.Setenv("OTEL_URL", ctr.OtelURL)
+	t.Setenv("OTEL_SERVICE_NAME", opts.ServiceName)
 
-	t.Logf("Grafana LGTM is running at %s:%s", host, port.Port())
+	t.Logf("Grafana LGTM is running - OTLP at %s, Grafana UI at %s", ctr.OtelURL, ctr.GrafanaURL)
 
-	return c, nil
+	return ctr, nil
 }
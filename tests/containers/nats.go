@@ -0,0 +1,102 @@
+package containers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+const (
+	// NATSClientPort is the container port NATS accepts client connections on.
+	NATSClientPort = "4222/tcp"
+
+	// NATSMonitorPort is the container port the NATS HTTP monitoring endpoints are served on.
+	NATSMonitorPort = "8222/tcp"
+)
+
+// NATSContainer wraps a generic NATS container with additional metadata.
+type NATSContainer struct {
+	NATS        *testcontainers.DockerContainer
+	Host        string
+	Port        string
+	MonitorPort string
+}
+
+// Cleanup terminates the NATS container.
+func (c NATSContainer) Cleanup(t testing.TB) {
+	if c.NATS != nil {
+		testcontainers.CleanupContainer(t, c.NATS)
+	}
+}
+
+// MappedPort returns the mapped port for the given container port.
+func (c NATSContainer) MappedPort(t testing.TB, ctx context.Context, port string) string {
+	t.Helper()
+	mappedPort, err := c.NATS.MappedPort(ctx, nat.Port(port))
+	if err != nil {
+		t.Fatalf("could not get mapped port %s: %v", port, err)
+	}
+
+	return mappedPort.Port()
+}
+
+// Hostname returns the hostname of the NATS container.
+func (c NATSContainer) Hostname(t testing.TB, ctx context.Context) string {
+	t.Helper()
+	host, err := c.NATS.Host(ctx)
+	if err != nil {
+		t.Fatalf("could not get host: %v", err)
+	}
+
+	return host
+}
+
+// URL returns the client connection URL for the NATS server.
+func (c NATSContainer) URL() string {
+	return fmt.Sprintf("nats://%s:%s", c.Host, c.Port)
+}
+
+// MonitorURL returns the base URL of the NATS HTTP monitoring endpoints (/varz, /connz, /jsz etc).
+func (c NATSContainer) MonitorURL() string {
+	return fmt.Sprintf("http://%s:%s", c.Host, c.MonitorPort)
+}
+
+// NATS starts a NATS container with JetStream enabled for testing purposes.
+// Containers are reused by name, so tests in the same run asking for the same $version share a server.
+func NATS(t *testing.T, ctx context.Context, version string) (container NATSContainer, fault error) {
+	t.Helper()
+	t.Log("Starting NATS container for testing...")
+
+	var err error
+
+	natsContainer := NATSContainer{}
+
+	name := fmt.Sprintf("go11y-test-nats-%s", version)
+
+	natsContainer.NATS, err = testcontainers.Run(
+		ctx,
+		fmt.Sprintf("nats:%s", version),
+		testcontainers.WithExposedPorts(NATSClientPort, NATSMonitorPort),
+		testcontainers.WithCmd("-js", "-m", "8222"),
+		testcontainers.WithWaitStrategy(
+			wait.ForLog("Server is ready"),
+			wait.ForListeningPort(NATSClientPort),
+		),
+		testcontainers.WithName(name),
+		testcontainers.WithReuseByName(name),
+	)
+	if err != nil {
+		t.Errorf("failed to start NATS container: %s", err)
+		return NATSContainer{}, err
+	}
+
+	natsContainer.Host = natsContainer.Hostname(t, ctx)
+	natsContainer.Port = natsContainer.MappedPort(t, ctx, NATSClientPort)
+	natsContainer.MonitorPort = natsContainer.MappedPort(t, ctx, NATSMonitorPort)
+
+	return natsContainer, nil
+}
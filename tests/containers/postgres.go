@@ -2,6 +2,9 @@
 // Currently, it includes functionality for
 // - Postgres containers
 // - Grafana LGTM containers
+// - Redis containers
+// - NATS containers (with JetStream enabled)
+// - trace query clients for the Tempo (LGTM) and Jaeger query APIs
 package containers
 
 import (
@@ -9,6 +12,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/cirruscomms/go11y/tests/db"
 	"github.com/docker/go-connections/nat"
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/testcontainers/testcontainers-go"
@@ -27,7 +31,7 @@ type DatabaseContainer struct {
 
 // Cleanup terminates the Postgres container.
 func (c DatabaseContainer) Cleanup(t testing.TB) {
-	if c.Postgres == nil {
+	if c.Postgres != nil {
 		testcontainers.CleanupContainer(t, c.Postgres)
 	}
 }
@@ -59,27 +63,74 @@ func (c DatabaseContainer) DatabaseURL() string {
 	return fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable", c.Username, c.Password, c.Host, c.Port, c.Database)
 }
 
+const (
+	// PostgresVersion is the default Postgres version started by Postgres.
+	PostgresVersion = "17"
+
+	// PostgresNamePrefix is the default prefix of the container name, which is used to reuse containers between tests.
+	PostgresNamePrefix = "go11y-test-postgres"
+)
+
+// PostgresOpts holds the options for starting a Postgres container.
+// The zero value starts PostgresVersion with a "go11y" database, owned by user "user" with password "password".
+type PostgresOpts struct {
+	// Version is the Postgres image tag, defaulting to PostgresVersion
+	Version string
+	// Database is the name of the database to create, defaulting to "go11y"
+	Database string
+	// Username is the owner of the database, defaulting to "user"
+	Username string
+	// Password is the password of $Username, defaulting to "password"
+	Password string
+	// NamePrefix is the prefix of the container name, defaulting to PostgresNamePrefix
+	NamePrefix string
+	// InitScripts are paths to .sql/.sh files run, in order, when the container is first created
+	InitScripts []string
+	// Migrations, when set, are applied with db.DBMigrator once the container is ready
+	Migrations db.FilesystemProvider
+	// MigrationLogger receives the migrator's logs, defaulting to the test log
+	MigrationLogger db.Logger
+}
+
 // Postgres starts a Postgres container for testing purposes.
-func Postgres(t *testing.T, ctx context.Context, version string) (container DatabaseContainer, fault error) {
+// Containers are reused by name (prefix, version and database), so tests asking for the same database share a server.
+func Postgres(t *testing.T, ctx context.Context, opts PostgresOpts) (container DatabaseContainer, fault error) {
 	t.Helper()
 	t.Log("Starting Postgres container for testing...")
 
+	if opts.Version == "" {
+		opts.Version = PostgresVersion
+	}
+	if opts.Database == "" {
+		opts.Database = "go11y"
+	}
+	if opts.Username == "" {
+		opts.Username = "user"
+	}
+	if opts.Password == "" {
+		opts.Password = "password"
+	}
+	if opts.NamePrefix == "" {
+		opts.NamePrefix = PostgresNamePrefix
+	}
+
 	var err error
 
 	dbContainer := DatabaseContainer{
-		Database: "api_calls",
-		Username: "user",
-		Password: "password",
+		Database: opts.Database,
+		Username: opts.Username,
+		Password: opts.Password,
 	}
 
-	name := fmt.Sprintf("vexil-test-postgres-%s", version)
+	name := fmt.Sprintf("%s-%s-%s", opts.NamePrefix, opts.Version, opts.Database)
 
 	dbContainer.Postgres, err = postgres.Run(
 		ctx,
-		fmt.Sprintf("postgres:%s", version),
+		fmt.Sprintf("postgres:%s", opts.Version),
 		postgres.WithDatabase(dbContainer.Database),
 		postgres.WithUsername(dbContainer.Username),
 		postgres.WithPassword(dbContainer.Password),
+		postgres.WithOrderedInitScripts(opts.InitScripts...),
 		postgres.BasicWaitStrategies(),
 		postgres.WithSQLDriver("pgx"),
 		testcontainers.WithName(name),
@@ -93,5 +144,44 @@ func Postgres(t *testing.T, ctx context.Context, version string) (container Data
 	dbContainer.Host = dbContainer.Hostname(t, ctx)
 	dbContainer.Port = dbContainer.MappedPort(t, ctx, "5432")
 
+	if opts.Migrations != nil {
+		logger := opts.MigrationLogger
+		if logger == nil {
+			logger = testLogger{t: t}
+		}
+
+		migrator, err := db.NewMigratorWithOpts(ctx, logger, dbContainer, opts.Migrations, db.MigratorOpts{ConnectRetries: 5})
+		if err != nil {
+			t.Errorf("failed to create migrator: %s", err)
+			return dbContainer, err
+		}
+		defer migrator.Close()
+
+		if err := migrator.Migrate(); err != nil {
+			t.Errorf("failed to run migrations: %s", err)
+			return dbContainer, err
+		}
+	}
+
 	return dbContainer, nil
 }
+
+// testLogger satisfies db.Logger by writing to the test log.
+type testLogger struct {
+	t testing.TB
+}
+
+func (l testLogger) Debug(msg string, ephemeralArgs ...any) {
+	l.t.Helper()
+	l.t.Log(append([]any{"DEBUG", msg}, ephemeralArgs...)...)
+}
+
+func (l testLogger) Info(msg string, ephemeralArgs ...any) {
+	l.t.Helper()
+	l.t.Log(append([]any{"INFO", msg}, ephemeralArgs...)...)
+}
+
+func (l testLogger) Error(msg string, err error, severity string, ephemeralArgs ...any) {
+	l.t.Helper()
+	l.t.Log(append([]any{severity, msg, err}, ephemeralArgs...)...)
+}
@@ -0,0 +1,86 @@
+package containers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/redis"
+)
+
+// RedisContainer wraps the Redis module container with additional metadata.
+type RedisContainer struct {
+	Redis *redis.RedisContainer
+	Host  string
+	Port  string
+}
+
+// Cleanup terminates the Redis container.
+func (c RedisContainer) Cleanup(t testing.TB) {
+	if c.Redis != nil {
+		testcontainers.CleanupContainer(t, c.Redis)
+	}
+}
+
+// MappedPort returns the mapped port for the given container port.
+func (c RedisContainer) MappedPort(t testing.TB, ctx context.Context, port string) string {
+	t.Helper()
+	mappedPort, err := c.Redis.MappedPort(ctx, nat.Port(port))
+	if err != nil {
+		t.Fatalf("could not get mapped port %s: %v", port, err)
+	}
+
+	return mappedPort.Port()
+}
+
+// Hostname returns the hostname of the Redis container.
+func (c RedisContainer) Hostname(t testing.TB, ctx context.Context) string {
+	t.Helper()
+	host, err := c.Redis.Host(ctx)
+	if err != nil {
+		t.Fatalf("could not get host: %v", err)
+	}
+
+	return host
+}
+
+// Address returns the host:port address of the Redis server, as expected by most Redis clients.
+func (c RedisContainer) Address() string {
+	return fmt.Sprintf("%s:%s", c.Host, c.Port)
+}
+
+// URL returns the connection URL for the Redis server.
+func (c RedisContainer) URL() string {
+	return fmt.Sprintf("redis://%s:%s", c.Host, c.Port)
+}
+
+// Redis starts a Redis container for testing purposes.
+// Containers are reused by name, so tests in the same run asking for the same $version share a server.
+func Redis(t *testing.T, ctx context.Context, version string) (container RedisContainer, fault error) {
+	t.Helper()
+	t.Log("Starting Redis container for testing...")
+
+	var err error
+
+	redisContainer := RedisContainer{}
+
+	name := fmt.Sprintf("go11y-test-redis-%s", version)
+
+	redisContainer.Redis, err = redis.Run(
+		ctx,
+		fmt.Sprintf("redis:%s", version),
+		testcontainers.WithName(name),
+		testcontainers.WithReuseByName(name),
+	)
+	if err != nil {
+		t.Errorf("failed to start Redis container: %s", err)
+		return RedisContainer{}, err
+	}
+
+	redisContainer.Host = redisContainer.Hostname(t, ctx)
+	redisContainer.Port = redisContainer.MappedPort(t, ctx, "6379")
+
+	return redisContainer, nil
+}
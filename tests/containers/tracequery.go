@@ -0,0 +1,300 @@
+package containers
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	grafanalgtm "github.com/testcontainers/testcontainers-go/modules/grafana-lgtm"
+)
+
+// TraceBackend is the query API spoken by a TraceQueryClient
+type TraceBackend int
+
+const (
+	// TraceBackendTempo queries Grafana Tempo, as run by the LGTM container
+	TraceBackendTempo TraceBackend = iota
+	// TraceBackendJaeger queries a Jaeger all-in-one container
+	TraceBackendJaeger
+)
+
+// ErrTraceNotFound is returned when the backend has no trace with the requested ID (yet)
+var ErrTraceNotFound = errors.New("trace not found")
+
+// Trace is a trace as returned by the backend's query API
+type Trace struct {
+	TraceID string
+	Spans   []TraceSpan
+}
+
+// TraceSpan is a span of a Trace
+type TraceSpan struct {
+	SpanID       string
+	ParentSpanID string // empty for root spans
+	Name         string
+	Service      string
+}
+
+// SpanNames returns the names of the trace's spans.
+func (t Trace) SpanNames() []string {
+	names := []string{}
+
+	for _, s := range t.Spans {
+		names = append(names, s.Name)
+	}
+
+	return names
+}
+
+// TraceQueryClient queries a tracing backend for traces, so integration tests can assert that spans actually arrived.
+type TraceQueryClient struct {
+	baseURL string
+	backend TraceBackend
+	client  *http.Client
+}
+
+// NewTraceQueryClient creates a client for the query API of $backend at $baseURL, e.g. http://localhost:3200 for Tempo
+// or http://localhost:16686 for Jaeger.
+func NewTraceQueryClient(baseURL string, backend TraceBackend) *TraceQueryClient {
+	if !strings.Contains(baseURL, "://") {
+		baseURL = "http://" + baseURL
+	}
+
+	return &TraceQueryClient{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		backend: backend,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// LGTMTraceQueryClient creates a client for the Tempo query API of a Grafana LGTM container started with LGTM.
+func LGTMTraceQueryClient(t testing.TB, ctx context.Context, c *grafanalgtm.GrafanaLGTMContainer) *TraceQueryClient {
+	t.Helper()
+
+	endpoint, err := c.TempoEndpoint(ctx)
+	if err != nil {
+		t.Fatalf("could not get tempo endpoint: %v", err)
+	}
+
+	return NewTraceQueryClient(endpoint, TraceBackendTempo)
+}
+
+// GetTrace fetches the trace with the hex encoded $traceID. ErrTraceNotFound is returned if the backend doesn't have it.
+func (c *TraceQueryClient) GetTrace(ctx context.Context, traceID string) (trace Trace, fault error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/api/traces/"+traceID, nil)
+	if err != nil {
+		return Trace{}, fmt.Errorf("could not create trace query request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return Trace{}, fmt.Errorf("could not query trace: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Trace{}, fmt.Errorf("could not read trace query response: %w", err)
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return Trace{}, ErrTraceNotFound
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return Trace{}, fmt.Errorf("trace query returned %d: %s", resp.StatusCode, body)
+	}
+
+	if c.backend == TraceBackendJaeger {
+		return parseJaegerTrace(traceID, body)
+	}
+
+	return parseTempoTrace(traceID, body)
+}
+
+// WaitForTrace polls the backend until the trace with $traceID has arrived with every span in $spanNames, or $timeout
+// has passed. Exporters batch spans, so traces take a few seconds to show up.
+func (c *TraceQueryClient) WaitForTrace(ctx context.Context, traceID string, timeout time.Duration, spanNames ...string) (trace Trace, fault error) {
+	deadline := time.Now().Add(timeout)
+
+	for {
+		trace, err := c.GetTrace(ctx, traceID)
+		if err == nil && containsAll(trace.SpanNames(), spanNames) {
+			return trace, nil
+		}
+
+		if time.Now().After(deadline) {
+			if err == nil {
+				return trace, fmt.Errorf("trace %s has spans %v, want %v", traceID, trace.SpanNames(), spanNames)
+			}
+			return Trace{}, fmt.Errorf("trace %s did not arrive: %w", traceID, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return Trace{}, ctx.Err()
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
+// AssertTrace fails the test unless the trace with $traceID arrives at the backend with every span in $spanNames
+// within $timeout.
+func (c *TraceQueryClient) AssertTrace(t testing.TB, ctx context.Context, traceID string, timeout time.Duration, spanNames ...string) Trace {
+	t.Helper()
+
+	trace, err := c.WaitForTrace(ctx, traceID, timeout, spanNames...)
+	if err != nil {
+		t.Errorf("%v", err)
+	}
+
+	return trace
+}
+
+func containsAll(have, want []string) bool {
+	for _, w := range want {
+		if !slices.Contains(have, w) {
+			return false
+		}
+	}
+
+	return true
+}
+
+type tempoTrace struct {
+	Batches []struct {
+		Resource struct {
+			Attributes []struct {
+				Key   string `json:"key"`
+				Value struct {
+					StringValue string `json:"stringValue"`
+				} `json:"value"`
+			} `json:"attributes"`
+		} `json:"resource"`
+		ScopeSpans                  []tempoScopeSpans `json:"scopeSpans"`
+		InstrumentationLibrarySpans []tempoScopeSpans `json:"instrumentationLibrarySpans"` // older Tempo versions
+	} `json:"batches"`
+}
+
+type tempoScopeSpans struct {
+	Spans []struct {
+		SpanID       string `json:"spanId"`
+		ParentSpanID string `json:"parentSpanId"`
+		Name         string `json:"name"`
+	} `json:"spans"`
+}
+
+func parseTempoTrace(traceID string, body []byte) (trace Trace, fault error) {
+	tt := tempoTrace{}
+	if err := json.Unmarshal(body, &tt); err != nil {
+		return Trace{}, fmt.Errorf("could not decode tempo trace: %w", err)
+	}
+
+	trace = Trace{TraceID: traceID}
+
+	for _, batch := range tt.Batches {
+		service := ""
+		for _, attr := range batch.Resource.Attributes {
+			if attr.Key == "service.name" {
+				service = attr.Value.StringValue
+			}
+		}
+
+		for _, scope := range append(batch.ScopeSpans, batch.InstrumentationLibrarySpans...) {
+			for _, s := range scope.Spans {
+				trace.Spans = append(trace.Spans, TraceSpan{
+					SpanID:       normaliseID(s.SpanID, 8),
+					ParentSpanID: normaliseID(s.ParentSpanID, 8),
+					Name:         s.Name,
+					Service:      service,
+				})
+			}
+		}
+	}
+
+	if len(trace.Spans) == 0 {
+		return Trace{}, ErrTraceNotFound
+	}
+
+	return trace, nil
+}
+
+type jaegerResponse struct {
+	Data []struct {
+		TraceID string `json:"traceID"`
+		Spans   []struct {
+			SpanID        string `json:"spanID"`
+			OperationName string `json:"operationName"`
+			ProcessID     string `json:"processID"`
+			References    []struct {
+				RefType string `json:"refType"`
+				SpanID  string `json:"spanID"`
+			} `json:"references"`
+		} `json:"spans"`
+		Processes map[string]struct {
+			ServiceName string `json:"serviceName"`
+		} `json:"processes"`
+	} `json:"data"`
+}
+
+func parseJaegerTrace(traceID string, body []byte) (trace Trace, fault error) {
+	jr := jaegerResponse{}
+	if err := json.Unmarshal(body, &jr); err != nil {
+		return Trace{}, fmt.Errorf("could not decode jaeger trace: %w", err)
+	}
+
+	trace = Trace{TraceID: traceID}
+
+	for _, d := range jr.Data {
+		for _, s := range d.Spans {
+			span := TraceSpan{
+				SpanID:  s.SpanID,
+				Name:    s.OperationName,
+				Service: d.Processes[s.ProcessID].ServiceName,
+			}
+
+			for _, ref := range s.References {
+				if ref.RefType == "CHILD_OF" {
+					span.ParentSpanID = ref.SpanID
+				}
+			}
+
+			trace.Spans = append(trace.Spans, span)
+		}
+	}
+
+	if len(trace.Spans) == 0 {
+		return Trace{}, ErrTraceNotFound
+	}
+
+	return trace, nil
+}
+
+// normaliseID returns $id as lower case hex - Tempo returns IDs base64 encoded in its JSON API
+func normaliseID(id string, size int) string {
+	if id == "" {
+		return ""
+	}
+
+	if b, err := hex.DecodeString(id); err == nil && len(b) == size {
+		return strings.ToLower(id)
+	}
+
+	if b, err := base64.StdEncoding.DecodeString(id); err == nil && len(b) == size {
+		return hex.EncodeToString(b)
+	}
+
+	return id
+}
@@ -0,0 +1,62 @@
+package containers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTraceQueryClient(t *testing.T) {
+	testCases := map[string]struct {
+		backend TraceBackend
+		body    string
+	}{
+		"tempo": {
+			backend: TraceBackendTempo,
+			body: `{"batches":[{"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"widgets"}}]},
+				"scopeSpans":[{"spans":[
+					{"spanId":"AQIDBAUGBwg=","name":"parent"},
+					{"spanId":"CQoLDA0ODxA=","parentSpanId":"AQIDBAUGBwg=","name":"child"}]}]}]}`,
+		},
+		"jaeger": {
+			backend: TraceBackendJaeger,
+			body: `{"data":[{"traceID":"abc","processes":{"p1":{"serviceName":"widgets"}},"spans":[
+				{"spanID":"0102030405060708","operationName":"parent","processID":"p1"},
+				{"spanID":"090a0b0c0d0e0f10","operationName":"child","processID":"p1",
+					"references":[{"refType":"CHILD_OF","spanID":"0102030405060708"}]}]}]}`,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/api/traces/0102030405060708090a0b0c0d0e0f10" {
+					http.NotFound(w, r)
+					return
+				}
+				_, _ = w.Write([]byte(tc.body))
+			}))
+			defer server.Close()
+
+			client := NewTraceQueryClient(server.URL, tc.backend)
+
+			trace := client.AssertTrace(t, context.Background(), "0102030405060708090a0b0c0d0e0f10", time.Second, "parent", "child")
+
+			if len(trace.Spans) != 2 {
+				t.Fatalf("expected 2 spans, got %+v", trace.Spans)
+			}
+
+			child := trace.Spans[1]
+			if child.ParentSpanID != "0102030405060708" || child.SpanID != "090a0b0c0d0e0f10" || child.Service != "widgets" {
+				t.Errorf("unexpected child span: %+v", child)
+			}
+
+			if _, err := client.GetTrace(context.Background(), "ffffffffffffffffffffffffffffffff"); !errors.Is(err, ErrTraceNotFound) {
+				t.Errorf("expected ErrTraceNotFound, got %v", err)
+			}
+		})
+	}
+}
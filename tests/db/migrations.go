@@ -4,11 +4,17 @@ package db
 import (
 	"context"
 	"embed"
+	"errors"
 	"fmt"
 	"io/fs"
 	"os"
+	"strconv"
+	"sync"
+	"time"
 
+	"github.com/cirruscomms/go11y"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 	migrate "github.com/jackc/tern/v2/migrate"
 )
 
@@ -77,9 +83,40 @@ type Configurator interface {
 type DBMigrator struct {
 	context       context.Context
 	connection    *pgx.Conn
+	poolConn      *pgxpool.Conn // the connection acquired from MigratorOpts.Pool, released by Close
+	ownsConn      bool          // whether the connection was dialled by the DBMigrator, and so is closed by Close
 	migrator      *migrate.Migrator
+	versionTable  string
+	fs            FilesystemProvider
+	environment   string // the environment whose seed scripts are applied, see MigratorOpts.Environment
 	configuration Configurator
 	logger        Logger
+	mu            *sync.Mutex // serialises the use of the connection by StatusHandler
+}
+
+// DefaultVersionTable is the table the migration version is stored in, unless MigratorOpts.VersionTable is set
+const DefaultVersionTable = "db_version"
+
+// DefaultConnectBackoff is the delay before the first connection retry, unless MigratorOpts.ConnectBackoff is set
+const DefaultConnectBackoff = 500 * time.Millisecond
+
+// MigratorOpts are the settings of a DBMigrator created by NewMigratorWithOpts
+type MigratorOpts struct {
+	// optional - an existing connection to migrate with, left open by Close, defaults to dialling the DatabaseURL of
+	// the Configurator
+	Conn *pgx.Conn
+	// optional - a pool to acquire the connection to migrate with from, released by Close, used if Conn is nil
+	Pool *pgxpool.Pool
+	// optional - the table the migration version is stored in, defaults to DefaultVersionTable
+	VersionTable string
+	// optional - the number of times dialling the database is retried, for containers that start slowly, defaults
+	// to 0
+	ConnectRetries int
+	// optional - the delay before the first retry, doubled after each one, defaults to DefaultConnectBackoff
+	ConnectBackoff time.Duration
+	// optional - the environment (e.g. "development") whose seed scripts, in SeedDir/<Environment>, are applied by
+	// Migrate, defaults to none so no seeds are applied
+	Environment string
 }
 
 // FilesystemProvider defines the interface for providing migration files from a filesystem.
@@ -89,44 +126,142 @@ type FilesystemProvider interface {
 	Open(name string) (fs.File, error)
 }
 
-// NewMigrator creates a new DBMigrator instance.
+// NewMigrator creates a new DBMigrator instance, dialling the DatabaseURL of $connParams.
+// The connection is closed by Close.
 func NewMigrator(ctx context.Context, logger Logger, connParams Configurator, fs FilesystemProvider) (migrator DBMigrator, fault error) {
-	conn, err := pgx.Connect(ctx, connParams.DatabaseURL())
-	if err != nil {
-		return DBMigrator{}, fmt.Errorf("could not connect to database: %w", err)
+	return NewMigratorWithOpts(ctx, logger, connParams, fs, MigratorOpts{})
+}
+
+// NewMigratorWithOpts creates a new DBMigrator instance with the connection, version table and connection retries of
+// $opts. $connParams is only needed to dial the database when neither $opts.Conn nor $opts.Pool is set.
+// The DBMigrator should be closed with Close once it is no longer needed.
+func NewMigratorWithOpts(
+	ctx context.Context,
+	logger Logger,
+	connParams Configurator,
+	fs FilesystemProvider,
+	opts MigratorOpts,
+) (migrator DBMigrator, fault error) {
+	if opts.VersionTable == "" {
+		opts.VersionTable = DefaultVersionTable
+	}
+
+	m := DBMigrator{
+		context:       ctx,
+		versionTable:  opts.VersionTable,
+		fs:            fs,
+		environment:   opts.Environment,
+		configuration: connParams,
+		logger:        logger,
+		mu:            &sync.Mutex{},
+	}
+
+	switch {
+	case opts.Conn != nil:
+		m.connection = opts.Conn
+	case opts.Pool != nil:
+		pc, err := opts.Pool.Acquire(ctx)
+		if err != nil {
+			return DBMigrator{}, fmt.Errorf("could not acquire a connection from the pool: %w", err)
+		}
+
+		m.poolConn = pc
+		m.connection = pc.Conn()
+	default:
+		if connParams == nil {
+			return DBMigrator{}, errors.New("could not connect to database: no connection, pool or configuration given")
+		}
+
+		conn, err := connect(ctx, logger, connParams.DatabaseURL(), opts)
+		if err != nil {
+			return DBMigrator{}, fmt.Errorf("could not connect to database: %w", err)
+		}
+
+		m.connection = conn
+		m.ownsConn = true
 	}
 
 	mo := &migrate.MigratorOptions{
 		DisableTx: false,
 	}
 
-	mig, err := migrate.NewMigratorEx(ctx, conn, "db_version", mo)
+	mig, err := migrate.NewMigratorEx(ctx, m.connection, opts.VersionTable, mo)
 	if err != nil {
+		m.Close()
 		return DBMigrator{}, fmt.Errorf("could not create migratorEx %w", err)
 	}
 
 	err = mig.LoadMigrations(fs)
 	if err != nil {
+		m.Close()
 		return DBMigrator{}, fmt.Errorf("could not load migrations: %w", err)
 	}
 
-	return DBMigrator{
-		context:       ctx,
-		connection:    conn,
-		migrator:      mig,
-		configuration: connParams,
-		logger:        logger,
-	}, nil
+	m.migrator = mig
+
+	return m, nil
+}
+
+// connect dials $dbURL, retrying with backoff as configured by $opts
+func connect(ctx context.Context, logger Logger, dbURL string, opts MigratorOpts) (conn *pgx.Conn, fault error) {
+	backoff := opts.ConnectBackoff
+	if backoff <= 0 {
+		backoff = DefaultConnectBackoff
+	}
+
+	for attempt := 0; ; attempt++ {
+		conn, err := pgx.Connect(ctx, dbURL)
+		if err == nil {
+			return conn, nil
+		}
+
+		if attempt >= opts.ConnectRetries {
+			return nil, fmt.Errorf("could not connect after %d attempts: %w", attempt+1, err)
+		}
+
+		logger.Debug("could not connect to database - retrying", "attempt", attempt+1, "backoff", backoff.String(), "error", err.Error())
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("could not connect before the context was done: %w", err)
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+	}
+}
+
+// Close closes the connection the DBMigrator dialled, or releases the one it acquired from a pool. A connection passed
+// in MigratorOpts.Conn is left open for the caller to close.
+func (m *DBMigrator) Close() {
+	switch {
+	case m.poolConn != nil:
+		m.poolConn.Release()
+	case m.ownsConn && m.connection != nil:
+		_ = m.connection.Close(context.WithoutCancel(m.context))
+	}
+
+	m.poolConn = nil
+	m.connection = nil
+	m.ownsConn = false
 }
 
 // Info holds information about the current migration status.
 type Info struct {
-	DBConnStr  string
+	DBConnStr  string // the connection string, with its password redacted by go11y.RedactDSN
+	Host       string
 	Port       string
 	Database   string
+	User       string
 	Migrations MigrationInfo
 }
 
+// LogArgs returns the database the Info is about as structured log fields, without the connection string and so
+// without its password
+func (i Info) LogArgs() (args []any) {
+	return []any{"host", i.Host, "port", i.Port, "database", i.Database, "user", i.User}
+}
+
 // MigrationInfo holds information about the migration status.
 type MigrationInfo struct {
 	CurrentVersion int32
@@ -152,14 +287,36 @@ func (m DBMigrator) GetCurrentVersion() (currentVersion int32, fault error) {
 	return m.migrator.GetCurrentVersion(m.context)
 }
 
+// connectionInfo returns the Info of the database the DBMigrator migrates, without its migrations
+func (m DBMigrator) connectionInfo() (i Info) {
+	if m.configuration != nil {
+		i.DBConnStr = go11y.RedactDSN(m.configuration.DatabaseURL())
+	}
+
+	// the connection's config covers connections and pools passed in MigratorOpts, which have no connection string
+	var cfg *pgx.ConnConfig
+	switch {
+	case m.connection != nil:
+		cfg = m.connection.Config()
+	case m.configuration != nil:
+		cfg, _ = pgx.ParseConfig(m.configuration.DatabaseURL())
+	}
+
+	if cfg != nil {
+		i.Host = cfg.Host
+		i.Port = strconv.Itoa(int(cfg.Port))
+		i.Database = cfg.Database
+		i.User = cfg.User
+	}
+
+	return i
+}
+
 // Info provides information about the current migration status.
 func (m DBMigrator) Info(stopAfter int32) (information Info, fault error) {
 	var err error
 
-	i := Info{
-		DBConnStr:  m.configuration.DatabaseURL(),
-		Migrations: MigrationInfo{},
-	}
+	i := m.connectionInfo()
 
 	i.Migrations.CurrentVersion, err = m.migrator.GetCurrentVersion(m.context)
 	if err != nil {
@@ -195,7 +352,9 @@ func (m DBMigrator) Info(stopAfter int32) (information Info, fault error) {
 	return i, nil
 }
 
-// Migrate migrates the database to the latest version.
+// Migrate migrates the database to the latest version, then applies the repeatable migrations that have changed and
+// the environment's seed scripts that haven't been applied (see RepeatablePrefix and SeedDir), recording the checksums
+// checked by Verify.
 func (m *DBMigrator) Migrate() (fault error) {
 	m.migrator.OnStart = func(sequence int32, name string, direction string, sql string) {
 		if direction == "up" {
@@ -210,10 +369,19 @@ func (m *DBMigrator) Migrate() (fault error) {
 		return fmt.Errorf("could not migrate: %w", err)
 	}
 
+	if err := m.applyScripts(m.context); err != nil {
+		return fmt.Errorf("could not apply repeatable migrations and seeds: %w", err)
+	}
+
+	if err := m.recordChecksums(m.context); err != nil {
+		return fmt.Errorf("could not record migration checksums: %w", err)
+	}
+
 	return nil
 }
 
-// MigrateTo migrates the database to the specified sequence number.
+// MigrateTo migrates the database to the specified sequence number, recording the checksums checked by Verify.
+// Repeatable migrations and seed scripts, which may depend on the latest schema, are only applied by Migrate.
 func (m *DBMigrator) MigrateTo(sequence int32) (fault error) {
 	m.migrator.OnStart = func(sequence int32, name string, direction string, _ string) {
 		// if direction == "up" {
@@ -229,6 +397,10 @@ func (m *DBMigrator) MigrateTo(sequence int32) (fault error) {
 		return fmt.Errorf("could not migrate to %d: %w", sequence, err)
 	}
 
+	if err := m.recordChecksums(m.context); err != nil {
+		return fmt.Errorf("could not record migration checksums: %w", err)
+	}
+
 	return nil
 }
 
@@ -238,14 +410,17 @@ func RunMigrations(ctx context.Context, logger Logger, connParams Configurator,
 	if err != nil {
 		return fmt.Errorf("could not create migrator: %w", err)
 	}
+	defer m.Close()
 
 	info, err := m.Info(stopAfter)
 	if err != nil {
 		return fmt.Errorf("could not get migration info: %w", err)
 	}
 
+	logger.Info("running migrations", append(info.LogArgs(), "current_version", info.Migrations.CurrentVersion)...)
+
 	if printSummary {
-		fmt.Printf("Migrations for %s:%s/%s\n", info.DBConnStr, info.Port, info.Database)
+		fmt.Printf("Migrations for %s@%s:%s/%s\n", info.User, info.Host, info.Port, info.Database)
 		fmt.Println(info.Migrations.Summary)
 	}
 
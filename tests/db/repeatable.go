@@ -0,0 +1,173 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// RepeatablePrefix is the prefix of repeatable migrations, e.g. R__calls_view.sql, which are applied by Migrate after
+// the numbered migrations whenever their content has changed, for views, functions and the like that are replaced
+// rather than altered
+const RepeatablePrefix = "R__"
+
+// SeedDir is the directory of seed scripts, applied by Migrate once each from the subdirectory named after
+// MigratorOpts.Environment, e.g. seeds/development/001_users.sql
+const SeedDir = "seeds"
+
+// repeatableTable is the table the checksums of the applied repeatable migrations are recorded in
+func (m DBMigrator) repeatableTable() string {
+	return m.versionTable + "_repeatable"
+}
+
+// seedTable is the table the applied seed scripts are recorded in
+func (m DBMigrator) seedTable() string {
+	return m.versionTable + "_seeds"
+}
+
+// script is a repeatable migration or seed script
+type script struct {
+	name string
+	sql  string
+}
+
+// readScripts returns the .sql files in $dir of the migrations filesystem, in name order, that $keep returns true for.
+// A directory that doesn't exist has no scripts.
+func (m DBMigrator) readScripts(dir string, keep func(name string) bool) (scripts []script, fault error) {
+	files, err := m.fs.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read '%s': %w", dir, err)
+	}
+
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), ".sql") || !keep(f.Name()) {
+			continue
+		}
+
+		b, err := m.fs.ReadFile(path.Join(dir, f.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("could not read '%s': %w", f.Name(), err)
+		}
+
+		scripts = append(scripts, script{name: f.Name(), sql: string(b)})
+	}
+
+	slices.SortFunc(scripts, func(a, b script) int { return strings.Compare(a.name, b.name) })
+
+	return scripts, nil
+}
+
+// applyScripts applies the repeatable migrations that are new or have changed, then the seed scripts of the
+// environment that haven't been applied yet, each in a transaction with its record
+func (m DBMigrator) applyScripts(ctx context.Context) (fault error) {
+	_, err := m.connection.Exec(ctx, fmt.Sprintf(`
+		create table if not exists %s (
+			name text primary key,
+			checksum text not null,
+			applied_at timestamptz not null default now()
+		);
+		create table if not exists %s (
+			environment text not null,
+			name text not null,
+			checksum text not null,
+			applied_at timestamptz not null default now(),
+			primary key (environment, name)
+		);`, m.repeatableTable(), m.seedTable()))
+	if err != nil {
+		return fmt.Errorf("could not create script tables: %w", err)
+	}
+
+	repeatables, err := m.readScripts(".", func(name string) bool { return strings.HasPrefix(name, RepeatablePrefix) })
+	if err != nil {
+		return err
+	}
+
+	for _, s := range repeatables {
+		var recorded string
+		err := m.connection.QueryRow(ctx, fmt.Sprintf("select checksum from %s where name = $1", m.repeatableTable()), s.name).
+			Scan(&recorded)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("could not read checksum of '%s': %w", s.name, err)
+		}
+
+		if recorded == checksum(s.sql) {
+			continue
+		}
+
+		m.logger.Info("applying repeatable migration", "name", s.name)
+
+		err = m.applyScript(ctx, s, fmt.Sprintf(`
+			insert into %s (name, checksum) values ($1, $2)
+			on conflict (name) do update set checksum = excluded.checksum, applied_at = now()`, m.repeatableTable(),
+		), s.name, checksum(s.sql))
+		if err != nil {
+			return err
+		}
+	}
+
+	if m.environment == "" {
+		return nil
+	}
+
+	seeds, err := m.readScripts(path.Join(SeedDir, m.environment), func(string) bool { return true })
+	if err != nil {
+		return err
+	}
+
+	for _, s := range seeds {
+		var applied bool
+		err := m.connection.QueryRow(ctx, fmt.Sprintf(
+			"select exists (select 1 from %s where environment = $1 and name = $2)", m.seedTable(),
+		), m.environment, s.name).Scan(&applied)
+		if err != nil {
+			return fmt.Errorf("could not check if seed '%s' has been applied: %w", s.name, err)
+		}
+
+		if applied {
+			continue
+		}
+
+		m.logger.Info("applying seed", "environment", m.environment, "name", s.name)
+
+		err = m.applyScript(ctx, s, fmt.Sprintf(
+			"insert into %s (environment, name, checksum) values ($1, $2, $3)", m.seedTable(),
+		), m.environment, s.name, checksum(s.sql))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// applyScript runs $s and the statement recording it, $record with $args, in a transaction
+func (m DBMigrator) applyScript(ctx context.Context, s script, record string, args ...any) (fault error) {
+	tx, err := m.connection.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("could not begin transaction for '%s': %w", s.name, err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if _, err := tx.Exec(ctx, s.sql); err != nil {
+		return fmt.Errorf("could not apply '%s': %w", s.name, err)
+	}
+
+	if _, err := tx.Exec(ctx, record, args...); err != nil {
+		return fmt.Errorf("could not record '%s': %w", s.name, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("could not commit '%s': %w", s.name, err)
+	}
+
+	return nil
+}
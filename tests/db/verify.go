@@ -0,0 +1,325 @@
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+
+	migrate "github.com/jackc/tern/v2/migrate"
+)
+
+const (
+	// DriftEdited is the kind of a DriftError for an applied migration whose file has been edited since
+	DriftEdited = "edited"
+	// DriftMissing is the kind of a DriftError for an applied migration whose file no longer exists
+	DriftMissing = "missing"
+	// DriftSchema is the kind of a DriftError for tables or columns changed outside of the migrations
+	DriftSchema = "schema"
+)
+
+// DriftError is a discrepancy between the migrations and the database found by Verify
+type DriftError struct {
+	Kind     string `json:"kind"`               // DriftEdited, DriftMissing or DriftSchema
+	Sequence int32  `json:"sequence,omitempty"` // the migration's sequence, or the version the schema was recorded at
+	Name     string `json:"name,omitempty"`
+	Expected string `json:"expected"` // the checksum recorded when the migration was applied
+	Actual   string `json:"actual"`   // the checksum of the migration file or schema now
+}
+
+func (e *DriftError) Error() string {
+	switch e.Kind {
+	case DriftSchema:
+		return fmt.Sprintf("schema has drifted since v%d was migrated to: checksum %s, recorded %s", e.Sequence, e.Actual, e.Expected)
+	case DriftMissing:
+		return fmt.Sprintf("applied migration %d (%s) is missing", e.Sequence, e.Name)
+	default:
+		return fmt.Sprintf("applied migration %d (%s) has been edited: checksum %s, recorded %s", e.Sequence, e.Name, e.Actual, e.Expected)
+	}
+}
+
+// Status is the migration status served by StatusHandler
+type Status struct {
+	Status         string        `json:"status"` // "ok", "pending" if there are migrations to apply, or "failing"
+	CurrentVersion int32         `json:"current_version"`
+	TargetVersion  int32         `json:"target_version"`
+	Drift          []*DriftError `json:"drift,omitempty"`
+	Error          string        `json:"error,omitempty"`
+}
+
+// checksum returns the checksum of $sql, as recorded for applied migrations and schemas
+func checksum(sql string) string {
+	sum := sha256.Sum256([]byte(sql))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// checksumTable is the table the checksums of the applied migrations are recorded in
+func (m DBMigrator) checksumTable() string {
+	return m.versionTable + "_checksums"
+}
+
+// schemaTable is the table the checksum of the schema is recorded in
+func (m DBMigrator) schemaTable() string {
+	return m.versionTable + "_schema"
+}
+
+// ensureChecksumTables creates the tables the checksums are recorded in, if they don't exist
+func (m DBMigrator) ensureChecksumTables(ctx context.Context) (fault error) {
+	_, err := m.connection.Exec(ctx, fmt.Sprintf(`
+		create table if not exists %s (
+			sequence integer primary key,
+			name text not null,
+			checksum text not null,
+			applied_at timestamptz not null default now()
+		);
+		create table if not exists %s (
+			id boolean primary key default true check (id),
+			version integer not null,
+			checksum text not null,
+			recorded_at timestamptz not null default now()
+		);`, m.checksumTable(), m.schemaTable()))
+	if err != nil {
+		return fmt.Errorf("could not create checksum tables: %w", err)
+	}
+
+	return nil
+}
+
+// recordChecksums records the checksums of the migrations applied up to the current version, forgetting those rolled
+// back, and the checksum of the resulting schema. Checksums already recorded are kept, so edits to the files of
+// applied migrations are found by Verify. Migrations applied before checksums were recorded are recorded as they are
+// now.
+func (m DBMigrator) recordChecksums(ctx context.Context) (fault error) {
+	if err := m.ensureChecksumTables(ctx); err != nil {
+		return err
+	}
+
+	current, err := m.migrator.GetCurrentVersion(ctx)
+	if err != nil {
+		return fmt.Errorf("could not get current version: %w", err)
+	}
+
+	if _, err = m.connection.Exec(ctx, fmt.Sprintf("delete from %s where sequence > $1", m.checksumTable()), current); err != nil {
+		return fmt.Errorf("could not forget rolled back checksums: %w", err)
+	}
+
+	for _, mig := range m.migrator.Migrations {
+		if mig.Sequence > current {
+			break
+		}
+
+		_, err = m.connection.Exec(ctx, fmt.Sprintf(
+			"insert into %s (sequence, name, checksum) values ($1, $2, $3) on conflict (sequence) do nothing",
+			m.checksumTable(),
+		), mig.Sequence, mig.Name, checksum(mig.UpSQL))
+		if err != nil {
+			return fmt.Errorf("could not record checksum of migration %d: %w", mig.Sequence, err)
+		}
+	}
+
+	schema, err := m.schemaChecksum(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = m.connection.Exec(ctx, fmt.Sprintf(`
+		insert into %s (version, checksum) values ($1, $2)
+		on conflict (id) do update set version = excluded.version, checksum = excluded.checksum, recorded_at = now()`,
+		m.schemaTable(),
+	), current, schema)
+	if err != nil {
+		return fmt.Errorf("could not record schema checksum: %w", err)
+	}
+
+	return nil
+}
+
+// schemaChecksum returns the checksum of the tables and columns of the database, other than the migrator's own
+func (m DBMigrator) schemaChecksum(ctx context.Context) (sum string, fault error) {
+	own := []string{}
+	for _, t := range []string{m.versionTable, m.checksumTable(), m.schemaTable(), m.repeatableTable(), m.seedTable()} {
+		own = append(own, t[strings.LastIndex(t, ".")+1:])
+	}
+
+	rows, err := m.connection.Query(ctx, `
+		select table_schema, table_name, column_name, data_type, is_nullable
+		from information_schema.columns
+		where table_schema not in ('pg_catalog', 'information_schema') and not (table_name = any($1))
+		order by table_schema, table_name, column_name`, own)
+	if err != nil {
+		return "", fmt.Errorf("could not read schema: %w", err)
+	}
+	defer rows.Close()
+
+	b := strings.Builder{}
+	for rows.Next() {
+		var schema, table, column, dataType, nullable string
+		if err := rows.Scan(&schema, &table, &column, &dataType, &nullable); err != nil {
+			return "", fmt.Errorf("could not read schema: %w", err)
+		}
+
+		fmt.Fprintf(&b, "%s.%s.%s %s %s\n", schema, table, column, dataType, nullable)
+	}
+
+	if err := rows.Err(); err != nil {
+		return "", fmt.Errorf("could not read schema: %w", err)
+	}
+
+	return checksum(b.String()), nil
+}
+
+// recordedChecksum is the checksum of a migration recorded when it was applied
+type recordedChecksum struct {
+	name     string
+	checksum string
+}
+
+// compareChecksums returns the applied migrations, recorded in $recorded, that have been edited or are missing from
+// $migrations
+func compareChecksums(migrations []*migrate.Migration, recorded map[int32]recordedChecksum) (drift []*DriftError) {
+	files := map[int32]*migrate.Migration{}
+	for _, mig := range migrations {
+		files[mig.Sequence] = mig
+	}
+
+	for _, seq := range slices.Sorted(maps.Keys(recorded)) {
+		r := recorded[seq]
+
+		mig, ok := files[seq]
+		if !ok {
+			drift = append(drift, &DriftError{Kind: DriftMissing, Sequence: seq, Name: r.name, Expected: r.checksum})
+			continue
+		}
+
+		if actual := checksum(mig.UpSQL); actual != r.checksum {
+			drift = append(drift, &DriftError{
+				Kind:     DriftEdited,
+				Sequence: seq,
+				Name:     mig.Name,
+				Expected: r.checksum,
+				Actual:   actual,
+			})
+		}
+	}
+
+	return drift
+}
+
+// Verify checks the migrations applied to the database against the checksums recorded when they were applied, finding
+// historical migrations that have been edited or removed, and the schema against the checksum recorded after the
+// last migration, finding tables and columns changed outside of the migrations. Each discrepancy is logged and
+// returned, joined, as a *DriftError. Databases migrated before checksums were recorded have nothing to verify until
+// they are next migrated.
+func (m DBMigrator) Verify() (fault error) {
+	drift, err := m.verify(m.context)
+	if err != nil {
+		return fmt.Errorf("could not verify migrations: %w", err)
+	}
+
+	errs := make([]error, 0, len(drift))
+	for _, d := range drift {
+		m.logger.Error("migration drift detected", d, "high", "kind", d.Kind, "sequence", d.Sequence, "name", d.Name)
+		errs = append(errs, d)
+	}
+
+	return errors.Join(errs...)
+}
+
+func (m DBMigrator) verify(ctx context.Context) (drift []*DriftError, fault error) {
+	if err := m.ensureChecksumTables(ctx); err != nil {
+		return nil, err
+	}
+
+	rows, err := m.connection.Query(ctx, fmt.Sprintf("select sequence, name, checksum from %s", m.checksumTable()))
+	if err != nil {
+		return nil, fmt.Errorf("could not read recorded checksums: %w", err)
+	}
+	defer rows.Close()
+
+	recorded := map[int32]recordedChecksum{}
+	for rows.Next() {
+		var seq int32
+		r := recordedChecksum{}
+		if err := rows.Scan(&seq, &r.name, &r.checksum); err != nil {
+			return nil, fmt.Errorf("could not read recorded checksums: %w", err)
+		}
+
+		recorded[seq] = r
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not read recorded checksums: %w", err)
+	}
+
+	drift = compareChecksums(m.migrator.Migrations, recorded)
+
+	var version int32
+	var expected string
+	err = m.connection.QueryRow(ctx, fmt.Sprintf("select version, checksum from %s", m.schemaTable())).Scan(&version, &expected)
+	if err != nil {
+		// nothing recorded yet
+		return drift, nil
+	}
+
+	actual, err := m.schemaChecksum(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if actual != expected {
+		drift = append(drift, &DriftError{Kind: DriftSchema, Sequence: version, Expected: expected, Actual: actual})
+	}
+
+	return drift, nil
+}
+
+// Status returns the migration status of the database: "failing" if Verify finds drift or the status can't be read,
+// "pending" if there are migrations to apply, otherwise "ok".
+func (m DBMigrator) Status(ctx context.Context) (status Status) {
+	status = Status{Status: "ok"}
+	if len(m.migrator.Migrations) != 0 {
+		status.TargetVersion = m.migrator.Migrations[len(m.migrator.Migrations)-1].Sequence
+	}
+
+	var err error
+	status.CurrentVersion, err = m.migrator.GetCurrentVersion(ctx)
+	if err == nil {
+		status.Drift, err = m.verify(ctx)
+	}
+
+	switch {
+	case err != nil:
+		status.Status = "failing"
+		status.Error = err.Error()
+	case len(status.Drift) != 0:
+		status.Status = "failing"
+	case status.CurrentVersion < status.TargetVersion:
+		status.Status = "pending"
+	}
+
+	return status
+}
+
+// StatusHandler returns a handler for a migration status endpoint, serving the Status as JSON with a 503 status code
+// when it is failing. The DBMigrator's connection is used, so requests are served one at a time.
+func (m DBMigrator) StatusHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.mu.Lock()
+		status := m.Status(r.Context())
+		m.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		if status.Status == "failing" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+
+		_ = json.NewEncoder(w).Encode(status)
+	})
+}
@@ -0,0 +1,8 @@
+ALTER TABLE remote_api_requests ALTER COLUMN status_code DROP NOT NULL;
+ALTER TABLE remote_api_requests ADD COLUMN IF NOT EXISTS error TEXT;
+
+---- create above / drop below ----
+
+ALTER TABLE remote_api_requests DROP COLUMN IF EXISTS error;
+DELETE FROM remote_api_requests WHERE status_code IS NULL;
+ALTER TABLE remote_api_requests ALTER COLUMN status_code SET NOT NULL;
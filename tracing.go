@@ -2,8 +2,13 @@ package go11y
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"log/slog"
+	"math"
+	"runtime/debug"
 	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -17,12 +22,59 @@ import (
 	otelTrace "go.opentelemetry.io/otel/trace"
 )
 
-// Tracer gets a Tracer with the given name and options using the Observer's tracer provider.
+// Tracer gets a Tracer with the given name and options using the Observer's tracer provider, or the global one if
+// tracing isn't configured.
 func (o *Observer) Tracer(name string, opts ...otelTrace.TracerOption) otelTrace.Tracer {
+	if o.traceProvider == nil {
+		return otel.GetTracerProvider().Tracer(name, opts...)
+	}
+
 	return o.traceProvider.Tracer(name, opts...)
 }
 
-func tracerProvider(ctx context.Context, cfg Configurator) (tracerProvider *otelSDKTrace.TracerProvider, fault error) {
+// WithTracer sets the tracer used by Span, StartSpan and Expand when they aren't given one, overriding the tracer
+// Initialise creates with the main module as its instrumentation scope.
+func WithTracer(tracer otelTrace.Tracer) Option {
+	return func(o *Observer) {
+		o.tracer = tracer
+	}
+}
+
+// instrumentationScope returns the path and version of the main module, the instrumentation scope of the tracer
+// Initialise creates, falling back to go11y's module path if the binary has no build info
+func instrumentationScope() (name, version string) {
+	name = "github.com/cirruscomms/go11y"
+	if bi, ok := debug.ReadBuildInfo(); ok && bi.Main.Path != "" {
+		name, version = bi.Main.Path, bi.Main.Version
+	}
+
+	return name, version
+}
+
+// spanTracer returns $tracer, or the Observer's tracer if it is nil
+func (o *Observer) spanTracer(tracer otelTrace.Tracer) otelTrace.Tracer {
+	if tracer != nil {
+		return tracer
+	}
+
+	if o.tracer == nil {
+		// an Observer that wasn't created by Initialise
+		name, version := instrumentationScope()
+		return o.Tracer(name, otelTrace.WithInstrumentationVersion(version))
+	}
+
+	return o.tracer
+}
+
+func tracerProvider(
+	ctx context.Context,
+	cfg Configurator,
+	watchdog *exportWatchdog,
+	degraded bool,
+) (
+	tracerProvider *otelSDKTrace.TracerProvider,
+	fault error,
+) {
 	if cfg.OtelURL() == "" {
 		// Skip-tracer Randy: if no OTEL URL is provided, we assume the user does not want to set up tracing and we
 		// return nil for the tracer provider
@@ -50,19 +102,43 @@ func tracerProvider(ctx context.Context, cfg Configurator) (tracerProvider *otel
 		return nil, fmt.Errorf("failed to create exporter: %w", err)
 	}
 
+	var spanExporter otelSDKTrace.SpanExporter = exporter
+	if degraded {
+		// the collector was unreachable at startup and isn't required, so queue spans until it can be reached
+		address, err := otelAddress(cfg.OtelURL())
+		if err != nil {
+			return nil, fmt.Errorf("failed to create exporter: %w", err)
+		}
+
+		spanExporter = newReconnectingExporter(exporter, address, watchdog)
+	}
+
+	resourceAttrs := configServiceIdentity(cfg).resourceAttributes()
+	if revision := ReadBuildInfo(cfg).Revision; revision != "" {
+		resourceAttrs = append(resourceAttrs, otelAttribute.String("vcs.revision", revision))
+	}
+	if environment := configEnvironment(cfg); environment != "" {
+		resourceAttrs = append(resourceAttrs, otelSemConv.DeploymentEnvironmentKey.String(string(environment)))
+	}
+
+	// the batch span processor blocks when its queue is full, so the spans it would have dropped are counted by the
+	// queueLimitProcessor, which drops them before they reach it
+	batcher := otelSDKTrace.NewBatchSpanProcessor(
+		watchedExporter{SpanExporter: spanExporter, watchdog: watchdog},
+		otelSDKTrace.WithMaxExportBatchSize(otelSDKTrace.DefaultMaxExportBatchSize),
+		otelSDKTrace.WithBatchTimeout(otelSDKTrace.DefaultScheduleDelay*time.Millisecond),
+		otelSDKTrace.WithMaxQueueSize(otelSDKTrace.DefaultMaxQueueSize),
+		otelSDKTrace.WithBlocking(),
+	)
+
 	tp := otelSDKTrace.NewTracerProvider(
-		otelSDKTrace.WithBatcher(
-			exporter,
-			otelSDKTrace.WithMaxExportBatchSize(otelSDKTrace.DefaultMaxExportBatchSize),
-			otelSDKTrace.WithBatchTimeout(otelSDKTrace.DefaultScheduleDelay*time.Millisecond),
-			otelSDKTrace.WithMaxExportBatchSize(otelSDKTrace.DefaultMaxExportBatchSize),
-		),
-		otelSDKTrace.WithResource(
-			otelResource.NewWithAttributes(
-				otelSemConv.SchemaURL,
-				otelSemConv.ServiceNameKey.String(cfg.ServiceName()),
-			),
-		),
+		otelSDKTrace.WithSpanProcessor(queueLimitProcessor{
+			SpanProcessor: batcher,
+			watchdog:      watchdog,
+			limit:         otelSDKTrace.DefaultMaxQueueSize,
+		}),
+		otelSDKTrace.WithResource(otelResource.NewWithAttributes(otelSemConv.SchemaURL, resourceAttrs...)),
+		otelSDKTrace.WithSampler(otelSDKTrace.ParentBased(otelSDKTrace.TraceIDRatioBased(configSampleRatio(cfg)))),
 	)
 
 	otel.SetTracerProvider(tp)
@@ -70,58 +146,139 @@ func tracerProvider(ctx context.Context, cfg Configurator) (tracerProvider *otel
 	return tp, nil
 }
 
+// maxAttributeJSONSize caps the size of span attributes holding values encoded as JSON (structs, maps...)
+const maxAttributeJSONSize = 1024
+
 func argsToAttributes(combinedArgs ...any) []otelAttribute.KeyValue {
 	if len(combinedArgs) == 0 {
 		return nil
 	}
 
+	combinedArgs = argPairs(combinedArgs)
+
 	dropKeys := []string{
 		FieldSpanID,
 		FieldTraceID,
 	}
 	attrs := make([]otelAttribute.KeyValue, 0, len(combinedArgs)/2)
 	for i := 0; i < len(combinedArgs); i += 2 {
-		if i+1 < len(combinedArgs) {
-			key := fmt.Sprintf("%v", combinedArgs[i])
-
-			if !slices.Contains(dropKeys, key) {
-				switch V := combinedArgs[i+1].(type) {
-				case int:
-					attrs = append(attrs, otelAttribute.Int(key, V))
-				case int64:
-					attrs = append(attrs, otelAttribute.Int64(key, V))
-				case float32:
-					attrs = append(attrs, otelAttribute.Float64(key, float64(V)))
-				case float64:
-					attrs = append(attrs, otelAttribute.Float64(key, V))
-				case bool:
-					attrs = append(attrs, otelAttribute.Bool(key, V))
-				case string:
-					attrs = append(attrs, otelAttribute.String(key, V))
-				case []string:
-					attrs = append(attrs, otelAttribute.StringSlice(key, V))
-				case []int:
-					attrs = append(attrs, otelAttribute.IntSlice(key, V))
-				case []int64:
-					attrs = append(attrs, otelAttribute.Int64Slice(key, V))
-				case []float64:
-					attrs = append(attrs, otelAttribute.Float64Slice(key, V))
-				case []bool:
-					attrs = append(attrs, otelAttribute.BoolSlice(key, V))
-				default:
-					value := fmt.Sprintf("%v", V)
-					attrs = append(attrs, otelAttribute.String(key, value))
-				}
-			}
-		} else {
-			// If there's an odd number of arguments, the last one is ignored
-			break
+		// argPairs only returns string keys
+		key := combinedArgs[i].(string)
+
+		if !slices.Contains(dropKeys, key) {
+			attrs = appendAttribute(attrs, key, combinedArgs[i+1])
 		}
 	}
 
 	return attrs
 }
 
+// appendAttribute appends $value to $attrs as a typed span attribute named $key: numbers, bools, strings and slices of
+// them keep their type, durations are recorded in milliseconds, errors are recorded with their message along with the
+// OTel exception.message and exception.type attributes, groups are flattened into dotted keys and anything else is
+// recorded as JSON (capped at maxAttributeJSONSize bytes) or with fmt's %v when it can't be encoded. Values under keys
+// naming a secret (see forbiddenAttrKey) are redacted with RedactSecret, as they are in log records, unless they are
+// numbers, bools, times or durations.
+func appendAttribute(attrs []otelAttribute.KeyValue, key string, value any) []otelAttribute.KeyValue {
+	if forbiddenAttrKey(key) {
+		switch V := value.(type) {
+		case nil, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64,
+			time.Duration, time.Time, []slog.Attr, slog.LogValuer:
+			// not secrets, or redacted by their LogValue, e.g. Secret
+		case redactedValue:
+			return append(attrs, otelAttribute.String(key, string(V)))
+		case string:
+			return append(attrs, otelAttribute.String(key, RedactSecret(V, 6)))
+		case []byte:
+			return append(attrs, otelAttribute.String(key, RedactSecret(string(V), 6)))
+		default:
+			return append(attrs, otelAttribute.String(key, RedactSecret(fmt.Sprintf("%v", V), 6)))
+		}
+	}
+
+	switch V := value.(type) {
+	case redactedValue:
+		return append(attrs, otelAttribute.String(key, string(V)))
+	case nil:
+		return append(attrs, otelAttribute.String(key, ""))
+	case string:
+		return append(attrs, otelAttribute.String(key, V))
+	case bool:
+		return append(attrs, otelAttribute.Bool(key, V))
+	case int:
+		return append(attrs, otelAttribute.Int(key, V))
+	case int8:
+		return append(attrs, otelAttribute.Int64(key, int64(V)))
+	case int16:
+		return append(attrs, otelAttribute.Int64(key, int64(V)))
+	case int32:
+		return append(attrs, otelAttribute.Int64(key, int64(V)))
+	case int64:
+		return append(attrs, otelAttribute.Int64(key, V))
+	case uint8:
+		return append(attrs, otelAttribute.Int64(key, int64(V)))
+	case uint16:
+		return append(attrs, otelAttribute.Int64(key, int64(V)))
+	case uint32:
+		return append(attrs, otelAttribute.Int64(key, int64(V)))
+	case uint:
+		if uint64(V) > math.MaxInt64 {
+			return append(attrs, otelAttribute.String(key, strconv.FormatUint(uint64(V), 10)))
+		}
+		return append(attrs, otelAttribute.Int64(key, int64(V)))
+	case uint64:
+		if V > math.MaxInt64 {
+			return append(attrs, otelAttribute.String(key, strconv.FormatUint(V, 10)))
+		}
+		return append(attrs, otelAttribute.Int64(key, int64(V)))
+	case float32:
+		return append(attrs, otelAttribute.Float64(key, float64(V)))
+	case float64:
+		return append(attrs, otelAttribute.Float64(key, V))
+	case time.Duration:
+		return append(attrs, otelAttribute.Float64(key, float64(V)/float64(time.Millisecond)))
+	case time.Time:
+		return append(attrs, otelAttribute.String(key, V.Format(time.RFC3339Nano)))
+	case []string:
+		return append(attrs, otelAttribute.StringSlice(key, V))
+	case []int:
+		return append(attrs, otelAttribute.IntSlice(key, V))
+	case []int64:
+		return append(attrs, otelAttribute.Int64Slice(key, V))
+	case []float64:
+		return append(attrs, otelAttribute.Float64Slice(key, V))
+	case []bool:
+		return append(attrs, otelAttribute.BoolSlice(key, V))
+	case []slog.Attr:
+		for _, a := range V {
+			attrs = appendAttribute(attrs, key+"."+a.Key, a.Value.Resolve().Any())
+		}
+		return attrs
+	case slog.LogValuer:
+		return appendAttribute(attrs, key, V.LogValue().Resolve().Any())
+	case error:
+		return append(attrs,
+			otelAttribute.String(key, V.Error()),
+			otelSemConv.ExceptionMessageKey.String(V.Error()),
+			otelSemConv.ExceptionTypeKey.String(fmt.Sprintf("%T", V)),
+		)
+	case fmt.Stringer:
+		return append(attrs, otelAttribute.String(key, V.String()))
+	}
+
+	b, err := json.Marshal(value)
+	if err != nil {
+		return append(attrs, otelAttribute.String(key, fmt.Sprintf("%v", value)))
+	}
+
+	if len(b) > maxAttributeJSONSize {
+		// the cut could split a multi-byte character
+		return append(attrs, otelAttribute.String(key, truncateString(string(b), maxAttributeJSONSize)+"..."))
+	}
+
+	return append(attrs, otelAttribute.String(key, string(b)))
+}
+
 // SpanKindInternal is a constant that aliases otelTrace.SpanKindInternal
 const SpanKindInternal = otelTrace.SpanKindInternal
 
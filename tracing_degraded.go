@@ -0,0 +1,116 @@
+package go11y
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	otelSDKTrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// ErrEndpointUnreachable is wrapped by the configuration problems of endpoints that don't accept connections
+var ErrEndpointUnreachable = errors.New("endpoint unreachable")
+
+// errCollectorUnreachable is returned by a reconnectingExporter that has queued spans rather than exporting them
+var errCollectorUnreachable = errors.New("collector unreachable - spans queued until it can be reached")
+
+const (
+	// otelProbeTimeout is the timeout of the startup probe of the collector when OTEL_REQUIRED is false
+	otelProbeTimeout = 2 * time.Second
+	// reconnectInterval is the minimum time between attempts to reach an unreachable collector
+	reconnectInterval = 30 * time.Second
+	// reconnectQueueSize is the maximum number of spans queued while the collector is unreachable
+	reconnectQueueSize = otelSDKTrace.DefaultMaxQueueSize
+)
+
+// OtelRequirementConfigurator is implemented by Configurators that say whether the OTel collector must be reachable
+// for Initialise to succeed. Configuration implements it, other Configurators are treated as requiring it.
+type OtelRequirementConfigurator interface {
+	OtelRequired() bool
+}
+
+// WithOtelRequired sets whether the OTel collector is required. When it isn't, a collector that is unreachable at
+// startup doesn't fail Initialise: spans are queued, the collector is retried periodically and a warning is logged.
+// An invalid OTEL_URL disables tracing instead of failing Initialise.
+func WithOtelRequired(required bool) ConfigOption {
+	return func(c *Configuration) {
+		c.otelRequired = required
+	}
+}
+
+// OtelRequired returns whether the OTel collector must be reachable for Initialise to succeed.
+// This method is part of the OtelRequirementConfigurator interface.
+func (c *Configuration) OtelRequired() bool {
+	return c.otelRequired
+}
+
+// configOtelRequired returns whether the OTel collector of $cfg is required
+func configOtelRequired(cfg Configurator) bool {
+	if rc, ok := cfg.(OtelRequirementConfigurator); ok {
+		return rc.OtelRequired()
+	}
+
+	return true
+}
+
+// reconnectingExporter queues spans while the collector is unreachable, trying to reach it again at most once per
+// interval, and exports the queue along with the next batch once it can.
+type reconnectingExporter struct {
+	otelSDKTrace.SpanExporter
+	address     string
+	interval    time.Duration
+	watchdog    *exportWatchdog
+	mu          sync.Mutex
+	connected   bool
+	lastAttempt time.Time
+	queue       []otelSDKTrace.ReadOnlySpan
+}
+
+func newReconnectingExporter(
+	exporter otelSDKTrace.SpanExporter,
+	address string,
+	watchdog *exportWatchdog,
+) *reconnectingExporter {
+	return &reconnectingExporter{
+		SpanExporter: exporter,
+		address:      address,
+		interval:     reconnectInterval,
+		watchdog:     watchdog,
+		lastAttempt:  time.Now(),
+	}
+}
+
+func (e *reconnectingExporter) ExportSpans(ctx context.Context, spans []otelSDKTrace.ReadOnlySpan) error {
+	e.mu.Lock()
+
+	if !e.connected && time.Since(e.lastAttempt) >= e.interval {
+		e.lastAttempt = time.Now()
+		if probe(ctx, e.address, otelProbeTimeout) == nil {
+			e.connected = true
+			e.watchdog.logInfo("telemetry collector reachable - exporting queued spans", "queued", len(e.queue))
+		}
+	}
+
+	if !e.connected {
+		e.queue = append(e.queue, spans...)
+		if overflow := len(e.queue) - reconnectQueueSize; overflow > 0 {
+			e.queue = e.queue[overflow:]
+			ExportSpansDropped.Add(float64(overflow))
+		}
+		e.mu.Unlock()
+
+		return errCollectorUnreachable
+	}
+
+	queued := e.queue
+	e.queue = nil
+	e.mu.Unlock()
+
+	if err := e.SpanExporter.ExportSpans(ctx, append(queued, spans...)); err != nil {
+		return fmt.Errorf("could not export spans: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package go11y
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+
+	otelSDKTrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type recordingExporter struct {
+	exported int
+}
+
+func (e *recordingExporter) ExportSpans(_ context.Context, spans []otelSDKTrace.ReadOnlySpan) error {
+	e.exported += len(spans)
+	return nil
+}
+
+func (e *recordingExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+func closedAddress(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	address := listener.Addr().String()
+	_ = listener.Close()
+
+	return address
+}
+
+func TestReconnectingExporter(t *testing.T) {
+	inner := &recordingExporter{}
+	exporter := newReconnectingExporter(inner, closedAddress(t), newExportWatchdog())
+	exporter.interval = 0
+
+	spans := tracetest.SpanStubs{{Name: "one"}, {Name: "two"}}.Snapshots()
+
+	if err := exporter.ExportSpans(context.Background(), spans); !errors.Is(err, errCollectorUnreachable) {
+		t.Fatalf("expected spans to be queued while the collector is unreachable, got %v", err)
+	}
+	if inner.exported != 0 || len(exporter.queue) != 2 {
+		t.Fatalf("expected 2 queued spans and none exported, got %d queued and %d exported",
+			len(exporter.queue), inner.exported)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer listener.Close()
+	exporter.address = listener.Addr().String()
+
+	if err := exporter.ExportSpans(context.Background(), spans[:1]); err != nil {
+		t.Fatalf("expected spans to be exported once the collector is reachable, got %v", err)
+	}
+	if inner.exported != 3 || len(exporter.queue) != 0 {
+		t.Errorf("expected the queue to be flushed with the batch, got %d queued and %d exported",
+			len(exporter.queue), inner.exported)
+	}
+}
+
+func TestOtelNotRequired(t *testing.T) {
+	t.Setenv("ENV", "test")
+
+	otelURL := "http://" + closedAddress(t) + "/v1/traces"
+
+	cfg := NewConfig(WithOtelURL(otelURL))
+	if _, _, err := Initialise(context.Background(), cfg, new(bytes.Buffer), new(bytes.Buffer)); err != nil {
+		t.Fatalf("expected the collector not to be probed by default, got %v", err)
+	}
+
+	buf := new(bytes.Buffer)
+	cfg = NewConfig(WithOtelURL(otelURL), WithOtelRequired(false))
+	_, o, err := Initialise(context.Background(), cfg, buf, buf)
+	if err != nil {
+		t.Fatalf("expected an unreachable collector not to fail initialisation, got %v", err)
+	}
+	defer o.Close()
+
+	if o.traceProvider == nil {
+		t.Errorf("expected tracing to be degraded rather than disabled")
+	}
+	logged := buf.String()
+	if !strings.Contains(logged, "telemetry collector unreachable") || !strings.Contains(logged, `"tracing":"degraded"`) {
+		t.Errorf("expected a warning that tracing is degraded, got %s", buf.String())
+	}
+
+	cfg = NewConfig(WithOtelURL("ftp://collector"), WithOtelRequired(false))
+	_, o, err = Initialise(context.Background(), cfg, new(bytes.Buffer), new(bytes.Buffer))
+	if err != nil {
+		t.Fatalf("expected an invalid OTel URL not to fail initialisation, got %v", err)
+	}
+
+	if o.traceProvider != nil {
+		t.Errorf("expected tracing to be disabled for an invalid OTel URL")
+	}
+}
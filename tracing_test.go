@@ -0,0 +1,556 @@
+package go11y
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	otelAttribute "go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	otelSDKTrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSpanStatus(t *testing.T) {
+	testCases := map[string]struct {
+		options  []any
+		fail     bool
+		expected codes.Code
+	}{
+		"error sets error status": {
+			options:  []any{},
+			fail:     true,
+			expected: codes.Error,
+		},
+		"success leaves status unset by default": {
+			options:  []any{},
+			fail:     false,
+			expected: codes.Unset,
+		},
+		"success marked ok when enabled": {
+			options:  []any{WithSpanStatusOK(true)},
+			fail:     false,
+			expected: codes.Ok,
+		},
+		"error is not overridden by ok": {
+			options:  []any{WithSpanStatusOK(true)},
+			fail:     true,
+			expected: codes.Error,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			recorder := tracetest.NewSpanRecorder()
+			tp := otelSDKTrace.NewTracerProvider(otelSDKTrace.WithSpanProcessor(recorder))
+
+			cfg := CreateConfig(LevelInfo, "", "", "", []string{}, []string{})
+
+			ctx, _, err := Initialise(context.Background(), cfg, io.Discard, io.Discard, tc.options...)
+			if err != nil {
+				t.Fatalf("failed to initialise observer: %v", err)
+			}
+
+			_, o, err := Span(ctx, tp.Tracer("test"), "TestSpanStatus", SpanKindInternal)
+			if err != nil {
+				t.Fatalf("failed to start span: %v", err)
+			}
+
+			if tc.fail {
+				o.Error("something failed", errors.New("failure"), SeverityLow)
+			}
+
+			o.End()
+
+			spans := recorder.Ended()
+			if len(spans) != 1 {
+				t.Fatalf("expected 1 ended span, got %d", len(spans))
+			}
+
+			if spans[0].Status().Code != tc.expected {
+				t.Errorf("expected status %v, got %v", tc.expected, spans[0].Status().Code)
+			}
+
+			if tc.fail && spans[0].Status().Description != "something failed" {
+				t.Errorf("expected status description %q, got %q", "something failed", spans[0].Status().Description)
+			}
+		})
+	}
+}
+
+func TestStartSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := otelSDKTrace.NewTracerProvider(otelSDKTrace.WithSpanProcessor(recorder))
+
+	cfg := CreateConfig(LevelInfo, "", "", "", []string{}, []string{})
+
+	ctx, parent, err := Initialise(context.Background(), cfg, io.Discard, io.Discard)
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	ctx, _, endOuter, err := StartSpan(ctx, tp.Tracer("test"), "outer", SpanKindInternal)
+	if err != nil {
+		t.Fatalf("failed to start span: %v", err)
+	}
+
+	done := make(chan func(), 10)
+	for range 10 {
+		go func() {
+			_, child, end, err := StartSpan(ctx, tp.Tracer("test"), "inner", SpanKindInternal, "worker", true)
+			if err != nil {
+				t.Errorf("failed to start span: %v", err)
+			}
+			child.Info("working")
+			done <- end
+		}()
+	}
+
+	// end the outer span before its children to prove out-of-order ending is safe
+	endOuter()
+	endOuter()
+
+	for range 10 {
+		end := <-done
+		end()
+	}
+
+	if parent.span != nil || len(parent.spans) != 0 {
+		t.Errorf("expected the parent observer to have no spans")
+	}
+
+	if len(recorder.Ended()) != 11 {
+		t.Errorf("expected 11 ended spans, got %d", len(recorder.Ended()))
+	}
+
+	for _, s := range recorder.Ended() {
+		if s.Name() == "inner" && s.Parent().SpanID() == [8]byte{} {
+			t.Errorf("expected inner span to have a parent")
+		}
+	}
+}
+
+func TestDefaultTracer(t *testing.T) {
+	ctx, _, spans, err := InitialiseTestTracerInMemory(context.Background(), LevelInfo, io.Discard, io.Discard)
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	ctx, _, endParent, err := StartSpan(ctx, nil, "span", SpanKindInternal)
+	if err != nil {
+		t.Fatalf("failed to start span: %v", err)
+	}
+
+	_, o, err := Expand(ctx, nil, "expanded", SpanKindInternal)
+	if err != nil {
+		t.Fatalf("failed to expand observer: %v", err)
+	}
+	o.End()
+	endParent()
+
+	if names := spans.Names(); len(names) != 2 || names[0] != "expanded" || names[1] != "span" {
+		t.Fatalf("expected the expanded span and its parent, got %v", names)
+	}
+
+	if !spans.IsChildOf("expanded", "span") {
+		t.Errorf("expected the expanded span to be a child of the first")
+	}
+
+	scope, _ := instrumentationScope()
+	if name := spans.Spans()[0].InstrumentationScope.Name; name != scope {
+		t.Errorf("expected the instrumentation scope %q, got %q", scope, name)
+	}
+
+	recorder := tracetest.NewSpanRecorder()
+	tp := otelSDKTrace.NewTracerProvider(otelSDKTrace.WithSpanProcessor(recorder))
+
+	cfg := CreateConfig(LevelInfo, "", "", "", []string{}, []string{})
+
+	ctx, _, err = Initialise(context.Background(), cfg, io.Discard, io.Discard, WithTracer(tp.Tracer("override")))
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	_, o, err = Span(ctx, nil, "overridden", SpanKindInternal)
+	if err != nil {
+		t.Fatalf("failed to start span: %v", err)
+	}
+	o.End()
+
+	if ended := recorder.Ended(); len(ended) != 1 || ended[0].InstrumentationScope().Name != "override" {
+		t.Errorf("expected the span to be started by the tracer given to WithTracer")
+	}
+}
+
+func TestEndWithoutSpan(t *testing.T) {
+	cfg := CreateConfig(LevelInfo, "", "", "", []string{}, []string{})
+
+	_, o, err := Initialise(context.Background(), cfg, io.Discard, io.Discard)
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	// must not panic
+	o.End()
+}
+
+func TestInitialiseTestTracerInMemory(t *testing.T) {
+	ctx, o, spans, err := InitialiseTestTracerInMemory(context.Background(), LevelInfo, io.Discard, io.Discard)
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	ctx, _, endParent, err := StartSpan(ctx, o.Tracer("test"), "parent", SpanKindInternal)
+	if err != nil {
+		t.Fatalf("failed to start span: %v", err)
+	}
+
+	_, child, endChild, err := StartSpan(ctx, o.Tracer("test"), "child", SpanKindInternal)
+	if err != nil {
+		t.Fatalf("failed to start span: %v", err)
+	}
+
+	child.Error("child failed", errors.New("failure"), SeverityLow, "attempt", 3)
+	endChild()
+	endParent()
+
+	if names := spans.Names(); len(names) != 2 || names[0] != "child" || names[1] != "parent" {
+		t.Errorf("expected child and parent spans, got %v", names)
+	}
+
+	if !spans.IsChildOf("child", "parent") || spans.IsChildOf("parent", "child") {
+		t.Errorf("expected child to be a child of parent")
+	}
+
+	if spans.Status("child") != codes.Error {
+		t.Errorf("expected the child span to have an error status, got %v", spans.Status("child"))
+	}
+
+	if v, ok := spans.Attribute("child", "attempt"); !ok || v != int64(3) {
+		t.Errorf("expected the child span to have attempt=3, got %v", v)
+	}
+
+	spans.Reset()
+	if len(spans.Spans()) != 0 {
+		t.Errorf("expected no spans after reset")
+	}
+}
+
+type invoice struct {
+	ID    int    `json:"id"`
+	Payee string `json:"payee"`
+}
+
+type declinedError struct{}
+
+func (declinedError) Error() string {
+	return "card declined"
+}
+
+func TestArgsToAttributes(t *testing.T) {
+	at := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	attrs := argsToAttributes(
+		"count", uint32(7),
+		"ratio", float32(0.5),
+		"elapsed", 1500*time.Microsecond,
+		"at", at,
+		"tags", []string{"a", "b"},
+		"invoice", invoice{ID: 1, Payee: "acme"},
+		"big", strings.Repeat("x", 2*maxAttributeJSONSize),
+		"huge", map[string]string{"v": strings.Repeat("é", maxAttributeJSONSize)},
+		"err", declinedError{},
+		Any("http", slog.GroupValue(slog.String("method", "GET"), slog.Int("status", 200))),
+		FieldTraceID, "dropped",
+	)
+
+	got := map[otelAttribute.Key]otelAttribute.Value{}
+	for _, a := range attrs {
+		got[a.Key] = a.Value
+	}
+
+	expected := map[otelAttribute.Key]otelAttribute.Value{
+		"count":             otelAttribute.Int64Value(7),
+		"ratio":             otelAttribute.Float64Value(0.5),
+		"elapsed":           otelAttribute.Float64Value(1.5),
+		"at":                otelAttribute.StringValue("2025-01-02T03:04:05Z"),
+		"tags":              otelAttribute.StringSliceValue([]string{"a", "b"}),
+		"invoice":           otelAttribute.StringValue(`{"id":1,"payee":"acme"}`),
+		"big":               otelAttribute.StringValue(strings.Repeat("x", 2*maxAttributeJSONSize)),
+		"err":               otelAttribute.StringValue("card declined"),
+		"exception.message": otelAttribute.StringValue("card declined"),
+		"exception.type":    otelAttribute.StringValue("go11y.declinedError"),
+		"http.method":       otelAttribute.StringValue("GET"),
+		"http.status":       otelAttribute.Int64Value(200),
+	}
+	for k, v := range expected {
+		if got[k] != v {
+			t.Errorf("expected %s to be %v (%s), got %v (%s)", k, v.Emit(), v.Type(), got[k].Emit(), got[k].Type())
+		}
+	}
+
+	huge := got["huge"].AsString()
+	if len(huge) > maxAttributeJSONSize+3 || !strings.HasSuffix(huge, "...") || !utf8.ValidString(huge) {
+		t.Errorf("expected JSON values to be capped at a valid UTF-8 boundary, got %d bytes", len(huge))
+	}
+
+	if _, ok := got[FieldTraceID]; ok {
+		t.Errorf("expected the trace ID not to be added as an attribute")
+	}
+}
+
+func TestSpanAttributeRedaction(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := otelSDKTrace.NewTracerProvider(otelSDKTrace.WithSpanProcessor(recorder))
+
+	cfg := CreateConfig(LevelInfo, "", "", "", []string{}, []string{})
+
+	ctx, _, err := Initialise(context.Background(), cfg, io.Discard, io.Discard, WithAttrRedaction(false))
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	_, o, err := Span(ctx, tp.Tracer("test"), "TestSpanAttributeRedaction", SpanKindInternal)
+	if err != nil {
+		t.Fatalf("failed to start span: %v", err)
+	}
+
+	o.Info("login", "password", "hunter2-supersecret", "api_key", []byte("hunter2-supersecret"),
+		"client_secret", Secret("hunter2-supersecret"), "token_count", 42, "cache_key", "user:42")
+	o.End()
+
+	got := map[otelAttribute.Key]otelAttribute.Value{}
+	for _, a := range recorder.Ended()[0].Attributes() {
+		got[a.Key] = a.Value
+	}
+
+	for _, key := range []otelAttribute.Key{"password", "api_key", "client_secret"} {
+		if v, ok := got[key]; !ok || v.AsString() != RedactSecret("hunter2-supersecret", 6) {
+			t.Errorf("expected %s to be redacted, got %v", key, v.Emit())
+		}
+	}
+
+	if got["token_count"].AsInt64() != 42 || got["cache_key"].AsString() != "user:42" {
+		t.Errorf("expected attributes not naming a secret to be left alone, got %v", got)
+	}
+}
+
+func TestSpanAttributeLimits(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := otelSDKTrace.NewTracerProvider(otelSDKTrace.WithSpanProcessor(recorder))
+
+	cfg := CreateConfig(LevelInfo, "", "", "", []string{}, []string{})
+
+	limits := WithSpanAttributeLimits(SpanAttributeLimits{MaxAttributes: 3, MaxValueLength: 4})
+	ctx, _, err := Initialise(context.Background(), cfg, io.Discard, io.Discard, limits, "service", "billing")
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	_, o, err := Span(ctx, tp.Tracer("test"), "TestSpanAttributeLimits", SpanKindInternal)
+	if err != nil {
+		t.Fatalf("failed to start span: %v", err)
+	}
+
+	o.Info("sent", FieldRequestBody, `{"card":"4111"}`, "tags", []string{"abcdef"}, "status", 200, "dropped", true)
+	o.End()
+
+	got := map[otelAttribute.Key]otelAttribute.Value{}
+	for _, a := range recorder.Ended()[0].Attributes() {
+		got[a.Key] = a.Value
+	}
+
+	if len(got) != 3 {
+		t.Errorf("expected 3 attributes, got %v", got)
+	}
+	if _, ok := got[FieldRequestBody]; ok {
+		t.Errorf("expected the request body not to be set as an attribute")
+	}
+	if got["service"].AsString() != "bill" || got["tags"].AsStringSlice()[0] != "abcd" {
+		t.Errorf("expected string values to be truncated, got %v", got)
+	}
+	if _, ok := got["dropped"]; ok {
+		t.Errorf("expected attributes over the cap to be dropped")
+	}
+}
+
+func TestSpanEvents(t *testing.T) {
+	testCases := map[string]struct {
+		option          Option
+		events          []string
+		spanAttr        bool
+		eventAttr       bool
+		exceptionEvents int
+	}{
+		"attributes by default": {
+			option:          WithSpanEvents(SpanEventsAttributes, LevelDevelop),
+			events:          []string{"debug", "info"},
+			spanAttr:        true,
+			exceptionEvents: 1,
+		},
+		"args on events": {
+			option:          WithSpanEvents(SpanEventsWithArgs, LevelDevelop),
+			events:          []string{"debug", "info"},
+			eventAttr:       true,
+			exceptionEvents: 1,
+		},
+		"level threshold": {
+			option:          WithSpanEvents(SpanEventsAttributes, LevelInfo),
+			events:          []string{"info"},
+			spanAttr:        true,
+			exceptionEvents: 1,
+		},
+		"off": {
+			option:          WithSpanEvents(SpanEventsOff, LevelDevelop),
+			exceptionEvents: 1,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			recorder := tracetest.NewSpanRecorder()
+			tp := otelSDKTrace.NewTracerProvider(otelSDKTrace.WithSpanProcessor(recorder))
+
+			cfg := CreateConfig(LevelDebug, "", "", "", []string{}, []string{})
+
+			ctx, _, err := Initialise(context.Background(), cfg, io.Discard, io.Discard, tc.option)
+			if err != nil {
+				t.Fatalf("failed to initialise observer: %v", err)
+			}
+
+			_, o, err := Span(ctx, tp.Tracer("test"), "TestSpanEvents", SpanKindInternal)
+			if err != nil {
+				t.Fatalf("failed to start span: %v", err)
+			}
+
+			o.Debug("debug", "attempt", 1)
+			o.Info("info", "attempt", 2)
+			o.Error("error", errors.New("failure"), SeverityLow, "attempt", 3)
+			o.End()
+
+			span := recorder.Ended()[0]
+
+			events, exceptions, eventAttr := []string{}, 0, false
+			for _, e := range span.Events() {
+				if e.Name == "exception" {
+					exceptions++
+					continue
+				}
+
+				events = append(events, e.Name)
+				eventAttr = eventAttr || len(e.Attributes) != 0
+			}
+
+			if strings.Join(events, ",") != strings.Join(tc.events, ",") {
+				t.Errorf("expected events %v, got %v", tc.events, events)
+			}
+			if exceptions != tc.exceptionEvents {
+				t.Errorf("expected %d exception events, got %d", tc.exceptionEvents, exceptions)
+			}
+			if eventAttr != tc.eventAttr {
+				t.Errorf("expected event attributes to be %v", tc.eventAttr)
+			}
+
+			spanAttr := false
+			for _, a := range span.Attributes() {
+				spanAttr = spanAttr || a.Key == "attempt"
+			}
+			if spanAttr != tc.spanAttr {
+				t.Errorf("expected span attributes to be %v", tc.spanAttr)
+			}
+
+			if span.Status().Code != codes.Error {
+				t.Errorf("expected the error to fail the span")
+			}
+		})
+	}
+}
+
+func TestLevelMethodSpanAttributes(t *testing.T) {
+	testCases := map[string]func(o *Observer, msg string, args ...any){
+		"develop": (*Observer).Develop,
+		"debug":   (*Observer).Debug,
+		"info":    (*Observer).Info,
+		"notice":  (*Observer).Notice,
+		"warning": (*Observer).Warning,
+		"warn":    (*Observer).Warn,
+	}
+
+	for name, logFn := range testCases {
+		t.Run(name, func(t *testing.T) {
+			recorder := tracetest.NewSpanRecorder()
+			tp := otelSDKTrace.NewTracerProvider(otelSDKTrace.WithSpanProcessor(recorder))
+
+			cfg := CreateConfig(LevelDevelop, "", "", "", []string{}, []string{})
+
+			ctx, _, err := Initialise(context.Background(), cfg, io.Discard, io.Discard, "service", "billing")
+			if err != nil {
+				t.Fatalf("failed to initialise observer: %v", err)
+			}
+
+			for attempt := 1; attempt <= 2; attempt++ {
+				_, o, err := Span(ctx, tp.Tracer("test"), "TestLevelMethodSpanAttributes", SpanKindInternal)
+				if err != nil {
+					t.Fatalf("failed to start span: %v", err)
+				}
+
+				logFn(o, name, fmt.Sprintf("attempt_%d", attempt), attempt)
+				o.End()
+			}
+
+			ended := recorder.Ended()
+			if len(ended) != 2 {
+				t.Fatalf("expected 2 ended spans, got %d", len(ended))
+			}
+
+			for i, span := range ended {
+				got := map[otelAttribute.Key]otelAttribute.Value{}
+				for _, a := range span.Attributes() {
+					got[a.Key] = a.Value
+				}
+
+				key := otelAttribute.Key(fmt.Sprintf("attempt_%d", i+1))
+				if len(got) != 2 || got["service"].AsString() != "billing" || got[key].AsInt64() != int64(i+1) {
+					t.Errorf("expected the stable arg and %s=%d as separate attributes, got %v", key, i+1, got)
+				}
+			}
+		})
+	}
+}
+
+// TestSpanConcurrentLogging is run by the race detector: logging with the shared Observer while Span and End move its
+// current span must not race.
+func TestSpanConcurrentLogging(t *testing.T) {
+	tp := otelSDKTrace.NewTracerProvider(otelSDKTrace.WithSpanProcessor(tracetest.NewSpanRecorder()))
+	cfg := CreateConfig(LevelDebug, "", "", "", []string{}, []string{})
+
+	ctx, o, err := Initialise(context.Background(), cfg, io.Discard, io.Discard)
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		for i := range 100 {
+			o.Info("step", "attempt", i)
+			o.Error("step failed", errors.New("TestSpanConcurrentLogging"), SeverityLow, "attempt", i)
+		}
+	}()
+
+	for range 100 {
+		if _, _, err := Span(ctx, tp.Tracer("test"), "TestSpanConcurrentLogging", SpanKindInternal); err != nil {
+			t.Fatalf("failed to start span: %v", err)
+		}
+		o.End()
+	}
+
+	<-done
+}
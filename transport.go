@@ -7,11 +7,12 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"time"
+	"strconv"
 
 	"github.com/jackc/pgx/v5/pgtype"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/propagation"
+	otelTrace "go.opentelemetry.io/otel/trace"
 )
 
 // RoundTripperFunc type is an adapter to allow the use of ordinary functions as http.RoundTripper
@@ -25,8 +26,34 @@ func (rt RoundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
 	return rt(r)
 }
 
+// outboundObserver returns the request's context and the Observer to log an outbound call with - the Observer in the
+// request's context if there is one, otherwise the Observer the roundtripper was created with.
+// Trace and span IDs of the span in the request's context are added to $args.
+func outboundObserver(ctxWithObserver context.Context, r *http.Request, args []any) (ctx context.Context, observer *Observer, fullArgs []any) {
+	ctx = r.Context()
+
+	_, o, err := Get(ctx)
+	if err != nil {
+		_, o, _ = Get(ctxWithObserver)
+	}
+
+	if sc := otelTrace.SpanContextFromContext(ctx); sc.IsValid() {
+		args = append(args, FieldSpanID, sc.SpanID(), FieldTraceID, sc.TraceID())
+	}
+
+	return ctx, o, args
+}
+
+// contextErrorArgs returns the log args describing why $ctx is done, or nil if it isn't
+func contextErrorArgs(ctx context.Context) []any {
+	if ctx.Err() == nil {
+		return nil
+	}
+
+	return []any{FieldContextError, context.Cause(ctx).Error()}
+}
+
 func logRoundTripper(ctxWithObserver context.Context, next http.RoundTripper) http.RoundTripper {
-	ctx, o, _ := Get(ctxWithObserver)
 	return RoundTripperFunc(func(r *http.Request) (w *http.Response, fault error) {
 		reqBody := []byte{}
 		if r.Body != nil {
@@ -45,17 +72,37 @@ func logRoundTripper(ctxWithObserver context.Context, next http.RoundTripper) ht
 		requestArgs := []any{
 			FieldRequestHeaders, RedactHeaders(r.Header),
 			FieldRequestMethod, r.Method,
-			FieldRequestURL, r.URL.String(),
-			FieldRequestBody, RedactBody(reqBody),
+			FieldRequestURL, RedactURL(r.URL),
+			FieldRequestBody, RedactBodyByContentType(r.Header.Get("Content-Type"), reqBody),
+		}
+
+		ctx, o, requestArgs := outboundObserver(ctxWithObserver, r, requestArgs)
+
+		if deadline, ok := ctx.Deadline(); ok {
+			requestArgs = append(requestArgs, FieldDeadlineRemaining, deadline.Sub(o.clock.Now()))
 		}
 
 		o.log(ctx, 8, LevelInfo, "outbound call - request", requestArgs...)
-		start := time.Now()
+		start := o.clock.Now()
 
 		// Send the actual request
 		resp, err := next.RoundTrip(r)
 		if err != nil {
-			return nil, err
+			failureArgs := []any{
+				FieldRequestMethod, r.Method,
+				FieldRequestURL, RedactURL(r.URL),
+				FieldCallDuration, o.clock.Since(start),
+				"error", err.Error(),
+			}
+
+			if ctxArgs := contextErrorArgs(ctx); ctxArgs != nil {
+				// the caller gave up - cancellation and deadlines are expected, so they're not logged as errors
+				o.log(ctx, 8, LevelWarning, "outbound call - context done", append(failureArgs, ctxArgs...)...)
+			} else {
+				o.error(ctx, 8, LevelError, "outbound call - failed", failureArgs...)
+			}
+
+			return nil, recordOutboundError(r, err)
 		}
 
 		// read the response body, use it to log the response body, then build a new response to return
@@ -68,19 +115,22 @@ func logRoundTripper(ctxWithObserver context.Context, next http.RoundTripper) ht
 
 				respBody, err = io.ReadAll(resp.Body)
 				if err != nil {
+					if ctxArgs := contextErrorArgs(ctx); ctxArgs != nil {
+						o.log(ctx, 8, LevelWarning, "outbound call - context done", append(ctxArgs, FieldCallDuration, o.clock.Since(start))...)
+					}
 					return nil, fmt.Errorf("failed to read response body: %w", err)
 				}
 				// Create a new response with the read body
 				resp.Body = io.NopCloser(bytes.NewBuffer(respBody)) // Use NopCloser to allow reading the body again if needed
 			}
 
-			duration := time.Since(start)
+			duration := o.clock.Since(start)
 
 			responseArgs := []any{
 				FieldCallDuration, duration,
 				FieldStatusCode, resp.StatusCode,
 				FieldResponseHeaders, RedactHeaders(resp.Header),
-				FieldResponseBody, string(respBody),
+				FieldResponseBody, string(RedactBodyByContentType(resp.Header.Get("Content-Type"), respBody)),
 			}
 			o.log(ctx, 8, LevelInfo, "outbound call - response", responseArgs...)
 		}
@@ -90,7 +140,7 @@ func logRoundTripper(ctxWithObserver context.Context, next http.RoundTripper) ht
 
 func dbStoreRoundTripper(ctxWithObserver context.Context, dbStorer DBStorer, next http.RoundTripper) http.RoundTripper {
 	return RoundTripperFunc(func(r *http.Request) (w *http.Response, fault error) {
-		ctx, o, _ := Get(ctxWithObserver)
+		ctx, o, _ := outboundObserver(ctxWithObserver, r, nil)
 		reqBody := []byte{}
 		if r.Body != nil {
 			defer func() {
@@ -105,14 +155,37 @@ func dbStoreRoundTripper(ctxWithObserver context.Context, dbStorer DBStorer, nex
 			r.Body = io.NopCloser(bytes.NewBuffer(reqBody)) // Use NopCloser to allow reading the body again if needed
 
 			// keep the secrets secret
-			reqBody = RedactBody(reqBody)
+			reqBody = RedactBodyByContentType(r.Header.Get("Content-Type"), reqBody)
+		}
+
+		start := o.clock.Now()
+
+		reqHeaders, err := json.Marshal(RedactHeaders(r.Header))
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request headers: %w", err)
 		}
 
-		start := time.Now()
+		errorStorer, canStoreErrors := dbStorer.(DBErrorStorer)
 
 		resp, err := next.RoundTrip(r)
 		if err != nil {
-			return nil, err
+			if canStoreErrors {
+				dbStorer.SetURL(RedactURL(r.URL))
+				dbStorer.SetMethod(r.Method)
+				dbStorer.SetRequestHeaders(reqHeaders)
+				dbStorer.SetRequestBody(pgtype.Text{String: string(reqBody), Valid: true})
+				dbStorer.SetResponseTimeMS(o.clock.Since(start).Milliseconds())
+				dbStorer.SetResponseHeaders([]byte("{}"))
+				dbStorer.SetResponseBody(pgtype.Text{})
+				dbStorer.SetStatusCode(0)
+				errorStorer.SetError(pgtype.Text{String: err.Error(), Valid: true})
+
+				if storeErr := dbStorer.Exec(context.WithoutCancel(ctx)); storeErr != nil {
+					o.Error("failed to store failed request in database", storeErr, SeverityHigh)
+				}
+			}
+
+			return nil, recordOutboundError(r, err)
 		}
 
 		// read the response body, use it to log the response body, then build a new response to return
@@ -132,22 +205,17 @@ func dbStoreRoundTripper(ctxWithObserver context.Context, dbStorer DBStorer, nex
 				resp.Body = io.NopCloser(bytes.NewBuffer(respBody)) // Use NopCloser to allow reading the body again if needed
 
 				// keep the secrets secret
-				respBody = RedactBody(respBody)
+				respBody = RedactBodyByContentType(resp.Header.Get("Content-Type"), respBody)
 			}
 
-			duration := time.Since(start)
-
-			reqHeaders, err := json.Marshal(RedactHeaders(r.Header))
-			if err != nil {
-				return nil, fmt.Errorf("failed to marshal request headers: %w", err)
-			}
+			duration := o.clock.Since(start)
 
 			respHeaders, err := json.Marshal(RedactHeaders(resp.Header))
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal response headers: %w", err)
 			}
 
-			dbStorer.SetURL(r.URL.String())
+			dbStorer.SetURL(RedactURL(r.URL))
 			dbStorer.SetMethod(r.Method)
 			dbStorer.SetRequestHeaders(reqHeaders)
 			dbStorer.SetRequestBody(pgtype.Text{String: string(reqBody), Valid: true})
@@ -155,7 +223,11 @@ func dbStoreRoundTripper(ctxWithObserver context.Context, dbStorer DBStorer, nex
 			dbStorer.SetResponseHeaders(respHeaders)
 			dbStorer.SetResponseBody(pgtype.Text{String: string(respBody), Valid: true})
 			dbStorer.SetStatusCode(int32(resp.StatusCode))
-			err = dbStorer.Exec(ctx)
+			if canStoreErrors {
+				errorStorer.SetError(pgtype.Text{})
+			}
+			// the call has completed, so store it even if the caller's context is cancelled from here on
+			err = dbStorer.Exec(context.WithoutCancel(ctx))
 			if err != nil {
 				o.Error("failed to store request/response in database", err, SeverityHigh)
 				return nil, fmt.Errorf("failed to store request/response in database: %w", err)
@@ -172,13 +244,35 @@ func propagateRoundTripper(next http.RoundTripper) http.RoundTripper {
 
 		otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(r.Header))
 
+		if TraceKept(ctx) {
+			r.Header.Set(KeepTraceHeader, "1")
+		}
+
+		return next.RoundTrip(r)
+	})
+}
+
+func requestIDRoundTripper(next http.RoundTripper) http.RoundTripper {
+	return RoundTripperFunc(func(r *http.Request) (w *http.Response, fault error) {
+		requestID := GetRequestID(r.Context())
+		if requestID != "" && r.Header.Get(RequestIDHeader) == "" {
+			// clone the request rather than modifying the caller's headers, as required of a RoundTripper
+			r = r.Clone(r.Context())
+			r.Header.Set(RequestIDHeader, requestID)
+		}
+
 		return next.RoundTrip(r)
 	})
 }
 
-func metricsRoundTripper(next http.RoundTripper, recorder MetricsRecorder, pathMaskFunc PathMask) http.RoundTripper {
+func metricsRoundTripper(next http.RoundTripper, recorder ContextMetricsRecorder, pathMaskFunc PathMask) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
 	return RoundTripperFunc(func(r *http.Request) (w *http.Response, fault error) {
-		t0 := time.Now()
+		clock := FromContext(r.Context()).clock
+		t0 := clock.Now()
 
 		resp, err := next.RoundTrip(r)
 
@@ -187,10 +281,14 @@ func metricsRoundTripper(next http.RoundTripper, recorder MetricsRecorder, pathM
 			path = pathMaskFunc(path)
 		}
 
-		if resp != nil {
-			recorder(resp.StatusCode, r.Method, path, t0)
+		status := OutboundStatusError
+		if err == nil && resp != nil {
+			status = strconv.Itoa(resp.StatusCode)
 		}
 
+		recorder(r.Context(), status, r.Method, r.URL.Scheme, r.URL.Host, path, t0)
+		recordIntegrationCall(r.Context(), r, status, clock.Since(t0))
+
 		return resp, err
 	})
 }
@@ -207,3 +305,9 @@ type DBStorer interface {
 	SetStatusCode(int32)
 	Exec(ctx context.Context) error
 }
+
+// DBErrorStorer is implemented by DBStorers that can also store calls that failed at the transport level (e.g. DNS
+// failures and timeouts), which have an error but no status code. storer.StoreRequest implements it.
+type DBErrorStorer interface {
+	SetError(pgtype.Text)
+}
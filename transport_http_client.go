@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
@@ -37,6 +38,110 @@ func (c *HTTPClient) AddPropagation(ctxWithObserver context.Context) (fault erro
 	return nil
 }
 
+// AddRequestID wraps a http.Client's transporter so the request ID in the request's context (see
+// SetRequestIDMiddleware) is sent in the RequestIDHeader of outbound requests
+// This allows us to follow a request ID across the whole call chain
+func (c *HTTPClient) AddRequestID(ctxWithObserver context.Context) (fault error) {
+	_, _, err := Get(ctxWithObserver)
+	if err != nil {
+		return fmt.Errorf("could not get go11y observer from context: %w", err)
+	}
+
+	c.Transport = requestIDRoundTripper(c.Transport)
+	return nil
+}
+
+// AddConnectionTimings wraps a http.Client's transporter with net/http/httptrace instrumentation
+// This records DNS, connect, TLS handshake and time-to-first-byte timings per host as span attributes, Debug log fields
+// and metrics, to help diagnose slow third-party calls
+func (c *HTTPClient) AddConnectionTimings(ctxWithObserver context.Context) (fault error) {
+	_, _, err := Get(ctxWithObserver)
+	if err != nil {
+		return fmt.Errorf("could not get go11y observer from context: %w", err)
+	}
+
+	registerOutboundMetrics()
+
+	c.Transport = timingsRoundTripper(ctxWithObserver, c.Transport)
+	return nil
+}
+
+// AddHostMonitoring wraps a http.Client's transporter so the hosts of its https calls are added to $monitor
+// This lets the monitor learn which third-party hosts to check for DNS resolution and TLS certificate expiry
+func (c *HTTPClient) AddHostMonitoring(monitor *HostMonitor) (fault error) {
+	if monitor == nil {
+		return errors.New("monitor cannot be nil")
+	}
+
+	c.Transport = hostMonitorRoundTripper(monitor, c.Transport)
+	return nil
+}
+
+// AddGraphQL wraps a http.Client's transporter so each outbound GraphQL operation gets a client span named after it,
+// e.g. "query GetUser", is logged at Debug level with its variables redacted, and is recorded in the GraphQL metrics
+// by operation rather than the single endpoint path
+func (c *HTTPClient) AddGraphQL(ctxWithObserver context.Context) (fault error) {
+	_, _, err := Get(ctxWithObserver)
+	if err != nil {
+		return fmt.Errorf("could not get go11y observer from context: %w", err)
+	}
+
+	registerGraphQLMetrics()
+
+	c.Transport = graphQLRoundTripper(ctxWithObserver, c.Transport)
+	return nil
+}
+
+// AddSOAP wraps a http.Client's transporter so each outbound SOAP call gets a client span named after its operation,
+// taken from the SOAPAction header or the envelope (see SOAPOperation), responses that are SOAP Faults are logged and
+// fail the span, and the SOAP metrics are recorded by operation. Envelopes logged or stored by the logging and
+// database storage roundtrippers are redacted by RedactXML when their Content-Type is XML
+func (c *HTTPClient) AddSOAP(ctxWithObserver context.Context) (fault error) {
+	_, _, err := Get(ctxWithObserver)
+	if err != nil {
+		return fmt.Errorf("could not get go11y observer from context: %w", err)
+	}
+
+	registerSOAPMetrics()
+	registerOutboundMetrics()
+
+	c.Transport = soapRoundTripper(ctxWithObserver, c.Transport)
+	return nil
+}
+
+// AddTimeouts wraps a http.Client's transporter so outbound calls without a deadline in their context get one,
+// counting them in the OutboundWithoutDeadline metric to catch the callers that forgot. Unlike http.Client.Timeout, the
+// deadline is in the request's context, so add it last: the roundtrippers added before it are covered by the deadline
+// and the logging roundtripper logs how much of it remains (see FieldDeadlineRemaining)
+// $opts is optional - only the first ClientTimeoutOpts provided is used.
+func (c *HTTPClient) AddTimeouts(opts ...ClientTimeoutOpts) (fault error) {
+	tOpts := ClientTimeoutOpts{}
+	if len(opts) > 0 {
+		tOpts = opts[0]
+	}
+
+	if tOpts.RequestTimeout <= 0 {
+		tOpts.RequestTimeout = DefaultRequestTimeout
+	}
+
+	registerOutboundMetrics()
+
+	c.Transport = deadlineRoundTripper(tOpts.RequestTimeout, c.Transport)
+	return nil
+}
+
+// AddReplay wraps a http.Client's transporter so its calls are recorded to, or replayed from, fixture files in $dir
+// This allows teams to build deterministic tests of third-party integrations from real captured traffic. Add it first,
+// so the logging, metrics and storage roundtrippers added after it see the replayed calls like real ones
+func (c *HTTPClient) AddReplay(dir string, mode ReplayMode) (fault error) {
+	if dir == "" {
+		return errors.New("fixture directory cannot be empty")
+	}
+
+	c.Transport = NewReplayTransport(dir, mode, c.Transport)
+	return nil
+}
+
 // AddLogging wraps a http.Client's transporter with logging functionality
 // This allows us to log request and response details for debugging and monitoring purposes
 // Note: Ensure that the logging system is properly initialized before using this client
@@ -46,6 +151,8 @@ func (c *HTTPClient) AddLogging(ctxWithObserver context.Context) (fault error) {
 		return fmt.Errorf("could not get go11y observer from context: %w", err)
 	}
 
+	registerOutboundMetrics()
+
 	c.Transport = logRoundTripper(ctxWithObserver, c.Transport)
 	return nil
 }
@@ -59,22 +166,69 @@ func (c *HTTPClient) AddDBStore(ctxWithObserver context.Context, dbStorer DBStor
 		return fmt.Errorf("could not get go11y observer from context: %w", err)
 	}
 
+	registerOutboundMetrics()
+
 	c.Transport = dbStoreRoundTripper(ctxWithObserver, dbStorer, c.Transport)
 
 	return nil
 }
 
 // MetricsRecorder is a function type for recording metrics.
+//
+// Deprecated: a MetricsRecorder isn't told the host and scheme of calls, or about calls that failed without a
+// response. Use a HostMetricsRecorder with HTTPClient.AddHostMetrics instead.
 type MetricsRecorder func(statusCode int, method, path string, startTime time.Time)
 
+// HostMetricsRecorder is a function type for recording metrics of outbound calls by host and scheme.
+// $status is the response status code, or OutboundStatusError if no response was received
+type HostMetricsRecorder func(status, method, scheme, host, path string, startTime time.Time)
+
+// ContextMetricsRecorder is a HostMetricsRecorder that is also given the request's context, e.g. to record exemplars
+// linking the metrics to the request's trace
+type ContextMetricsRecorder func(ctx context.Context, status, method, scheme, host, path string, startTime time.Time)
+
 // AddMetrics wraps a http.Client's transporter with metrics recording functionality
 // $recorder is the function that actually records the metrics - if it is nil an error is returned
 // This allows us to record metrics for request and response details for monitoring purposes
+// Calls to the integrations registered with RegisterIntegrations are also counted against their SLIs.
+//
+// Deprecated: use AddHostMetrics, whose recorder is also told the host and scheme of calls and about calls that failed
+// without a response.
 func (c *HTTPClient) AddMetrics(recorder MetricsRecorder, pathMaskFunc PathMask) (fault error) {
 	if recorder == nil {
 		return errors.New("recorder cannot be nil")
 	}
 
+	return c.AddContextMetrics(func(_ context.Context, status, method, _, _, path string, startTime time.Time) {
+		// calls without a response weren't recorded before the host and scheme were
+		if statusCode, err := strconv.Atoi(status); err == nil {
+			recorder(statusCode, method, path, startTime)
+		}
+	}, pathMaskFunc)
+}
+
+// AddHostMetrics wraps a http.Client's transporter with metrics recording functionality, like AddMetrics, giving
+// $recorder the host and scheme of each call, and recording calls that failed without a response too.
+// $recorder is the function that actually records the metrics - if it is nil an error is returned. Use
+// PrometheusMetricsRecorder to publish the standard go11y outbound metrics.
+func (c *HTTPClient) AddHostMetrics(recorder HostMetricsRecorder, pathMaskFunc PathMask) (fault error) {
+	if recorder == nil {
+		return errors.New("recorder cannot be nil")
+	}
+
+	return c.AddContextMetrics(func(_ context.Context, status, method, scheme, host, path string, startTime time.Time) {
+		recorder(status, method, scheme, host, path, startTime)
+	}, pathMaskFunc)
+}
+
+// AddContextMetrics wraps a http.Client's transporter with metrics recording functionality, like AddMetrics, giving
+// $recorder the request's context. Use PrometheusContextMetricsRecorder to publish the standard go11y outbound metrics
+// with exemplars of the trace IDs of the calls.
+func (c *HTTPClient) AddContextMetrics(recorder ContextMetricsRecorder, pathMaskFunc PathMask) (fault error) {
+	if recorder == nil {
+		return errors.New("recorder cannot be nil")
+	}
+
 	c.Transport = metricsRoundTripper(c.Transport, recorder, pathMaskFunc)
 
 	return nil
@@ -0,0 +1,201 @@
+package go11y
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// ReplayMode determines whether a replay transport records real calls or replays recorded ones
+type ReplayMode int
+
+const (
+	// ReplayModeRecord sends calls on to the wrapped transport and writes each roundtrip to a fixture file
+	ReplayModeRecord ReplayMode = iota
+	// ReplayModeReplay answers calls from the fixture files without making them - calls with no fixture fail with
+	// ErrNoFixture
+	ReplayModeReplay
+)
+
+// ErrNoFixture is returned in ReplayModeReplay when no fixture has been recorded for a call
+var ErrNoFixture = errors.New("no recorded fixture for request")
+
+// Fixture is a recorded roundtrip, as stored on disk by the replay transport.
+// Headers and bodies are redacted with the same policy as the logging roundtripper before they are written, so
+// captured traffic can be committed alongside the tests that replay it.
+type Fixture struct {
+	Request  FixtureRequest  `json:"request"`
+	Response FixtureResponse `json:"response"`
+}
+
+// FixtureRequest is the request half of a Fixture
+type FixtureRequest struct {
+	Method  string      `json:"method"`
+	URL     string      `json:"url"`
+	Headers http.Header `json:"headers"`
+	Body    FixtureBody `json:"body"`
+}
+
+// FixtureResponse is the response half of a Fixture
+type FixtureResponse struct {
+	StatusCode int         `json:"status_code"`
+	Headers    http.Header `json:"headers"`
+	Body       FixtureBody `json:"body"`
+}
+
+// FixtureBody is a request or response body - text bodies are stored as-is, binary bodies base64 encoded
+type FixtureBody struct {
+	Text   string `json:"text,omitempty"`
+	Base64 string `json:"base64,omitempty"`
+}
+
+func newFixtureBody(b []byte) FixtureBody {
+	if utf8.Valid(b) {
+		return FixtureBody{Text: string(b)}
+	}
+
+	return FixtureBody{Base64: base64.StdEncoding.EncodeToString(b)}
+}
+
+// Bytes returns the decoded body
+func (fb FixtureBody) Bytes() (body []byte, fault error) {
+	if fb.Base64 != "" {
+		return base64.StdEncoding.DecodeString(fb.Base64)
+	}
+
+	return []byte(fb.Text), nil
+}
+
+// NewReplayTransport returns a RoundTripper that records roundtrips to fixture files in $dir or replays them, according
+// to $mode. $next is the transport used to make real calls when recording - http.DefaultTransport if nil.
+// Fixtures are matched on the method, URL and request body, so a test replays exactly the calls it recorded.
+func NewReplayTransport(dir string, mode ReplayMode, next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return RoundTripperFunc(func(r *http.Request) (w *http.Response, fault error) {
+		reqBody := []byte{}
+		if r.Body != nil {
+			var err error
+			reqBody, err = io.ReadAll(r.Body)
+			_ = r.Body.Close()
+			if err != nil {
+				return nil, fmt.Errorf("failed to read request body: %w", err)
+			}
+			r.Body = io.NopCloser(bytes.NewReader(reqBody))
+		}
+
+		path := filepath.Join(dir, fixtureName(r, reqBody))
+
+		if mode == ReplayModeReplay {
+			return replayFixture(r, path)
+		}
+
+		resp, err := next.RoundTrip(r)
+		if err != nil {
+			return nil, err
+		}
+
+		respBody, err := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response body: %w", err)
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+		fixture := Fixture{
+			Request: FixtureRequest{
+				Method:  r.Method,
+				URL:     RedactURL(r.URL),
+				Headers: RedactHeaders(r.Header),
+				Body:    newFixtureBody(RedactBodyByContentType(r.Header.Get("Content-Type"), reqBody)),
+			},
+			Response: FixtureResponse{
+				StatusCode: resp.StatusCode,
+				Headers:    RedactHeaders(resp.Header),
+				Body:       newFixtureBody(RedactBodyByContentType(resp.Header.Get("Content-Type"), respBody)),
+			},
+		}
+
+		if err := writeFixture(path, fixture); err != nil {
+			return nil, err
+		}
+
+		return resp, nil
+	})
+}
+
+// fixtureName derives the fixture file name from the parts of the request that identify it. The URL and body are
+// hashed rather than used directly so secrets in them never end up in file names.
+func fixtureName(r *http.Request, body []byte) string {
+	h := sha256.New()
+	_, _ = io.WriteString(h, r.Method+" "+r.URL.String()+"\n")
+	_, _ = h.Write(body)
+
+	return fmt.Sprintf("%s_%s.json", strings.ToLower(r.Method), hex.EncodeToString(h.Sum(nil))[:16])
+}
+
+func writeFixture(path string, fixture Fixture) error {
+	b, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not marshal fixture: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("could not create fixture directory: %w", err)
+	}
+
+	if err := os.WriteFile(path, append(b, '\n'), 0o644); err != nil {
+		return fmt.Errorf("could not write fixture: %w", err)
+	}
+
+	return nil
+}
+
+func replayFixture(r *http.Request, path string) (resp *http.Response, fault error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s %s", ErrNoFixture, r.Method, RedactURL(r.URL))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read fixture: %w", err)
+	}
+
+	fixture := Fixture{}
+	if err := json.Unmarshal(b, &fixture); err != nil {
+		return nil, fmt.Errorf("could not unmarshal fixture %s: %w", path, err)
+	}
+
+	body, err := fixture.Response.Body.Bytes()
+	if err != nil {
+		return nil, fmt.Errorf("could not decode fixture body %s: %w", path, err)
+	}
+
+	header := fixture.Response.Headers
+	if header == nil {
+		header = http.Header{}
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", fixture.Response.StatusCode, http.StatusText(fixture.Response.StatusCode)),
+		StatusCode:    fixture.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       r,
+	}, nil
+}
@@ -0,0 +1,76 @@
+package go11y_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/cirruscomms/go11y"
+)
+
+func TestReplayTransport(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=supersecretsession")
+		_, _ = w.Write([]byte(`{"name":"widget"}`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+
+	recorder := &go11y.HTTPClient{Client: &http.Client{}}
+	if err := recorder.AddReplay(dir, go11y.ReplayModeRecord); err != nil {
+		t.Fatalf("failed to add replay to HTTP client: %v", err)
+	}
+
+	resp, err := recorder.Post(server.URL+"/widgets?api_key=supersecretkey", "application/json", strings.NewReader(`{"id":1}`))
+	if err != nil {
+		t.Fatalf("failed to record request: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	files, _ := os.ReadDir(dir)
+	if len(files) != 1 {
+		t.Fatalf("expected 1 fixture, got %d", len(files))
+	}
+
+	fixture, _ := os.ReadFile(dir + "/" + files[0].Name())
+	for _, secret := range []string{"supersecretkey", "supersecretsession"} {
+		if strings.Contains(string(fixture), secret) {
+			t.Errorf("expected %s to be redacted from the fixture: %s", secret, fixture)
+		}
+	}
+
+	server.Close()
+
+	replayer := &go11y.HTTPClient{Client: &http.Client{}}
+	if err := replayer.AddReplay(dir, go11y.ReplayModeReplay); err != nil {
+		t.Fatalf("failed to add replay to HTTP client: %v", err)
+	}
+
+	resp, err = replayer.Post(server.URL+"/widgets?api_key=supersecretkey", "application/json", strings.NewReader(`{"id":1}`))
+	if err != nil {
+		t.Fatalf("failed to replay request: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || string(body) != `{"name":"widget"}` {
+		t.Errorf("expected the recorded response, got %d %s", resp.StatusCode, body)
+	}
+
+	if calls != 1 {
+		t.Errorf("expected the server to be called once, got %d", calls)
+	}
+
+	_, err = replayer.Post(server.URL+"/widgets", "application/json", strings.NewReader(`{"id":2}`))
+	if !errors.Is(err, go11y.ErrNoFixture) {
+		t.Errorf("expected ErrNoFixture for an unrecorded request, got %v", err)
+	}
+}
@@ -38,6 +38,34 @@ func (r *ReverseProxy) AddPropagation(ctxWithObserver context.Context) (fault er
 	return nil
 }
 
+// AddRequestID wraps a httputil.ReverseProxy's transporter so the request ID in the request's context (see
+// SetRequestIDMiddleware) is sent in the RequestIDHeader of proxied requests
+// This allows us to follow a request ID across the whole call chain
+func (r *ReverseProxy) AddRequestID(ctxWithObserver context.Context) (fault error) {
+	_, _, err := Get(ctxWithObserver)
+	if err != nil {
+		return fmt.Errorf("could not get go11y observer from context: %w", err)
+	}
+
+	r.Transport = requestIDRoundTripper(r.Transport)
+	return nil
+}
+
+// AddConnectionTimings wraps a httputil.ReverseProxy's transporter with net/http/httptrace instrumentation
+// This records DNS, connect, TLS handshake and time-to-first-byte timings per host as span attributes, Debug log fields
+// and metrics, to help diagnose slow third-party calls
+func (r *ReverseProxy) AddConnectionTimings(ctxWithObserver context.Context) (fault error) {
+	_, _, err := Get(ctxWithObserver)
+	if err != nil {
+		return fmt.Errorf("could not get go11y observer from context: %w", err)
+	}
+
+	registerOutboundMetrics()
+
+	r.Transport = timingsRoundTripper(ctxWithObserver, r.Transport)
+	return nil
+}
+
 // AddLogging wraps a httputil.ReverseProxy's transporter with logging functionality
 // This allows us to log request and response details for debugging and monitoring purposes
 // Note: Ensure that the logging system is properly initialized before using this client
@@ -47,6 +75,8 @@ func (r *ReverseProxy) AddLogging(ctxWithObserver context.Context) (fault error)
 		return fmt.Errorf("could not get go11y observer from context: %w", err)
 	}
 
+	registerOutboundMetrics()
+
 	r.Transport = logRoundTripper(ctxWithObserver, r.Transport)
 
 	return nil
@@ -61,6 +91,8 @@ func (r *ReverseProxy) AddDBStore(ctxWithObserver context.Context, dbStorer DBSt
 		return fmt.Errorf("could not get go11y observer from context: %w", err)
 	}
 
+	registerOutboundMetrics()
+
 	r.Transport = dbStoreRoundTripper(ctxWithObserver, dbStorer, r.Transport)
 	return nil
 }
@@ -3,18 +3,27 @@ package go11y_test
 import (
 	"bytes"
 	"context"
+	"fmt"
+	"io"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/cirruscomms/go11y"
+	"github.com/cirruscomms/go11y/go11ytest"
 	"github.com/cirruscomms/go11y/storer"
 	testingContainers "github.com/cirruscomms/go11y/tests/containers"
 	"github.com/cirruscomms/go11y/tests/db"
 	"github.com/cirruscomms/go11y/tests/etc/migrations"
 
+	"github.com/jackc/pgx/v5/pgtype"
 	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/testcontainers/testcontainers-go"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func TestLoggingTransport(t *testing.T) {
@@ -87,7 +96,7 @@ func TestStoringTransport(t *testing.T) {
 	t.Setenv("LOG_LEVEL", "develop")
 
 	ctx := context.Background()
-	ctr, err := testingContainers.Postgres(t, ctx, "17")
+	ctr, err := testingContainers.Postgres(t, ctx, testingContainers.PostgresOpts{Version: "17"})
 	if err != nil {
 		t.Fatalf("failed to start Postgres container: %v", err)
 	}
@@ -129,12 +138,17 @@ func TestStoringTransport(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed to create migrator: %v", err)
 	}
+	defer migrator.Close()
 
 	err = migrator.Migrate()
 	if err != nil {
 		t.Fatalf("failed to run migrations: %v", err)
 	}
 
+	if err := migrator.Verify(); err != nil {
+		t.Fatalf("expected the migrations to verify: %v", err)
+	}
+
 	dbStorer, err := storer.New(ctx, ctr.DatabaseURL())
 	if err != nil {
 		t.Fatalf("failed to create DB storer: %v", err)
@@ -169,7 +183,7 @@ func TestPropagatingTransport(t *testing.T) {
 	t.Setenv("LOG_LEVEL", "develop")
 
 	ctx := context.Background()
-	ctr, err := testingContainers.LGTM(t, ctx)
+	ctr, err := testingContainers.LGTM(t, ctx, testingContainers.LGTMOpts{})
 	if err != nil {
 		t.Fatalf("failed to start Grafana LGTM container: %v", err)
 	}
@@ -181,14 +195,7 @@ func TestPropagatingTransport(t *testing.T) {
 		}
 	}()
 
-	time.Sleep(60 * time.Second)
-
-	cfg, err := go11y.LoadConfig()
-	if err != nil {
-		t.Fatalf("failed to load config: %v", err)
-	}
-
-	ctx, o, err := go11y.Initialise(ctx, cfg, nil, nil)
+	ctx, o, err := go11y.Initialise(ctx, ctr.Config, nil, nil)
 	if err != nil {
 		t.Fatalf("failed to initialise observer: %v", err)
 	}
@@ -221,3 +228,379 @@ func TestPropagatingTransport(t *testing.T) {
 		_ = resp.Body.Close()
 	}()
 }
+
+func TestOutboundLoggingContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			<-r.Context().Done()
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	wrapOut := new(bytes.Buffer)
+	cfg := go11y.CreateConfig(go11y.LevelInfo, "", "", "", []string{}, []string{})
+	wrapCtx, _, err := go11y.Initialise(context.Background(), cfg, wrapOut, wrapOut)
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	client := &go11y.HTTPClient{Client: &http.Client{Transport: http.DefaultTransport}}
+	if err := client.AddLogging(wrapCtx); err != nil {
+		t.Fatalf("failed to add logging to HTTP client: %v", err)
+	}
+
+	t.Run("request observer is used", func(t *testing.T) {
+		reqOut := new(bytes.Buffer)
+		reqCtx, _, err := go11y.Initialise(context.Background(), cfg, reqOut, reqOut, go11y.FieldRequestID, "from-request")
+		if err != nil {
+			t.Fatalf("failed to initialise observer: %v", err)
+		}
+
+		req, _ := http.NewRequestWithContext(reqCtx, http.MethodGet, server.URL+"/fast", nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("failed to execute request: %v", err)
+		}
+		_ = resp.Body.Close()
+
+		if !strings.Contains(reqOut.String(), `"request_id":"from-request"`) {
+			t.Errorf("expected the request context's observer to log the call, got %s", reqOut.String())
+		}
+	})
+
+	t.Run("deadline is logged", func(t *testing.T) {
+		reqCtx, cancel := context.WithTimeout(wrapCtx, 50*time.Millisecond)
+		defer cancel()
+
+		req, _ := http.NewRequestWithContext(reqCtx, http.MethodGet, server.URL+"/slow", nil)
+		if _, err := client.Do(req); err == nil {
+			t.Fatalf("expected the request to time out")
+		}
+
+		if !strings.Contains(wrapOut.String(), `"context_error":"context deadline exceeded"`) {
+			t.Errorf("expected the deadline to be logged, got %s", wrapOut.String())
+		}
+	})
+}
+
+type fakeStorer struct {
+	url        string
+	statusCode int32
+	failure    pgtype.Text
+	stored     int
+}
+
+func (f *fakeStorer) SetURL(input string)                { f.url = input }
+func (f *fakeStorer) SetMethod(string)                   {}
+func (f *fakeStorer) SetRequestHeaders([]byte)           {}
+func (f *fakeStorer) SetRequestBody(pgtype.Text)         {}
+func (f *fakeStorer) SetResponseTimeMS(int64)            {}
+func (f *fakeStorer) SetResponseHeaders([]byte)          {}
+func (f *fakeStorer) SetResponseBody(pgtype.Text)        {}
+func (f *fakeStorer) SetStatusCode(input int32)          { f.statusCode = input }
+func (f *fakeStorer) SetError(input pgtype.Text)         { f.failure = input }
+func (f *fakeStorer) Exec(ctx context.Context) (e error) { f.stored++; return nil }
+
+func TestTransportErrors(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close() // nothing is listening any more, so calls fail to connect
+
+	out := new(bytes.Buffer)
+	cfg := go11y.CreateConfig(go11y.LevelInfo, "", "", "", []string{}, []string{})
+	ctx, _, err := go11y.Initialise(context.Background(), cfg, out, out)
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	fs := &fakeStorer{}
+	client := &go11y.HTTPClient{Client: &http.Client{Transport: http.DefaultTransport}}
+	if err := client.AddDBStore(ctx, fs); err != nil {
+		t.Fatalf("failed to add db store to HTTP client: %v", err)
+	}
+	if err := client.AddLogging(ctx); err != nil {
+		t.Fatalf("failed to add logging to HTTP client: %v", err)
+	}
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	before := testutil.ToFloat64(go11y.OutboundErrors.WithLabelValues(host, http.MethodGet, go11y.OutboundErrorConnection))
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/gone", nil)
+	if _, err := client.Do(req); err == nil {
+		t.Fatalf("expected the request to fail")
+	}
+
+	if !strings.Contains(out.String(), `"msg":"outbound call - failed"`) {
+		t.Errorf("expected the failure to be logged, got %s", out.String())
+	}
+
+	if fs.stored != 1 || !fs.failure.Valid || fs.statusCode != 0 {
+		t.Errorf("expected the failure to be stored with an error and no status, got %+v", fs)
+	}
+
+	// the logging and db roundtrippers both see the error, but it must only be counted once
+	after := testutil.ToFloat64(go11y.OutboundErrors.WithLabelValues(host, http.MethodGet, go11y.OutboundErrorConnection))
+	if after-before != 1 {
+		t.Errorf("expected 1 outbound connection error, got %v", after-before)
+	}
+}
+
+func TestConnectionTimings(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	out := new(bytes.Buffer)
+	cfg := go11y.CreateConfig(go11y.LevelDebug, "", "", "", []string{}, []string{})
+	ctx, _, err := go11y.Initialise(context.Background(), cfg, out, out)
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	client := &go11y.HTTPClient{Client: &http.Client{Transport: &http.Transport{}}}
+	if err := client.AddConnectionTimings(ctx); err != nil {
+		t.Fatalf("failed to add connection timings to HTTP client: %v", err)
+	}
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("failed to execute request: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	for _, want := range []string{`"msg":"outbound call - timings"`, `"connect_ms":`, `"ttfb_ms":`, `"connection_reused":false`} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected logs to contain %s, got %s", want, out.String())
+		}
+	}
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	if n := testutil.CollectAndCount(go11y.OutboundPhaseDuration.MustCurryWith(map[string]string{"host": host})); n != 2 {
+		t.Errorf("expected connect and ttfb timings for %s, got %d series", host, n)
+	}
+}
+
+func TestOutboundMetrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	client := &go11y.HTTPClient{Client: &http.Client{}}
+	if err := client.AddHostMetrics(go11y.PrometheusMetricsRecorder(), nil); err != nil {
+		t.Fatalf("failed to add metrics to HTTP client: %v", err)
+	}
+
+	resp, err := client.Get(server.URL + "/metrics-test")
+	if err != nil {
+		t.Fatalf("failed to execute request: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	if _, err := client.Get(closed.URL + "/metrics-test"); err == nil {
+		t.Fatalf("expected the request to a closed server to fail")
+	}
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	if v := testutil.ToFloat64(go11y.OutboundRequests.WithLabelValues(host, "http", http.MethodGet, "/metrics-test", "202")); v != 1 {
+		t.Errorf("expected 1 successful outbound request, got %v", v)
+	}
+
+	closedHost := strings.TrimPrefix(closed.URL, "http://")
+	if v := testutil.ToFloat64(go11y.OutboundRequests.WithLabelValues(closedHost, "http", http.MethodGet, "/metrics-test", go11y.OutboundStatusError)); v != 1 {
+		t.Errorf("expected 1 failed outbound request, got %v", v)
+	}
+}
+
+func TestOutboundClock(t *testing.T) {
+	clock := go11ytest.NewClock(time.Now())
+
+	out := new(bytes.Buffer)
+	cfg := go11y.CreateConfig(go11y.LevelInfo, "", "", "", []string{}, []string{})
+	ctx, _, err := go11y.Initialise(context.Background(), cfg, out, out, go11y.WithClock(clock))
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	client := &go11y.HTTPClient{Client: &http.Client{
+		Transport: go11y.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			clock.Advance(2 * time.Second)
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: r}, nil
+		}),
+	}}
+	if err := client.AddLogging(ctx); err != nil {
+		t.Fatalf("failed to add logging to HTTP client: %v", err)
+	}
+	if err := client.AddContextMetrics(go11y.PrometheusContextMetricsRecorder(), nil); err != nil {
+		t.Fatalf("failed to add metrics to HTTP client: %v", err)
+	}
+
+	reqCtx, cancel := context.WithDeadline(ctx, clock.Now().Add(5*time.Second))
+	defer cancel()
+
+	req, _ := http.NewRequestWithContext(reqCtx, http.MethodGet, "http://clock.test/things", nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("failed to execute request: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	if want := fmt.Sprintf(`"%s":%d`, go11y.FieldDeadlineRemaining, 5*time.Second); !strings.Contains(out.String(), want) {
+		t.Errorf("expected the remaining deadline to be measured with the observer's clock (%s), got %s", want, out.String())
+	}
+
+	metrics := go11ytest.GatherMetrics(t, prometheus.DefaultGatherer)
+	sum, found := metrics.HistogramSum("go11y_outbound_request_duration_seconds", go11ytest.Labels{"host": "clock.test"})
+	if !found || sum != 2 {
+		t.Errorf("expected the call to take 2s on the observer's clock, got %v (found: %v)", sum, found)
+	}
+}
+
+func TestDeprecatedMetricsRecorder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	recorded := []string{}
+	recorder := go11y.MetricsRecorder(func(statusCode int, method, path string, startTime time.Time) {
+		recorded = append(recorded, fmt.Sprintf("%d %s %s", statusCode, method, path))
+	})
+
+	client := &go11y.HTTPClient{Client: &http.Client{}}
+	if err := client.AddMetrics(recorder, nil); err != nil { //nolint:staticcheck // the deprecated API must keep working
+		t.Fatalf("failed to add metrics to HTTP client: %v", err)
+	}
+
+	resp, err := client.Get(server.URL + "/legacy")
+	if err != nil {
+		t.Fatalf("failed to execute request: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	if _, err := client.Get(closed.URL + "/legacy"); err == nil {
+		t.Fatalf("expected the request to a closed server to fail")
+	}
+
+	if len(recorded) != 1 || recorded[0] != "202 GET /legacy" {
+		t.Errorf("expected only the call with a response to be recorded, got %v", recorded)
+	}
+}
+
+func TestClientTimeouts(t *testing.T) {
+	buf := new(bytes.Buffer)
+	ctx, _, err := go11y.InitialiseTestLogger(context.Background(), go11y.LevelInfo, buf, buf)
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	deadlines := []time.Duration{}
+	client := &go11y.HTTPClient{Client: &http.Client{
+		Transport: go11y.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			if deadline, ok := r.Context().Deadline(); ok {
+				deadlines = append(deadlines, time.Until(deadline).Round(time.Second))
+			}
+
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: r}, nil
+		}),
+	}}
+
+	if err := client.AddLogging(ctx); err != nil {
+		t.Fatalf("failed to add logging to HTTP client: %v", err)
+	}
+	if err := client.AddTimeouts(go11y.ClientTimeoutOpts{RequestTimeout: 5 * time.Second}); err != nil {
+		t.Fatalf("failed to add timeouts to HTTP client: %v", err)
+	}
+
+	before := testutil.ToFloat64(go11y.OutboundWithoutDeadline.WithLabelValues("timeouts.test", http.MethodGet))
+
+	for _, timeout := range []time.Duration{0, time.Minute} {
+		reqCtx, cancel := context.WithCancel(ctx)
+		if timeout != 0 {
+			reqCtx, cancel = context.WithTimeout(ctx, timeout)
+		}
+
+		req, _ := http.NewRequestWithContext(reqCtx, http.MethodGet, "http://timeouts.test/", nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("failed to execute request: %v", err)
+		}
+		_ = resp.Body.Close()
+		cancel()
+	}
+
+	if len(deadlines) != 2 || deadlines[0] != 5*time.Second || deadlines[1] != time.Minute {
+		t.Errorf("expected the default deadline to be given only to the call without one, got %v", deadlines)
+	}
+
+	if after := testutil.ToFloat64(go11y.OutboundWithoutDeadline.WithLabelValues("timeouts.test", http.MethodGet)); after != before+1 {
+		t.Errorf("expected 1 call without a deadline to be counted, got %v", after-before)
+	}
+
+	if strings.Count(buf.String(), `"`+go11y.FieldDeadlineRemaining+`"`) != 2 {
+		t.Errorf("expected the remaining deadline to be logged for both calls, got %s", buf.String())
+	}
+}
+
+func TestOutboundExemplars(t *testing.T) {
+	ctx, o, _, err := go11y.InitialiseTestTracerInMemory(context.Background(), go11y.LevelInfo, io.Discard, io.Discard)
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	client := &go11y.HTTPClient{Client: &http.Client{
+		Transport: go11y.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: r}, nil
+		}),
+	}}
+
+	if err := client.AddContextMetrics(go11y.PrometheusContextMetricsRecorder(), nil); err != nil {
+		t.Fatalf("failed to add metrics to HTTP client: %v", err)
+	}
+
+	ctx, _, end, err := go11y.StartSpan(ctx, o.Tracer("test"), "call", go11y.SpanKindInternal)
+	if err != nil {
+		t.Fatalf("failed to start span: %v", err)
+	}
+	defer end()
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://exemplars.test/things", nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("failed to execute request: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatalf("failed to gather metrics: %v", err)
+	}
+
+	traceID := trace.SpanContextFromContext(ctx).TraceID().String()
+	for _, f := range families {
+		if f.GetName() != "go11y_outbound_request_duration_seconds" {
+			continue
+		}
+
+		for _, m := range f.GetMetric() {
+			for _, b := range m.GetHistogram().GetBucket() {
+				for _, l := range b.GetExemplar().GetLabel() {
+					if l.GetName() == go11y.ExemplarTraceID && l.GetValue() == traceID {
+						return
+					}
+				}
+			}
+		}
+	}
+
+	t.Errorf("expected an exemplar of trace %s on the outbound request times", traceID)
+}
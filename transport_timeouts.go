@@ -0,0 +1,52 @@
+package go11y
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+// DefaultRequestTimeout is the deadline given by AddTimeouts to outbound calls whose context has none
+const DefaultRequestTimeout = 30 * time.Second
+
+// ClientTimeoutOpts are the options used by HTTPClient.AddTimeouts
+type ClientTimeoutOpts struct {
+	RequestTimeout time.Duration // optional - deadline of calls whose context has none, defaults to DefaultRequestTimeout
+}
+
+// deadlineBody cancels the deadline of an outbound call when its response body is closed
+type deadlineBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b deadlineBody) Close() error {
+	defer b.cancel()
+
+	return b.ReadCloser.Close()
+}
+
+// deadlineRoundTripper gives outbound calls whose context has no deadline one of $timeout, counting them in
+// OutboundWithoutDeadline. The deadline lasts until the response body is closed, so reading it is covered too.
+func deadlineRoundTripper(timeout time.Duration, next http.RoundTripper) http.RoundTripper {
+	return RoundTripperFunc(func(r *http.Request) (w *http.Response, fault error) {
+		if _, ok := r.Context().Deadline(); ok {
+			return next.RoundTrip(r)
+		}
+
+		OutboundWithoutDeadline.WithLabelValues(r.URL.Host, r.Method).Inc()
+
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+
+		resp, err := next.RoundTrip(r.WithContext(ctx))
+		if err != nil || resp.Body == nil {
+			cancel()
+			return resp, err
+		}
+
+		resp.Body = deadlineBody{ReadCloser: resp.Body, cancel: cancel}
+
+		return resp, nil
+	})
+}
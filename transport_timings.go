@@ -0,0 +1,134 @@
+package go11y
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	otelAttribute "go.opentelemetry.io/otel/attribute"
+	otelTrace "go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// PhaseDNS is the phase label for resolving the host of an outbound call
+	PhaseDNS = "dns"
+	// PhaseConnect is the phase label for opening the TCP connection of an outbound call
+	PhaseConnect = "connect"
+	// PhaseTLS is the phase label for the TLS handshake of an outbound call
+	PhaseTLS = "tls"
+	// PhaseTTFB is the phase label for the time from sending an outbound call until the first response byte arrives
+	PhaseTTFB = "ttfb"
+)
+
+// connectionTimings collects the httptrace callbacks of a single outbound call
+type connectionTimings struct {
+	mu           sync.Mutex
+	start        time.Time
+	dnsStart     time.Time
+	connectStart time.Time
+	tlsStart     time.Time
+	phases       map[string]time.Duration
+	reused       bool
+}
+
+func (ct *connectionTimings) done(phase string, since time.Time) {
+	if since.IsZero() {
+		return
+	}
+
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+
+	ct.phases[phase] = time.Since(since)
+}
+
+func (ct *connectionTimings) clientTrace() *httptrace.ClientTrace {
+	set := func(t *time.Time) {
+		ct.mu.Lock()
+		defer ct.mu.Unlock()
+
+		*t = time.Now()
+	}
+
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) { set(&ct.dnsStart) },
+		DNSDone:  func(httptrace.DNSDoneInfo) { ct.done(PhaseDNS, ct.get(&ct.dnsStart)) },
+		// with happy eyeballs several connections may be attempted - the first to start and the last to finish are used
+		ConnectStart: func(string, string) {
+			ct.mu.Lock()
+			defer ct.mu.Unlock()
+
+			if ct.connectStart.IsZero() {
+				ct.connectStart = time.Now()
+			}
+		},
+		ConnectDone:       func(string, string, error) { ct.done(PhaseConnect, ct.get(&ct.connectStart)) },
+		TLSHandshakeStart: func() { set(&ct.tlsStart) },
+		TLSHandshakeDone:  func(tls.ConnectionState, error) { ct.done(PhaseTLS, ct.get(&ct.tlsStart)) },
+		GotConn: func(info httptrace.GotConnInfo) {
+			ct.mu.Lock()
+			defer ct.mu.Unlock()
+
+			ct.reused = info.Reused
+		},
+		GotFirstResponseByte: func() { ct.done(PhaseTTFB, ct.start) },
+	}
+}
+
+func (ct *connectionTimings) get(t *time.Time) time.Time {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+
+	return *t
+}
+
+// timingsRoundTripper records DNS, connect, TLS handshake and time-to-first-byte timings of outbound calls as span
+// attributes on the span in the request's context, Debug log fields and OutboundPhaseDuration metrics.
+func timingsRoundTripper(ctxWithObserver context.Context, next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return RoundTripperFunc(func(r *http.Request) (w *http.Response, fault error) {
+		ct := &connectionTimings{start: time.Now(), phases: map[string]time.Duration{}}
+
+		r = r.WithContext(httptrace.WithClientTrace(r.Context(), ct.clientTrace()))
+
+		resp, err := next.RoundTrip(r)
+
+		ct.mu.Lock()
+		phases := ct.phases
+		reused := ct.reused
+		ct.mu.Unlock()
+
+		ctx, o, args := outboundObserver(ctxWithObserver, r, []any{
+			FieldRequestMethod, r.Method,
+			FieldRequestURL, RedactURL(r.URL),
+			"connection_reused", reused,
+		})
+
+		attrs := []otelAttribute.KeyValue{otelAttribute.Bool("http.connection.reused", reused)}
+
+		for _, phase := range []string{PhaseDNS, PhaseConnect, PhaseTLS, PhaseTTFB} {
+			d, ok := phases[phase]
+			if !ok {
+				continue
+			}
+
+			OutboundPhaseDuration.WithLabelValues(r.URL.Host, phase).Observe(d.Seconds())
+			args = append(args, phase+"_ms", float64(d.Microseconds())/1000)
+			attrs = append(attrs, otelAttribute.Float64("http.timing."+phase+"_ms", float64(d.Microseconds())/1000))
+		}
+
+		otelTrace.SpanFromContext(ctx).SetAttributes(attrs...)
+
+		if o != nil {
+			o.log(ctx, 8, LevelDebug, "outbound call - timings", args...)
+		}
+
+		return resp, err
+	})
+}
@@ -0,0 +1,84 @@
+package go11y
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DefaultRestartRecordTimeout is the time Initialise waits for a RestartRecorder to record the start of the process
+const DefaultRestartRecordTimeout = 5 * time.Second
+
+// processStart approximates the time the process started: when the go11y package was initialised
+var processStart = time.Now()
+
+// ProcessStartTime is the metric for the time the process started, in seconds since the Unix epoch
+var ProcessStartTime = prometheus.NewGauge(prometheus.GaugeOpts{
+	Name: "go11y_process_start_time_seconds",
+	Help: "Time the process started, in seconds since the Unix epoch",
+})
+
+// Uptime is the metric for the time since the process started, computed when scraped
+var Uptime = prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+	Name: "go11y_uptime_seconds",
+	Help: "Time since the process started",
+}, func() float64 {
+	return time.Since(processStart).Seconds()
+})
+
+// Restarts is the metric for the number of times the service has restarted, by version, as recorded by the
+// RestartRecorder passed to WithRestartTracking. It is a gauge set to the persisted total rather than a counter, as it
+// survives restarts: crash loops show up as a climbing value, which rate() would read as a series of counter resets.
+var Restarts = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "go11y_restarts",
+	Help: "Number of times the service has restarted, by version",
+}, []string{"version"})
+
+var registerUptimeMetricsOnce sync.Once
+
+func registerUptimeMetrics() {
+	registerUptimeMetricsOnce.Do(func() {
+		registerCollectors(ProcessStartTime, Uptime, Restarts)
+		ProcessStartTime.Set(float64(processStart.UnixNano()) / 1e9)
+	})
+}
+
+// RestartRecorder persists the starts of a service, so restarts can be counted across processes.
+// storer.StoreRequest implements it.
+type RestartRecorder interface {
+	// RecordStart records a start of version $version of service $service, returning how many times that version has
+	// started, including this one
+	RecordStart(ctx context.Context, service, version string) (starts int64, fault error)
+}
+
+// WithRestartTracking makes Initialise record the start of the process with $recorder, e.g. a storer.StoreRequest,
+// setting the Restarts metric to the number of previous starts of the service's version and logging it. A failure to
+// record the start is logged as a warning and doesn't fail Initialise.
+func WithRestartTracking(recorder RestartRecorder) Option {
+	return func(o *Observer) {
+		o.restartRecorder = recorder
+	}
+}
+
+// recordRestart records the start of the process with the Observer's RestartRecorder, if it has one
+func (o *Observer) recordRestart(ctx context.Context, info BuildInfo) {
+	if o.restartRecorder == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, DefaultRestartRecordTimeout)
+	defer cancel()
+
+	starts, err := o.restartRecorder.RecordStart(ctx, o.cfg.ServiceName(), info.Version)
+	if err != nil {
+		o.Warning("could not record the start of the process", "error", err.Error())
+		return
+	}
+
+	restarts := max(starts-1, 0)
+	Restarts.WithLabelValues(info.Version).Set(float64(restarts))
+
+	o.Debug("process start recorded", FieldServiceVersion, info.Version, "restarts", restarts)
+}
@@ -0,0 +1,81 @@
+package go11y_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/cirruscomms/go11y"
+	"github.com/cirruscomms/go11y/go11ytest"
+)
+
+// restartRecorderFunc adapts a function to a go11y.RestartRecorder
+type restartRecorderFunc func(ctx context.Context, service, version string) (int64, error)
+
+func (f restartRecorderFunc) RecordStart(ctx context.Context, service, version string) (int64, error) {
+	return f(ctx, service, version)
+}
+
+func TestRestartTracking(t *testing.T) {
+	buf := new(bytes.Buffer)
+	cfg := go11y.NewConfig(
+		go11y.WithLogLevel(go11y.LevelDebug),
+		go11y.WithServiceName("billing"),
+		go11y.WithServiceVersion("v2.0.1"),
+	)
+
+	recorder := restartRecorderFunc(func(_ context.Context, service, version string) (int64, error) {
+		if service != "billing" || version != "v2.0.1" {
+			t.Errorf("expected the start of billing v2.0.1 to be recorded, got %s %s", service, version)
+		}
+
+		return 4, nil
+	})
+
+	_, o, err := go11y.Initialise(context.Background(), cfg, buf, buf, go11y.WithRestartTracking(recorder))
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+	defer o.Close()
+
+	// the persisted total is reported as it is, not added up each time an Observer is initialised
+	_, again, err := go11y.Initialise(context.Background(), cfg, buf, buf, go11y.WithRestartTracking(recorder))
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+	defer again.Close()
+
+	metrics := go11ytest.GatherMetrics(t, prometheus.DefaultGatherer)
+	metrics.AssertValue(t, "go11y_restarts", go11ytest.Labels{"version": "v2.0.1"}, 3)
+
+	if uptime, found := metrics.Value("go11y_uptime_seconds", nil); !found || uptime <= 0 {
+		t.Errorf("expected a positive uptime, got %v", uptime)
+	}
+
+	if start, found := metrics.Value("go11y_process_start_time_seconds", nil); !found || start <= 0 {
+		t.Errorf("expected the process start time, got %v", start)
+	}
+}
+
+func TestRestartTrackingFailure(t *testing.T) {
+	buf := new(bytes.Buffer)
+	cfg := go11y.NewConfig(go11y.WithLogLevel(go11y.LevelInfo), go11y.WithServiceName("billing"))
+
+	recorder := restartRecorderFunc(func(context.Context, string, string) (int64, error) {
+		return 0, errors.New("relation \"service_starts\" does not exist")
+	})
+
+	_, o, err := go11y.Initialise(context.Background(), cfg, buf, buf, go11y.WithRestartTracking(recorder))
+	if err != nil {
+		t.Fatalf("expected a failure to record the start not to fail Initialise, got %v", err)
+	}
+	defer o.Close()
+
+	if !strings.Contains(buf.String(), "could not record the start of the process") {
+		t.Errorf("expected the failure to be logged, got %s", buf.String())
+	}
+}
@@ -0,0 +1,390 @@
+package go11y
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// WebhookSent is the outcome label of a notification the webhook accepted
+	WebhookSent = "sent"
+	// WebhookFailed is the outcome label of a notification the webhook still rejected, or couldn't be reached for,
+	// after every retry
+	WebhookFailed = "failed"
+	// WebhookRateLimited is the outcome label of a notification not sent because the sink's rate limit was reached
+	WebhookRateLimited = "rate_limited"
+	// WebhookQueueFull is the outcome label of a notification not sent because the sink's queue was full
+	WebhookQueueFull = "queue_full"
+)
+
+// WebhookTemplateText is the template of notifications with the alert as a "text" field, as accepted by the incoming
+// webhooks of Slack and Microsoft Teams
+const WebhookTemplateText = `{"text": {{ json (printf "[%s] %s: %s" .Severity .Service .Message) }}` +
+	`{{ if .Error }}, "attachments": [{"text": {{ json .Error }}}]{{ end }}}`
+
+// DefaultWebhookRateLimit is the number of notifications a WebhookSink sends per DefaultWebhookRateInterval, unless
+// its options set their own
+const DefaultWebhookRateLimit = 10
+
+// DefaultWebhookRateInterval is the interval the rate limit of a WebhookSink applies to, unless its options set their
+// own
+const DefaultWebhookRateInterval = time.Minute
+
+// WebhookNotifications is the metric for the number of notifications of the errors logged that a WebhookSink tried to
+// send, by outcome (WebhookSent, WebhookFailed, WebhookRateLimited or WebhookQueueFull)
+var WebhookNotifications = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "go11y_webhook_notifications_total",
+	Help: "Number of notifications of errors sent to webhooks",
+}, []string{"outcome"})
+
+var registerWebhookMetricsOnce sync.Once
+
+func registerWebhookMetrics() {
+	registerWebhookMetricsOnce.Do(func() {
+		registerCollectors(WebhookNotifications)
+	})
+}
+
+// WebhookTemplatePagerDuty returns the template of notifications sent as PagerDuty Events API v2 trigger events (to
+// https://events.pagerduty.com/v2/enqueue) with the integration key $routingKey, deduplicated by message and service.
+func WebhookTemplatePagerDuty(routingKey string) string {
+	key, _ := json.Marshal(routingKey)
+
+	return `{"routing_key": ` + string(key) + `, "event_action": "trigger",` +
+		` "dedup_key": {{ json (printf "%s/%s" .Service .Message) }},` +
+		` "payload": {"summary": {{ json (printf "%s: %s" .Message .Error) }}, "source": {{ json .Service }},` +
+		` "severity": {{ if eq .Level "FATAL" "PANIC" }}"critical"{{ else }}"error"{{ end }},` +
+		` "timestamp": {{ json .Time }}, "custom_details": {{ json .Fields }}}}`
+}
+
+// WebhookAlert is an error logged at or above the minimum severity of a WebhookSink, as passed to its template
+type WebhookAlert struct {
+	Message  string
+	Error    string
+	Severity string
+	Level    string
+	Time     string
+	Service  string
+	TraceID  string
+	Fields   map[string]any // every field of the record, redacted as it was logged
+}
+
+// WebhookSinkOpts are the options used to create a WebhookSink
+type WebhookSinkOpts struct {
+	URL string // the webhook notifications are POSTed to
+
+	// optional - errors logged at a lower severity (see Error) aren't notified, defaults to SeverityHigh. Severities
+	// that aren't built in rank as SeverityLowest.
+	MinSeverity string
+	// optional - a text/template executed with a WebhookAlert to make the JSON body of notifications, defaults to
+	// WebhookTemplateText. The json function formats a value as JSON, e.g. {{ json .Message }}.
+	Template string
+	// optional - the number of notifications sent per RateInterval, the others are dropped, defaults to
+	// DefaultWebhookRateLimit
+	RateLimit int
+	// optional - the interval RateLimit applies to, defaults to DefaultWebhookRateInterval
+	RateInterval time.Duration
+	// optional - the number of times a notification is retried when the webhook can't be reached or responds with a
+	// 429 or 5xx, defaults to 3. Set it below 0 to never retry.
+	Retries int
+	// optional - the wait before the first retry, doubled for each retry after it, defaults to a second
+	RetryBackoff time.Duration
+	// optional - the number of notifications waiting to be sent, those notified while it is full are dropped, defaults
+	// to 100
+	QueueSize int
+	// optional - the client notifications are sent with, defaults to a client with a 10s timeout recording the go11y
+	// outbound metrics. A client that logs failed calls at Error level (see AddLogging) notifies its own failures.
+	Client *HTTPClient
+}
+
+// WebhookSink notifies a webhook, such as a Slack channel or PagerDuty, of the errors logged at or above a minimum
+// severity. It is an io.Writer of JSON records, added to an Observer with WithSinks(w.Sink()). Notifications are sent
+// in the background, rate limited and retried, and the outcome of each is counted in the WebhookNotifications metric;
+// failures aren't logged, as they would be notified in turn. Close it when the Observer is closed.
+type WebhookSink struct {
+	url          string
+	minRank      int
+	tmpl         *template.Template
+	rateLimit    int
+	rateInterval time.Duration
+	retries      int
+	backoff      time.Duration
+	client       *HTTPClient
+
+	mu          sync.Mutex
+	windowStart time.Time
+	windowCount int
+	closed      bool
+
+	queue   chan WebhookAlert
+	pending sync.WaitGroup
+	done    chan struct{}
+}
+
+// NewWebhookSink creates a WebhookSink with $opts, starting the goroutine that sends its notifications. It returns an
+// error if the URL is missing or the template can't be parsed.
+func NewWebhookSink(opts WebhookSinkOpts) (sink *WebhookSink, fault error) {
+	if opts.URL == "" {
+		return nil, errors.New("could not create webhook sink: no URL given")
+	}
+
+	if opts.MinSeverity == "" {
+		opts.MinSeverity = SeverityHigh
+	}
+
+	if opts.Template == "" {
+		opts.Template = WebhookTemplateText
+	}
+
+	tmpl, err := template.New("webhook").Funcs(template.FuncMap{"json": templateJSON}).Parse(opts.Template)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse webhook template: %w", err)
+	}
+
+	if opts.RateLimit <= 0 {
+		opts.RateLimit = DefaultWebhookRateLimit
+	}
+
+	if opts.RateInterval <= 0 {
+		opts.RateInterval = DefaultWebhookRateInterval
+	}
+
+	switch {
+	case opts.Retries == 0:
+		opts.Retries = 3
+	case opts.Retries < 0:
+		opts.Retries = 0
+	}
+
+	if opts.RetryBackoff <= 0 {
+		opts.RetryBackoff = time.Second
+	}
+
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = 100
+	}
+
+	if opts.Client == nil {
+		opts.Client = &HTTPClient{Client: &http.Client{Timeout: 10 * time.Second}}
+		if err := opts.Client.AddHostMetrics(PrometheusMetricsRecorder(), nil); err != nil {
+			return nil, fmt.Errorf("could not instrument webhook client: %w", err)
+		}
+	}
+
+	registerWebhookMetrics()
+
+	sink = &WebhookSink{
+		url:          opts.URL,
+		minRank:      severityRank(opts.MinSeverity),
+		tmpl:         tmpl,
+		rateLimit:    opts.RateLimit,
+		rateInterval: opts.RateInterval,
+		retries:      opts.Retries,
+		backoff:      opts.RetryBackoff,
+		client:       opts.Client,
+		queue:        make(chan WebhookAlert, opts.QueueSize),
+		done:         make(chan struct{}),
+	}
+
+	go sink.run()
+
+	return sink, nil
+}
+
+// Sink returns the Sink to add to an Observer with WithSinks, receiving the JSON records at or above LevelError
+func (w *WebhookSink) Sink() Sink {
+	return Sink{Writer: w, MinLevel: LevelError, Format: SinkFormatJSON}
+}
+
+// Write queues a notification of the JSON record $p if it is an error at or above the minimum severity and the rate
+// limit hasn't been reached. It never blocks on the webhook, and never fails.
+func (w *WebhookSink) Write(p []byte) (n int, fault error) {
+	record := map[string]any{}
+	if err := json.Unmarshal(p, &record); err != nil {
+		return len(p), nil
+	}
+
+	severity, _ := recordField(record, "severity").(string)
+	if severity == "" || severityRank(severity) < w.minRank {
+		return len(p), nil
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return len(p), nil
+	}
+
+	now := time.Now()
+	if now.Sub(w.windowStart) >= w.rateInterval {
+		w.windowStart, w.windowCount = now, 0
+	}
+
+	if w.windowCount >= w.rateLimit {
+		WebhookNotifications.WithLabelValues(WebhookRateLimited).Inc()
+		return len(p), nil
+	}
+
+	w.pending.Add(1)
+	select {
+	case w.queue <- newWebhookAlert(record, severity):
+		w.windowCount++
+	default:
+		w.pending.Done()
+		WebhookNotifications.WithLabelValues(WebhookQueueFull).Inc()
+	}
+
+	return len(p), nil
+}
+
+// Flush blocks until every notification queued so far has been sent or has failed.
+func (w *WebhookSink) Flush() error {
+	w.pending.Wait()
+	return nil
+}
+
+// Close sends the notifications already queued and stops the goroutine sending them. Records written after Close
+// aren't notified.
+func (w *WebhookSink) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+
+	w.closed = true
+	close(w.queue)
+	w.mu.Unlock()
+
+	<-w.done
+
+	return nil
+}
+
+func (w *WebhookSink) run() {
+	defer close(w.done)
+
+	for alert := range w.queue {
+		outcome := WebhookSent
+		if err := w.send(alert); err != nil {
+			outcome = WebhookFailed
+		}
+
+		WebhookNotifications.WithLabelValues(outcome).Inc()
+		w.pending.Done()
+	}
+}
+
+// send POSTs the notification of $alert, retrying with exponential backoff while the webhook can't be reached or
+// responds with a 429 or 5xx
+func (w *WebhookSink) send(alert WebhookAlert) (fault error) {
+	body := new(bytes.Buffer)
+	if err := w.tmpl.Execute(body, alert); err != nil {
+		return fmt.Errorf("could not execute webhook template: %w", err)
+	}
+
+	backoff := w.backoff
+	for attempt := 0; ; attempt++ {
+		retry, err := w.post(body.Bytes())
+		if err == nil {
+			return nil
+		}
+
+		if !retry || attempt >= w.retries {
+			return err
+		}
+
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// post POSTs $body to the webhook, returning whether a failure is worth retrying
+func (w *WebhookSink) post(body []byte) (retry bool, fault error) {
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("could not create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("could not post to webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	switch {
+	case resp.StatusCode < http.StatusMultipleChoices:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError:
+		return true, fmt.Errorf("webhook responded with %s", resp.Status)
+	default:
+		return false, fmt.Errorf("webhook responded with %s", resp.Status)
+	}
+}
+
+// newWebhookAlert returns the alert of the error $record logged at $severity
+func newWebhookAlert(record map[string]any, severity string) WebhookAlert {
+	str := func(field string) string {
+		s, _ := recordField(record, field).(string)
+		return s
+	}
+
+	return WebhookAlert{
+		Message:  str("msg"),
+		Error:    str("error"),
+		Severity: severity,
+		Level:    str("level"),
+		Time:     str("time"),
+		Service:  str(FieldServiceName),
+		TraceID:  str(FieldTraceID),
+		Fields:   record,
+	}
+}
+
+// recordField returns the value of $field in the JSON $record, whichever FieldSchema it was written with
+func recordField(record map[string]any, field string) any {
+	for _, schema := range []FieldSchema{SchemaGo11y, SchemaECS, SchemaOTel} {
+		if v, ok := record[schema.Name(field)]; ok {
+			return v
+		}
+	}
+
+	return nil
+}
+
+// severityRank ranks the built-in severities from SeverityLowest (0) to SeverityHighest, other severities rank as
+// SeverityLowest
+func severityRank(severity string) int {
+	switch strings.ToLower(severity) {
+	case SeverityLow:
+		return 1
+	case SeverityMedium:
+		return 2
+	case SeverityHigh:
+		return 3
+	case SeverityHighest:
+		return 4
+	default:
+		return 0
+	}
+}
+
+// templateJSON formats $v as JSON for webhook templates
+func templateJSON(v any) (string, error) {
+	b, err := json.Marshal(v)
+	return string(b), err
+}
@@ -0,0 +1,133 @@
+package go11y_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cirruscomms/go11y"
+)
+
+func TestWebhookSink(t *testing.T) {
+	t.Setenv("ENV", "test")
+
+	var mu sync.Mutex
+	var bodies []map[string]any
+	calls := 0
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		blob, _ := io.ReadAll(r.Body)
+		body := map[string]any{}
+		if err := json.Unmarshal(blob, &body); err != nil {
+			t.Errorf("expected a JSON body, got %s: %v", blob, err)
+		}
+		bodies = append(bodies, body)
+	}))
+	defer srv.Close()
+
+	webhook, err := go11y.NewWebhookSink(go11y.WebhookSinkOpts{
+		URL:          srv.URL,
+		RateLimit:    2,
+		RetryBackoff: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("failed to create webhook sink: %v", err)
+	}
+
+	cfg := go11y.CreateConfig(go11y.LevelInfo, "", "", "", []string{}, []string{})
+
+	_, o, err := go11y.Initialise(context.Background(), cfg, io.Discard, io.Discard,
+		go11y.WithSinks(webhook.Sink()), go11y.FieldServiceName, "orders")
+	if err != nil {
+		t.Fatalf("failed to initialise observer: %v", err)
+	}
+
+	o.Warning("warning message")
+	o.Error("low error", errors.New("not notified"), go11y.SeverityLow)
+	o.Error("payment failed", errors.New("card declined"), go11y.SeverityHigh)
+	o.Error("ledger failed", errors.New("timeout"), go11y.SeverityHighest)
+	o.Error("rate limited", errors.New("dropped"), go11y.SeverityHighest)
+
+	if err := webhook.Close(); err != nil {
+		t.Fatalf("failed to close webhook sink: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if calls != 3 {
+		t.Errorf("expected 2 notifications and a retry, got %d calls", calls)
+	}
+
+	if len(bodies) != 2 {
+		t.Fatalf("expected 2 notifications, got %v", bodies)
+	}
+
+	if bodies[0]["text"] != "[high] orders: payment failed" {
+		t.Errorf("unexpected notification text: %v", bodies[0]["text"])
+	}
+
+	if bodies[1]["text"] != "[highest] orders: ledger failed" {
+		t.Errorf("unexpected notification text: %v", bodies[1]["text"])
+	}
+}
+
+func TestWebhookSinkTemplate(t *testing.T) {
+	received := make(chan map[string]any, 1)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := map[string]any{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		received <- body
+	}))
+	defer srv.Close()
+
+	if _, err := go11y.NewWebhookSink(go11y.WebhookSinkOpts{URL: srv.URL, Template: "{{ .Missing"}); err == nil {
+		t.Errorf("expected an invalid template to be rejected")
+	}
+
+	webhook, err := go11y.NewWebhookSink(go11y.WebhookSinkOpts{
+		URL:         srv.URL,
+		MinSeverity: go11y.SeverityLow,
+		Template:    go11y.WebhookTemplatePagerDuty("routing-key"),
+	})
+	if err != nil {
+		t.Fatalf("failed to create webhook sink: %v", err)
+	}
+	defer webhook.Close()
+
+	record := `{"time":"2026-01-01T00:00:00Z","level":"ERR","msg":"payment \"failed\"","error":"declined",` +
+		`"severity":"medium","service.name":"orders"}`
+	if _, err := webhook.Write([]byte(record)); err != nil {
+		t.Fatalf("failed to write record: %v", err)
+	}
+
+	if err := webhook.Flush(); err != nil {
+		t.Fatalf("failed to flush webhook sink: %v", err)
+	}
+
+	body := <-received
+	if body["routing_key"] != "routing-key" || body["event_action"] != "trigger" {
+		t.Errorf("unexpected PagerDuty event: %v", body)
+	}
+
+	payload, _ := body["payload"].(map[string]any)
+	if payload["summary"] != `payment "failed": declined` || payload["source"] != "orders" {
+		t.Errorf("unexpected PagerDuty payload: %v", payload)
+	}
+}
@@ -1,6 +1,7 @@
 package go11y
 
 import (
+	"mime"
 	"net/http"
 )
 
@@ -8,9 +9,10 @@ import (
 // It implements the http.ResponseWriter interface and optionally the http.Flusher interface if the underlying writer
 // supports it.
 type HTTPWriter struct {
-	http       http.ResponseWriter // wrap an existing writer
-	statusCode int                 // capture the status code for logging
-	body       []byte              // capture the response body for logging
+	http         http.ResponseWriter // wrap an existing writer
+	statusCode   int                 // capture the status code for logging
+	body         []byte              // capture the response body for logging
+	bytesWritten int64
 }
 
 // Header returns the header map that will be sent by WriteHeader.
@@ -19,9 +21,16 @@ func (w *HTTPWriter) Header() http.Header {
 }
 
 // Write writes the data to the connection as part of an HTTP reply.
+// The bodies of event streams (see SSEWriter) are not captured, as they last as long as the connection.
 func (w *HTTPWriter) Write(data []byte) (int, error) {
-	w.body = append(w.body, data...) // capture the response body for logging
-	return w.http.Write(data)
+	n, err := w.http.Write(data)
+	w.bytesWritten += int64(n)
+
+	if !isEventStream(w.Header()) {
+		w.body = append(w.body, data[:n]...) // capture the response body for logging
+	}
+
+	return n, err
 }
 
 // WriteHeader sends an HTTP response header with the provided status code.
@@ -30,6 +39,26 @@ func (w *HTTPWriter) WriteHeader(statusCode int) {
 	w.http.WriteHeader(statusCode)
 }
 
+// StatusCode returns the status code written, http.StatusOK if WriteHeader hasn't been called
+func (w *HTTPWriter) StatusCode() int {
+	if w.statusCode == 0 {
+		return http.StatusOK
+	}
+
+	return w.statusCode
+}
+
+// BytesWritten returns the number of bytes of the response body written
+func (w *HTTPWriter) BytesWritten() int64 {
+	return w.bytesWritten
+}
+
+// isEventStream reports whether $header is that of a Server-Sent Events response
+func isEventStream(header http.Header) bool {
+	mediaType, _, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	return mediaType == "text/event-stream"
+}
+
 // HTTPWriterFlusher is a wrapper around HTTPWriter that also implements the http.Flusher interface if the underlying
 // http.ResponseWriter supports it. This allows us to use the Flush method to flush the response buffer when needed.
 type HTTPWriterFlusher struct {